@@ -26,6 +26,7 @@ import (
 	"golang.org/x/pkgsite/internal/fetchdatasource"
 	"golang.org/x/pkgsite/internal/frontend"
 	"golang.org/x/pkgsite/internal/frontend/fetchserver"
+	"golang.org/x/pkgsite/internal/localcache"
 	"golang.org/x/pkgsite/internal/log"
 	"golang.org/x/pkgsite/internal/middleware"
 	"golang.org/x/pkgsite/internal/middleware/timeout"
@@ -34,6 +35,7 @@ import (
 	"golang.org/x/pkgsite/internal/queue"
 	"golang.org/x/pkgsite/internal/queue/gcpqueue"
 	"golang.org/x/pkgsite/internal/source"
+	"golang.org/x/pkgsite/internal/sqlite"
 	"golang.org/x/pkgsite/internal/static"
 	"golang.org/x/pkgsite/internal/trace"
 	"golang.org/x/pkgsite/internal/vuln"
@@ -55,6 +57,8 @@ var (
 		"as a direct backend, bypassing the database")
 	bypassLicenseCheck = flag.Bool("bypass_license_check", false, "display all information, even for non-redistributable paths")
 	hostAddr           = flag.String("host", "localhost:8080", "Host address for the server")
+	sqlitePath         = flag.String("sqlite", "", "if set, path to a SQLite database file used instead of Postgres; "+
+		"modules are fetched from proxy_url on demand, stored there and served from it")
 )
 
 func main() {
@@ -65,6 +69,8 @@ func main() {
 		log.Fatal(ctx, err)
 	}
 	cfg.Dump(os.Stderr)
+	cmdconfig.BuildContexts(ctx, cfg)
+	cmdconfig.LicensePolicy(ctx, cfg)
 	if cfg.UseProfiler {
 		if err := profiler.Start(profiler.Config{}); err != nil {
 			log.Fatalf(ctx, "profiler.Start: %v", err)
@@ -99,6 +105,25 @@ func main() {
 			BypassLicenseCheck:   *bypassLicenseCheck,
 		}.New()
 		dsg = func(context.Context) internal.DataSource { return ds }
+	} else if *sqlitePath != "" {
+		open := sqlite.Open
+		if *bypassLicenseCheck {
+			open = sqlite.OpenBypassingLicenseCheck
+		}
+		db, err := open(ctx, *sqlitePath)
+		if err != nil {
+			log.Fatalf(ctx, "sqlite.Open: %v", err)
+		}
+		defer db.Close()
+		dsg = func(context.Context) internal.DataSource { return db }
+		sourceClient := source.NewClient(&http.Client{
+			Transport: new(ochttp.Transport),
+			Timeout:   config.SourceTimeout,
+		})
+		fetchQueue = queue.NewInMemory(ctx, *workers, nil,
+			func(ctx context.Context, modulePath, version string) (int, error) {
+				return fetchserver.FetchAndUpdateState(ctx, modulePath, version, proxyClient, sourceClient, db)
+			})
 	} else {
 		db, err := cmdconfig.OpenDB(ctx, cfg, *bypassLicenseCheck)
 		if err != nil {
@@ -183,6 +208,13 @@ func main() {
 			log.Infof(ctx, "connected to redis at %s", addr)
 		}
 		cacher = middleware.NewCacher(redisClient)
+	} else if cfg.LocalCacheMi > 0 {
+		s, err := localcache.New(int64(cfg.LocalCacheMi)<<20, cfg.LocalCacheDir)
+		if err != nil {
+			log.Fatalf(ctx, "local page cache: %v", err)
+		}
+		log.Infof(ctx, "caching up to %d MiB of pages in memory", cfg.LocalCacheMi)
+		cacher = middleware.NewCacherFromStore(s)
 	}
 	server.Install(router.Handle, cacher, cfg.AuthValues)
 	views := append(dcensus.ServerViews,
@@ -22,6 +22,7 @@ import (
 	"golang.org/x/pkgsite/internal/config/serverconfig"
 	"golang.org/x/pkgsite/internal/database"
 	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/licenses"
 	"golang.org/x/pkgsite/internal/log"
 	"golang.org/x/pkgsite/internal/log/stackdriverlogger"
 	"golang.org/x/pkgsite/internal/middleware"
@@ -88,6 +89,46 @@ func Experimenter(ctx context.Context, cfg *config.Config, getter middleware.Exp
 	return e
 }
 
+// BuildContexts sets the build contexts and build tags used for documentation
+// from the config. If the config lists no build contexts, the defaults are kept.
+func BuildContexts(ctx context.Context, cfg *config.Config) {
+	if len(cfg.BuildContexts) == 0 && len(cfg.BuildTags) == 0 {
+		return
+	}
+	bcs := internal.BuildContexts
+	if len(cfg.BuildContexts) > 0 {
+		bcs = nil
+		for _, s := range cfg.BuildContexts {
+			bc, err := internal.ParseBuildContext(s)
+			if err != nil {
+				log.Fatal(ctx, err)
+			}
+			bcs = append(bcs, bc)
+		}
+	}
+	if err := internal.SetBuildContexts(bcs, cfg.BuildTags); err != nil {
+		log.Fatal(ctx, err)
+	}
+	log.Infof(ctx, "build contexts: %v; build tags: %v", internal.BuildContexts, internal.BuildTags)
+}
+
+// LicensePolicy loads the license policy file named in the config, if any, and
+// makes it the active policy.
+func LicensePolicy(ctx context.Context, cfg *config.Config) {
+	if cfg.LicensePolicyFile == "" {
+		return
+	}
+	p, err := licenses.ReadPolicy(cfg.LicensePolicyFile)
+	if err != nil {
+		log.Fatal(ctx, err)
+	}
+	if err := licenses.SetPolicy(p); err != nil {
+		log.Fatal(ctx, err)
+	}
+	log.Infof(ctx, "license policy: accepted types %v; module prefixes %v; %d custom licenses",
+		p.AcceptedTypes, p.ModulePrefixes, len(p.Licenses))
+}
+
 // ExperimentGetter returns an ExperimentGetter using the config.
 func ExperimentGetter(ctx context.Context, cfg *config.Config) middleware.ExperimentGetter {
 	if cfg.DynamicConfigLocation == "" {
@@ -117,7 +158,8 @@ func ExperimentGetter(ctx context.Context, cfg *config.Config) middleware.Experi
 	}
 }
 
-// OpenDB opens the postgres database specified by the config.
+// OpenDB opens the postgres database specified by the config, and checks
+// that documentation can be stored for the configured build contexts.
 // It first tries the main connection info (DBConnInfo), and if that fails, it uses backup
 // connection info it if exists (DBSecondaryConnInfo).
 func OpenDB(ctx context.Context, cfg *config.Config, bypassLicenseCheck bool) (_ *postgres.DB, err error) {
@@ -147,8 +189,15 @@ func OpenDB(ctx context.Context, cfg *config.Config, bypassLicenseCheck bool) (_
 		log.Infof(ctx, "connected to secondary host %s", cfg.DBSecondaryHost)
 	}
 	log.Infof(ctx, "database open finished")
+	var db *postgres.DB
 	if bypassLicenseCheck {
-		return postgres.NewBypassingLicenseCheck(ddb), nil
+		db = postgres.NewBypassingLicenseCheck(ddb)
+	} else {
+		db = postgres.New(ddb)
+	}
+	if err := db.CheckBuildContexts(ctx, internal.BuildContexts); err != nil {
+		db.Close()
+		return nil, err
 	}
-	return postgres.New(ddb), nil
+	return db, nil
 }
@@ -27,6 +27,8 @@ import (
 	"golang.org/x/pkgsite/internal/log"
 	"golang.org/x/pkgsite/internal/proxy"
 	"golang.org/x/pkgsite/internal/source"
+	"golang.org/x/pkgsite/internal/stdlib"
+	"golang.org/x/pkgsite/internal/vuln"
 	"golang.org/x/pkgsite/static"
 	thirdparty "golang.org/x/pkgsite/third_party"
 )
@@ -42,6 +44,11 @@ type ServerConfig struct {
 	DevMode          bool
 	DevModeStaticDir string
 	GoRepoPath       string
+	GopRepoPath      string // path to a local clone of the Go+ repo, or empty
+	DocCacheDir      string // directory for the on-disk cache of fetched modules, or empty
+	TypeCheck        bool   // type-check packages to link identifiers in declarations exactly
+	VerifyExamples   bool   // run examples with output comments to verify their output
+	VulnDB           string // vulnerability database directory or zip file, or empty
 
 	Proxy *proxy.Client // client, or nil; controlled by the -proxy flag
 }
@@ -53,14 +60,16 @@ func BuildServer(ctx context.Context, serverCfg ServerConfig) (*frontend.Server,
 	}
 
 	cfg := getterConfig{
-		all:        serverCfg.UseListedMods,
-		proxy:      serverCfg.Proxy,
-		goRepoPath: serverCfg.GoRepoPath,
+		all:         serverCfg.UseListedMods,
+		proxy:       serverCfg.Proxy,
+		goRepoPath:  serverCfg.GoRepoPath,
+		gopRepoPath: serverCfg.GopRepoPath,
 	}
 
 	// By default, the requested Paths are interpreted as directories. However,
 	// if -gopath_mode is set, they are interpreted as relative Paths to modules
 	// in a GOPATH directory.
+	var workspaces []frontend.LocalWorkspace
 	if serverCfg.GOPATHMode {
 		var err error
 		cfg.dirs, err = getGOPATHModuleDirs(ctx, serverCfg.Paths)
@@ -73,6 +82,10 @@ func BuildServer(ctx context.Context, serverCfg ServerConfig) (*frontend.Server,
 		if err != nil {
 			return nil, fmt.Errorf("searching GOPATH: %v", err)
 		}
+		workspaces, err = getWorkspaces(ctx, cfg.dirs)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if serverCfg.UseCache {
@@ -114,7 +127,22 @@ func BuildServer(ctx context.Context, serverCfg ServerConfig) (*frontend.Server,
 		return allModules[i].ModulePath < allModules[j].ModulePath
 	})
 
-	return newServer(getters, allModules, cfg.proxy, serverCfg.DevMode, serverCfg.DevModeStaticDir)
+	var vc *vuln.Client
+	if serverCfg.VulnDB != "" {
+		vc, err = vuln.NewLocalClient(serverCfg.VulnDB)
+		if err != nil {
+			return nil, fmt.Errorf("loading vulnerability database: %v", err)
+		}
+	}
+
+	// Collect the build lists of the modules to check their dependencies for
+	// vulnerabilities. Modules in GOPATH mode don't have build lists.
+	var buildLists []*frontend.LocalBuildList
+	if vc != nil && !serverCfg.GOPATHMode {
+		buildLists = getBuildLists(ctx, allModules)
+	}
+
+	return newServer(getters, allModules, workspaces, buildLists, cfg.proxy, vc, serverCfg.DocCacheDir, serverCfg.TypeCheck, serverCfg.VerifyExamples, serverCfg.DevMode, serverCfg.DevModeStaticDir)
 }
 
 // getModuleDirs returns the set of workspace modules for each directory,
@@ -150,6 +178,137 @@ func getModuleDirs(ctx context.Context, dirs []string) (map[string][]frontend.Lo
 	return dirModules, nil
 }
 
+// getWorkspaces returns the go.work workspaces that contain the given
+// directories, along with the modules each one uses.
+//
+// Running go list -m in a workspace lists exactly the modules in its use
+// directives, so the modules of a workspace are those reported by
+// getModuleDirs for any directory inside it.
+func getWorkspaces(ctx context.Context, dirModules map[string][]frontend.LocalModule) ([]frontend.LocalWorkspace, error) {
+	var workspaces []frontend.LocalWorkspace
+	seen := make(map[string]bool)
+	for dir, modules := range dirModules {
+		out, err := runGo(dir, "env", "GOWORK")
+		if err != nil {
+			return nil, fmt.Errorf("finding workspace for %s: %v", dir, err)
+		}
+		gowork := strings.TrimSpace(string(out))
+		if gowork == "" || gowork == "off" || seen[gowork] {
+			continue
+		}
+		seen[gowork] = true
+		mods := append([]frontend.LocalModule(nil), modules...)
+		sort.Slice(mods, func(i, j int) bool {
+			return mods[i].ModulePath < mods[j].ModulePath
+		})
+		workspaces = append(workspaces, frontend.LocalWorkspace{GoWork: gowork, Modules: mods})
+	}
+	sort.Slice(workspaces, func(i, j int) bool {
+		return workspaces[i].GoWork < workspaces[j].GoWork
+	})
+	return workspaces, nil
+}
+
+// getBuildLists returns the build lists of the given modules, determined by
+// running go list -m all, along with the imports of their packages.
+//
+// Failures are logged, and the modules for which they occur are skipped, so
+// that the modules can be served without their vulnerability reports.
+func getBuildLists(ctx context.Context, modules []frontend.LocalModule) []*frontend.LocalBuildList {
+	var buildLists []*frontend.LocalBuildList
+	for _, m := range modules {
+		bl, err := getBuildList(m)
+		if err != nil {
+			log.Errorf(ctx, "getting build list of %s: %v", m.ModulePath, err)
+			continue
+		}
+		buildLists = append(buildLists, bl)
+	}
+	return buildLists
+}
+
+func getBuildList(m frontend.LocalModule) (*frontend.LocalBuildList, error) {
+	bl := &frontend.LocalBuildList{ModulePath: m.ModulePath}
+	out, err := runGo(m.Dir, "list", "-m", "-e", "-json", "all")
+	if err != nil {
+		return nil, err
+	}
+	bl.Deps, err = parseBuildList(out)
+	if err != nil {
+		return nil, err
+	}
+	out, err = runGo(m.Dir, "list", "-e", "-json", "./...")
+	if err != nil {
+		return nil, err
+	}
+	bl.Imports, err = parsePackageImports(m.ModulePath, out)
+	if err != nil {
+		return nil, err
+	}
+	out, err = runGo(m.Dir, "env", "GOVERSION")
+	if err != nil {
+		return nil, err
+	}
+	// Development versions of Go, like "devel go1.22-1a2b3c4", have no
+	// standard library version, so the standard library isn't checked.
+	bl.GoVersion = stdlib.VersionForTag(strings.TrimSpace(string(out)))
+	return bl, nil
+}
+
+// parseBuildList parses the output of go list -m -json all into the
+// dependencies of the main modules, sorted by path.
+func parseBuildList(out []byte) ([]*internal.Requirement, error) {
+	type module struct {
+		Path     string
+		Version  string
+		Main     bool
+		Indirect bool
+		Replace  *module
+	}
+	var deps []*internal.Requirement
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	for decoder.More() {
+		var m module
+		if err := decoder.Decode(&m); err != nil {
+			return nil, err
+		}
+		if m.Main {
+			continue
+		}
+		v := m.Version
+		if m.Replace != nil {
+			// The version of a replacement by a directory is empty.
+			v = m.Replace.Version
+		}
+		deps = append(deps, &internal.Requirement{ModulePath: m.Path, Version: v, Indirect: m.Indirect})
+	}
+	sort.Slice(deps, func(i, j int) bool { return deps[i].ModulePath < deps[j].ModulePath })
+	return deps, nil
+}
+
+// parsePackageImports parses the output of go list -json into a map from the
+// paths of the packages of the module to the paths of their imports.
+func parsePackageImports(modulePath string, out []byte) (map[string][]string, error) {
+	type pkg struct {
+		ImportPath string
+		Module     *struct{ Path string }
+		Imports    []string
+	}
+	imports := map[string][]string{}
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	for decoder.More() {
+		var p pkg
+		if err := decoder.Decode(&p); err != nil {
+			return nil, err
+		}
+		if p.Module == nil || p.Module.Path != modulePath {
+			continue
+		}
+		imports[p.ImportPath] = p.Imports
+	}
+	return imports, nil
+}
+
 // getGOPATHModuleDirs returns local module information for directories in
 // GOPATH corresponding to the requested module Paths.
 //
@@ -199,14 +358,16 @@ type getterConfig struct {
 	proxy          *proxy.Client                     // proxy client, or nil
 	useLocalStdlib bool                              // use go/packages for the local stdlib
 	goRepoPath     string                            // repo path for local stdlib
+	gopRepoPath    string                            // repo path for local Go+ module
 }
 
 // buildGetters constructs module getters based on the given configuration.
 //
 // Getters are returned in the following priority order:
 //  1. local getters for cfg.dirs, in the given order
-//  2. a module cache getter, if cfg.modCacheDir != ""
-//  3. a proxy getter, if cfg.proxy != nil
+//  2. a local getter for the Go+ repo, if cfg.gopRepoPath != ""
+//  3. a module cache getter, if cfg.modCacheDir != ""
+//  4. a proxy getter, if cfg.proxy != nil
 func buildGetters(ctx context.Context, cfg getterConfig) ([]fetch.ModuleGetter, error) {
 	var getters []fetch.ModuleGetter
 
@@ -217,7 +378,7 @@ func buildGetters(ctx context.Context, cfg getterConfig) ([]fetch.ModuleGetter,
 			patterns = append(patterns, "all")
 		} else {
 			for _, m := range modules {
-				patterns = append(patterns, fmt.Sprintf("%s/...", m))
+				patterns = append(patterns, fmt.Sprintf("%s/...", m.ModulePath))
 			}
 		}
 		mg, err := fetch.NewGoPackagesModuleGetter(ctx, dir, patterns...)
@@ -231,6 +392,15 @@ func buildGetters(ctx context.Context, cfg getterConfig) ([]fetch.ModuleGetter,
 		return nil, fmt.Errorf("failed to load any module(s) at %v", cfg.dirs)
 	}
 
+	// Add a getter for the local Go+ repo.
+	if cfg.gopRepoPath != "" {
+		mg, err := fetch.NewGoPackagesModuleGetter(ctx, cfg.gopRepoPath, stdlib.GopModulePath+"/...")
+		if err != nil {
+			return nil, fmt.Errorf("loading packages from Go+ repo %s: %v", cfg.gopRepoPath, err)
+		}
+		getters = append(getters, mg)
+	}
+
 	// Add a getter for the local module cache.
 	if cfg.modCacheDir != "" {
 		g, err := fetch.NewModCacheGetter(cfg.modCacheDir)
@@ -276,11 +446,14 @@ func getGOROOT() string {
 	return strings.TrimSpace(string(b))
 }
 
-func newServer(getters []fetch.ModuleGetter, localModules []frontend.LocalModule, prox *proxy.Client, devMode bool, staticFlag string) (*frontend.Server, error) {
+func newServer(getters []fetch.ModuleGetter, localModules []frontend.LocalModule, workspaces []frontend.LocalWorkspace, buildLists []*frontend.LocalBuildList, prox *proxy.Client, vc *vuln.Client, docCacheDir string, typeCheck, verifyExamples bool, devMode bool, staticFlag string) (*frontend.Server, error) {
 	lds := fetchdatasource.Options{
 		Getters:              getters,
 		ProxyClientForLatest: prox,
 		BypassLicenseCheck:   true,
+		CacheDir:             docCacheDir,
+		TypeCheck:            typeCheck,
+		VerifyExamples:       verifyExamples,
 	}.New()
 
 	// In dev mode, use a dirFS to pick up template/JS/CSS changes without
@@ -305,7 +478,10 @@ func newServer(getters []fetch.ModuleGetter, localModules []frontend.LocalModule
 		DevMode:          devMode,
 		LocalMode:        true,
 		LocalModules:     localModules,
+		LocalWorkspaces:  workspaces,
+		LocalBuildLists:  buildLists,
 		ThirdPartyFS:     thirdparty.FS,
+		VulndbClient:     vc,
 	})
 	if err != nil {
 		return nil, err
@@ -313,7 +489,7 @@ func newServer(getters []fetch.ModuleGetter, localModules []frontend.LocalModule
 	for _, g := range getters {
 		p, fsys := g.SourceFS()
 		if p != "" {
-			server.InstallFS(p, fsys)
+			server.InstallFS(p, fsys, g.SourceImportPath)
 		}
 	}
 	return server, nil
@@ -12,9 +12,13 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"golang.org/x/net/html"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/godoc/dochtml"
 	"golang.org/x/pkgsite/internal/proxy/proxytest"
 	"golang.org/x/pkgsite/internal/testenv"
 	"golang.org/x/pkgsite/internal/testing/htmlcheck"
@@ -50,6 +54,43 @@ func TestServer(t *testing.T) {
 module example.com/testmod
 -- a.go --
 package a
+`)
+	workspace, _ := testhelper.WriteTxtarToTempDir(t, `
+-- go.work --
+go 1.19
+
+use (
+	.
+	./sub
+)
+-- go.mod --
+module example.com/ws
+-- a.go --
+// Package a frobnicates widgets.
+package a
+
+import "example.com/ws/sub/b"
+
+// A returns a B.
+func A() b.B { return b.B{} }
+-- sub/go.mod --
+module example.com/ws/sub
+-- sub/b/b.go --
+package b
+
+type B struct{}
+`)
+	vulnDB, _ := testhelper.WriteTxtarToTempDir(t, `
+-- GO-2023-0001.json --
+{
+  "id": "GO-2023-0001",
+  "modified": "2023-01-01T00:00:00Z",
+  "affected": [{
+    "package": {"name": "example.com/single", "ecosystem": "Go"},
+    "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}]}],
+    "ecosystem_specific": {"imports": [{"path": "example.com/single/pkg"}]}
+  }]
+}
 `)
 	cacheDir := repoPath("internal/fetch/testdata/modcache")
 	testModules := proxytest.LoadTestModules(repoPath("internal/proxy/testdata"))
@@ -92,6 +133,37 @@ package a
 				in(".Documentation", hasText("There is no documentation for this package.")),
 				sourceLinks(path.Join(filepath.ToSlash(abs(localModule)), "example.com/testmod"), "a.go")),
 		},
+		{
+			"workspace homepage",
+			cfg(func(c *ServerConfig) {
+				c.Paths = []string{workspace}
+			}),
+			"",
+			http.StatusOK,
+			in(`[aria-label="Workspace Modules"]`,
+				hasText(filepath.Join(abs(workspace), "go.work")),
+				in("li:nth-child(1) a", href("/example.com/ws")),
+				in("li:nth-child(2) a", href("/example.com/ws/sub"))),
+		},
+		{
+			"workspace nested module not in parent",
+			cfg(func(c *ServerConfig) {
+				c.Paths = []string{workspace}
+			}),
+			"example.com/ws@v0.0.0/sub/b",
+			http.StatusFailedDependency, // local mode has no path-not-found page
+			hasText("page is not supported"),
+		},
+		{
+			"workspace nested module",
+			cfg(func(c *ServerConfig) {
+				c.Paths = []string{workspace}
+				c.UseListedMods = false
+			}),
+			"example.com/ws/sub/b",
+			http.StatusOK,
+			in(".UnitMeta-repo a", href(path.Join("/files", filepath.ToSlash(abs(workspace)), "example.com/ws/sub")+"/")),
+		},
 		{
 			"modcache",
 			cfg(nil),
@@ -122,6 +194,44 @@ package a
 			http.StatusOK,
 			hasText("G is new in v1.1.0"),
 		},
+		{
+			"source view",
+			cfg(func(c *ServerConfig) {
+				c.Paths = []string{workspace}
+			}),
+			path.Join("files", filepath.ToSlash(abs(workspace)), "example.com/ws/a.go"),
+			http.StatusOK,
+			in(".Source-code",
+				in(`a[href="/example.com/ws#A"]`, hasText("A")),
+				in(`a[href="/example.com/ws/sub/b#B"]`, hasText("B"))),
+		},
+		{
+			"markdown",
+			cfg(func(c *ServerConfig) {
+				c.Paths = []string{workspace}
+			}),
+			"example.com/ws?format=md",
+			http.StatusOK,
+			hasText(regexp.QuoteMeta("# package a") + `(.|\n)*Package a frobnicates widgets\.(.|\n)*### func A`),
+		},
+		{
+			"plain text",
+			cfg(func(c *ServerConfig) {
+				c.Paths = []string{workspace}
+			}),
+			"example.com/ws?format=txt",
+			http.StatusOK,
+			hasText(regexp.QuoteMeta(`package a // import "example.com/ws"`) + `(.|\n)*FUNCTIONS`),
+		},
+		{
+			"unknown format",
+			cfg(func(c *ServerConfig) {
+				c.Paths = []string{workspace}
+			}),
+			"example.com/ws?format=pdf",
+			http.StatusBadRequest,
+			hasText("Unsupported documentation format"),
+		},
 		{
 			"proxy unsupported",
 			cfg(func(c *ServerConfig) {
@@ -143,14 +253,41 @@ package a
 			),
 		},
 		{
-			"no symbol search",
+			"symbol search",
 			cfg(func(c *ServerConfig) {
+				c.Paths = []string{workspace}
 				c.UseLocalStdlib = false
 			}),
-			"search?q=A", // using a capital letter should not cause symbol search
+			"search?q=A", // using a capital letter causes symbol search
 			http.StatusOK,
 			in(".SearchResults",
-				hasText("example.com/testmod"),
+				in(`[data-gtmc="symbol search result symbol"]`, href("/example.com/ws#A")),
+				in(".SearchSnippet-symbolCode", hasText(regexp.QuoteMeta("func A() b.B"))),
+			),
+		},
+		{
+			"symbol search with filter",
+			cfg(func(c *ServerConfig) {
+				c.Paths = []string{workspace}
+				c.UseLocalStdlib = false
+			}),
+			"search?q=%23B+sub",
+			http.StatusOK,
+			in(".SearchResults",
+				in(`[data-gtmc="symbol search result symbol"]`, href("/example.com/ws/sub/b#B")),
+			),
+		},
+		{
+			"full-text search",
+			cfg(func(c *ServerConfig) {
+				c.Paths = []string{workspace}
+				c.UseLocalStdlib = false
+			}),
+			"search?q=frobnicate+the+widget",
+			http.StatusOK,
+			in(".SearchResults",
+				in(".SearchSnippet-header-path", hasText("example.com/ws")),
+				hasText("Package a frobnicates widgets."),
 			),
 		},
 		{
@@ -190,6 +327,35 @@ package a
 			http.StatusFailedDependency,
 			hasText("page is not supported"),
 		},
+		{
+			"vulns",
+			cfg(func(c *ServerConfig) {
+				c.VulnDB = vulnDB
+			}),
+			"vuln/GO-2023-0001",
+			http.StatusOK,
+			hasText("example.com/single"),
+		},
+		{
+			"vuln banner",
+			cfg(func(c *ServerConfig) {
+				c.VulnDB = vulnDB
+			}),
+			"example.com/single/pkg",
+			http.StatusOK,
+			in(".go-Message--alert", hasText("GO-2023-0001")),
+		},
+		{
+			"versions",
+			cfg(func(c *ServerConfig) {
+				c.VulnDB = vulnDB
+			}),
+			"example.com/single/pkg?tab=versions",
+			http.StatusOK,
+			in(".Versions-list",
+				in(".js-versionLink", hasText("v1.0.0")),
+				in(".go-Chip--alert", hasText("GO-2023-0001"))),
+		},
 		// TODO(rfindley): add a test for the standard library once it doesn't go
 		// through the stdlib package.
 		// See also golang/go#58923.
@@ -228,3 +394,85 @@ func sourceLinks(dir, filename string) htmlcheck.Checker {
 		in(".UnitFiles-titleLink a", href(filesPath)),
 		in(".UnitFiles-fileList a", href(filesPath+filename)))
 }
+
+func TestExportDocs(t *testing.T) {
+	testenv.MustHaveExecPath(t, "go") // for local modules
+
+	localModule, _ := testhelper.WriteTxtarToTempDir(t, `
+-- go.mod --
+module example.com/export
+-- a.go --
+// Package a is exported.
+package a
+-- b/b.go --
+package b
+
+// F is a function.
+func F() {}
+-- c/README.md --
+Not a package.
+`)
+	ctx := context.Background()
+	server, err := BuildServer(ctx, ServerConfig{Paths: []string{localModule}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	n, err := server.ExportDocs(ctx, dir, dochtml.FormatMarkdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("got %d files, want 2", n)
+	}
+	for file, want := range map[string]string{
+		"example.com/export.md":   "Package a is exported.",
+		"example.com/export/b.md": "### func F",
+	} {
+		b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(b), want) {
+			t.Errorf("%s does not contain %q:\n%s", file, want, b)
+		}
+	}
+}
+
+func TestParseBuildList(t *testing.T) {
+	out := []byte(`{"Path": "example.com/m", "Main": true, "Dir": "/m"}
+{"Path": "golang.org/x/text", "Version": "v0.3.0", "Indirect": true}
+{"Path": "example.com/a", "Version": "v1.0.0", "Replace": {"Path": "example.com/fork", "Version": "v1.0.1"}}
+{"Path": "example.com/b", "Version": "v1.0.0", "Replace": {"Path": "../b"}}
+`)
+	got, err := parseBuildList(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*internal.Requirement{
+		{ModulePath: "example.com/a", Version: "v1.0.1"},
+		{ModulePath: "example.com/b"},
+		{ModulePath: "golang.org/x/text", Version: "v0.3.0", Indirect: true},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestParsePackageImports(t *testing.T) {
+	out := []byte(`{"ImportPath": "example.com/m", "Module": {"Path": "example.com/m"}, "Imports": ["fmt", "golang.org/x/text/language"]}
+{"ImportPath": "example.com/m/nested", "Module": {"Path": "example.com/m/nested"}, "Imports": ["fmt"]}
+{"ImportPath": "example.com/m/util", "Module": {"Path": "example.com/m"}}
+`)
+	got, err := parsePackageImports("example.com/m", out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"example.com/m":      {"fmt", "golang.org/x/text/language"},
+		"example.com/m/util": nil,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
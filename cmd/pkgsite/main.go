@@ -25,6 +25,10 @@
 //
 //	go work init repos/cue repos/other && pkgsite
 //
+// When serving a workspace, the home page lists the modules in its use
+// directives, and modules nested inside other workspace modules are served
+// separately from their parents.
+//
 // By default, the resulting server will also serve all of the module's
 // dependencies at their required versions. You can disable serving the
 // required modules by passing -list=false.
@@ -45,6 +49,96 @@
 // processed. If you clone the repo yourself (https://go.googlesource.com/go),
 // you can provide its location with the -gorepo flag to save a little time.
 //
+// The Go+ repository (https://github.com/goplus/gop) is treated as a second
+// standard library: its packages are served at short paths like /gop/builtin,
+// and /gop lists them. Like any other module, it is fetched from the proxy or
+// module cache; to serve a local clone instead, provide its location with the
+// -goprepo flag:
+//
+//	pkgsite -proxy -goprepo ~/repos/gop
+//
+// Processed modules are kept in memory only. To keep them across restarts,
+// which makes serving a large set of dependencies fast after the first run,
+// provide a directory for them with the -doccache flag:
+//
+//	pkgsite -proxy -doccache ~/.cache/pkgsite
+//
+// Rendered pages are not cached by default. To keep up to N mebibytes of them
+// in memory, use the -pagecache flag; with -pagecachedir, pages evicted from
+// memory are kept in that directory instead of being discarded. Visiting
+// /clear-cache empties the page cache.
+//
+// Identifiers in declarations are linked by their syntax, which misses, for
+// example, methods promoted through embedding and names from dot-imports. With
+// the -typecheck flag, pkgsite type-checks packages, along with the packages
+// they import, and links each identifier to the exact declaration it refers
+// to, across modules. It also lists under each type the interfaces it
+// implements, and under each interface the types of the module that implement
+// it. This makes loading packages slower.
+//
+// With the -verifyexamples flag, pkgsite runs the examples that have output
+// comments with "go test" the first time one of the packages of their module
+// is shown, and marks the output of each example as verified or mismatched.
+// The examples run in a temporary copy of the module with a time limit. When
+// pkgsite runs as root on Linux, they also run in a sandbox, as the user
+// nobody and without network access. Otherwise they run with the permissions
+// of pkgsite, and only the examples of local modules are run, never those of
+// modules fetched from the proxy or the module cache; so only use this flag
+// for code you trust.
+//
+// Documentation is shown for linux/amd64, windows/amd64, darwin/amd64 and
+// js/wasm. To show it for other build contexts, list them in order of
+// preference with the -buildcontexts flag, and provide any additional build
+// tags that packages need with the -tags flag:
+//
+//	pkgsite -buildcontexts linux/arm64,freebsd/amd64 -tags board
+//
+// To report vulnerabilities on package and version pages and serve /vuln/
+// pages, provide a copy of the Go vulnerability database with the -vulndb
+// flag. It may be a directory or zip file laid out as the database at
+// https://vuln.go.dev (such as https://vuln.go.dev/vulndb.zip), or a
+// collection of OSV entries, one per .json file:
+//
+//	pkgsite -vulndb ~/vulndb.zip
+//
+// With a vulnerability database, the main page of each local module also has a
+// Vulnerabilities section. It checks every module in the build list reported
+// by go list -m all, and the standard library of the go command, against the
+// database, and lists the affected dependencies, the versions that fix them
+// and the packages of the module that import the affected packages.
+//
+// Licenses are detected and shown as on pkg.go.dev, although pkgsite displays
+// every module in full. To recognize license texts of your own, or to see how
+// a license policy classifies modules, provide a policy file in the format
+// used by the worker and frontend with the -licensepolicy flag:
+//
+//	pkgsite -licensepolicy policy.json
+//
+// The "Dependency licenses" tab of a module lists the licenses of the modules
+// required by its go.mod file and flags those that are missing, unrecognized
+// or not redistributable, and can be downloaded as CSV or SPDX. To write these
+// reports for the local modules to a directory instead of starting a server,
+// use the -license-report flag; the dependencies are found like other modules,
+// so it is usually combined with -proxy or -cache:
+//
+//	pkgsite -proxy -license-report reports
+//
+// A software bill of materials for a module version, describing its packages,
+// licenses, dependencies and their known vulnerabilities, is served at
+// /sbom/<module>@<version>, as SPDX JSON or, with ?format=cyclonedx, as
+// CycloneDX JSON.
+//
+// Source files of served modules are shown with their lines numbered and each
+// identifier linked to its definition; exported declarations link to their
+// documentation. Add ?raw=1 to the URL of a file for its plain contents.
+//
+// The documentation of a package is also available as Markdown or plain text
+// by adding ?format=md or ?format=txt to its URL. To write Markdown for every
+// package in the local modules to a directory, one file per package named by
+// its import path, instead of starting a server, use the -export-md flag:
+//
+//	pkgsite -export-md docs
+//
 // [workspace]: https://go.dev/ref/mod#workspaces
 package main
 
@@ -59,8 +153,14 @@ import (
 	"time"
 
 	"golang.org/x/pkgsite/cmd/internal/pkgsite"
+	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/browser"
+	"golang.org/x/pkgsite/internal/frontend"
+	"golang.org/x/pkgsite/internal/godoc/dochtml"
+	"golang.org/x/pkgsite/internal/licenses"
+	"golang.org/x/pkgsite/internal/localcache"
 	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/middleware/caching"
 	"golang.org/x/pkgsite/internal/middleware/timeout"
 	"golang.org/x/pkgsite/internal/proxy"
 	"golang.org/x/pkgsite/internal/stdlib"
@@ -69,10 +169,18 @@ import (
 const defaultAddr = "localhost:8080" // default webserver address
 
 var (
-	httpAddr   = flag.String("http", defaultAddr, "HTTP service address to listen for incoming requests on")
-	goRepoPath = flag.String("gorepo", "", "path to Go repo on local filesystem")
-	useProxy   = flag.Bool("proxy", false, "fetch from GOPROXY if not found locally")
-	openFlag   = flag.Bool("open", false, "open a browser window to the server's address")
+	httpAddr      = flag.String("http", defaultAddr, "HTTP service address to listen for incoming requests on")
+	goRepoPath    = flag.String("gorepo", "", "path to Go repo on local filesystem")
+	gopRepoPath   = flag.String("goprepo", "", "path to Go+ repo on local filesystem")
+	buildContexts = flag.String("buildcontexts", "", "comma-separated list of GOOS/GOARCH build contexts to show documentation for, in order of preference")
+	buildTags     = flag.String("tags", "", "comma-separated list of additional build tags to satisfy in every build context")
+	useProxy      = flag.Bool("proxy", false, "fetch from GOPROXY if not found locally")
+	openFlag      = flag.Bool("open", false, "open a browser window to the server's address")
+	pageCache     = flag.Int("pagecache", 0, "mebibytes of rendered pages to cache in memory (no page cache if 0)")
+	pageCacheDir  = flag.String("pagecachedir", "", "directory in which to keep pages evicted from the page cache (discarded if empty)")
+	licensePolicy = flag.String("licensepolicy", "", "JSON `file` describing a license policy that adds to the built-in one")
+	exportMD      = flag.String("export-md", "", "write Markdown documentation for the local modules' packages to `dir` and exit, instead of serving")
+	licenseReport = flag.String("license-report", "", "write CSV and SPDX license reports for the local modules to `dir` and exit, instead of serving")
 	// other flags are bound to ServerConfig below
 )
 
@@ -85,6 +193,10 @@ func main() {
 	flag.BoolVar(&serverCfg.UseListedMods, "list", true, "for each path, serve all modules in build list")
 	flag.BoolVar(&serverCfg.DevMode, "dev", false, "enable developer mode (reload templates on each page load, serve non-minified JS/CSS, etc.)")
 	flag.StringVar(&serverCfg.DevModeStaticDir, "static", "static", "path to folder containing static files served")
+	flag.StringVar(&serverCfg.VulnDB, "vulndb", "", "vulnerability database `directory or zip file`, in the layout of vuln.go.dev or of OSV entries")
+	flag.BoolVar(&serverCfg.TypeCheck, "typecheck", false, "type-check packages to link identifiers in declarations to their exact declarations (slower)")
+	flag.BoolVar(&serverCfg.VerifyExamples, "verifyexamples", false, "run examples that have output comments and show whether their output matches (runs the modules' code)")
+	flag.StringVar(&serverCfg.DocCacheDir, "doccache", "", "directory in which to keep processed modules across restarts (no on-disk cache if empty)")
 	serverCfg.UseLocalStdlib = true
 	serverCfg.GoRepoPath = *goRepoPath
 
@@ -97,6 +209,7 @@ func main() {
 	}
 	flag.Parse()
 	serverCfg.Paths = collectPaths(flag.Args())
+	serverCfg.GopRepoPath = *gopRepoPath
 
 	if serverCfg.UseCache || *useProxy {
 		fmt.Fprintf(os.Stderr, "BYPASSING LICENSE CHECKING: MAY DISPLAY NON-REDISTRIBUTABLE INFORMATION\n")
@@ -118,12 +231,57 @@ func main() {
 		stdlib.SetGoRepoPath(*goRepoPath)
 	}
 
+	if *buildContexts != "" || *buildTags != "" {
+		bcs := internal.BuildContexts
+		if *buildContexts != "" {
+			var err error
+			bcs, err = internal.ParseBuildContexts(*buildContexts)
+			if err != nil {
+				die(err.Error())
+			}
+		}
+		var tags []string
+		if *buildTags != "" {
+			tags = strings.Split(*buildTags, ",")
+		}
+		if err := internal.SetBuildContexts(bcs, tags); err != nil {
+			die(err.Error())
+		}
+	}
+
+	if *licensePolicy != "" {
+		p, err := licenses.ReadPolicy(*licensePolicy)
+		if err != nil {
+			die(err.Error())
+		}
+		if err := licenses.SetPolicy(p); err != nil {
+			die(err.Error())
+		}
+	}
+
 	ctx := context.Background()
 	server, err := pkgsite.BuildServer(ctx, serverCfg)
 	if err != nil {
 		die(err.Error())
 	}
 
+	if *exportMD != "" {
+		n, err := server.ExportDocs(ctx, *exportMD, dochtml.FormatMarkdown)
+		if err != nil {
+			die(err.Error())
+		}
+		log.Infof(ctx, "Wrote documentation for %d packages to %s", n, *exportMD)
+		return
+	}
+	if *licenseReport != "" {
+		n, err := server.ExportLicenseReports(ctx, *licenseReport)
+		if err != nil {
+			die(err.Error())
+		}
+		log.Infof(ctx, "Wrote %d license reports to %s", n, *licenseReport)
+		return
+	}
+
 	addr := *httpAddr
 	if addr == "" {
 		addr = ":http"
@@ -145,8 +303,17 @@ func main() {
 		}()
 	}
 
+	var cacher frontend.Cacher
+	if *pageCache > 0 {
+		s, err := localcache.New(int64(*pageCache)<<20, *pageCacheDir)
+		if err != nil {
+			die(err.Error())
+		}
+		cacher = caching.NewCacher(s, nil)
+	}
+
 	router := http.NewServeMux()
-	server.Install(router.Handle, nil, nil)
+	server.Install(router.Handle, cacher, nil)
 	mw := timeout.Timeout(54 * time.Second)
 	srv := &http.Server{Addr: addr, Handler: mw(router)}
 	die("%v", srv.Serve(ln))
@@ -28,7 +28,9 @@ import (
 	"golang.org/x/pkgsite/internal/middleware"
 	mtimeout "golang.org/x/pkgsite/internal/middleware/timeout"
 	"golang.org/x/pkgsite/internal/proxy"
+	"golang.org/x/pkgsite/internal/queue"
 	"golang.org/x/pkgsite/internal/queue/gcpqueue"
+	"golang.org/x/pkgsite/internal/queue/pgqueue"
 	"golang.org/x/pkgsite/internal/source"
 	"golang.org/x/pkgsite/internal/trace"
 	"golang.org/x/pkgsite/internal/worker"
@@ -37,7 +39,8 @@ import (
 var (
 	timeout   = serverconfig.GetEnvInt(context.Background(), "GO_DISCOVERY_WORKER_TIMEOUT_MINUTES", 10)
 	queueName = serverconfig.GetEnv("GO_DISCOVERY_WORKER_TASK_QUEUE", "")
-	workers   = flag.Int("workers", 10, "number of concurrent requests to the fetch service, when running locally")
+	workers   = flag.Int("workers", 10, "number of concurrent requests to the fetch service, when running locally or with the postgres queue")
+	queueType = flag.String("queue", "gcp", "task queue to use: gcp (Cloud Tasks on GCP, in-memory elsewhere) or postgres (a durable queue in the database)")
 	// flag used in call to safehtml/template.TrustedSourceFromFlag
 	_                  = flag.String("static", "static", "path to folder containing static files served")
 	bypassLicenseCheck = flag.Bool("bypass_license_check", false, "insert all data into the DB, even for non-redistributable paths")
@@ -53,6 +56,8 @@ func main() {
 		log.Fatal(ctx, err)
 	}
 	cfg.Dump(os.Stdout)
+	cmdconfig.BuildContexts(ctx, cfg)
+	cmdconfig.LicensePolicy(ctx, cfg)
 
 	if cfg.UseProfiler {
 		if err := profiler.Start(profiler.Config{}); err != nil {
@@ -70,31 +75,67 @@ func main() {
 		log.Fatal(ctx, err)
 	}
 
-	indexClient, err := index.New(cfg.IndexURL)
-	if err != nil {
-		log.Fatal(ctx, err)
-	}
 	proxyClient, err := proxy.New(cfg.ProxyURL, new(ochttp.Transport))
 	if err != nil {
 		log.Fatal(ctx, err)
 	}
+	var indexClient index.Source
+	switch {
+	case cfg.IndexDir != "":
+		log.Infof(ctx, "discovering new versions in %s", cfg.IndexDir)
+		indexClient = index.NewDirLister(cfg.IndexDir)
+	case len(cfg.IndexModulePrefixes) > 0:
+		log.Infof(ctx, "discovering new versions of modules under %v by listing them on the proxy", cfg.IndexModulePrefixes)
+		indexClient = index.NewProxyLister(proxyClient, cfg.IndexModulePrefixes, db)
+	default:
+		indexClient, err = index.New(cfg.IndexURL)
+		if err != nil {
+			log.Fatal(ctx, err)
+		}
+	}
 	sourceClient := source.NewClient(&http.Client{
 		Transport: &ochttp.Transport{},
 		Timeout:   config.SourceTimeout,
 	})
 	expg := cmdconfig.ExperimentGetter(ctx, cfg)
-	fetchQueue, err := gcpqueue.New(ctx, cfg, queueName, *workers, expg,
-		func(ctx context.Context, modulePath, version string) (int, error) {
-			f := &worker.Fetcher{
-				ProxyClient:  proxyClient,
-				SourceClient: sourceClient,
-				DB:           db,
-			}
-			code, _, err := f.FetchAndUpdateState(ctx, modulePath, version, cfg.AppVersionLabel())
-			return code, err
-		})
-	if err != nil {
-		log.Fatalf(ctx, "gcpqueue.New: %v", err)
+	var fetchQueue queue.Queue
+	switch *queueType {
+	case "gcp":
+		fetchQueue, err = gcpqueue.New(ctx, cfg, queueName, *workers, expg,
+			func(ctx context.Context, modulePath, version string) (int, error) {
+				f := &worker.Fetcher{
+					ProxyClient:  proxyClient,
+					SourceClient: sourceClient,
+					DB:           db,
+				}
+				code, _, err := f.FetchAndUpdateState(ctx, modulePath, version, cfg.AppVersionLabel())
+				return code, err
+			})
+		if err != nil {
+			log.Fatalf(ctx, "gcpqueue.New: %v", err)
+		}
+	case "postgres":
+		fetchQueue, err = pgqueue.New(ctx, db.Underlying(), *workers, expg,
+			func(ctx context.Context, modulePath, version string, opts *queue.Options) (int, error) {
+				f := &worker.Fetcher{
+					ProxyClient:  proxyClient.WithCache(),
+					SourceClient: sourceClient,
+					DB:           db,
+				}
+				if opts.DisableProxyFetch {
+					f.ProxyClient = f.ProxyClient.WithFetchDisabled()
+				}
+				if opts.Source == queue.SourceFrontendValue {
+					f.Source = queue.SourceFrontendValue
+				}
+				code, _, err := f.FetchAndUpdateState(ctx, modulePath, version, cfg.AppVersionLabel())
+				return code, err
+			})
+		if err != nil {
+			log.Fatalf(ctx, "pgqueue.New: %v", err)
+		}
+	default:
+		log.Fatalf(ctx, "unknown -queue %q: want gcp or postgres", *queueType)
 	}
 
 	reporter := cmdconfig.Reporter(ctx, cfg)
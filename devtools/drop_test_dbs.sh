@@ -6,9 +6,11 @@
 
 # Drop all test databases, when migrations are beyond repair.
 for dbname in \
+    discovery_datasource_test \
     discovery_frontend_test \
     discovery_frontend_test \
     discovery_integration_test \
+    discovery_pgqueue_test \
     discovery_postgres_test \
     discovery_worker_test \
     "discovery_postgres_test-0" \
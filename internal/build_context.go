@@ -4,7 +4,11 @@
 
 package internal
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 // A BuildContext describes a build context for the Go tool: information needed
 // to build a Go package. For our purposes, we only care about the information
@@ -49,6 +53,76 @@ var BuildContexts = []BuildContext{
 	BuildContextJS,
 }
 
+// BuildTags are additional build tags that are satisfied in every build
+// context, like those passed to the go command's -tags flag.
+var BuildTags []string
+
+// ParseBuildContext parses a build context of the form "GOOS/GOARCH". It
+// does not check that GOOS and GOARCH are known; documentation can only be
+// stored for those in the goos and goarch enums of the database (see
+// postgres.DB.CheckBuildContexts).
+func ParseBuildContext(s string) (BuildContext, error) {
+	goos, goarch, ok := strings.Cut(s, "/")
+	if !ok || goos == "" || goarch == "" || strings.Contains(goarch, "/") {
+		return BuildContext{}, fmt.Errorf("invalid build context %q: want GOOS/GOARCH", s)
+	}
+	if goos == All || goarch == All {
+		return BuildContext{}, fmt.Errorf("invalid build context %q: %q is reserved", s, All)
+	}
+	return BuildContext{GOOS: goos, GOARCH: goarch}, nil
+}
+
+// ParseBuildContexts parses a comma-separated list of build contexts of the
+// form "GOOS/GOARCH".
+func ParseBuildContexts(s string) ([]BuildContext, error) {
+	var bcs []BuildContext
+	for _, f := range strings.Split(s, ",") {
+		bc, err := ParseBuildContext(strings.TrimSpace(f))
+		if err != nil {
+			return nil, err
+		}
+		bcs = append(bcs, bc)
+	}
+	return bcs, nil
+}
+
+// SetBuildContexts replaces BuildContexts and BuildTags. It must be called
+// before any packages are loaded or documentation is served.
+//
+// Documentation is only stored for the build contexts in the list, so
+// changing it only affects modules processed afterwards.
+func SetBuildContexts(bcs []BuildContext, tags []string) error {
+	if len(bcs) == 0 {
+		return errors.New("SetBuildContexts: no build contexts")
+	}
+	seen := map[BuildContext]bool{}
+	for _, bc := range bcs {
+		if seen[bc] {
+			return fmt.Errorf("SetBuildContexts: duplicate build context %s", bc)
+		}
+		seen[bc] = true
+	}
+	BuildContexts = bcs
+	BuildTags = tags
+	return nil
+}
+
+// URLQuery returns the URL query that selects the documentation for b, or the
+// empty string if b is all/all or the first of BuildContexts, which are shown
+// by default. GOARCH is only included if another of BuildContexts has the same
+// GOOS.
+func (b BuildContext) URLQuery() string {
+	if b == BuildContextAll || len(BuildContexts) > 0 && b == BuildContexts[0] {
+		return ""
+	}
+	for _, bc := range BuildContexts {
+		if bc.GOOS == b.GOOS && bc.GOARCH != b.GOARCH {
+			return fmt.Sprintf("GOOS=%s&GOARCH=%s", b.GOOS, b.GOARCH)
+		}
+	}
+	return "GOOS=" + b.GOOS
+}
+
 // CompareBuildContexts returns a negative number, 0, or a positive number depending on
 // the relative positions of c1 and c2 in BuildContexts.
 func CompareBuildContexts(c1, c2 BuildContext) int {
@@ -4,7 +4,9 @@
 
 package internal
 
-import "testing"
+import (
+	"testing"
+)
 
 func TestCompareBuildContexts(t *testing.T) {
 	check := func(c1, c2 BuildContext, want int) {
@@ -37,3 +39,55 @@ func TestCompareBuildContexts(t *testing.T) {
 	// Special cases.
 	check(BuildContext{"?", "?"}, BuildContexts[len(BuildContexts)-1], 1) // unknown is last
 }
+
+func TestParseBuildContexts(t *testing.T) {
+	got, err := ParseBuildContexts("linux/arm64, freebsd/amd64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []BuildContext{{"linux", "arm64"}, {"freebsd", "amd64"}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	for _, s := range []string{"", "linux", "linux/", "/amd64", "linux/amd64/v2", "all/all", "linux/amd64,"} {
+		if _, err := ParseBuildContexts(s); err == nil {
+			t.Errorf("%q: got no error, want one", s)
+		}
+	}
+}
+
+func TestSetBuildContexts(t *testing.T) {
+	defer func(bcs []BuildContext, tags []string) {
+		BuildContexts, BuildTags = bcs, tags
+	}(BuildContexts, BuildTags)
+
+	if err := SetBuildContexts(nil, nil); err == nil {
+		t.Error("no build contexts: got no error, want one")
+	}
+	if err := SetBuildContexts([]BuildContext{BuildContextLinux, BuildContextLinux}, nil); err == nil {
+		t.Error("duplicate build contexts: got no error, want one")
+	}
+
+	linuxARM := BuildContext{"linux", "arm64"}
+	freeBSD := BuildContext{"freebsd", "amd64"}
+	if err := SetBuildContexts([]BuildContext{linuxARM, BuildContextLinux, freeBSD}, []string{"board"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := CompareBuildContexts(freeBSD, BuildContextWindows); got >= 0 {
+		t.Errorf("configured context vs. unknown: got %d, want < 0", got)
+	}
+	for _, test := range []struct {
+		bc   BuildContext
+		want string
+	}{
+		{BuildContextAll, ""},
+		{linuxARM, ""},
+		{BuildContextLinux, "GOOS=linux&GOARCH=amd64"},
+		{freeBSD, "GOOS=freebsd"},
+	} {
+		if got := test.bc.URLQuery(); got != test.want {
+			t.Errorf("%v.URLQuery() = %q, want %q", test.bc, got, test.want)
+		}
+	}
+}
@@ -2,8 +2,8 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// Package cache implements a redis-based page cache
-// for pkgsite.
+// Package cache implements a page cache for pkgsite, stored in Redis or in
+// another Store, like the in-process one of package localcache.
 package cache
 
 import (
@@ -14,76 +14,64 @@ import (
 	"golang.org/x/pkgsite/internal/derrors"
 )
 
-// Cache is a Redis-based cache.
+// A Store holds the entries of a Cache.
+type Store interface {
+	// Get returns the value for key, or nil if the key does not exist.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Put inserts the key with the given data and time-to-live. A
+	// time-to-live of zero means the entry does not expire.
+	Put(ctx context.Context, key string, data []byte, ttl time.Duration) error
+	// Clear deletes all entries.
+	Clear(ctx context.Context) error
+	// Delete deletes the given keys. It does not return an error if a key
+	// does not exist.
+	Delete(ctx context.Context, keys ...string) error
+	// DeletePrefix deletes all keys beginning with prefix.
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// Cache is a page cache.
 type Cache struct {
-	client *redis.Client
+	store Store
 }
 
 // New creates a new Cache using the given Redis client.
 func New(client *redis.Client) *Cache {
-	return &Cache{client: client}
+	return NewWithStore(&redisStore{client: client})
+}
+
+// NewWithStore creates a new Cache that keeps its entries in s.
+func NewWithStore(s Store) *Cache {
+	return &Cache{store: s}
 }
 
 // Get returns the value for key,  or nil if the key does not exist.
 func (c *Cache) Get(ctx context.Context, key string) (value []byte, err error) {
 	defer derrors.Wrap(&err, "Get(%q)", key)
-	val, err := c.client.Get(ctx, key).Bytes()
-	if err == redis.Nil { // not found
-		return nil, nil
-	}
-	if err != nil {
-		return nil, err
-	}
-	return val, nil
+	return c.store.Get(ctx, key)
 }
 
 // Put inserts the key with the given data and time-to-live.
 func (c *Cache) Put(ctx context.Context, key string, data []byte, ttl time.Duration) (err error) {
 	defer derrors.Wrap(&err, "Put(%q, data, %s)", key, ttl)
-	_, err = c.client.Set(ctx, key, data, ttl).Result()
-	return err
+	return c.store.Put(ctx, key, data, ttl)
 }
 
 // Clear deletes all entries from the cache.
 func (c *Cache) Clear(ctx context.Context) (err error) {
 	defer derrors.Wrap(&err, "Clear()")
-	status := c.client.FlushAll(ctx)
-	return status.Err()
+	return c.store.Clear(ctx)
 }
 
 // Delete deletes the given keys. It does not return an error if a key does not
 // exist.
 func (c *Cache) Delete(ctx context.Context, keys ...string) (err error) {
 	defer derrors.Wrap(&err, "Delete(%q)", keys)
-	cmd := c.client.Unlink(ctx, keys...) // faster, asynchronous delete
-	return cmd.Err()
+	return c.store.Delete(ctx, keys...)
 }
 
 // DeletePrefix deletes all keys beginning with prefix.
 func (c *Cache) DeletePrefix(ctx context.Context, prefix string) (err error) {
 	defer derrors.Wrap(&err, "DeletePrefix(%q)", prefix)
-	iter := c.client.Scan(ctx, 0, prefix+"*", int64(scanCount)).Iterator()
-	var keys []string
-	for iter.Next(ctx) {
-		keys = append(keys, iter.Val())
-		if len(keys) > scanCount {
-			if err := c.Delete(ctx, keys...); err != nil {
-				return err
-			}
-			keys = keys[:0]
-		}
-	}
-	if iter.Err() != nil {
-		return iter.Err()
-	}
-	if len(keys) > 0 {
-		return c.Delete(ctx, keys...)
-	}
-	return nil
+	return c.store.DeletePrefix(ctx, prefix)
 }
-
-// The "count" argument to the Redis SCAN command, which is a hint for how much
-// work to perform.
-// Also used as the batch size for Delete calls in DeletePrefix.
-// var for testing.
-var scanCount = 100
@@ -55,11 +55,12 @@ func TestDeletePrefix(t *testing.T) {
 		t.Fatal(err)
 	}
 	defer s.Close()
-	c := New(redis.NewClient(&redis.Options{Addr: s.Addr()}))
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	c := New(client)
 
 	check := func(want []string) {
 		t.Helper()
-		got, err := c.client.Keys(ctx, "*").Result()
+		got, err := client.Keys(ctx, "*").Result()
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -0,0 +1,70 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// redisStore is a Store backed by Redis.
+type redisStore struct {
+	client *redis.Client
+}
+
+func (s *redisStore) Get(ctx context.Context, key string) ([]byte, error) {
+	val, err := s.client.Get(ctx, key).Bytes()
+	if err == redis.Nil { // not found
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+func (s *redisStore) Put(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	_, err := s.client.Set(ctx, key, data, ttl).Result()
+	return err
+}
+
+func (s *redisStore) Clear(ctx context.Context) error {
+	status := s.client.FlushAll(ctx)
+	return status.Err()
+}
+
+func (s *redisStore) Delete(ctx context.Context, keys ...string) error {
+	cmd := s.client.Unlink(ctx, keys...) // faster, asynchronous delete
+	return cmd.Err()
+}
+
+func (s *redisStore) DeletePrefix(ctx context.Context, prefix string) error {
+	iter := s.client.Scan(ctx, 0, prefix+"*", int64(scanCount)).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) > scanCount {
+			if err := s.Delete(ctx, keys...); err != nil {
+				return err
+			}
+			keys = keys[:0]
+		}
+	}
+	if iter.Err() != nil {
+		return iter.Err()
+	}
+	if len(keys) > 0 {
+		return s.Delete(ctx, keys...)
+	}
+	return nil
+}
+
+// The "count" argument to the Redis SCAN command, which is a hint for how much
+// work to perform.
+// Also used as the batch size for Delete calls in DeletePrefix.
+// var for testing.
+var scanCount = 100
@@ -45,6 +45,14 @@ type Config struct {
 	// Discovery environment variables
 	ProxyURL, IndexURL string
 
+	// IndexDir and IndexModulePrefixes configure how the worker discovers
+	// new module versions when the proxy doesn't serve an index like the one
+	// at IndexURL. If IndexDir is set, versions are discovered in that proxy
+	// storage directory; otherwise, if IndexModulePrefixes is set, by listing
+	// the versions of the known modules under those prefixes on the proxy.
+	IndexDir            string
+	IndexModulePrefixes []string
+
 	// Ports used for hosting. 'DebugPort' is used for serving HTTP debug pages.
 	Port, DebugPort string
 
@@ -88,6 +96,17 @@ type Config struct {
 	// Configuration for redis page cache.
 	RedisCacheHost, RedisBetaCacheHost, RedisCachePort string
 
+	// Configuration for the in-process page cache, used when there is no
+	// redis page cache. LocalCacheMi is the number of mebibytes of pages to
+	// keep in memory; zero disables the cache. If LocalCacheDir is not empty,
+	// pages evicted from memory are kept in files there.
+	LocalCacheMi  int
+	LocalCacheDir string
+
+	// LicensePolicyFile is the path of a JSON file describing a license
+	// policy that adds to the built-in one. See licenses.Policy.
+	LicensePolicyFile string
+
 	// UseProfiler specifies whether to enable Stackdriver Profiler.
 	UseProfiler bool
 
@@ -115,6 +134,15 @@ type Config struct {
 
 	// VulnDB is the URL of the Go vulnerability DB.
 	VulnDB string
+
+	// BuildContexts lists the build contexts for which documentation is
+	// stored, in the form "GOOS/GOARCH" and in order of preference. If empty,
+	// the defaults in internal.BuildContexts are used.
+	BuildContexts []string
+
+	// BuildTags are additional build tags that are satisfied in every build
+	// context.
+	BuildTags []string
 }
 
 // MonitoredResource represents the resource that is running the current binary.
@@ -152,6 +152,11 @@ func Init(ctx context.Context) (_ *config.Config, err error) {
 		RedisCacheHost:       os.Getenv("GO_DISCOVERY_REDIS_HOST"),
 		RedisBetaCacheHost:   os.Getenv("GO_DISCOVERY_REDIS_BETA_HOST"),
 		RedisCachePort:       GetEnv("GO_DISCOVERY_REDIS_PORT", "6379"),
+		LocalCacheMi:         GetEnvInt(ctx, "GO_DISCOVERY_LOCAL_CACHE_MI", 0),
+		LocalCacheDir:        os.Getenv("GO_DISCOVERY_LOCAL_CACHE_DIR"),
+		IndexDir:             os.Getenv("GO_DISCOVERY_INDEX_DIR"),
+		IndexModulePrefixes:  parseCommaList(os.Getenv("GO_DISCOVERY_INDEX_MODULE_PREFIXES")),
+		LicensePolicyFile:    os.Getenv("GO_DISCOVERY_LICENSE_POLICY"),
 		Quota: config.QuotaSettings{
 			Enable:     os.Getenv("GO_DISCOVERY_ENABLE_QUOTA") == "true",
 			QPS:        GetEnvInt(ctx, "GO_DISCOVERY_QUOTA_QPS", 10),
@@ -168,6 +173,8 @@ func Init(ctx context.Context) (_ *config.Config, err error) {
 		ServeStats:            os.Getenv("GO_DISCOVERY_SERVE_STATS") == "true",
 		DisableErrorReporting: os.Getenv("GO_DISCOVERY_DISABLE_ERROR_REPORTING") == "true",
 		VulnDB:                GetEnv("GO_DISCOVERY_VULN_DB", "https://storage.googleapis.com/go-vulndb"),
+		BuildContexts:         parseCommaList(os.Getenv("GO_DISCOVERY_BUILD_CONTEXTS")),
+		BuildTags:             parseCommaList(os.Getenv("GO_DISCOVERY_BUILD_TAGS")),
 	}
 	log.SetLevel(cfg.LogLevel)
 
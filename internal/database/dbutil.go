@@ -12,6 +12,7 @@ import (
 	"net/url"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
 
 	"golang.org/x/pkgsite/internal/config/serverconfig"
 	"golang.org/x/pkgsite/internal/derrors"
@@ -41,6 +42,25 @@ func DBConnURI(dbName string) string {
 	return cs
 }
 
+// MakeValidUnicode removes null runes from a string that will be saved in a
+// column of type TEXT, because pq doesn't like them. It also replaces non-unicode
+// characters with the Unicode replacement character, which is the behavior of
+// for ... range on strings.
+func MakeValidUnicode(s string) string {
+	// If s is valid and has no zeroes, don't copy it.
+	if !strings.ContainsRune(s, 0) && utf8.ValidString(s) {
+		return s
+	}
+
+	var b strings.Builder
+	for _, r := range s {
+		if r != 0 {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
 // MultiErr can be used to combine one or more errors into a single error.
 type MultiErr []error
 
@@ -184,6 +204,9 @@ func ResetDB(ctx context.Context, db *DB) error {
 		if _, err := tx.Exec(ctx, `TRUNCATE excluded_prefixes;`); err != nil {
 			return err
 		}
+		if _, err := tx.Exec(ctx, `TRUNCATE queue_tasks;`); err != nil {
+			return err
+		}
 		return nil
 	}); err != nil {
 		return fmt.Errorf("error resetting test DB: %v", err)
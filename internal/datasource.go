@@ -88,6 +88,10 @@ type DataSource interface {
 	GetUnitMeta(ctx context.Context, path, requestedModulePath, requestedVersion string) (_ *UnitMeta, err error)
 	// GetModuleReadme gets the readme for the module.
 	GetModuleReadme(ctx context.Context, modulePath, resolvedVersion string) (*Readme, error)
+	// GetModuleRequirements returns the requirements in the go.mod file of
+	// the module version. It returns a derrors.RequirementsUnknown error if
+	// they were not recorded when the module version was processed.
+	GetModuleRequirements(ctx context.Context, modulePath, resolvedVersion string) ([]*Requirement, error)
 	// GetLatestInfo gets information about the latest versions of a unit and module.
 	// See LatestInfo for documentation.
 	GetLatestInfo(ctx context.Context, unitPath, modulePath string, latestUnitMeta *UnitMeta) (LatestInfo, error)
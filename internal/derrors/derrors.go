@@ -58,6 +58,10 @@ var (
 	// shouldn't be reprocessed.
 	Cleaned = errors.New("cleaned")
 
+	// RequirementsUnknown indicates that the requirements of a module
+	// version were not recorded when it was processed.
+	RequirementsUnknown = errors.New("requirements unknown")
+
 	// Unknown indicates that the error has unknown semantics.
 	Unknown = errors.New("unknown")
 
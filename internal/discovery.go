@@ -153,6 +153,17 @@ type Module struct {
 	// that may be contained in nested subdirectories.
 	Licenses []*licenses.License
 	Units    []*Unit
+	// Requirements holds the requirements in the module's go.mod file.
+	Requirements []*Requirement
+}
+
+// A Requirement is a module version required by the go.mod file of another
+// module.
+type Requirement struct {
+	ModulePath string
+	Version    string
+	// Indirect reports whether the requirement is marked "// indirect".
+	Indirect bool
 }
 
 // Packages returns all of the units for a module that are packages.
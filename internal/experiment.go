@@ -7,12 +7,16 @@ package internal
 
 const (
 	ExperimentEnableStdFrontendFetch = "enable-std-frontend-fetch"
+	ExperimentTypeCheck              = "type-check"
+	ExperimentVerifyExamples         = "verify-examples"
 )
 
 // Experiments represents all of the active experiments in the codebase and
 // a description of each experiment.
 var Experiments = map[string]string{
 	ExperimentEnableStdFrontendFetch: "Enable frontend fetching for module std.",
+	ExperimentTypeCheck:              "Type-check packages when fetching modules, to link identifiers and record promoted methods and implementations.",
+	ExperimentVerifyExamples:         "Run examples with output comments in a sandbox when fetching modules, and show whether their output matches.",
 }
 
 // Experiment holds data associated with an experimental feature for frontend
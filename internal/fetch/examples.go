@@ -0,0 +1,195 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/stdlib"
+)
+
+// exampleTimeout bounds the time taken to run the examples of a module.
+const exampleTimeout = 5 * time.Minute
+
+// An exampleVerifier runs the examples of a module that have output comments,
+// to record whether their output matches.
+//
+// The examples are run with "go test" on a copy of the module's files in a
+// temporary directory, so that they cannot modify the module's content
+// directory, and with a time limit.
+//
+// Where the platform allows it (see sandboxSupported), they also run in a
+// sandbox: the module's dependencies are downloaded first, into a module cache
+// in the temporary directory, and then "go test" runs as an unprivileged user
+// without network access. Otherwise they are not isolated: they run as the
+// current user, with network access, and the go command downloads the
+// module's dependencies as usual. That is why, without a sandbox, only the
+// examples of modules on the local filesystem, which the user of cmd/pkgsite
+// is working on, are run.
+type exampleVerifier struct {
+	modulePath string
+	contentDir fs.FS
+	sandboxed  bool
+
+	once sync.Once
+	// passed maps import paths to the names of the example functions that
+	// ran, like "ExampleT_M", to whether their output matched.
+	passed map[string]map[string]bool
+}
+
+// EnableExampleVerification arranges for the examples of lm with output
+// comments to be run when its units are computed, so that the documentation
+// can show whether their output matches. The examples of all packages of the
+// module are run together, the first time a unit is computed.
+//
+// Unless examples can be run in a sandbox, verification is only done for
+// modules in local directories. It is never done for the standard library.
+// For other modules, EnableExampleVerification does nothing.
+func (lm *LazyModule) EnableExampleVerification() {
+	if lm.contentDir == nil || lm.ModulePath == stdlib.ModulePath {
+		return
+	}
+	sandboxed := sandboxSupported()
+	if !lm.local && !sandboxed {
+		return
+	}
+	lm.exampleVerifier = &exampleVerifier{
+		modulePath: lm.ModulePath,
+		contentDir: lm.contentDir,
+		sandboxed:  sandboxed,
+	}
+}
+
+// results returns the results of the examples of the package with the given
+// import path, by example function name. Examples that did not run, because
+// they have no output comment or did not compile, are omitted.
+func (ev *exampleVerifier) results(ctx context.Context, importPath string) map[string]bool {
+	ev.once.Do(func() {
+		var err error
+		ev.passed, err = ev.run(ctx)
+		if err != nil {
+			log.Infof(ctx, "exampleVerifier: %v", err)
+		}
+	})
+	return ev.passed[importPath]
+}
+
+// run runs the examples of the module and returns their results. Results
+// are returned even if run fails partway.
+func (ev *exampleVerifier) run(ctx context.Context) (_ map[string]map[string]bool, err error) {
+	tmp, err := os.MkdirTemp("", "pkgsite-examples-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	dir := filepath.Join(tmp, "module")
+	if err := copyFS(dir, ev.contentDir); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); err != nil {
+		// Modules without a go.mod file are treated as having a minimal one.
+		gomod := fmt.Sprintf("module %s\n", ev.modulePath)
+		if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(gomod), 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	// The context of the first request for a unit should not cut the run
+	// short for later ones.
+	runCtx, cancel := context.WithTimeout(context.Background(), exampleTimeout)
+	defer cancel()
+	args := []string{"test", "-json", "-count=1", "-vet=off", "-run=^Example", "./..."}
+	var stdout, stderr bytes.Buffer
+	var runErr error
+	if ev.sandboxed {
+		runErr = runSandboxed(runCtx, tmp, dir, args, &stdout, &stderr)
+	} else {
+		cmd := exec.CommandContext(runCtx, "go", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		runErr = cmd.Run()
+	}
+	// go test exits with an error if any example fails, so only report
+	// the error if there are no results.
+	passed, err := parseTestEvents(&stdout)
+	if err != nil {
+		return passed, err
+	}
+	if len(passed) == 0 && runErr != nil {
+		return nil, fmt.Errorf("go test: %v: %s", runErr, strings.TrimSpace(stderr.String()))
+	}
+	return passed, nil
+}
+
+// A testEvent is an event printed by "go test -json". See
+// "go doc test2json".
+type testEvent struct {
+	Action  string
+	Package string
+	Test    string
+}
+
+// parseTestEvents reads the output of "go test -json" and returns the
+// results of the examples it ran, by import path and function name.
+func parseTestEvents(r io.Reader) (map[string]map[string]bool, error) {
+	passed := map[string]map[string]bool{}
+	dec := json.NewDecoder(r)
+	for {
+		var ev testEvent
+		if err := dec.Decode(&ev); err == io.EOF {
+			return passed, nil
+		} else if err != nil {
+			return passed, fmt.Errorf("parsing test output: %v", err)
+		}
+		if !strings.HasPrefix(ev.Test, "Example") || (ev.Action != "pass" && ev.Action != "fail") {
+			continue
+		}
+		if passed[ev.Package] == nil {
+			passed[ev.Package] = map[string]bool{}
+		}
+		passed[ev.Package][ev.Test] = ev.Action == "pass"
+	}
+}
+
+// copyFS copies the regular files of fsys that the go command may read to the
+// directory dir.
+func copyFS(dir string, fsys fs.FS) error {
+	return fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(dir, filepath.FromSlash(p))
+		if d.IsDir() {
+			// The go command ignores these directories, which in a local
+			// directory may include large ones like .git.
+			if p != "." && (strings.HasPrefix(d.Name(), ".") || strings.HasPrefix(d.Name(), "_")) {
+				return fs.SkipDir
+			}
+			return os.MkdirAll(dst, 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		data, err := readFSFile(fsys, p, MaxFileSize)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(dst, data, 0644)
+	})
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// nobody is the user and group ID that sandboxed examples run as.
+const nobody = 65534
+
+// sandboxSupported reports whether examples can be run in a sandbox. Running
+// them as another user, in a network namespace of their own, requires root.
+func sandboxSupported() bool {
+	return os.Geteuid() == 0
+}
+
+// runSandboxed runs the go command with args in moduleDir, a directory under
+// tmp, writing its output to stdout and stderr.
+//
+// The module's dependencies are first downloaded, into a module cache under
+// tmp, by listing its packages, which does not run any of its code. Then the
+// go command runs as nobody in a new network namespace, which has no
+// network access, with tmp as its home directory and with the module proxy
+// turned off. When ctx is done, every process it started is killed.
+func runSandboxed(ctx context.Context, tmp, moduleDir string, args []string, stdout, stderr io.Writer) error {
+	env := []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + filepath.Join(tmp, "home"),
+		"GOCACHE=" + filepath.Join(tmp, "gocache"),
+		"GOMODCACHE=" + filepath.Join(tmp, "gomodcache"),
+		"GOFLAGS=-mod=mod",
+		"GOWORK=off",
+		"GOTOOLCHAIN=local",
+	}
+	if v := os.Getenv("GOROOT"); v != "" {
+		env = append(env, "GOROOT="+v)
+	}
+
+	var proxyEnv []string
+	for _, name := range []string{"GOPROXY", "GOSUMDB", "GONOSUMDB", "GOPRIVATE"} {
+		if v := os.Getenv(name); v != "" {
+			proxyEnv = append(proxyEnv, name+"="+v)
+		}
+	}
+	var listErr bytes.Buffer
+	list := exec.CommandContext(ctx, "go", "list", "-e", "-deps", "-test", "./...")
+	list.Dir = moduleDir
+	list.Env = append(proxyEnv, env...)
+	list.Stderr = &listErr
+	if err := list.Run(); err != nil {
+		return fmt.Errorf("downloading dependencies: %v: %s", err, strings.TrimSpace(listErr.String()))
+	}
+
+	if err := os.MkdirAll(filepath.Join(tmp, "home"), 0755); err != nil {
+		return err
+	}
+	err := filepath.WalkDir(tmp, func(p string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(p, nobody, nobody)
+	})
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("go", args...)
+	cmd.Dir = moduleDir
+	cmd.Env = append(env, "GOPROXY=off")
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags: syscall.CLONE_NEWNET,
+		Credential: &syscall.Credential{Uid: nobody, Gid: nobody},
+		Setpgid:    true,
+		Pdeathsig:  syscall.SIGKILL,
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// Kill the whole process group, so that no test binary or process
+	// started by an example outlives the run.
+	defer syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		<-done
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,23 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !linux
+
+package fetch
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// sandboxSupported reports whether examples can be run in a sandbox, which
+// is only possible on Linux.
+func sandboxSupported() bool {
+	return false
+}
+
+func runSandboxed(ctx context.Context, tmp, moduleDir string, args []string, stdout, stderr io.Writer) error {
+	return errors.New("running examples in a sandbox is not supported on this platform")
+}
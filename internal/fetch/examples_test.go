@@ -0,0 +1,176 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/testenv"
+	"golang.org/x/pkgsite/internal/testing/testhelper"
+)
+
+func TestParseTestEvents(t *testing.T) {
+	const output = `{"Action":"run","Package":"example.com/m","Test":"ExampleF"}
+{"Action":"output","Package":"example.com/m","Test":"ExampleF","Output":"=== RUN   ExampleF\n"}
+{"Action":"pass","Package":"example.com/m","Test":"ExampleF","Elapsed":0}
+{"Action":"fail","Package":"example.com/m/sub","Test":"ExampleT_M","Elapsed":0}
+{"Action":"pass","Package":"example.com/m/sub","Test":"TestOther","Elapsed":0}
+{"Action":"fail","Package":"example.com/m/sub","Elapsed":0.1}
+`
+	got, err := parseTestEvents(strings.NewReader(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]map[string]bool{
+		"example.com/m":     {"ExampleF": true},
+		"example.com/m/sub": {"ExampleT_M": false},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestEnableExampleVerification(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		modulePath string
+		local      bool
+		want       bool
+	}{
+		{"local", "example.com/m", true, true},
+		{"proxy", "example.com/m", false, sandboxSupported()},
+		{"std", "std", true, false},
+	} {
+		lm := &LazyModule{contentDir: fstest.MapFS{}, local: test.local}
+		lm.ModulePath = test.modulePath
+		lm.EnableExampleVerification()
+		if got := lm.exampleVerifier != nil; got != test.want {
+			t.Errorf("%s: enabled = %t, want %t", test.name, got, test.want)
+		}
+	}
+}
+
+func TestExampleVerifier(t *testing.T) {
+	testenv.MustHaveExecPath(t, "go")
+	ev := &exampleVerifier{
+		modulePath: "example.com/m",
+		contentDir: fstest.MapFS{
+			"go.mod": {Data: []byte("module example.com/m\n\ngo 1.19\n")},
+			"m.go":   {Data: []byte("package m\n\nfunc F() int { return 1 }\n")},
+			"example_test.go": {Data: []byte(`package m_test
+
+import (
+	"fmt"
+
+	"example.com/m"
+)
+
+func ExampleF() {
+	fmt.Println(m.F())
+	// Output: 1
+}
+
+func ExampleF_wrong() {
+	fmt.Println(m.F())
+	// Output: 2
+}
+
+func ExampleF_noOutput() {
+	fmt.Println(m.F())
+}
+`)},
+			".git/config": {Data: []byte("ignored")},
+		},
+	}
+	got := ev.results(context.Background(), "example.com/m")
+	want := map[string]bool{"ExampleF": true, "ExampleF_wrong": false}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestExampleResultsInDocumentation(t *testing.T) {
+	testenv.MustHaveExecPath(t, "go")
+	ctx := context.Background()
+	dir, _ := testhelper.WriteTxtarToTempDir(t, `
+-- go.mod --
+module example.com/m
+
+go 1.19
+-- m.go --
+package m
+-- example_test.go --
+package m_test
+
+import "fmt"
+
+func Example() {
+	fmt.Println("hello")
+	// Output: hello
+}
+`)
+	g, err := NewDirectoryModuleGetter("", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lm := FetchLazyModule(ctx, "example.com/m", LocalVersion, g)
+	if lm.Error != nil {
+		t.Fatal(lm.Error)
+	}
+	lm.EnableExampleVerification()
+	u, err := lm.Unit(ctx, "example.com/m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{"Example": true}
+	for _, doc := range u.Documentation {
+		if diff := cmp.Diff(want, doc.ExampleResults); diff != "" {
+			t.Errorf("%s/%s: mismatch (-want, +got):\n%s", doc.GOOS, doc.GOARCH, diff)
+		}
+	}
+}
+
+func TestExampleVerifierSandbox(t *testing.T) {
+	testenv.MustHaveExecPath(t, "go")
+	if !sandboxSupported() {
+		t.Skip("examples cannot be run in a sandbox")
+	}
+	ev := &exampleVerifier{
+		modulePath: "example.com/m",
+		sandboxed:  true,
+		contentDir: fstest.MapFS{
+			"go.mod": {Data: []byte("module example.com/m\n\ngo 1.19\n")},
+			"m.go":   {Data: []byte("package m\n")},
+			"example_test.go": {Data: []byte(`package m_test
+
+import (
+	"fmt"
+	"net"
+	"os"
+)
+
+func ExampleUser() {
+	fmt.Println(os.Getuid(), os.Getgid())
+	// Output: 65534 65534
+}
+
+func ExampleNetwork() {
+	_, err := net.Dial("tcp", "proxy.golang.org:443")
+	fmt.Println(err != nil)
+	// Output: true
+}
+`)},
+		},
+	}
+	got := ev.results(context.Background(), "example.com/m")
+	want := map[string]bool{"ExampleUser": true, "ExampleNetwork": true}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
@@ -43,7 +43,7 @@ type FetchResult struct {
 
 // A LazyModule contains the information needed to compute a FetchResult,
 // but has only done enough work to compute the UnitMetas in the module.
-// It provides a Unit method to compute a single unit or a fetchResult
+// It provides a Unit method to compute a single unit or a FetchResult
 // method to compute the whole FetchResult.
 type LazyModule struct {
 	internal.ModuleInfo
@@ -54,6 +54,10 @@ type LazyModule struct {
 	licenseDetector  *licenses.Detector
 	contentDir       fs.FS
 	godocModInfo     *godoc.ModuleInfo
+	typeChecker      *typeChecker     // nil unless EnableTypeChecking was called
+	exampleVerifier  *exampleVerifier // nil unless EnableExampleVerification was called
+	local            bool             // the module is in a directory on the local filesystem
+	Requirements     []*internal.Requirement
 	Error            error
 }
 
@@ -64,7 +68,7 @@ type LazyModule struct {
 // Even if err is non-nil, the result may contain useful information, like the go.mod path.
 func FetchModule(ctx context.Context, modulePath, requestedVersion string, mg ModuleGetter) (fr *FetchResult) {
 	lm := FetchLazyModule(ctx, modulePath, requestedVersion, mg)
-	return lm.fetchResult(ctx)
+	return lm.FetchResult(ctx)
 }
 
 // FetchLazyModule queries the proxy or the Go repo for the requested module
@@ -82,6 +86,10 @@ func fetchLazyModule(ctx context.Context, modulePath, requestedVersion string, m
 	lm := &LazyModule{
 		requestedVersion: requestedVersion,
 	}
+	switch mg.(type) {
+	case *directoryModuleGetter, *goPackagesModuleGetter:
+		lm.local = true
+	}
 	lm.ModuleInfo.ModulePath = modulePath
 
 	info, err := GetInfo(ctx, modulePath, requestedVersion, mg)
@@ -167,7 +175,7 @@ func fetchLazyModule(ctx context.Context, modulePath, requestedVersion string, m
 		return lm, err
 	}
 	if goModBytes != nil {
-		if err := processGoModFile(goModBytes, &lm.ModuleInfo); err != nil {
+		if err := processGoModFile(goModBytes, lm); err != nil {
 			return lm, fmt.Errorf("%v: %w", err, derrors.BadModule)
 		}
 	}
@@ -201,7 +209,7 @@ func (lm *LazyModule) unit(ctx context.Context, unitMeta *internal.UnitMeta) (*i
 	if !unitMeta.IsPackage() {
 		return moduleUnit(lm.ModulePath, unitMeta, nil, readme, lm.licenseDetector), nil, nil
 	}
-	pkg, pvs, err := extractPackage(ctx, lm.ModulePath, unitMeta.Path, lm.contentDir, lm.licenseDetector, lm.SourceInfo, lm.godocModInfo)
+	pkg, pvs, err := extractPackage(ctx, lm.ModulePath, unitMeta.Path, lm.contentDir, lm.licenseDetector, lm.SourceInfo, lm.godocModInfo, lm.typeChecker, lm.exampleVerifier)
 	if err != nil || (pvs != nil && pvs.Status != 200) {
 		// pvs can be non-nil even if err is non-nil.
 		return nil, pvs, err
@@ -211,13 +219,16 @@ func (lm *LazyModule) unit(ctx context.Context, unitMeta *internal.UnitMeta) (*i
 	return u, pvs, nil
 }
 
-func (lm *LazyModule) fetchResult(ctx context.Context) *FetchResult {
+// FetchResult computes all the units of lm and returns them with the other
+// information about the module as a FetchResult, as FetchModule does.
+func (lm *LazyModule) FetchResult(ctx context.Context) *FetchResult {
 	fr := &FetchResult{
 		ModulePath:       lm.ModulePath,
 		RequestedVersion: lm.requestedVersion,
 		ResolvedVersion:  lm.ModuleInfo.Version,
 		Module: &internal.Module{
-			ModuleInfo: lm.ModuleInfo,
+			ModuleInfo:   lm.ModuleInfo,
+			Requirements: lm.Requirements,
 		},
 		HasGoMod:  lm.HasGoMod,
 		GoModPath: lm.goModPath,
@@ -316,15 +327,22 @@ func hasGoModFile(contentDir fs.FS) bool {
 	return err == nil && !info.IsDir()
 }
 
-// processGoModFile populates mod with information extracted from the contents of the go.mod file.
-func processGoModFile(goModBytes []byte, mod *internal.ModuleInfo) (err error) {
+// processGoModFile populates lm with information extracted from the contents of the go.mod file.
+func processGoModFile(goModBytes []byte, lm *LazyModule) (err error) {
 	defer derrors.Wrap(&err, "processGoModFile")
 
 	mf, err := modfile.Parse("go.mod", goModBytes, nil)
 	if err != nil {
 		return err
 	}
-	mod.Deprecated, mod.DeprecationComment = extractDeprecatedComment(mf)
+	lm.Deprecated, lm.DeprecationComment = extractDeprecatedComment(mf)
+	for _, r := range mf.Require {
+		lm.Requirements = append(lm.Requirements, &internal.Requirement{
+			ModulePath: r.Mod.Path,
+			Version:    r.Mod.Version,
+			Indirect:   r.Indirect,
+		})
+	}
 	return nil
 }
 
@@ -293,3 +293,29 @@ func TestExtractDeprecatedComment(t *testing.T) {
 		}
 	}
 }
+
+func TestProcessGoModFile(t *testing.T) {
+	goMod := `
+		// Deprecated: use m/v2
+		module m
+
+		require (
+			example.com/a v1.0.0
+			example.com/b v0.1.0 // indirect
+		)
+	`
+	var lm LazyModule
+	if err := processGoModFile([]byte(goMod), &lm); err != nil {
+		t.Fatal(err)
+	}
+	if !lm.Deprecated || lm.DeprecationComment != "use m/v2" {
+		t.Errorf("got (%t, %q), want (true, %q)", lm.Deprecated, lm.DeprecationComment, "use m/v2")
+	}
+	want := []*internal.Requirement{
+		{ModulePath: "example.com/a", Version: "v1.0.0"},
+		{ModulePath: "example.com/b", Version: "v0.1.0", Indirect: true},
+	}
+	if diff := cmp.Diff(want, lm.Requirements); diff != "" {
+		t.Errorf("Requirements mismatch (-want, +got):\n%s", diff)
+	}
+}
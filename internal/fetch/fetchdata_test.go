@@ -1701,10 +1701,11 @@ var moduleStd = &testModule{
 								},
 								{
 									SymbolMeta: internal.SymbolMeta{
-										Name:     "InvalidUTF8Error",
-										Synopsis: "type InvalidUTF8Error struct{ ... }",
-										Section:  "Types",
-										Kind:     "Type",
+										Name:       "InvalidUTF8Error",
+										Synopsis:   "type InvalidUTF8Error struct{ ... }",
+										Section:    "Types",
+										Kind:       "Type",
+										Deprecated: true,
 									},
 									Children: []*internal.SymbolMeta{
 										{
@@ -1884,10 +1885,11 @@ var moduleStd = &testModule{
 								},
 								{
 									SymbolMeta: internal.SymbolMeta{
-										Name:     "UnmarshalFieldError",
-										Synopsis: "type UnmarshalFieldError struct{ ... }",
-										Section:  "Types",
-										Kind:     "Type",
+										Name:       "UnmarshalFieldError",
+										Synopsis:   "type UnmarshalFieldError struct{ ... }",
+										Section:    "Types",
+										Kind:       "Type",
+										Deprecated: true,
 									},
 									Children: []*internal.SymbolMeta{
 										{
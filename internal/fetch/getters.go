@@ -23,6 +23,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/mod/modfile"
@@ -63,6 +64,11 @@ type ModuleGetter interface {
 	// internal/frontend.Server.InstallFiles.
 	SourceFS() (string, fs.FS)
 
+	// SourceImportPath returns the import path of the package whose files
+	// are in the directory dir of the FS returned by SourceFS, or "" if it is
+	// not known.
+	SourceImportPath(dir string) string
+
 	// String returns a representation of the getter for testing and debugging.
 	String() string
 }
@@ -75,6 +81,25 @@ type SearchableModuleGetter interface {
 	Search(ctx context.Context, q string, limit int) ([]*internal.SearchResult, error)
 }
 
+// SymbolSearchableModuleGetter is an additional interface that may be
+// implemented by SearchableModuleGetters to support symbol search.
+type SymbolSearchableModuleGetter interface {
+	SearchableModuleGetter
+
+	// SearchSymbols searches for symbols matching the given query, returning
+	// at most limit results. If symbolFilter is non-empty, it is the name of
+	// the symbol to search for, and q selects the packages to search in.
+	SearchSymbols(ctx context.Context, q, symbolFilter string, limit int) ([]*internal.SearchResult, error)
+}
+
+// VersionListingModuleGetter is an additional interface that may be
+// implemented by ModuleGetters that can list the versions of a module.
+type VersionListingModuleGetter interface {
+	// Versions returns the versions of the module with the given path that
+	// the getter can serve, in no particular order.
+	Versions(ctx context.Context, path string) ([]string, error)
+}
+
 // VolatileModuleGetter is an additional interface that may be implemented by
 // ModuleGetters to support invalidating content.
 type VolatileModuleGetter interface {
@@ -122,6 +147,12 @@ func (g *proxyModuleGetter) SourceFS() (string, fs.FS) {
 	return "", nil
 }
 
+// SourceImportPath is unimplemented for modules served from the proxy, because
+// their files are not served.
+func (g *proxyModuleGetter) SourceImportPath(string) string {
+	return ""
+}
+
 func (g *proxyModuleGetter) String() string {
 	return "Proxy"
 }
@@ -215,6 +246,12 @@ func (g *directoryModuleGetter) SourceFS() (string, fs.FS) {
 	return g.fileServingPath(), os.DirFS(g.dir)
 }
 
+// SourceImportPath returns the import path of the package in dir, a directory
+// of the module.
+func (g *directoryModuleGetter) SourceImportPath(dir string) string {
+	return path.Join(g.modulePath, dir)
+}
+
 func (g *directoryModuleGetter) fileServingPath() string {
 	return path.Join(filepath.ToSlash(g.dir), g.modulePath)
 }
@@ -231,6 +268,10 @@ type goPackagesModuleGetter struct {
 	packages []*packages.Package // all packages
 	modules  []*packages.Module  // modules references by packagages; sorted by path
 	isStd    bool
+
+	mu         sync.Mutex   // guards the fields below
+	index      *searchIndex // index of the documentation of packages, or nil
+	indexMtime time.Time    // latest mtime of the files indexed by index
 }
 
 // NewGoPackagesModuleGetter returns a ModuleGetter that loads packages using
@@ -469,66 +510,143 @@ func (g *goPackagesModuleGetter) SourceFS() (string, fs.FS) {
 	return filepath.ToSlash(g.dir), g
 }
 
+// SourceImportPath returns dir, since the files of each loaded module are
+// served under the module path.
+func (g *goPackagesModuleGetter) SourceImportPath(dir string) string {
+	return dir
+}
+
 // For testing.
 func (g *goPackagesModuleGetter) String() string {
 	return fmt.Sprintf("Dir(%s)", g.dir)
 }
 
-// Search implements a crude search, using fuzzy matching to match loaded
-// packages.
-//
-// It parses file headers to produce a synopsis of results.
+// Search searches loaded packages, combining fuzzy matching of package paths
+// with full-text search of their synopses, package comments, READMEs and
+// symbol names.
 func (g *goPackagesModuleGetter) Search(ctx context.Context, query string, limit int) ([]*internal.SearchResult, error) {
-	matcher := fuzzy.NewSymbolMatcher(query)
-
-	type scoredPackage struct {
-		pkg   *packages.Package
-		score float64
+	idx, err := g.searchIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byPath := make(map[string]*internal.SearchResult)
+	for _, r := range idx.searchPackages(query) {
+		byPath[r.PackagePath] = r
 	}
 
-	var pkgs []scoredPackage
+	matcher := fuzzy.NewSymbolMatcher(query)
 	for _, pkg := range g.packages {
 		i, score := matcher.Match([]string{pkg.PkgPath})
 		if i < 0 {
 			continue
 		}
-		pkgs = append(pkgs, scoredPackage{pkg, score})
+		r, ok := byPath[pkg.PkgPath]
+		if !ok {
+			r = &internal.SearchResult{
+				Name:        pkg.Name,
+				PackagePath: pkg.PkgPath,
+				Synopsis:    packageSynopsis(pkg),
+			}
+			if pkg.Module != nil {
+				r.ModulePath = pkg.Module.Path
+				r.Version = pkg.Module.Version
+			}
+			byPath[pkg.PkgPath] = r
+		}
+		r.Score += score
 	}
 
-	// Sort and truncate results before parsing, to save on work.
-	sort.Slice(pkgs, func(i, j int) bool {
-		return pkgs[i].score > pkgs[j].score
-	})
+	var results []*internal.SearchResult
+	for _, r := range byPath {
+		results = append(results, r)
+	}
+	return sortSearchResults(results, limit), nil
+}
 
-	if len(pkgs) > limit {
-		pkgs = pkgs[:limit]
+// SearchSymbols searches for symbols in loaded packages, matching them by
+// name.
+func (g *goPackagesModuleGetter) SearchSymbols(ctx context.Context, query, symbolFilter string, limit int) ([]*internal.SearchResult, error) {
+	idx, err := g.searchIndex(ctx)
+	if err != nil {
+		return nil, err
 	}
+	return sortSearchResults(idx.searchSymbols(query, symbolFilter), limit), nil
+}
 
-	var results []*internal.SearchResult
-	for i, pkg := range pkgs {
-		result := &internal.SearchResult{
-			Name:        pkg.pkg.Name,
-			PackagePath: pkg.pkg.PkgPath,
-			Score:       pkg.score,
-			Offset:      i,
+// sortSearchResults sorts results by decreasing score, breaking ties by
+// package path and symbol name, truncates them to limit and sets their
+// offsets.
+func sortSearchResults(results []*internal.SearchResult, limit int) []*internal.SearchResult {
+	sort.Slice(results, func(i, j int) bool {
+		ri, rj := results[i], results[j]
+		if ri.Score != rj.Score {
+			return ri.Score > rj.Score
 		}
-		if pkg.pkg.Module != nil {
-			result.ModulePath = pkg.pkg.Module.Path
-			result.Version = pkg.pkg.Module.Version
+		if ri.PackagePath != rj.PackagePath {
+			return ri.PackagePath < rj.PackagePath
 		}
-		for _, file := range pkg.pkg.CompiledGoFiles {
-			mode := parser.PackageClauseOnly | parser.ParseComments
-			f, err := parser.ParseFile(token.NewFileSet(), file, nil, mode)
-			if err != nil {
+		return ri.SymbolName < rj.SymbolName
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	for i, r := range results {
+		r.Offset = i
+	}
+	return results
+}
+
+// packageSynopsis parses the file headers of pkg to produce its synopsis.
+func packageSynopsis(pkg *packages.Package) string {
+	var synopsis string
+	for _, file := range pkg.CompiledGoFiles {
+		mode := parser.PackageClauseOnly | parser.ParseComments
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, mode)
+		if err != nil {
+			continue
+		}
+		if f.Doc != nil {
+			synopsis = doc.Synopsis(f.Doc.Text())
+		}
+	}
+	return synopsis
+}
+
+// searchIndex returns an index of the documentation of the loaded packages,
+// building it if it has not been built, or if any of their files have changed
+// since it was.
+func (g *goPackagesModuleGetter) searchIndex(ctx context.Context) (*searchIndex, error) {
+	var latest time.Time
+	for _, pkg := range g.packages {
+		for _, f := range pkg.GoFiles {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			fi, err := os.Stat(f)
+			if os.IsNotExist(err) {
 				continue
 			}
-			if f.Doc != nil {
-				result.Synopsis = doc.Synopsis(f.Doc.Text())
+			if err != nil {
+				return nil, err
+			}
+			if fi.ModTime().After(latest) {
+				latest = fi.ModTime()
 			}
 		}
-		results = append(results, result)
 	}
-	return results, nil
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.index != nil && g.indexMtime.Equal(latest) {
+		return g.index, nil
+	}
+	idx := newSearchIndex(ctx, g.packages)
+	// As in mtime, a recent modification time is unreliable, so don't reuse
+	// the index until it is old enough.
+	if time.Since(latest) >= 2*time.Second {
+		g.index, g.indexMtime = idx, latest
+	}
+	return idx, nil
 }
 
 // HasChanged stats the filesystem to see if content has changed for the
@@ -607,6 +725,10 @@ func (g *stdlibZipModuleGetter) SourceFS() (string, fs.FS) {
 	return "", nil
 }
 
+func (g *stdlibZipModuleGetter) SourceImportPath(string) string {
+	return ""
+}
+
 func (g *stdlibZipModuleGetter) String() string {
 	return "stdlib"
 }
@@ -719,27 +841,56 @@ func (g *modCacheModuleGetter) SourceFS() (string, fs.FS) {
 	return filepath.ToSlash(g.dir), os.DirFS(g.dir)
 }
 
+// SourceImportPath returns the import path of the package in dir, which is
+// laid out like "<escaped module path>@<version>/<package directory>".
+func (g *modCacheModuleGetter) SourceImportPath(dir string) string {
+	before, after, ok := strings.Cut(dir, "@")
+	if !ok {
+		return ""
+	}
+	modulePath, err := module.UnescapePath(before)
+	if err != nil {
+		return ""
+	}
+	_, inner, _ := strings.Cut(after, "/")
+	return path.Join(modulePath, inner)
+}
+
 // latestVersion gets the latest version that is in the directory.
 func (g *modCacheModuleGetter) latestVersion(modulePath string) (_ string, err error) {
 	defer derrors.Wrap(&err, "modCacheModuleGetter.latestVersion(%q)", modulePath)
 
-	dir, err := g.moduleDir(modulePath)
+	versions, err := g.versions(modulePath)
 	if err != nil {
 		return "", err
 	}
+	return version.LatestOf(versions), nil
+}
+
+// Versions returns the versions of the module that have zips in the cache.
+func (g *modCacheModuleGetter) Versions(ctx context.Context, modulePath string) (_ []string, err error) {
+	defer derrors.Wrap(&err, "modCacheModuleGetter.Versions(%q)", modulePath)
+	return g.versions(modulePath)
+}
+
+func (g *modCacheModuleGetter) versions(modulePath string) ([]string, error) {
+	dir, err := g.moduleDir(modulePath)
+	if err != nil {
+		return nil, err
+	}
 	zips, err := filepath.Glob(filepath.Join(dir, "*.zip"))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if len(zips) == 0 {
-		return "", fmt.Errorf("no zips in %q for module %q: %w", g.dir, modulePath, derrors.NotFound)
+		return nil, fmt.Errorf("no zips in %q for module %q: %w", g.dir, modulePath, derrors.NotFound)
 	}
 	var versions []string
 	for _, z := range zips {
 		vers := strings.TrimSuffix(filepath.Base(z), ".zip")
 		versions = append(versions, vers)
 	}
-	return version.LatestOf(versions), nil
+	return versions, nil
 }
 
 func (g *modCacheModuleGetter) readFile(path, version, suffix string) (_ []byte, err error) {
@@ -253,6 +253,24 @@ func TestEscapedPath(t *testing.T) {
 	}
 }
 
+func TestModCacheSourceImportPath(t *testing.T) {
+	g, err := NewModCacheGetter("dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		dir, want string
+	}{
+		{"m.com@v1.0.0", "m.com"},
+		{"github.com/a!bc@v2.3.4/pkg/sub", "github.com/aBc/pkg/sub"},
+		{"cache/download", ""},
+	} {
+		if got := g.SourceImportPath(test.dir); got != test.want {
+			t.Errorf("SourceImportPath(%q) = %q, want %q", test.dir, got, test.want)
+		}
+	}
+}
+
 func TestFSProxyGetter(t *testing.T) {
 	ctx := context.Background()
 	const (
@@ -291,6 +309,18 @@ func TestFSProxyGetter(t *testing.T) {
 			t.Errorf("got %v, want NotFound", err)
 		}
 	})
+	t.Run("versions", func(t *testing.T) {
+		got, err := g.Versions(ctx, modulePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{vers}; !cmp.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+		if _, err := g.Versions(ctx, "nozip.com"); !errors.Is(err, derrors.NotFound) {
+			t.Errorf("got %v, want NotFound", err)
+		}
+	})
 	t.Run("mod", func(t *testing.T) {
 		got, err := g.Mod(ctx, modulePath, vers)
 		if err != nil {
@@ -49,8 +49,11 @@ func (bpe *BadPackageError) Error() string { return bpe.Err.Error() }
 //
 // If a package is fine except that its documentation is too large, loadPackage
 // returns a goPackage whose err field is a non-nil error with godoc.ErrTooLarge in its chain.
+//
+// If tc is non-nil, it is used to link identifiers in the package's declarations.
+// If ev is non-nil, it is used to verify the output of the package's examples.
 func loadPackage(ctx context.Context, contentDir fs.FS, goFilePaths []string, innerPath string,
-	sourceInfo *source.Info, modInfo *godoc.ModuleInfo) (_ *goPackage, err error) {
+	sourceInfo *source.Info, modInfo *godoc.ModuleInfo, tc *typeChecker, ev *exampleVerifier) (_ *goPackage, err error) {
 	defer derrors.Wrap(&err, "loadPackage(ctx, zipGoFiles, %q, sourceInfo, modInfo)", innerPath)
 	ctx, span := trace.StartSpan(ctx, "fetch.loadPackage")
 	defer span.End()
@@ -103,8 +106,8 @@ func loadPackage(ctx context.Context, contentDir fs.FS, goFilePaths []string, in
 			pkg.docs = append(pkg.docs, &doc2)
 			continue
 		}
-		name, imports, synopsis, source, api, err := loadPackageForBuildContext(ctx,
-			mfiles, innerPath, sourceInfo, modInfo)
+		name, imports, synopsis, source, api, impls, err := loadPackageForBuildContext(ctx,
+			mfiles, innerPath, sourceInfo, modInfo, tc, bc)
 		for _, s := range api {
 			s.GOOS = bc.GOOS
 			s.GOARCH = bc.GOARCH
@@ -128,11 +131,12 @@ func loadPackage(ctx context.Context, contentDir fs.FS, goFilePaths []string, in
 				name:    name,
 				imports: imports,
 				docs: []*internal.Documentation{{
-					GOOS:     internal.All,
-					GOARCH:   internal.All,
-					Synopsis: synopsis,
-					Source:   source,
-					API:      api,
+					GOOS:            internal.All,
+					GOARCH:          internal.All,
+					Synopsis:        synopsis,
+					Source:          source,
+					API:             api,
+					Implementations: impls,
 				}},
 			}, nil
 		case err != nil:
@@ -157,11 +161,12 @@ func loadPackage(ctx context.Context, contentDir fs.FS, goFilePaths []string, in
 				}
 			}
 			doc := &internal.Documentation{
-				GOOS:     bc.GOOS,
-				GOARCH:   bc.GOARCH,
-				Synopsis: synopsis,
-				Source:   source,
-				API:      api,
+				GOOS:            bc.GOOS,
+				GOARCH:          bc.GOARCH,
+				Synopsis:        synopsis,
+				Source:          source,
+				API:             api,
+				Implementations: impls,
 			}
 			docsByFiles[filesKey] = doc
 			pkg.docs = append(pkg.docs, doc)
@@ -179,6 +184,14 @@ func loadPackage(ctx context.Context, contentDir fs.FS, goFilePaths []string, in
 			s.GOARCH = internal.All
 		}
 	}
+	if ev != nil && pkg != nil {
+		// Examples are run on the host, so their results are the same for
+		// every build context.
+		results := ev.results(ctx, importPath)
+		for _, doc := range pkg.docs {
+			doc.ExampleResults = results
+		}
+	}
 	return pkg, nil
 }
 
@@ -283,16 +296,29 @@ var httpPost = http.Post
 //
 // If it returns an error with ErrTooLarge in its chain, the other return values
 // are still valid.
-func loadPackageForBuildContext(ctx context.Context, files map[string][]byte, innerPath string, sourceInfo *source.Info, modInfo *godoc.ModuleInfo) (
-	name string, imports []string, synopsis string, source []byte, api []*internal.Symbol, err error) {
+//
+// If tc is non-nil, it type-checks the package for the build context bc to
+// link identifiers in its declarations and returns the implementations it
+// finds.
+func loadPackageForBuildContext(ctx context.Context, files map[string][]byte, innerPath string, sourceInfo *source.Info, modInfo *godoc.ModuleInfo,
+	tc *typeChecker, bc internal.BuildContext) (
+	name string, imports []string, synopsis string, source []byte, api []*internal.Symbol, impls []*internal.Implementation, err error) {
 	modulePath := modInfo.ModulePath
 	defer derrors.Wrap(&err, "loadPackageWithBuildContext(files, %q, %q, %+v)", innerPath, modulePath, sourceInfo)
 
 	packageName, goFiles, fset, err := loadFilesWithBuildContext(innerPath, files)
 	if err != nil {
-		return "", nil, "", nil, nil, err
+		return "", nil, "", nil, nil, nil, err
 	}
 	docPkg := godoc.NewPackage(fset, modInfo.ModulePackages)
+	importPath := path.Join(modulePath, innerPath)
+	if modulePath == stdlib.ModulePath {
+		importPath = innerPath
+	}
+	if tc != nil {
+		// Type-check before AddFile removes nodes from the files.
+		docPkg.IdentLinks, docPkg.PromotedMethods, impls = tc.check(ctx, importPath, fset, goFiles, bc)
+	}
 	for _, pf := range goFiles {
 		removeNodes := true
 		// Don't strip the seemingly unexported functions from the builtin package;
@@ -306,14 +332,14 @@ func loadPackageForBuildContext(ctx context.Context, files map[string][]byte, in
 	// Encode first, because Render messes with the AST.
 	src, err := docPkg.Encode(ctx)
 	if err != nil {
-		return "", nil, "", nil, nil, err
+		return "", nil, "", nil, nil, nil, err
 	}
 
 	synopsis, imports, api, err = docPkg.DocInfo(ctx, innerPath, sourceInfo, modInfo)
 	if err != nil {
-		return "", nil, "", nil, nil, err
+		return "", nil, "", nil, nil, nil, err
 	}
-	return packageName, imports, synopsis, src, api, err
+	return packageName, imports, synopsis, src, api, impls, err
 }
 
 // loadFilesWithBuildContext loads all the given Go files at innerPath. It
@@ -407,7 +433,8 @@ func loadPackageName(innerPath string, files map[string][]byte) (pkgName string,
 }
 
 // matchingFiles returns a map from file names to their contents, read from zipGoFiles.
-// It includes only those files that match the build context determined by goos and goarch.
+// It includes only those files that match the build context determined by goos
+// and goarch, with the additional build tags in internal.BuildTags.
 func matchingFiles(goos, goarch string, allFiles map[string][]byte) (matchedFiles map[string][]byte, err error) {
 	defer derrors.Wrap(&err, "matchingFiles(%q, %q, zipGoFiles)", goos, goarch)
 
@@ -419,6 +446,7 @@ func matchingFiles(goos, goarch string, allFiles map[string][]byte) (matchedFile
 		CgoEnabled:  true,
 		Compiler:    build.Default.Compiler,
 		ReleaseTags: build.Default.ReleaseTags,
+		BuildTags:   internal.BuildTags,
 
 		JoinPath: path.Join,
 		OpenFile: func(name string) (io.ReadCloser, error) {
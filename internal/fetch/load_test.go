@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/testing/testhelper"
 )
 
@@ -21,6 +22,11 @@ func TestMatchingFiles(t *testing.T) {
 		// Package js only works with wasm.
 		package js
 		type Value int`
+	boardGoBody := `
+		//go:build linux && arm64 && board
+
+		package board
+		type Value int`
 
 	plainContents := map[string]string{
 		"README.md":  "THIS IS A README",
@@ -33,9 +39,14 @@ func TestMatchingFiles(t *testing.T) {
 		"LICENSE.md": testhelper.MITLicense,
 		"js.go":      jsGoBody,
 	}
+
+	boardContents := map[string]string{
+		"board.go": boardGoBody,
+	}
 	for _, test := range []struct {
 		name         string
 		goos, goarch string
+		tags         []string
 		contents     map[string]string
 		want         map[string][]byte
 	}{
@@ -73,8 +84,27 @@ func TestMatchingFiles(t *testing.T) {
 				"js.go": []byte(jsGoBody),
 			},
 		},
+		{
+			name:     "board-no-tags",
+			goos:     "linux",
+			goarch:   "arm64",
+			contents: boardContents,
+			want:     map[string][]byte{},
+		},
+		{
+			name:     "board-tags",
+			goos:     "linux",
+			goarch:   "arm64",
+			tags:     []string{"board"},
+			contents: boardContents,
+			want: map[string][]byte{
+				"board.go": []byte(boardGoBody),
+			},
+		},
 	} {
 		t.Run(test.name, func(t *testing.T) {
+			defer func(tags []string) { internal.BuildTags = tags }(internal.BuildTags)
+			internal.BuildTags = test.tags
 			files := map[string][]byte{}
 			for n, c := range test.contents {
 				files[n] = []byte(c)
@@ -22,6 +22,7 @@ import (
 	"golang.org/x/pkgsite/internal/licenses"
 	"golang.org/x/pkgsite/internal/log"
 	"golang.org/x/pkgsite/internal/source"
+	"golang.org/x/pkgsite/internal/stdlib"
 	"golang.org/x/pkgsite/internal/trace"
 	"golang.org/x/sync/errgroup"
 )
@@ -57,7 +58,9 @@ func rel(pkgPath, modulePath string) string {
 // It returns a packageVersionState representing the status of doing the work
 // of computing the package after the UnitMeta was computed. The packageVersionState
 // of a package that failed to have a UnitMeta produced was produced by extractPackageMetas.
-func extractPackage(ctx context.Context, modulePath, pkgPath string, contentDir fs.FS, d *licenses.Detector, sourceInfo *source.Info, modInfo *godoc.ModuleInfo) (*goPackage, *internal.PackageVersionState, error) {
+// If tc is non-nil, it is used to link identifiers in the package's declarations.
+// If ev is non-nil, it is used to verify the output of the package's examples.
+func extractPackage(ctx context.Context, modulePath, pkgPath string, contentDir fs.FS, d *licenses.Detector, sourceInfo *source.Info, modInfo *godoc.ModuleInfo, tc *typeChecker, ev *exampleVerifier) (*goPackage, *internal.PackageVersionState, error) {
 	innerPath := rel(pkgPath, modulePath)
 	f, err := contentDir.Open(innerPath)
 	if err != nil {
@@ -91,7 +94,7 @@ func extractPackage(ctx context.Context, modulePath, pkgPath string, contentDir
 		status error
 		errMsg string
 	)
-	pkg, err := loadPackage(ctx, contentDir, goFiles, innerPath, sourceInfo, modInfo)
+	pkg, err := loadPackage(ctx, contentDir, goFiles, innerPath, sourceInfo, modInfo, tc, ev)
 	if bpe := (*BadPackageError)(nil); errors.As(err, &bpe) {
 		log.Infof(ctx, "Error loading %s: %v", innerPath, err)
 		status = derrors.PackageInvalidContents
@@ -207,7 +210,13 @@ func extractPackageMetas(ctx context.Context, modulePath, resolvedVersion string
 			return err
 		}
 		if d.IsDir() {
-			// Skip directories.
+			// Skip nested modules. Module zips never contain them, but
+			// directories on the local filesystem (for example, modules in a
+			// go.work workspace) may. The standard library is exempt, because
+			// its cmd packages are served as part of the std module.
+			if pathname != "." && modulePath != stdlib.ModulePath && isModuleRoot(contentDir, pathname) {
+				return fs.SkipDir
+			}
 			return nil
 		}
 		innerPath := path.Dir(pathname)
@@ -386,3 +395,10 @@ func isVendored(importPath string) bool {
 	return strings.HasPrefix(importPath, "vendor/") ||
 		strings.Contains(importPath, "/vendor/")
 }
+
+// isModuleRoot reports whether the directory dir in contentDir contains a
+// go.mod file, and is therefore the root of a separate module.
+func isModuleRoot(contentDir fs.FS, dir string) bool {
+	info, err := fs.Stat(contentDir, path.Join(dir, "go.mod"))
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,426 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/godoc/dochtml"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/search/text"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/tools/go/packages"
+	"rsc.io/markdown"
+)
+
+// Weights of the sections of an indexed package. They mirror the default
+// weights of Postgres's ts_rank, which ranks the search documents used in
+// production: the package name and path are weighted as section A, the
+// synopsis and symbol names as section B, and the rest of the package comment
+// and README as sections C and D.
+const (
+	weightA = 1.0
+	weightB = 0.4
+	weightC = 0.2
+	weightD = 0.1
+)
+
+// A searchIndex is an in-memory inverted index over the documentation of a
+// set of packages. It supports full-text search of package documentation
+// and READMEs, and search for symbols by name, without a database.
+type searchIndex struct {
+	packages []*indexedPackage
+	words    map[string][]wordHit        // stemmed word -> packages containing it
+	symbols  map[string][]*indexedSymbol // lower-cased symbol name -> symbols
+}
+
+// An indexedPackage is a package in a searchIndex.
+type indexedPackage struct {
+	name        string
+	packagePath string
+	modulePath  string
+	version     string
+	synopsis    string
+}
+
+// A wordHit records that a word appears in a package, with the weight of the
+// highest-weighted section it appears in.
+type wordHit struct {
+	pkg    int // index into searchIndex.packages
+	weight float64
+}
+
+// An indexedSymbol is a symbol in a searchIndex.
+type indexedSymbol struct {
+	pkg int // index into searchIndex.packages
+	internal.SymbolMeta
+}
+
+// newSearchIndex builds a searchIndex from the documentation of pkgs.
+// Packages that cannot be parsed are skipped.
+func newSearchIndex(ctx context.Context, pkgs []*packages.Package) *searchIndex {
+	docs := make([]*packageDoc, len(pkgs))
+	var g errgroup.Group
+	g.SetLimit(runtime.GOMAXPROCS(0))
+	for i, pkg := range pkgs {
+		i, pkg := i, pkg
+		g.Go(func() error {
+			d, err := loadPackageDoc(pkg)
+			if err != nil {
+				log.Debugf(ctx, "indexing %s: %v", pkg.PkgPath, err)
+				return nil
+			}
+			docs[i] = d
+			return nil
+		})
+	}
+	g.Wait()
+
+	idx := &searchIndex{
+		words:   make(map[string][]wordHit),
+		symbols: make(map[string][]*indexedSymbol),
+	}
+	for _, d := range docs {
+		if d != nil {
+			idx.add(d)
+		}
+	}
+	return idx
+}
+
+// packageDoc holds the documentation of a package to be indexed.
+type packageDoc struct {
+	pkg     indexedPackage
+	doc     string
+	readme  *internal.Readme
+	symbols []*internal.Symbol
+}
+
+// loadPackageDoc parses the non-test Go files of pkg and reads its README, if
+// any.
+func loadPackageDoc(pkg *packages.Package) (*packageDoc, error) {
+	if len(pkg.GoFiles) == 0 {
+		return nil, nil
+	}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range pkg.GoFiles {
+		f, err := parser.ParseFile(fset, name, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		if f.Name.Name == pkg.Name {
+			files = append(files, f)
+		}
+	}
+	p, err := doc.NewFromFiles(fset, files, pkg.PkgPath)
+	if err != nil {
+		return nil, err
+	}
+	syms, err := dochtml.GetSymbols(p, fset, nil)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(pkg.GoFiles[0])
+	readme, err := extractReadme(pkg.PkgPath, pkg.PkgPath, "", os.DirFS(dir))
+	if err != nil {
+		return nil, err
+	}
+	d := &packageDoc{
+		pkg: indexedPackage{
+			name:        pkg.Name,
+			packagePath: pkg.PkgPath,
+			synopsis:    p.Synopsis(p.Doc),
+		},
+		doc:     p.Doc,
+		readme:  readme,
+		symbols: syms,
+	}
+	if pkg.Module != nil {
+		d.pkg.modulePath = pkg.Module.Path
+		d.pkg.version = pkg.Module.Version
+	}
+	return d, nil
+}
+
+// add adds the documentation of a package to the index.
+func (idx *searchIndex) add(d *packageDoc) {
+	pi := len(idx.packages)
+	idx.packages = append(idx.packages, &d.pkg)
+
+	weights := make(map[string]float64)
+	addWords := func(words []string, weight float64) {
+		for _, w := range words {
+			w = stem(w)
+			if w != "" && weight > weights[w] {
+				weights[w] = weight
+			}
+		}
+	}
+	// Section A: the package name and the elements of its path.
+	addWords(text.ProcessWords(d.pkg.name), weightA)
+	addWords(text.ProcessWords(strings.ReplaceAll(d.pkg.packagePath, "/", " ")), weightA)
+
+	// Sections B through D: the synopsis and README, processed as for the
+	// search documents in Postgres. The rest of the package comment is
+	// weighted like the first sentence of the README.
+	var readme string
+	if d.readme != nil {
+		readme = readmeText(d.readme)
+	}
+	b, c, dd := text.DocumentSections(d.pkg.synopsis, readme, text.MaxSectionWords, text.MaxReadmeFraction)
+	addWords(strings.Fields(b), weightB)
+	addWords(strings.Fields(c), weightC)
+	addWords(strings.Fields(dd), weightD)
+	addWords(text.ProcessWords(strings.TrimPrefix(d.doc, d.pkg.synopsis)), weightC)
+
+	for _, s := range d.symbols {
+		idx.addSymbol(pi, s.SymbolMeta)
+		addWords([]string{strings.ToLower(s.Name)}, weightB)
+		for _, c := range s.Children {
+			idx.addSymbol(pi, *c)
+		}
+	}
+	for w, weight := range weights {
+		idx.words[w] = append(idx.words[w], wordHit{pkg: pi, weight: weight})
+	}
+}
+
+// readmeText returns the text of a README. If it is markdown, the
+// formatting, images and code blocks are omitted.
+func readmeText(r *internal.Readme) string {
+	if !text.IsMarkdown(r.Filepath) {
+		return r.Contents
+	}
+	doc := (&markdown.Parser{}).Parse(r.Contents)
+	var buf bytes.Buffer
+	markdownText(&buf, doc.Blocks)
+	return buf.String()
+}
+
+// markdownText writes the text of the markdown blocks bs to buf.
+func markdownText(buf *bytes.Buffer, bs []markdown.Block) {
+	writeText := func(t *markdown.Text) {
+		if buf.Len() > 0 {
+			buf.WriteByte(' ')
+		}
+		markdownInlineText(buf, t.Inline)
+	}
+	for _, b := range bs {
+		switch b := b.(type) {
+		case *markdown.Paragraph:
+			writeText(b.Text)
+		case *markdown.Heading:
+			writeText(b.Text)
+		case *markdown.Text:
+			writeText(b)
+		case *markdown.Quote:
+			markdownText(buf, b.Blocks)
+		case *markdown.List:
+			markdownText(buf, b.Items)
+		case *markdown.Item:
+			markdownText(buf, b.Blocks)
+		}
+		// Code blocks are skipped because they have a wide variety of
+		// unrelated symbols, and HTML blocks because they are markup.
+	}
+}
+
+// markdownInlineText writes the text of the markdown inlines ins to buf.
+func markdownInlineText(buf *bytes.Buffer, ins []markdown.Inline) {
+	for _, in := range ins {
+		switch in := in.(type) {
+		case *markdown.Image:
+			// Skip images because they usually are irrelevant to the
+			// package (badges and such).
+		case *markdown.Link:
+			markdownInlineText(buf, in.Inner)
+		case *markdown.Strong:
+			markdownInlineText(buf, in.Inner)
+		case *markdown.Emph:
+			markdownInlineText(buf, in.Inner)
+		case *markdown.Del:
+			markdownInlineText(buf, in.Inner)
+		default:
+			in.PrintText(buf)
+		}
+	}
+}
+
+func (idx *searchIndex) addSymbol(pkg int, sm internal.SymbolMeta) {
+	s := &indexedSymbol{pkg: pkg, SymbolMeta: sm}
+	name := strings.ToLower(sm.Name)
+	idx.symbols[name] = append(idx.symbols[name], s)
+	// Also index methods and fields by their own name, so that a search for
+	// "Begin" finds "DB.Begin".
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		idx.symbols[name[i+1:]] = append(idx.symbols[name[i+1:]], s)
+	}
+}
+
+// scorePackages returns the score of each package matching the words of the
+// query q. A package's score is the sum of the weights of the query words it
+// contains, scaled by the fraction of query words it contains.
+func (idx *searchIndex) scorePackages(q string) map[int]float64 {
+	var words []string
+	for _, w := range text.ProcessWords(q) {
+		if w = stem(w); w != "" && !stopWords[w] {
+			words = append(words, w)
+		}
+	}
+	if len(words) == 0 {
+		return nil
+	}
+	scores := make(map[int]float64)
+	matched := make(map[int]int)
+	for _, w := range words {
+		for _, h := range idx.words[w] {
+			scores[h.pkg] += h.weight
+			matched[h.pkg]++
+		}
+	}
+	for pi := range scores {
+		scores[pi] *= float64(matched[pi]) / float64(len(words))
+	}
+	return scores
+}
+
+// searchPackages returns the packages matching q, with the scores computed by
+// scorePackages.
+func (idx *searchIndex) searchPackages(q string) []*internal.SearchResult {
+	var results []*internal.SearchResult
+	for pi, score := range idx.scorePackages(q) {
+		r := idx.packages[pi].searchResult()
+		r.Score = score
+		results = append(results, r)
+	}
+	return results
+}
+
+// searchSymbols returns the symbols matching q.
+//
+// If symbolFilter is non-empty, it is the name of the symbol to search for,
+// and the words of q select the packages to search in. Otherwise, q is
+// interpreted as symbol search queries are in Postgres: a single word is a
+// symbol name, optionally qualified by a package name (as in "sql.DB" or
+// "sql.DB.Begin"), and in a query of several words one word is a symbol name
+// and the others select packages.
+func (idx *searchIndex) searchSymbols(q, symbolFilter string) []*internal.SearchResult {
+	var results []*internal.SearchResult
+	add := func(symbol, pkgName, pkgQuery string) {
+		var pkgScores map[int]float64
+		if pkgQuery != "" {
+			pkgScores = idx.scorePackages(pkgQuery)
+		}
+		for _, s := range idx.symbols[strings.ToLower(symbol)] {
+			p := idx.packages[s.pkg]
+			if pkgName != "" && p.name != pkgName {
+				continue
+			}
+			score := symbolScore(s, symbol)
+			if pkgQuery != "" {
+				ps, ok := pkgScores[s.pkg]
+				if !ok {
+					continue
+				}
+				score += ps
+			}
+			r := p.searchResult()
+			r.Score = score
+			r.SymbolName = s.Name
+			r.SymbolKind = s.Kind
+			r.SymbolSynopsis = s.Synopsis
+			r.SymbolGOOS = internal.All
+			r.SymbolGOARCH = internal.All
+			results = append(results, r)
+		}
+	}
+
+	words := strings.Fields(q)
+	switch {
+	case symbolFilter != "":
+		add(symbolFilter, "", q)
+	case len(words) == 1:
+		add(q, "", "")
+		if pkgName, symbol, ok := strings.Cut(q, "."); ok {
+			add(symbol, pkgName, "")
+		}
+	default:
+		for i, w := range words {
+			rest := append(append([]string(nil), words[:i]...), words[i+1:]...)
+			add(w, "", strings.Join(rest, " "))
+		}
+	}
+	return dedupSymbolResults(results)
+}
+
+// symbolScore scores how well s matches the requested symbol name: exact
+// matches are best, followed by case-insensitive matches of the full name,
+// and finally matches of only a method or field name.
+func symbolScore(s *indexedSymbol, symbol string) float64 {
+	switch {
+	case s.Name == symbol:
+		return 1
+	case strings.EqualFold(s.Name, symbol):
+		return 0.8
+	default:
+		return 0.6
+	}
+}
+
+// dedupSymbolResults removes duplicate symbols from rs, which can arise when a
+// query is interpreted in more than one way, keeping the highest-scoring one.
+func dedupSymbolResults(rs []*internal.SearchResult) []*internal.SearchResult {
+	sort.Slice(rs, func(i, j int) bool { return rs[i].Score > rs[j].Score })
+	type key struct{ pkg, symbol string }
+	seen := make(map[key]bool)
+	var out []*internal.SearchResult
+	for _, r := range rs {
+		k := key{r.PackagePath, r.SymbolName}
+		if !seen[k] {
+			seen[k] = true
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func (p *indexedPackage) searchResult() *internal.SearchResult {
+	return &internal.SearchResult{
+		Name:        p.name,
+		PackagePath: p.packagePath,
+		ModulePath:  p.modulePath,
+		Version:     p.version,
+		Synopsis:    p.synopsis,
+	}
+}
+
+// stem reduces a word to a crude stem, so that singular and plural forms of a
+// word match. It is a much simpler version of the Postgres English stemmer.
+func stem(w string) string {
+	if len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") {
+		return w[:len(w)-1]
+	}
+	return w
+}
+
+// stopWords are words ignored in queries, because they appear in most
+// documentation. The Postgres English configuration ignores them too.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "for": true, "from": true, "in": true,
+	"is": true, "it": true, "of": true, "on": true, "or": true, "the": true,
+	"that": true, "this": true, "to": true, "with": true,
+}
@@ -0,0 +1,133 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/testenv"
+	"golang.org/x/pkgsite/internal/testing/testhelper"
+)
+
+const searchModule = `
+-- go.mod --
+module example.com/search
+
+go 1.19
+-- yaml/yaml.go --
+// Package yaml parses YAML documents.
+//
+// It supports anchors and aliases.
+package yaml
+
+// Decoder reads YAML documents from a stream.
+type Decoder struct{}
+
+// Decode decodes the next document.
+func (d *Decoder) Decode(v any) error { return nil }
+
+// Unmarshal parses a document.
+func Unmarshal(data []byte, v any) error { return nil }
+-- yaml/README.md --
+# yaml
+
+A fast configuration file reader.
+-- json/json.go --
+// Package json encodes and decodes JSON.
+package json
+
+// Unmarshal parses JSON-encoded data.
+func Unmarshal(data []byte, v any) error { return nil }
+`
+
+func TestSearchIndex(t *testing.T) {
+	testenv.MustHaveExecPath(t, "go")
+
+	ctx := context.Background()
+	dir, _ := testhelper.WriteTxtarToTempDir(t, searchModule)
+	g, err := NewGoPackagesModuleGetter(ctx, dir, "example.com/search/...")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("packages", func(t *testing.T) {
+		for _, test := range []struct {
+			query string
+			want  []string
+		}{
+			{"yaml", []string{"example.com/search/yaml"}},
+			{"parses documents", []string{"example.com/search/yaml"}},    // synopsis
+			{"aliases", []string{"example.com/search/yaml"}},             // package comment
+			{"configuration files", []string{"example.com/search/yaml"}}, // README
+			{"encodes", []string{"example.com/search/json"}},
+			{"unmarshal", []string{"example.com/search/json", "example.com/search/yaml"}}, // symbol names
+			{"xxxxxx", nil},
+		} {
+			results, err := g.Search(ctx, test.query, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, r := range results {
+				got = append(got, r.PackagePath)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Search(%q) mismatch [-want +got]:\n%s", test.query, diff)
+			}
+		}
+	})
+
+	t.Run("symbols", func(t *testing.T) {
+		for _, test := range []struct {
+			query, filter string
+			want          []string
+		}{
+			{"Unmarshal", "", []string{"example.com/search/json.Unmarshal", "example.com/search/yaml.Unmarshal"}},
+			{"yaml.Unmarshal", "", []string{"example.com/search/yaml.Unmarshal"}},
+			{"Decode", "", []string{"example.com/search/yaml.Decoder.Decode"}},
+			{"Decoder.Decode", "", []string{"example.com/search/yaml.Decoder.Decode"}},
+			{"json", "Unmarshal", []string{"example.com/search/json.Unmarshal"}},
+			{"unmarshal encodes", "", []string{"example.com/search/json.Unmarshal"}},
+			{"Marshal", "", nil},
+		} {
+			results, err := g.SearchSymbols(ctx, test.query, test.filter, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, r := range results {
+				got = append(got, r.PackagePath+"."+r.SymbolName)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("SearchSymbols(%q, %q) mismatch [-want +got]:\n%s", test.query, test.filter, diff)
+			}
+		}
+	})
+}
+
+func TestReadmeText(t *testing.T) {
+	for _, test := range []struct {
+		filename, contents, want string
+	}{
+		{"README", "Plain *text*.", "Plain *text*."},
+		{
+			"README.md",
+			"# Title [![Build](https://example.com/badge.svg)](https://example.com)\n\n" +
+				"_Pkg_ is a [Markdown][1] *processor*.\n\n" +
+				"```\ncode()\n```\n\n" +
+				"- one\n- two\n\n" +
+				"[1]: https://example.com/md\n",
+			"Title  Pkg is a Markdown processor. one two",
+		},
+	} {
+		got := readmeText(&internal.Readme{Filepath: test.filename, Contents: test.contents})
+		if got != test.want {
+			t.Errorf("%s: got %q, want %q", test.filename, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,625 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/godoc"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/stdlib"
+)
+
+// A typeChecker type-checks the packages of a module, so that the identifiers
+// in their declarations can be linked to the exact declarations they refer
+// to, even when those are in other modules, are promoted through embedding or
+// come from dot-imports.
+//
+// Module zips contain no export data, so imported packages are type-checked
+// from source as well: packages of the module itself from its content
+// directory, packages of the standard library from the local GOROOT, and
+// packages of other modules from the versions required by the module's go.mod
+// file, fetched with the first of the getters that has them. Type errors,
+// including those from packages that cannot be found, are ignored; they only
+// result in fewer links.
+type typeChecker struct {
+	modulePath string
+	contentDir fs.FS
+	goroot     fs.FS                     // the src directory of GOROOT; nil if unknown
+	requires   map[string]module.Version // module versions to use, by required module path
+	getters    []ModuleGetter
+	packages   []string // import paths of the packages of the module
+
+	mu        sync.Mutex // protects the fields below, and serializes type-checking
+	importers map[internal.BuildContext]*sourceImporter
+	modules   map[module.Version]fs.FS // nil value if the module could not be fetched
+}
+
+// EnableTypeChecking arranges for the packages of lm to be type-checked when
+// their units are computed, so that identifiers in their declarations link to
+// the exact declarations they refer to. Dependencies of the module are fetched
+// with the first of getters that has them.
+//
+// Type-checking is expensive: it must be done from source for every
+// package imported directly or indirectly by a package of the module.
+func (lm *LazyModule) EnableTypeChecking(getters []ModuleGetter) {
+	if lm.contentDir == nil {
+		return
+	}
+	tc := &typeChecker{
+		modulePath: lm.ModulePath,
+		contentDir: lm.contentDir,
+		requires:   map[string]module.Version{},
+		getters:    getters,
+		importers:  map[internal.BuildContext]*sourceImporter{},
+		modules:    map[module.Version]fs.FS{},
+	}
+	for _, um := range lm.UnitMetas {
+		if um.IsPackage() {
+			tc.packages = append(tc.packages, um.Path)
+		}
+	}
+	if build.Default.GOROOT != "" {
+		tc.goroot = os.DirFS(filepath.Join(build.Default.GOROOT, "src"))
+	}
+	if data, err := readFSFile(lm.contentDir, "go.mod", MaxFileSize); err == nil {
+		if mf, err := modfile.ParseLax("go.mod", data, nil); err == nil {
+			for _, r := range mf.Require {
+				tc.requires[r.Mod.Path] = r.Mod
+			}
+			// Directory replacements cannot be followed.
+			for _, r := range mf.Replace {
+				if req, ok := tc.requires[r.Old.Path]; ok && r.New.Version != "" &&
+					(r.Old.Version == "" || r.Old.Version == req.Version) {
+					tc.requires[r.Old.Path] = r.New
+				}
+			}
+		}
+	}
+	lm.typeChecker = tc
+}
+
+// check type-checks the non-test files of the package with the given import
+// path for the build context bc. It returns a map from the positions of
+// identifiers in the files to the declarations they refer to, in the form
+// described by godoc.Package.IdentLinks, the methods promoted to the
+// exported types of the package through embedding, and the implementation
+// relationships of the exported types of the package.
+func (tc *typeChecker) check(ctx context.Context, importPath string, fset *token.FileSet, files map[string]*ast.File, bc internal.BuildContext) (
+	map[token.Pos]string, []*godoc.PromotedMethod, []*godoc.Implementation) {
+	var names []string
+	for name := range files {
+		if !strings.HasSuffix(name, "_test.go") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var astFiles []*ast.File
+	for _, name := range names {
+		astFiles = append(astFiles, files[name])
+	}
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	imp := tc.importers[bc]
+	if imp == nil {
+		imp = &sourceImporter{
+			tc:     tc,
+			bc:     bc,
+			fset:   token.NewFileSet(),
+			pkgs:   map[string]*importResult{},
+			fields: map[*types.Var]string{},
+		}
+		tc.importers[bc] = imp
+	}
+	imp.ctx = ctx
+	info := &types.Info{Uses: map[*ast.Ident]types.Object{}}
+	// The package is returned even if there are type errors.
+	pkg, _ := imp.config().Check(importPath, fset, astFiles, info)
+	imp.addFields(pkg)
+	links := map[token.Pos]string{}
+	for id, obj := range info.Uses {
+		if link := imp.link(obj); link != "" {
+			links[id.Pos()] = link
+		}
+	}
+	return links, imp.promotedMethods(pkg), imp.implementations(importPath)
+}
+
+// moduleContentDir returns the contents of the module version m, or nil if
+// none of the getters has it. It must be called with tc.mu held.
+func (tc *typeChecker) moduleContentDir(ctx context.Context, m module.Version) fs.FS {
+	if fsys, ok := tc.modules[m]; ok {
+		return fsys
+	}
+	var fsys fs.FS
+	for _, g := range tc.getters {
+		dir, err := g.ContentDir(ctx, m.Path, m.Version)
+		if err == nil {
+			fsys = dir
+			break
+		}
+	}
+	if fsys == nil {
+		log.Infof(ctx, "typeChecker: could not fetch %s", m)
+	}
+	tc.modules[m] = fsys
+	return fsys
+}
+
+// findPackage returns the filesystem and directory holding the package with
+// the given import path. It must be called with tc.mu held.
+func (tc *typeChecker) findPackage(ctx context.Context, importPath string) (fs.FS, string, error) {
+	if tc.modulePath == stdlib.ModulePath {
+		if stdlib.Contains(importPath) {
+			return tc.contentDir, importPath, nil
+		}
+		return tc.contentDir, path.Join("vendor", importPath), nil
+	}
+	if inModule(importPath, tc.modulePath) {
+		return tc.contentDir, innerDir(importPath, tc.modulePath), nil
+	}
+	if stdlib.Contains(importPath) {
+		if tc.goroot == nil {
+			return nil, "", fmt.Errorf("no GOROOT for %q", importPath)
+		}
+		return tc.goroot, importPath, nil
+	}
+	// Use the required module with the longest path that is a prefix of
+	// importPath.
+	var modPath string
+	for p := range tc.requires {
+		if inModule(importPath, p) && len(p) > len(modPath) {
+			modPath = p
+		}
+	}
+	if modPath != "" {
+		m := tc.requires[modPath]
+		fsys := tc.moduleContentDir(ctx, m)
+		if fsys == nil {
+			return nil, "", fmt.Errorf("module %s not found", m)
+		}
+		return fsys, innerDir(importPath, modPath), nil
+	}
+	// The standard library vendors some packages from other modules.
+	if tc.goroot != nil {
+		return tc.goroot, path.Join("vendor", importPath), nil
+	}
+	return nil, "", fmt.Errorf("no module provides %q", importPath)
+}
+
+// inModule reports whether importPath is in the module with path modulePath,
+// ignoring nested modules.
+func inModule(importPath, modulePath string) bool {
+	return importPath == modulePath || strings.HasPrefix(importPath, modulePath+"/")
+}
+
+// innerDir returns the directory of the package with the given import path
+// relative to the root of the module with path modulePath.
+func innerDir(importPath, modulePath string) string {
+	return path.Join(".", strings.TrimPrefix(importPath, modulePath))
+}
+
+// A sourceImporter is a types.Importer that type-checks imported packages
+// from source for a single build context.
+type sourceImporter struct {
+	ctx  context.Context // context of the current call to check
+	tc   *typeChecker
+	bc   internal.BuildContext
+	fset *token.FileSet
+	pkgs map[string]*importResult // by import path
+	// fields maps the fields of the struct types declared at package level
+	// in the checked packages to their anchor IDs.
+	fields map[*types.Var]string
+}
+
+type importResult struct {
+	pkg *types.Package // nil while the package is being imported
+	err error
+}
+
+func (imp *sourceImporter) config() *types.Config {
+	return &types.Config{
+		Importer:         imp,
+		IgnoreFuncBodies: true,
+		FakeImportC:      true,
+		Error:            func(error) {}, // keep going after errors
+		Sizes:            types.SizesFor("gc", imp.bc.GOARCH),
+	}
+}
+
+// Import implements types.Importer.
+func (imp *sourceImporter) Import(importPath string) (*types.Package, error) {
+	if importPath == "unsafe" {
+		return types.Unsafe, nil
+	}
+	if r, ok := imp.pkgs[importPath]; ok {
+		if r.pkg == nil && r.err == nil {
+			return nil, fmt.Errorf("import cycle through %q", importPath)
+		}
+		return r.pkg, r.err
+	}
+	r := &importResult{}
+	imp.pkgs[importPath] = r
+	r.pkg, r.err = imp.load(importPath)
+	if r.err == nil {
+		imp.addFields(r.pkg)
+	}
+	return r.pkg, r.err
+}
+
+// load type-checks the package with the given import path from source.
+func (imp *sourceImporter) load(importPath string) (*types.Package, error) {
+	fsys, dir, err := imp.tc.findPackage(imp.ctx, importPath)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]byte{}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		data, err := readFSFile(fsys, path.Join(dir, name), MaxFileSize)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = data
+	}
+	files, err = matchingFiles(imp.bc.GOOS, imp.bc.GOARCH, files)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var astFiles []*ast.File
+	for _, name := range names {
+		f, err := parser.ParseFile(imp.fset, path.Join(importPath, name), files[name], parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		// Skip files like generators that are excluded only by their
+		// package name.
+		if len(astFiles) > 0 && f.Name.Name != astFiles[0].Name.Name {
+			continue
+		}
+		astFiles = append(astFiles, f)
+	}
+	if len(astFiles) == 0 {
+		return nil, fmt.Errorf("no Go files for %q in %s", importPath, imp.bc)
+	}
+	// The package is returned even if there are type errors.
+	pkg, _ := imp.config().Check(importPath, imp.fset, astFiles, nil)
+	return pkg, nil
+}
+
+// addFields records the anchor IDs of the fields of the struct types declared
+// at package level in pkg.
+func (imp *sourceImporter) addFields(pkg *types.Package) {
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		tn, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || !tn.Exported() {
+			continue
+		}
+		st, ok := tn.Type().Underlying().(*types.Struct)
+		if !ok {
+			continue
+		}
+		for i := 0; i < st.NumFields(); i++ {
+			if f := st.Field(i); f.Exported() {
+				imp.fields[f] = name + "." + f.Name()
+			}
+		}
+	}
+}
+
+// link returns the link to the declaration of obj, in the form described by
+// godoc.Package.IdentLinks, or the empty string if obj has no documentation
+// of its own.
+func (imp *sourceImporter) link(obj types.Object) string {
+	switch obj := obj.(type) {
+	case *types.PkgName:
+		return obj.Imported().Path()
+	case *types.Builtin, *types.Nil:
+		return "builtin#" + obj.Name()
+	}
+	if obj.Pkg() == nil {
+		if obj.Parent() == types.Universe {
+			return "builtin#" + obj.Name()
+		}
+		return "" // e.g. the Error method of error
+	}
+	if !obj.Exported() {
+		return ""
+	}
+	prefix := obj.Pkg().Path() + "#"
+	switch obj := obj.(type) {
+	case *types.Func:
+		recv := obj.Type().(*types.Signature).Recv()
+		if recv == nil {
+			break
+		}
+		t := recv.Type()
+		if p, ok := t.(*types.Pointer); ok {
+			t = p.Elem()
+		}
+		if n, ok := t.(*types.Named); ok {
+			tn := n.Obj()
+			if tn.Exported() && tn.Pkg() != nil && tn.Parent() == tn.Pkg().Scope() {
+				return prefix + tn.Name() + "." + obj.Name()
+			}
+		}
+		return ""
+	case *types.Var:
+		if obj.IsField() {
+			if id, ok := imp.fields[obj]; ok {
+				return prefix + id
+			}
+			return ""
+		}
+	}
+	if obj.Parent() == obj.Pkg().Scope() {
+		return prefix + obj.Name()
+	}
+	return ""
+}
+
+// promotedMethods returns the exported methods promoted to the exported
+// types of pkg from embedded types, sorted by type and method name. Methods
+// promoted from unexported types are omitted, since they are documented as
+// methods of the embedding type.
+func (imp *sourceImporter) promotedMethods(pkg *types.Package) []*godoc.PromotedMethod {
+	qualifier := func(p *types.Package) string {
+		if p == pkg {
+			return ""
+		}
+		return p.Name()
+	}
+	var pms []*godoc.PromotedMethod
+	add := func(typeName string, m *types.Func, recv string) {
+		if !m.Exported() {
+			return
+		}
+		link := imp.link(m)
+		i := strings.IndexByte(link, '#')
+		if i < 0 {
+			return
+		}
+		pkgPath, id := link[:i], link[i+1:]
+		from := strings.TrimSuffix(id, "."+m.Name())
+		if m.Pkg() != pkg {
+			from = m.Pkg().Name() + "." + from
+		}
+		sig := strings.TrimPrefix(types.TypeString(m.Type(), qualifier), "func")
+		synopsis := m.Name() + sig
+		if recv != "" {
+			synopsis = "func (" + recv + ") " + synopsis
+		}
+		pms = append(pms, &godoc.PromotedMethod{
+			TypeName: typeName,
+			Name:     m.Name(),
+			Synopsis: synopsis,
+			From:     from,
+			PkgPath:  pkgPath,
+		})
+	}
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		tn, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || !tn.Exported() || tn.IsAlias() {
+			continue
+		}
+		named, ok := tn.Type().(*types.Named)
+		if !ok {
+			continue
+		}
+		if iface, ok := named.Underlying().(*types.Interface); ok {
+			explicit := map[*types.Func]bool{}
+			for i := 0; i < iface.NumExplicitMethods(); i++ {
+				explicit[iface.ExplicitMethod(i)] = true
+			}
+			for i := 0; i < iface.NumMethods(); i++ {
+				if m := iface.Method(i); !explicit[m] {
+					add(name, m, "")
+				}
+			}
+			continue
+		}
+		values := types.NewMethodSet(named)
+		ptrs := types.NewMethodSet(types.NewPointer(named))
+		for i := 0; i < ptrs.Len(); i++ {
+			sel := ptrs.At(i)
+			if len(sel.Index()) < 2 {
+				continue // declared by the type itself
+			}
+			recv := "*" + name
+			if values.Lookup(pkg, sel.Obj().Name()) != nil {
+				recv = name
+			}
+			add(name, sel.Obj().(*types.Func), recv)
+		}
+	}
+	sort.Slice(pms, func(i, j int) bool {
+		if pms[i].TypeName != pms[j].TypeName {
+			return pms[i].TypeName < pms[j].TypeName
+		}
+		return pms[i].Name < pms[j].Name
+	})
+	return pms
+}
+
+// popularInterfaces are interfaces outside the module that types are checked
+// against, in the form "importPath#Name".
+var popularInterfaces = []string{
+	"builtin#error",
+	"context#Context",
+	"encoding#BinaryMarshaler",
+	"encoding#BinaryUnmarshaler",
+	"encoding#TextMarshaler",
+	"encoding#TextUnmarshaler",
+	"encoding/json#Marshaler",
+	"encoding/json#Unmarshaler",
+	"flag#Value",
+	"fmt#Formatter",
+	"fmt#GoStringer",
+	"fmt#Stringer",
+	"hash#Hash",
+	"io#Closer",
+	"io#ReadCloser",
+	"io#ReadWriteCloser",
+	"io#ReadWriter",
+	"io#Reader",
+	"io#ReaderAt",
+	"io#ReaderFrom",
+	"io#Seeker",
+	"io#Writer",
+	"io#WriterAt",
+	"io#WriterTo",
+	"net/http#Handler",
+	"sort#Interface",
+}
+
+// implementations returns the implementation relationships between the
+// exported types of the package with the given import path and the exported
+// interfaces of the module and popularInterfaces, in both directions. Generic
+// types and interfaces are omitted.
+//
+// To compare types consistently, the package and the other packages of the
+// module are loaded through imp.
+func (imp *sourceImporter) implementations(importPath string) []*godoc.Implementation {
+	pkg, err := imp.Import(importPath)
+	if err != nil || pkg == nil {
+		return nil
+	}
+	var ifaces, others []*types.TypeName
+	for _, path := range imp.tc.packages {
+		p, err := imp.Import(path)
+		if err != nil || p == nil {
+			continue
+		}
+		for _, tn := range exportedTypes(p) {
+			if types.IsInterface(tn.Type()) {
+				ifaces = append(ifaces, tn)
+			} else if tn.Pkg() != pkg {
+				others = append(others, tn)
+			}
+		}
+	}
+	for _, pi := range popularInterfaces {
+		path, name, _ := strings.Cut(pi, "#")
+		if path == importPath || inModule(path, imp.tc.modulePath) {
+			continue // found above
+		}
+		var obj types.Object
+		if path == "builtin" {
+			obj = types.Universe.Lookup(name)
+		} else if p, err := imp.Import(path); err == nil && p != nil {
+			obj = p.Scope().Lookup(name)
+		}
+		if tn, ok := obj.(*types.TypeName); ok {
+			ifaces = append(ifaces, tn)
+		}
+	}
+
+	qualify := func(tn *types.TypeName) (name, pkgPath string) {
+		switch tn.Pkg() {
+		case nil:
+			return tn.Name(), "builtin"
+		case pkg:
+			return tn.Name(), importPath
+		default:
+			return tn.Pkg().Name() + "." + tn.Name(), tn.Pkg().Path()
+		}
+	}
+	var impls []*godoc.Implementation
+	add := func(t, iface *types.TypeName) {
+		iv := iface.Type().Underlying().(*types.Interface)
+		if iv.NumMethods() == 0 || !iv.IsMethodSet() {
+			return // every type implements it, or it is a constraint
+		}
+		var ptr string
+		if !types.Implements(t.Type(), iv) {
+			if !types.Implements(types.NewPointer(t.Type()), iv) {
+				return
+			}
+			ptr = "*"
+		}
+		impl := &godoc.Implementation{}
+		impl.Type, impl.TypePkgPath = qualify(t)
+		impl.Type = ptr + impl.Type
+		impl.Interface, impl.InterfacePkgPath = qualify(iface)
+		impls = append(impls, impl)
+	}
+	for _, tn := range exportedTypes(pkg) {
+		if types.IsInterface(tn.Type()) {
+			// Types of the module that implement tn.
+			for _, other := range others {
+				add(other, tn)
+			}
+			continue
+		}
+		// Interfaces that tn implements, and those of the package that the
+		// types of the package implement.
+		for _, iface := range ifaces {
+			add(tn, iface)
+		}
+	}
+	sort.Slice(impls, func(i, j int) bool {
+		a, b := impls[i], impls[j]
+		if a.InterfacePkgPath != b.InterfacePkgPath {
+			return a.InterfacePkgPath < b.InterfacePkgPath
+		}
+		if a.Interface != b.Interface {
+			return a.Interface < b.Interface
+		}
+		if a.TypePkgPath != b.TypePkgPath {
+			return a.TypePkgPath < b.TypePkgPath
+		}
+		return a.Type < b.Type
+	})
+	return impls
+}
+
+// exportedTypes returns the exported, non-generic named types declared at
+// package level in pkg, sorted by name.
+func exportedTypes(pkg *types.Package) []*types.TypeName {
+	var tns []*types.TypeName
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		tn, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || !tn.Exported() || tn.IsAlias() {
+			continue
+		}
+		if named, ok := tn.Type().(*types.Named); !ok || named.TypeParams().Len() > 0 {
+			continue
+		}
+		tns = append(tns, tn)
+	}
+	return tns
+}
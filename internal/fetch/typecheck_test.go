@@ -0,0 +1,158 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetch
+
+import (
+	"context"
+	"go/ast"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/godoc"
+	"golang.org/x/pkgsite/internal/testing/testhelper"
+)
+
+const typeCheckModules = `
+-- a/go.mod --
+module example.com/a
+
+go 1.19
+
+require example.com/dep v1.0.0
+-- a/a.go --
+package a
+
+import (
+	. "example.com/dep"
+	"fmt"
+)
+
+// T embeds a type from a dot-import.
+type T struct {
+	Base
+	S fmt.Stringer
+}
+
+var (
+	// M is a method promoted from Base.
+	M = T.M
+	// B sets a field of Base.
+	B = Base{X: 1}
+	// E is a builtin type.
+	E error
+)
+
+// Doer is implemented by T and sub.S.
+type Doer interface {
+	M()
+}
+
+// Name implements fmt.Stringer.
+type Name string
+
+func (Name) String() string { return "" }
+-- a/sub/sub.go --
+package sub
+
+type S struct{}
+
+func (*S) M() {}
+-- dep/go.mod --
+module example.com/dep
+
+go 1.19
+-- dep/dep.go --
+package dep
+
+type Base struct {
+	X int
+}
+
+func (Base) M() {}
+`
+
+func TestTypeCheck(t *testing.T) {
+	ctx := context.Background()
+	dir, _ := testhelper.WriteTxtarToTempDir(t, typeCheckModules)
+	g, err := NewDirectoryModuleGetter("", filepath.Join(dir, "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	depg, err := NewDirectoryModuleGetter("", filepath.Join(dir, "dep"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lm := FetchLazyModule(ctx, "example.com/a", LocalVersion, g)
+	if lm.Error != nil {
+		t.Fatal(lm.Error)
+	}
+	lm.EnableTypeChecking([]ModuleGetter{depg})
+	u, err := lm.Unit(ctx, "example.com/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := godoc.DecodePackage(u.Documentation[0].Source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Collect the links of identifiers by name.
+	got := map[string]string{}
+	for _, f := range pkg.Files {
+		ast.Inspect(f.AST, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok {
+				if link, ok := pkg.IdentLinks[id.Pos()]; ok {
+					got[id.Name] = link
+				}
+			}
+			return true
+		})
+	}
+	for name, want := range map[string]string{
+		"Base":     "example.com/dep#Base",
+		"fmt":      "fmt",
+		"Stringer": "fmt#Stringer",
+		"T":        "example.com/a#T",
+		"M":        "example.com/dep#Base.M",
+		"X":        "example.com/dep#Base.X",
+		"error":    "builtin#error",
+	} {
+		if got[name] != want {
+			t.Errorf("link for %s: got %q, want %q", name, got[name], want)
+		}
+	}
+
+	wantPromoted := []*godoc.PromotedMethod{{
+		TypeName: "T",
+		Name:     "M",
+		Synopsis: "func (T) M()",
+		From:     "dep.Base",
+		PkgPath:  "example.com/dep",
+	}}
+	if diff := cmp.Diff(wantPromoted, pkg.PromotedMethods); diff != "" {
+		t.Errorf("promoted methods mismatch (-want +got):\n%s", diff)
+	}
+	// The promoted method is part of the API stored for the package.
+	var gotT []string
+	for _, s := range u.Documentation[0].API {
+		if s.Name == "T" {
+			for _, c := range s.Children {
+				gotT = append(gotT, c.Name)
+			}
+		}
+	}
+	if want := []string{"T.S", "T.M"}; !cmp.Equal(gotT, want) {
+		t.Errorf("children of T: got %v, want %v", gotT, want)
+	}
+
+	wantImpls := []*godoc.Implementation{
+		{Type: "T", TypePkgPath: "example.com/a", Interface: "Doer", InterfacePkgPath: "example.com/a"},
+		{Type: "*sub.S", TypePkgPath: "example.com/a/sub", Interface: "Doer", InterfacePkgPath: "example.com/a"},
+		{Type: "Name", TypePkgPath: "example.com/a", Interface: "fmt.Stringer", InterfacePkgPath: "fmt"},
+	}
+	if diff := cmp.Diff(wantImpls, u.Documentation[0].Implementations); diff != "" {
+		t.Errorf("implementations mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fetchdatasource
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/mod/semver"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/fetch"
+	"golang.org/x/pkgsite/internal/log"
+)
+
+// A diskCache stores fetched modules in a directory, so that they survive
+// restarts of the process.
+//
+// Each module version is stored in its own subdirectory of the directory for
+// the current diskCacheVersion, named by a hash of the getter that fetched
+// it, its path and its version. The subdirectory
+// holds a file with the module's ModuleInfo, UnitMetas and requirements, and a
+// file for each unit that has been computed, holding the unit with its encoded
+// documentation (see godoc.Package.Encode).
+//
+// Errors are not cached, and neither are the contents of module versions that
+// may change without their version changing, unless their getter can report
+// such changes (see fetch.VolatileModuleGetter).
+type diskCache struct {
+	dir string
+	// typeCheck reports whether units are type-checked. Their documentation
+	// differs from that of units that are not, so they are kept apart.
+	typeCheck bool
+	// verifyExamples reports whether the output of examples is verified,
+	// which likewise changes the documentation.
+	verifyExamples bool
+}
+
+// diskCacheVersion names the subdirectory of the cache directory that holds
+// modules in the current format. Bump it whenever diskModule, diskUnit or the
+// encoding of documentation changes, so that entries in an earlier format,
+// which would decode with missing fields, are ignored.
+const diskCacheVersion = "v1"
+
+// diskModule is the representation of a module in the disk cache.
+type diskModule struct {
+	ModuleInfo   internal.ModuleInfo
+	UnitMetas    []*internal.UnitMeta
+	Requirements []*internal.Requirement
+}
+
+// diskUnit is the representation of a unit in the disk cache.
+//
+// The encoding loses the IsRedistributable field of the unit's ModuleInfo,
+// which is shadowed by that of the unit, so the UnitMeta of a unit read from
+// the cache is restored from the UnitMetas of its module.
+type diskUnit struct {
+	*internal.Unit
+	// Symbols is not populated by fetching, and cannot be encoded as JSON
+	// because its keys are structs. Hide it.
+	Symbols struct{} `json:"-"`
+}
+
+// cacheable reports whether the contents of the module version fetched by g
+// can be stored in the disk cache.
+func cacheable(g fetch.ModuleGetter, version string) bool {
+	if _, ok := g.(fetch.VolatileModuleGetter); ok {
+		return true
+	}
+	// Other local modules are always at LocalVersion, and branch names and
+	// "latest" resolve to different versions over time.
+	return version != fetch.LocalVersion && semver.IsValid(version)
+}
+
+// moduleDir returns the directory holding the module version fetched by g.
+func (c *diskCache) moduleDir(g fetch.ModuleGetter, modulePath, version string) string {
+	key := g.String() + "\x00" + modulePath + "@" + version
+	if c.typeCheck {
+		key += "\x00typecheck"
+	}
+	if c.verifyExamples {
+		key += "\x00examples"
+	}
+	// Documentation is stored for the configured build contexts only.
+	key += fmt.Sprintf("\x00%v%v", internal.BuildContexts, internal.BuildTags)
+	return filepath.Join(c.dir, diskCacheVersion, hash(key))
+}
+
+// getModule returns the module version fetched by g, or nil if it is not in
+// the cache.
+func (c *diskCache) getModule(ctx context.Context, g fetch.ModuleGetter, modulePath, version string) *fetch.LazyModule {
+	var dm diskModule
+	if !c.read(ctx, filepath.Join(c.moduleDir(g, modulePath, version), "module.json"), &dm) {
+		return nil
+	}
+	return &fetch.LazyModule{ModuleInfo: dm.ModuleInfo, UnitMetas: dm.UnitMetas, Requirements: dm.Requirements}
+}
+
+// putModule stores the ModuleInfo, UnitMetas and Requirements of m, which was fetched by g.
+// If g is volatile, it removes any units stored for an earlier fetch of the
+// same version, since they may have changed.
+func (c *diskCache) putModule(ctx context.Context, g fetch.ModuleGetter, m *fetch.LazyModule) {
+	dir := c.moduleDir(g, m.ModulePath, m.Version)
+	if _, ok := g.(fetch.VolatileModuleGetter); ok {
+		if err := os.RemoveAll(dir); err != nil {
+			log.Errorf(ctx, "diskCache: %v", err)
+			return
+		}
+	}
+	c.write(ctx, filepath.Join(dir, "module.json"), diskModule{
+		ModuleInfo:   m.ModuleInfo,
+		UnitMetas:    m.UnitMetas,
+		Requirements: m.Requirements,
+	})
+}
+
+// getUnit returns the unit with the given path in m, which was fetched by g,
+// or nil if it is not in the cache.
+func (c *diskCache) getUnit(ctx context.Context, g fetch.ModuleGetter, m *fetch.LazyModule, path string) *internal.Unit {
+	du := diskUnit{Unit: &internal.Unit{}}
+	if !c.read(ctx, filepath.Join(c.moduleDir(g, m.ModulePath, m.Version), hash(path)+".json"), &du) {
+		return nil
+	}
+	um, err := findUnitMeta(m, path)
+	if err != nil {
+		return nil
+	}
+	du.UnitMeta = *um
+	return du.Unit
+}
+
+// putUnit stores the unit u of m, which was fetched by g.
+func (c *diskCache) putUnit(ctx context.Context, g fetch.ModuleGetter, m *fetch.LazyModule, u *internal.Unit) {
+	c.write(ctx, filepath.Join(c.moduleDir(g, m.ModulePath, m.Version), hash(u.Path)+".json"), diskUnit{Unit: u})
+}
+
+// read decodes the file into v. It reports whether it succeeded. A missing
+// file is not an error; other errors are logged.
+func (c *diskCache) read(ctx context.Context, filename string, v any) bool {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Errorf(ctx, "diskCache: %v", err)
+		}
+		return false
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		log.Errorf(ctx, "diskCache: decoding %s: %v", filename, err)
+		return false
+	}
+	return true
+}
+
+// write encodes v into the file. Errors are logged.
+func (c *diskCache) write(ctx context.Context, filename string, v any) {
+	if err := writeFile(filename, v); err != nil {
+		log.Errorf(ctx, "diskCache: %v", err)
+	}
+}
+
+// writeFile encodes v into the file, replacing it atomically so that
+// concurrent readers never see a partial file.
+func writeFile(filename string, v any) (err error) {
+	defer derrors.Wrap(&err, "writeFile(%q)", filename)
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(filename), "tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(f.Name())
+		}
+	}()
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
+
+func hash(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
@@ -4,7 +4,8 @@
 
 // Package fetchdatasource provides an internal.DataSource implementation
 // that fetches modules (rather than reading them from a database).
-// Search and other tabs are not supported.
+// Search is supported only by getters that implement it; other tabs are not
+// supported.
 package fetchdatasource
 
 import (
@@ -23,6 +24,7 @@ import (
 	"golang.org/x/pkgsite/internal/log"
 	"golang.org/x/pkgsite/internal/lru"
 	"golang.org/x/pkgsite/internal/proxy"
+	"golang.org/x/pkgsite/internal/stdlib"
 	"golang.org/x/pkgsite/internal/version"
 )
 
@@ -31,6 +33,7 @@ import (
 type FetchDataSource struct {
 	opts  Options
 	cache *lru.Cache[internal.Modver, cacheEntry]
+	disk  *diskCache // nil if there is no CacheDir
 }
 
 // Options are parameters for creating a new FetchDataSource.
@@ -41,6 +44,20 @@ type Options struct {
 	// include a ProxyModuleGetter in Getters.
 	ProxyClientForLatest *proxy.Client
 	BypassLicenseCheck   bool
+	// If set, fetched modules and their units are also cached in files under
+	// this directory, so that they need not be fetched and processed again
+	// after a restart. The directory is created if needed. Its size is not
+	// limited.
+	CacheDir string
+	// If set, packages are type-checked so that the identifiers in their
+	// declarations link to the exact declarations they refer to. The
+	// dependencies of modules are fetched with Getters.
+	TypeCheck bool
+	// If set, the examples of packages that have output comments are run
+	// when the packages are loaded, and their documentation shows whether the
+	// output matched. See fetch.LazyModule.EnableExampleVerification for
+	// which modules' examples are run, and how.
+	VerifyExamples bool
 }
 
 // New creates a new FetchDataSource from the options.
@@ -51,10 +68,14 @@ func (o Options) New() *FetchDataSource {
 	// Copy getters slice so caller doesn't modify us.
 	opts.Getters = make([]fetch.ModuleGetter, len(opts.Getters))
 	copy(opts.Getters, o.Getters)
-	return &FetchDataSource{
+	ds := &FetchDataSource{
 		opts:  opts,
 		cache: cache,
 	}
+	if opts.CacheDir != "" {
+		ds.disk = &diskCache{dir: opts.CacheDir, typeCheck: opts.TypeCheck, verifyExamples: opts.VerifyExamples}
+	}
+	return ds
 }
 
 // cacheEntry holds a fetched module or an error, if the fetch failed.
@@ -62,64 +83,109 @@ type cacheEntry struct {
 	g      fetch.ModuleGetter
 	module *fetch.LazyModule
 	err    error
+	// onDisk reports whether the module was read from the disk cache. Such a
+	// module has only its ModuleInfo and UnitMetas; units that are not in the
+	// disk cache require fetching the module again.
+	onDisk bool
 }
 
 const maxCachedModules = 100
 
 // cacheGet returns information from the cache if it is present, and (nil, nil) otherwise.
 func (ds *FetchDataSource) cacheGet(path, version string) (fetch.ModuleGetter, *fetch.LazyModule, error) {
+	e, _ := ds.cacheLookup(path, version)
+	return e.g, e.module, e.err
+}
+
+// cacheLookup returns the cache entry for the module, and whether there is one.
+func (ds *FetchDataSource) cacheLookup(path, version string) (cacheEntry, bool) {
 	// Look for an exact match first, then use LocalVersion, as for a
 	// directory-based or GOPATH-mode module.
 	for _, v := range []string{version, fetch.LocalVersion} {
 		if e, ok := ds.cache.Get(internal.Modver{Path: path, Version: v}); ok {
-			return e.g, e.module, e.err
+			return e, true
 		}
 	}
-	return nil, nil, nil
+	return cacheEntry{}, false
 }
 
 // cachePut puts information into the cache.
 func (ds *FetchDataSource) cachePut(g fetch.ModuleGetter, path, version string, m *fetch.LazyModule, err error) {
-	ds.cache.Put(internal.Modver{Path: path, Version: version}, cacheEntry{g, m, err})
+	ds.cache.Put(internal.Modver{Path: path, Version: version}, cacheEntry{g: g, module: m, err: err})
 }
 
 // getModule gets the module at the given path and version. It first checks the
 // cache, and if it isn't there it then tries to fetch it.
-func (ds *FetchDataSource) getModule(ctx context.Context, modulePath, vers string) (_ *fetch.LazyModule, err error) {
+func (ds *FetchDataSource) getModule(ctx context.Context, modulePath, vers string) (*fetch.LazyModule, error) {
+	e, err := ds.getModuleEntry(ctx, modulePath, vers)
+	return e.module, err
+}
+
+// getModuleEntry is like getModule, but returns the cache entry for the module.
+func (ds *FetchDataSource) getModuleEntry(ctx context.Context, modulePath, vers string) (_ cacheEntry, err error) {
 	defer derrors.Wrap(&err, "FetchDataSource.getModule(%q, %q)", modulePath, vers)
 
-	g, mod, err := ds.cacheGet(modulePath, vers)
-	if err != nil {
-		return nil, err
+	e, ok := ds.cacheLookup(modulePath, vers)
+	if e.err != nil {
+		return cacheEntry{}, e.err
 	}
-	if mod != nil {
+	if e.module != nil {
 		// For getters supporting invalidation, check whether cached contents have
 		// changed.
-		v, ok := g.(fetch.VolatileModuleGetter)
+		v, ok := e.g.(fetch.VolatileModuleGetter)
 		if !ok {
-			return mod, nil
+			return e, nil
 		}
-		hasChanged, err := v.HasChanged(ctx, mod.ModuleInfo)
+		hasChanged, err := v.HasChanged(ctx, e.module.ModuleInfo)
 		if err != nil {
-			return nil, err
+			return cacheEntry{}, err
 		}
 		if !hasChanged {
-			return mod, nil
+			return e, nil
+		}
+	} else if !ok && ds.disk != nil {
+		if e, ok := ds.diskGet(ctx, modulePath, vers); ok {
+			return e, nil
 		}
 	}
+	return ds.fetchEntry(ctx, modulePath, vers)
+}
 
+// diskGet looks for the module in the disk cache, trying each getter in turn.
+// If it finds the module and the module has not changed, it adds the module
+// to the in-memory cache and returns it.
+func (ds *FetchDataSource) diskGet(ctx context.Context, modulePath, vers string) (cacheEntry, bool) {
+	for _, g := range ds.opts.Getters {
+		for _, v := range []string{vers, fetch.LocalVersion} {
+			if !cacheable(g, v) {
+				continue
+			}
+			m := ds.disk.getModule(ctx, g, modulePath, v)
+			if m == nil {
+				continue
+			}
+			if vg, ok := g.(fetch.VolatileModuleGetter); ok {
+				if hasChanged, err := vg.HasChanged(ctx, m.ModuleInfo); err != nil || hasChanged {
+					continue
+				}
+			}
+			ds.populateLatestInfo(ctx, g, m)
+			e := cacheEntry{g: g, module: m, onDisk: true}
+			ds.cache.Put(internal.Modver{Path: modulePath, Version: v}, e)
+			return e, true
+		}
+	}
+	return cacheEntry{}, false
+}
+
+// fetchEntry fetches the module and caches the result.
+func (ds *FetchDataSource) fetchEntry(ctx context.Context, modulePath, vers string) (cacheEntry, error) {
 	// There can be a benign race here, where two goroutines both fetch the same
 	// module. At worst some work will be duplicated, but if that turns out to
 	// be a problem we could use golang.org/x/sync/singleflight.
 	m, g, err := ds.fetch(ctx, modulePath, vers)
-	if m != nil && ds.opts.ProxyClientForLatest != nil {
-		// Use the go.mod file at the raw latest version to fill in deprecation
-		// and retraction information. Ignore any problems getting the
-		// information, because we may be trying to do this for a local module
-		// that the proxy doesn't know about.
-		if lmv, err := fetch.LatestModuleVersions(ctx, modulePath, ds.opts.ProxyClientForLatest, nil); err == nil {
-			lmv.PopulateModuleInfo(&m.ModuleInfo)
-		}
+	if m != nil {
+		ds.populateLatestInfo(ctx, g, m)
 	}
 
 	// Cache both successes and failures, but not cancellations.
@@ -131,7 +197,11 @@ func (ds *FetchDataSource) getModule(ctx context.Context, modulePath, vers strin
 			ds.cachePut(g, modulePath, m.Version, m, err)
 		}
 	}
-	return m, err
+	// Only successes are cached on disk, under the resolved version.
+	if err == nil && ds.disk != nil && cacheable(g, m.Version) {
+		ds.disk.putModule(ctx, g, m)
+	}
+	return cacheEntry{g: g, module: m, err: err}, err
 }
 
 // fetch fetches a module using the configured ModuleGetters.
@@ -148,6 +218,12 @@ func (ds *FetchDataSource) fetch(ctx context.Context, modulePath, version string
 			if ds.opts.BypassLicenseCheck {
 				m.IsRedistributable = true
 			}
+			if ds.opts.TypeCheck {
+				m.EnableTypeChecking(ds.opts.Getters)
+			}
+			if ds.opts.VerifyExamples {
+				m.EnableExampleVerification()
+			}
 			return m, g, nil
 		}
 		if !errors.Is(m.Error, derrors.NotFound) {
@@ -157,6 +233,54 @@ func (ds *FetchDataSource) fetch(ctx context.Context, modulePath, version string
 	return nil, nil, fmt.Errorf("%s@%s: %w", modulePath, version, derrors.NotFound)
 }
 
+// populateLatestInfo uses the go.mod file at the latest version of m's module
+// to fill in the deprecation and retraction information of m and its units.
+func (ds *FetchDataSource) populateLatestInfo(ctx context.Context, g fetch.ModuleGetter, m *fetch.LazyModule) {
+	lmv := ds.latestModuleVersions(ctx, g, m.ModulePath)
+	if lmv == nil {
+		return
+	}
+	lmv.PopulateModuleInfo(&m.ModuleInfo)
+	// The units have their own copies of the ModuleInfo, which the unit page
+	// uses for its deprecation and retraction banners.
+	for _, um := range m.UnitMetas {
+		lmv.PopulateModuleInfo(&um.ModuleInfo)
+	}
+}
+
+// latestModuleVersions returns information about the latest version of the
+// module, including its go.mod file, or nil if it cannot be determined. It
+// asks the ProxyClientForLatest if there is one, and otherwise, or if the
+// proxy doesn't know the module, the getter g that serves the module. So in
+// local mode, retractions come from the go.mod file of the latest version
+// that g has.
+func (ds *FetchDataSource) latestModuleVersions(ctx context.Context, g fetch.ModuleGetter, modulePath string) *internal.LatestModuleVersions {
+	// Ignore any problems getting the information, because we may be trying
+	// to do this for a local module that the proxy doesn't know about.
+	if ds.opts.ProxyClientForLatest != nil {
+		if lmv, err := fetch.LatestModuleVersions(ctx, modulePath, ds.opts.ProxyClientForLatest, nil); err == nil && lmv != nil {
+			return lmv
+		}
+	}
+	if g == nil || modulePath == stdlib.ModulePath {
+		return nil
+	}
+	info, err := g.Info(ctx, modulePath, version.Latest)
+	if err != nil {
+		return nil
+	}
+	modBytes, err := g.Mod(ctx, modulePath, info.Version)
+	if err != nil {
+		return nil
+	}
+	lmv, err := internal.NewLatestModuleVersions(modulePath, info.Version, info.Version, "", modBytes)
+	if err != nil {
+		log.Infof(ctx, "FetchDataSource: parsing go.mod of %s@%s: %v", modulePath, info.Version, err)
+		return nil
+	}
+	return lmv
+}
+
 func (ds *FetchDataSource) populateUnitSubdirectories(u *internal.Unit, m *fetch.LazyModule) {
 	p := u.Path + "/"
 	for _, u2 := range m.UnitMetas {
@@ -207,11 +331,11 @@ func (ds *FetchDataSource) GetUnitMeta(ctx context.Context, path, requestedModul
 func (ds *FetchDataSource) GetUnit(ctx context.Context, um *internal.UnitMeta, fields internal.FieldSet, bc internal.BuildContext) (_ *internal.Unit, err error) {
 	defer derrors.Wrap(&err, "FetchDataSource.GetUnit(%q, %q)", um.Path, um.ModulePath)
 
-	m, err := ds.getModule(ctx, um.ModulePath, um.Version)
+	e, err := ds.getModuleEntry(ctx, um.ModulePath, um.Version)
 	if err != nil {
 		return nil, err
 	}
-	u, err := ds.findUnit(ctx, m, um.Path)
+	u, err := ds.findUnit(ctx, e, um.Path)
 	if u == nil {
 		return nil, fmt.Errorf("import path %s not found in module %s: %w", um.Path, um.ModulePath, derrors.NotFound)
 	}
@@ -227,13 +351,14 @@ func (ds *FetchDataSource) GetUnit(ctx context.Context, um *internal.UnitMeta, f
 	return &u2, nil
 }
 
-// findUnit returns the unit with the given path in m, or nil if none.
-func (ds *FetchDataSource) findUnit(ctx context.Context, m *fetch.LazyModule, path string) (*internal.Unit, error) {
-	unit, err := m.Unit(ctx, path)
-	ds.populateUnitSubdirectories(unit, m)
+// findUnit returns the unit with the given path in the module of e, or nil if
+// none.
+func (ds *FetchDataSource) findUnit(ctx context.Context, e cacheEntry, path string) (*internal.Unit, error) {
+	unit, err := ds.computeUnit(ctx, e, path)
 	if err != nil {
 		return nil, err
 	}
+	ds.populateUnitSubdirectories(unit, e.module)
 	if ds.opts.BypassLicenseCheck {
 		unit.IsRedistributable = true
 	} else {
@@ -242,6 +367,34 @@ func (ds *FetchDataSource) findUnit(ctx context.Context, m *fetch.LazyModule, pa
 	return unit, nil
 }
 
+// computeUnit returns the unit with the given path in the module of e, from
+// the disk cache if possible.
+func (ds *FetchDataSource) computeUnit(ctx context.Context, e cacheEntry, path string) (*internal.Unit, error) {
+	m := e.module
+	useDisk := ds.disk != nil && cacheable(e.g, m.Version)
+	if useDisk {
+		if u := ds.disk.getUnit(ctx, e.g, m, path); u != nil {
+			return u, nil
+		}
+	}
+	if e.onDisk {
+		// Only the module's metadata is available. Fetch it again.
+		ne, err := ds.fetchEntry(ctx, m.ModulePath, m.Version)
+		if err != nil {
+			return nil, err
+		}
+		m = ne.module
+	}
+	unit, err := m.Unit(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if useDisk {
+		ds.disk.putUnit(ctx, e.g, m, unit)
+	}
+	return unit, nil
+}
+
 func findUnitMeta(m *fetch.LazyModule, path string) (*internal.UnitMeta, error) {
 	for _, um := range m.UnitMetas {
 		if um.Path == path {
@@ -339,29 +492,104 @@ func (ds *FetchDataSource) getLatestMajorVersion(ctx context.Context, fullPath,
 	}
 }
 
+// GetVersionsForPath returns the versions of the module containing path,
+// sorted by semver with the latest first. Versions are listed by the
+// ProxyClientForLatest, or else by the getter serving the module if it is a
+// fetch.VersionListingModuleGetter; otherwise, or for a local module or the
+// standard library, only the module version serving path is returned. Commit
+// times are known only for that version. Retractions are applied as for the
+// module itself.
+func (ds *FetchDataSource) GetVersionsForPath(ctx context.Context, path string) (_ []*internal.ModuleInfo, err error) {
+	defer derrors.Wrap(&err, "FetchDataSource.GetVersionsForPath(%q)", path)
+
+	um, err := ds.GetUnitMeta(ctx, path, internal.UnknownModulePath, version.Latest)
+	if err != nil {
+		return nil, err
+	}
+	current := um.ModuleInfo
+	if current.Version == fetch.LocalVersion || current.ModulePath == stdlib.ModulePath {
+		return []*internal.ModuleInfo{&current}, nil
+	}
+	e, err := ds.getModuleEntry(ctx, current.ModulePath, current.Version)
+	if err != nil {
+		return nil, err
+	}
+	var versions []string
+	if prox := ds.opts.ProxyClientForLatest; prox != nil {
+		versions, err = prox.Versions(ctx, current.ModulePath)
+	} else if vg, ok := e.g.(fetch.VersionListingModuleGetter); ok {
+		versions, err = vg.Versions(ctx, current.ModulePath)
+	} else {
+		return []*internal.ModuleInfo{&current}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	lmv := ds.latestModuleVersions(ctx, e.g, current.ModulePath)
+	mis := []*internal.ModuleInfo{&current}
+	for _, v := range versions {
+		if v != current.Version {
+			mis = append(mis, &internal.ModuleInfo{ModulePath: current.ModulePath, Version: v})
+		}
+	}
+	if lmv != nil {
+		for _, mi := range mis {
+			lmv.PopulateModuleInfo(mi)
+		}
+	}
+	sort.Slice(mis, func(i, j int) bool {
+		return semver.Compare(mis[i].Version, mis[j].Version) > 0
+	})
+	return mis, nil
+}
+
 // GetNestedModules is not implemented.
 func (ds *FetchDataSource) GetNestedModules(ctx context.Context, modulePath string) ([]*internal.ModuleInfo, error) {
 	return nil, nil
 }
 
+// GetModuleRequirements returns the requirements in the go.mod file of the
+// module version, sorted by module path and version.
+func (ds *FetchDataSource) GetModuleRequirements(ctx context.Context, modulePath, resolvedVersion string) (_ []*internal.Requirement, err error) {
+	defer derrors.Wrap(&err, "FetchDataSource.GetModuleRequirements(%q, %q)", modulePath, resolvedVersion)
+	m, err := ds.getModule(ctx, modulePath, resolvedVersion)
+	if err != nil {
+		return nil, err
+	}
+	reqs := append([]*internal.Requirement(nil), m.Requirements...)
+	sort.Slice(reqs, func(i, j int) bool {
+		if reqs[i].ModulePath != reqs[j].ModulePath {
+			return reqs[i].ModulePath < reqs[j].ModulePath
+		}
+		return reqs[i].Version < reqs[j].Version
+	})
+	return reqs, nil
+}
+
 // GetModuleReadme is not implemented.
 func (*FetchDataSource) GetModuleReadme(ctx context.Context, modulePath, resolvedVersion string) (*internal.Readme, error) {
 	return nil, nil
 }
 
-// SupportsSearch reports whether any of the configured Getters are searchable.
+// SupportsSearch reports whether any of the configured Getters are searchable,
+// and whether they support symbol search.
 func (ds *FetchDataSource) SearchSupport() internal.SearchSupport {
+	support := internal.NoSearch
 	for _, g := range ds.opts.Getters {
-		if _, ok := g.(fetch.SearchableModuleGetter); ok {
-			// Getters only support basic search.
-			return internal.BasicSearch
+		switch g.(type) {
+		case fetch.SymbolSearchableModuleGetter:
+			return internal.FullSearch
+		case fetch.SearchableModuleGetter:
+			support = internal.BasicSearch
 		}
 	}
-	return internal.NoSearch
+	return support
 }
 
 // Search delegates search to any configured getters that support the
-// SearchableModuleGetter interface, merging their results.
+// SearchableModuleGetter interface, merging their results. Symbol searches are
+// delegated to getters that support the SymbolSearchableModuleGetter
+// interface.
 func (ds *FetchDataSource) Search(ctx context.Context, q string, opts internal.SearchOptions) (_ []*internal.SearchResult, err error) {
 	var results []*internal.SearchResult
 	// Since results are potentially merged from multiple sources, we can't know
@@ -370,13 +598,27 @@ func (ds *FetchDataSource) Search(ctx context.Context, q string, opts internal.S
 	// Offset+MaxResults is an upper bound.
 	limit := opts.Offset + opts.MaxResults
 	for _, g := range ds.opts.Getters {
-		if s, ok := g.(fetch.SearchableModuleGetter); ok {
-			rs, err := s.Search(ctx, q, limit)
-			if err != nil {
-				return nil, err
+		var (
+			rs  []*internal.SearchResult
+			err error
+		)
+		if opts.SearchSymbols {
+			s, ok := g.(fetch.SymbolSearchableModuleGetter)
+			if !ok {
+				continue
+			}
+			rs, err = s.SearchSymbols(ctx, q, opts.SymbolFilter, limit)
+		} else {
+			s, ok := g.(fetch.SearchableModuleGetter)
+			if !ok {
+				continue
 			}
-			results = append(results, rs...)
+			rs, err = s.Search(ctx, q, limit)
+		}
+		if err != nil {
+			return nil, err
 		}
+		results = append(results, rs...)
 	}
 	sort.Slice(results, func(i, j int) bool {
 		return results[i].Score > results[j].Score
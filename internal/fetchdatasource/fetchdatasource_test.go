@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"regexp"
 	"testing"
 
@@ -580,3 +581,180 @@ func TestCache(t *testing.T) {
 		}
 	}
 }
+
+func TestDiskCache(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	newDS := func(modules []*proxytest.Module) *FetchDataSource {
+		client, teardown := proxytest.SetupTestClient(t, modules)
+		t.Cleanup(teardown)
+		return Options{
+			Getters:  []fetch.ModuleGetter{fetch.NewProxyModuleGetter(client, source.NewClientForTesting())},
+			CacheDir: dir,
+		}.New()
+	}
+	getUnit := func(ds *FetchDataSource, path, vers string) (*internal.Unit, error) {
+		um, err := ds.GetUnitMeta(ctx, path, internal.UnknownModulePath, vers)
+		if err != nil {
+			return nil, err
+		}
+		return ds.GetUnit(ctx, um, internal.AllFields, internal.BuildContext{})
+	}
+
+	want, err := getUnit(newDS(defaultTestModules), "example.com/single/pkg", "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A data source whose proxy has no modules can serve the unit from the disk
+	// cache.
+	ds := newDS(nil)
+	got, err := getUnit(ds, "example.com/single/pkg", "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(source.Info{})); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	// Units that were not computed, and versions that must be resolved, are
+	// not in the disk cache.
+	if _, err := getUnit(ds, "example.com/single", "v1.0.0"); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("unit not in cache: got %v, want NotFound", err)
+	}
+	if _, err := getUnit(ds, "example.com/single/pkg", version.Latest); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("latest: got %v, want NotFound", err)
+	}
+
+	// Everything is stored under the directory for the format version.
+	des, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(des) != 1 || des[0].Name() != diskCacheVersion {
+		var names []string
+		for _, de := range des {
+			names = append(names, de.Name())
+		}
+		t.Errorf("cache directory contains %v, want only %s", names, diskCacheVersion)
+	}
+}
+
+func TestGetVersionsForPath(t *testing.T) {
+	ctx, ds, teardown := setup(t, defaultTestModules, false)
+	defer teardown()
+
+	for _, test := range []struct {
+		path string
+		want []string
+	}{
+		{"example.com/retractions", []string{"v1.2.0 (retracted)", "v1.1.0 (retracted)", "v1.0.0"}},
+		{"github.com/my/module/foo", []string{fetch.LocalVersion}},
+	} {
+		t.Run(test.path, func(t *testing.T) {
+			mis, err := ds.GetVersionsForPath(ctx, test.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, mi := range mis {
+				v := mi.Version
+				if mi.Retracted {
+					v += " (retracted)"
+				}
+				got = append(got, v)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRetractions(t *testing.T) {
+	const modulePath = "example.com/retractions"
+
+	// writeModCache writes the versions of the module to a module cache.
+	writeModCache := func(t *testing.T) string {
+		dir := t.TempDir()
+		vdir := filepath.Join(dir, "cache", "download", modulePath, "@v")
+		if err := os.MkdirAll(vdir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range defaultTestModules {
+			if m.ModulePath != modulePath {
+				continue
+			}
+			contents := map[string]string{}
+			for name, data := range m.Files {
+				contents[modulePath+"@"+m.Version+"/"+name] = data
+			}
+			zip, err := testhelper.ZipContents(contents)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for suffix, data := range map[string][]byte{
+				"info": []byte(fmt.Sprintf(`{"Version": %q, "Time": "2019-01-30T00:00:00Z"}`, m.Version)),
+				"mod":  []byte(m.Files["go.mod"]),
+				"zip":  zip,
+			} {
+				if err := os.WriteFile(filepath.Join(vdir, m.Version+"."+suffix), data, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		return dir
+	}
+
+	for _, test := range []struct {
+		name  string
+		setup func(t *testing.T) (context.Context, *FetchDataSource, func())
+	}{
+		{
+			name: "proxy",
+			setup: func(t *testing.T) (context.Context, *FetchDataSource, func()) {
+				return setup(t, defaultTestModules, false)
+			},
+		},
+		{
+			name: "modcache",
+			setup: func(t *testing.T) (context.Context, *FetchDataSource, func()) {
+				g, err := fetch.NewModCacheGetter(writeModCache(t))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return context.Background(), Options{Getters: []fetch.ModuleGetter{g}}.New(), func() {}
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, ds, teardown := test.setup(t)
+			defer teardown()
+
+			um, err := ds.GetUnitMeta(ctx, modulePath, internal.UnknownModulePath, "v1.1.0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !um.Retracted || um.RetractionRationale != "worse" {
+				t.Errorf("v1.1.0: got Retracted=%t, RetractionRationale=%q; want true, %q",
+					um.Retracted, um.RetractionRationale, "worse")
+			}
+
+			mis, err := ds.GetVersionsForPath(ctx, modulePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, mi := range mis {
+				v := mi.Version
+				if mi.Retracted {
+					v += " (" + mi.RetractionRationale + ")"
+				}
+				got = append(got, v)
+			}
+			want := []string{"v1.2.0 (bad)", "v1.1.0 (worse)", "v1.0.0"}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("GetVersionsForPath mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ package frontend
 
 import (
 	"path"
+	"strings"
 
 	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/frontend/versions"
@@ -20,6 +21,9 @@ func displayBreadcrumb(um *internal.UnitMeta, requestedVersion string) breadcrum
 	if um.ModulePath == stdlib.ModulePath && um.Path != stdlib.ModulePath {
 		bc.Links = append([]link{{Href: "/std", Body: "Standard library"}}, bc.Links...)
 	}
+	if um.ModulePath == stdlib.GopModulePath && um.Path != stdlib.GopModulePath {
+		bc.Links = append([]link{{Href: "/" + stdlib.GopShortPrefix, Body: "Go+ standard library"}}, bc.Links...)
+	}
 	bc.Links = append([]link{{Href: "/", Body: "Discover Packages"}}, bc.Links...)
 	return bc
 }
@@ -46,10 +50,21 @@ func breadcrumbPath(pkgPath, modPath, requestedVersion string) breadcrumb {
 	if pkgPath == stdlib.ModulePath {
 		return breadcrumb{Current: "Standard library"}
 	}
+	if pkgPath == stdlib.GopModulePath {
+		return breadcrumb{Current: "Go+ standard library"}
+	}
 	// Obtain successive prefixes of pkgPath, stopping at modPath,
-	// or for the stdlib, at the end.
+	// or for the stdlib, at the end. The Go+ module is treated like the
+	// stdlib, with its paths relative to the module.
+	copyData := pkgPath
+	hrefPrefix := "/"
 	minLen := len(modPath) - 1
-	if modPath == stdlib.ModulePath {
+	switch modPath {
+	case stdlib.ModulePath:
+		minLen = 1
+	case stdlib.GopModulePath:
+		pkgPath = strings.TrimPrefix(pkgPath, modPath+"/")
+		hrefPrefix += stdlib.GopShortPrefix + "/"
 		minLen = 1
 	}
 	var dirs []string
@@ -68,7 +83,7 @@ func breadcrumbPath(pkgPath, modPath, requestedVersion string) breadcrumb {
 	// Make all the other parts into links.
 	b.Links = make([]link, len(dirs)-1)
 	for i := 1; i < len(dirs); i++ {
-		href := "/" + dirs[i]
+		href := hrefPrefix + dirs[i]
 		if requestedVersion != version.Latest {
 			href += "@" + versions.LinkVersion(modPath, requestedVersion, requestedVersion)
 		}
@@ -79,6 +94,6 @@ func breadcrumbPath(pkgPath, modPath, requestedVersion string) breadcrumb {
 		b.Links[len(b.Links)-i] = link{href, el}
 	}
 	// Add a "copy" button for the path.
-	b.CopyData = pkgPath
+	b.CopyData = copyData
 	return b
 }
@@ -81,6 +81,20 @@ func TestBreadcrumbPath(t *testing.T) {
 				Links:   nil,
 			},
 		},
+		{
+			// Special case: Go+ package.
+			"github.com/goplus/gop/tpl/ast", "github.com/goplus/gop", "v1.2.6",
+			breadcrumb{
+				Current:  "ast",
+				Links:    []link{{"/gop/tpl@v1.2.6", "tpl"}},
+				CopyData: "github.com/goplus/gop/tpl/ast",
+			},
+		},
+		{
+			// Special case: Go+ module.
+			"github.com/goplus/gop", "github.com/goplus/gop", version.Latest,
+			breadcrumb{Current: "Go+ standard library"},
+		},
 		{
 			"example.com/blob/s3blob", "example.com", "v1",
 			breadcrumb{
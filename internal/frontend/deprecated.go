@@ -0,0 +1,117 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/frontend/serrors"
+	"golang.org/x/pkgsite/internal/frontend/versions"
+)
+
+// DeprecatedDetails contains information about the deprecations in a module
+// version.
+type DeprecatedDetails struct {
+	ModulePath string
+
+	// DeprecationComment is the deprecation comment in the module's go.mod
+	// file, if the module is deprecated.
+	Deprecated         bool
+	DeprecationComment string
+
+	// Retractions are the versions of the module retracted in its go.mod
+	// file, latest first.
+	Retractions []*Retraction
+
+	// Packages are the packages of the module version that have deprecated
+	// symbols, sorted by path.
+	Packages []*DeprecatedPackage
+}
+
+// A Retraction is a retracted version of a module.
+type Retraction struct {
+	Version   string
+	Link      string
+	Rationale string
+}
+
+// A DeprecatedPackage holds the deprecated symbols of a package.
+type DeprecatedPackage struct {
+	Path    string
+	Link    string
+	Symbols []*DeprecatedSymbol
+}
+
+// A DeprecatedSymbol is a symbol that is deprecated.
+type DeprecatedSymbol struct {
+	// Name is the name of the symbol, like "T.M" for a method.
+	Name string
+	// Link is the URL of the symbol's documentation.
+	Link string
+	// Since is the first version from which the symbol has been deprecated,
+	// or empty if it is not known.
+	Since string
+	// Replacement is the name of the symbol's suggested replacement, as
+	// parsed from its deprecation comment, or empty.
+	Replacement string
+}
+
+// fetchDeprecatedDetails returns the deprecated symbols of the module version
+// of um, along with the deprecation and retractions in its go.mod file.
+func fetchDeprecatedDetails(ctx context.Context, ds internal.DataSource, um *internal.UnitMeta) (_ *DeprecatedDetails, err error) {
+	db, ok := ds.(internal.PostgresDB)
+	if !ok {
+		// Deprecated symbols are computed when a module is inserted into
+		// the database.
+		return nil, serrors.DatasourceNotSupportedError()
+	}
+	syms, err := db.GetDeprecatedSymbols(ctx, um.ModulePath, um.Version)
+	if err != nil {
+		return nil, err
+	}
+	mis, err := db.GetVersionsForPath(ctx, um.ModulePath)
+	if err != nil {
+		return nil, err
+	}
+	return deprecatedDetails(um, syms, mis), nil
+}
+
+// deprecatedDetails builds the DeprecatedDetails for um from its deprecated
+// symbols and the versions of its module.
+func deprecatedDetails(um *internal.UnitMeta, syms []*internal.DeprecatedSymbol, mis []*internal.ModuleInfo) *DeprecatedDetails {
+	dd := &DeprecatedDetails{
+		ModulePath:         um.ModulePath,
+		Deprecated:         um.Deprecated,
+		DeprecationComment: um.DeprecationComment,
+	}
+	for _, mi := range mis {
+		if mi.ModulePath != um.ModulePath || !mi.Retracted {
+			continue
+		}
+		dd.Retractions = append(dd.Retractions, &Retraction{
+			Version:   mi.Version,
+			Link:      versions.ConstructUnitURL(um.ModulePath, um.ModulePath, mi.Version),
+			Rationale: mi.RetractionRationale,
+		})
+	}
+	var pkg *DeprecatedPackage
+	for _, s := range syms {
+		if pkg == nil || pkg.Path != s.PackagePath {
+			pkg = &DeprecatedPackage{
+				Path: s.PackagePath,
+				Link: versions.ConstructUnitURL(s.PackagePath, um.ModulePath, um.Version),
+			}
+			dd.Packages = append(dd.Packages, pkg)
+		}
+		pkg.Symbols = append(pkg.Symbols, &DeprecatedSymbol{
+			Name:        s.Name,
+			Link:        pkg.Link + "#" + s.Name,
+			Since:       s.Since,
+			Replacement: s.Replacement,
+		})
+	}
+	return dd
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+)
+
+func TestDeprecatedDetails(t *testing.T) {
+	const modulePath = "example.com/mod"
+	um := &internal.UnitMeta{
+		Path: modulePath + "/a",
+		ModuleInfo: internal.ModuleInfo{
+			ModulePath:         modulePath,
+			Version:            "v1.2.0",
+			Deprecated:         true,
+			DeprecationComment: "use example.com/mod/v2",
+		},
+	}
+	sym := func(pkg, name, since, replacement string) *internal.DeprecatedSymbol {
+		return &internal.DeprecatedSymbol{
+			SymbolMeta:  internal.SymbolMeta{Name: name, Deprecated: true, Replacement: replacement},
+			PackagePath: modulePath + pkg,
+			Since:       since,
+		}
+	}
+	syms := []*internal.DeprecatedSymbol{
+		sym("/a", "F", "v1.1.0", "G"),
+		sym("/a", "T.M", "", ""),
+		sym("/b", "V", "v1.0.0", ""),
+	}
+	mis := []*internal.ModuleInfo{
+		{ModulePath: modulePath + "/v2", Version: "v2.0.0", Retracted: true},
+		{ModulePath: modulePath, Version: "v1.2.0"},
+		{ModulePath: modulePath, Version: "v1.1.1", Retracted: true, RetractionRationale: "broken build"},
+		{ModulePath: modulePath, Version: "v1.1.0"},
+	}
+
+	got := deprecatedDetails(um, syms, mis)
+	want := &DeprecatedDetails{
+		ModulePath:         modulePath,
+		Deprecated:         true,
+		DeprecationComment: "use example.com/mod/v2",
+		Retractions: []*Retraction{
+			{Version: "v1.1.1", Link: "/example.com/mod@v1.1.1", Rationale: "broken build"},
+		},
+		Packages: []*DeprecatedPackage{
+			{
+				Path: modulePath + "/a",
+				Link: "/example.com/mod@v1.2.0/a",
+				Symbols: []*DeprecatedSymbol{
+					{Name: "F", Link: "/example.com/mod@v1.2.0/a#F", Since: "v1.1.0", Replacement: "G"},
+					{Name: "T.M", Link: "/example.com/mod@v1.2.0/a#T.M"},
+				},
+			},
+			{
+				Path: modulePath + "/b",
+				Link: "/example.com/mod@v1.2.0/b",
+				Symbols: []*DeprecatedSymbol{
+					{Name: "V", Link: "/example.com/mod@v1.2.0/b#V", Since: "v1.0.0"},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
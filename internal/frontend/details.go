@@ -13,6 +13,7 @@ import (
 	"golang.org/x/pkgsite/internal/frontend/page"
 	"golang.org/x/pkgsite/internal/frontend/serrors"
 	"golang.org/x/pkgsite/internal/frontend/urlinfo"
+	"golang.org/x/pkgsite/internal/frontend/versions"
 	mstats "golang.org/x/pkgsite/internal/middleware/stats"
 
 	"golang.org/x/pkgsite/internal"
@@ -86,6 +87,20 @@ func stdlibRedirectURL(fullPath string) string {
 	return "/" + urlPath2
 }
 
+// gopRedirectURL returns the URL path to redirect a request for urlPath to,
+// once it has resolved to the unit um, or "" if there is none. Units of the
+// Go+ module are served at short paths, like /gop/builtin, so a request for
+// one by its import path is redirected to its short path. A short path that
+// resolves to a unit of another module, like a nested module of the Go+
+// repo, is redirected to the unit's import path.
+func gopRedirectURL(urlPath string, um *internal.UnitMeta, requestedVersion string) string {
+	p, _, _ := strings.Cut(strings.Trim(urlPath, "/"), "@")
+	if stdlib.IsGopShortPath(strings.TrimSuffix(p, "/")) == (um.ModulePath == stdlib.GopModulePath) {
+		return ""
+	}
+	return versions.ConstructUnitURL(um.Path, um.ModulePath, requestedVersion)
+}
+
 func checkExcluded(ctx context.Context, ds internal.DataSource, fullPath, version string) error {
 	db, ok := ds.(internal.PostgresDB)
 	if !ok {
@@ -6,6 +6,10 @@ package frontend
 
 import (
 	"testing"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/stdlib"
+	"golang.org/x/pkgsite/internal/version"
 )
 
 func TestStdlibRedirectURL(t *testing.T) {
@@ -28,3 +32,33 @@ func TestStdlibRedirectURL(t *testing.T) {
 		}
 	}
 }
+
+func TestGopRedirectURL(t *testing.T) {
+	const nested = stdlib.GopModulePath + "/x"
+	for _, test := range []struct {
+		path                 string
+		unitPath, modulePath string
+		version              string
+		want                 string
+	}{
+		{"/github.com/goplus/gop", stdlib.GopModulePath, stdlib.GopModulePath, version.Latest, "/gop"},
+		{"/github.com/goplus/gop/", stdlib.GopModulePath, stdlib.GopModulePath, version.Latest, "/gop"},
+		{"/github.com/goplus/gop@v1.2.6", stdlib.GopModulePath, stdlib.GopModulePath, "v1.2.6", "/gop@v1.2.6"},
+		{"/github.com/goplus/gop/builtin", stdlib.GopModulePath + "/builtin", stdlib.GopModulePath, version.Latest, "/gop/builtin"},
+		{"/github.com/goplus/gop@v1.2.6/builtin", stdlib.GopModulePath + "/builtin", stdlib.GopModulePath, "v1.2.6", "/gop/builtin@v1.2.6"},
+		{"/github.com/goplus/gop/builtin@main", stdlib.GopModulePath + "/builtin", stdlib.GopModulePath, "main", "/gop/builtin@main"},
+		// A nested module is served at its import path.
+		{"/github.com/goplus/gop/x/y", nested + "/y", nested, version.Latest, ""},
+		{"/github.com/goplus/gop/x@v0.1.0/y", nested + "/y", nested, "v0.1.0", ""},
+		{"/gop/x/y", nested + "/y", nested, version.Latest, "/github.com/goplus/gop/x/y"},
+		{"/gop/x/y@v0.1.0", nested + "/y", nested, "v0.1.0", "/github.com/goplus/gop/x@v0.1.0/y"},
+		{"/gop", stdlib.GopModulePath, stdlib.GopModulePath, version.Latest, ""},
+		{"/gop/builtin@1.2.6", stdlib.GopModulePath + "/builtin", stdlib.GopModulePath, "v1.2.6", ""},
+		{"/fmt", "fmt", stdlib.ModulePath, version.Latest, ""},
+	} {
+		um := &internal.UnitMeta{Path: test.unitPath, ModuleInfo: internal.ModuleInfo{ModulePath: test.modulePath}}
+		if got := gopRedirectURL(test.path, um, test.version); got != test.want {
+			t.Errorf("gopRedirectURL(%q) = %q; want = %q", test.path, got, test.want)
+		}
+	}
+}
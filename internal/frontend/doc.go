@@ -6,18 +6,25 @@ package frontend
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
 	"path"
+	"path/filepath"
 	"sort"
 	"strings"
 
 	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/frontend/page"
+	"golang.org/x/pkgsite/internal/frontend/serrors"
 	"golang.org/x/pkgsite/internal/godoc"
 	"golang.org/x/pkgsite/internal/godoc/dochtml"
 	"golang.org/x/pkgsite/internal/log"
 	"golang.org/x/pkgsite/internal/middleware/stats"
 	"golang.org/x/pkgsite/internal/stdlib"
+	"golang.org/x/pkgsite/internal/version"
 )
 
 func renderDocParts(ctx context.Context, u *internal.Unit, docPkg *godoc.Package,
@@ -39,6 +46,114 @@ func renderDocParts(ctx context.Context, u *internal.Unit, docPkg *godoc.Package
 	return docPkg.Render(ctx, innerPath, u.SourceInfo, modInfo, nameToVersion, bc)
 }
 
+// docTextContentTypes maps each documentation format to its content type.
+var docTextContentTypes = map[godoc.Format]string{
+	dochtml.FormatMarkdown: "text/markdown; charset=utf-8",
+	dochtml.FormatText:     "text/plain; charset=utf-8",
+}
+
+// serveDocText serves the documentation of the package um in the format
+// named by f, which is "md" or "txt".
+func serveDocText(ctx context.Context, w http.ResponseWriter, ds internal.DataSource,
+	um *internal.UnitMeta, f string, bc internal.BuildContext) (err error) {
+	defer derrors.Wrap(&err, "serveDocText(%q, %q)", um.Path, f)
+
+	format, err := dochtml.ParseFormat(f)
+	if err != nil {
+		return &serrors.ServerError{
+			Status: http.StatusBadRequest,
+			Err:    err,
+			Epage: &page.ErrorPage{
+				MessageData: fmt.Sprintf(`Unsupported documentation format %q. Use "md" or "txt".`, f),
+			},
+		}
+	}
+	b, err := docText(ctx, ds, um, bc, format)
+	if errors.Is(err, derrors.NotFound) {
+		return &serrors.ServerError{
+			Status: http.StatusNotFound,
+			Err:    err,
+			Epage:  &page.ErrorPage{MessageData: "There is no package documentation at this path."},
+		}
+	}
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", docTextContentTypes[format])
+	if _, err := w.Write(b); err != nil {
+		return fmt.Errorf("w.Write: %v", err)
+	}
+	return nil
+}
+
+// docText returns the documentation of the package um in the given format.
+// It returns a NotFound error if um is not a package or has no documentation
+// for bc.
+func docText(ctx context.Context, ds internal.DataSource, um *internal.UnitMeta,
+	bc internal.BuildContext, format godoc.Format) (_ []byte, err error) {
+	defer stats.Elapsed(ctx, "docText")()
+
+	if !um.IsPackage() {
+		return nil, fmt.Errorf("%s is not a package: %w", um.Path, derrors.NotFound)
+	}
+	u, err := ds.GetUnit(ctx, um, internal.WithMain, bc)
+	if err != nil {
+		return nil, err
+	}
+	u.Documentation = cleanDocumentation(u.Documentation)
+	if len(u.Documentation) == 0 || len(u.Documentation[0].Source) == 0 {
+		return nil, fmt.Errorf("no documentation for %s: %w", um.Path, derrors.NotFound)
+	}
+	return godoc.RenderTextFromUnit(ctx, u, bc, format)
+}
+
+// ExportDocs writes the documentation of every package in the server's local
+// modules to dir in the given format, at dir/<import path>.<format>. It
+// returns the number of files written.
+func (s *Server) ExportDocs(ctx context.Context, dir string, format godoc.Format) (n int, err error) {
+	defer derrors.Wrap(&err, "ExportDocs(%q, %q)", dir, format)
+
+	ds := s.getDataSource(ctx)
+	for _, lm := range s.localModules {
+		um, err := ds.GetUnitMeta(ctx, lm.ModulePath, lm.ModulePath, version.Latest)
+		if err != nil {
+			return n, err
+		}
+		u, err := ds.GetUnit(ctx, um, internal.WithMain, internal.BuildContext{})
+		if err != nil {
+			return n, err
+		}
+		paths := []string{u.Path}
+		for _, sub := range u.Subdirectories {
+			if sub.Name != "" {
+				paths = append(paths, sub.Path)
+			}
+		}
+		for _, p := range paths {
+			pum, err := ds.GetUnitMeta(ctx, p, um.ModulePath, um.Version)
+			if err != nil {
+				return n, err
+			}
+			b, err := docText(ctx, ds, pum, internal.BuildContext{}, format)
+			if errors.Is(err, derrors.NotFound) {
+				continue // not a package, or no documentation
+			}
+			if err != nil {
+				return n, err
+			}
+			filename := filepath.Join(dir, filepath.FromSlash(p)+"."+string(format))
+			if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+				return n, err
+			}
+			if err := os.WriteFile(filename, b, 0o644); err != nil {
+				return n, err
+			}
+			n++
+		}
+	}
+	return n, nil
+}
+
 // sourceFiles returns the .go files for a package.
 func sourceFiles(u *internal.Unit, docPkg *godoc.Package) []*File {
 	var files []*File
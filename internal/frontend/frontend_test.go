@@ -6,15 +6,20 @@ package frontend
 
 import (
 	"context"
+	"errors"
+	"io/fs"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/google/safehtml/template"
 	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/config"
+	"golang.org/x/pkgsite/internal/frontend/serrors"
 	"golang.org/x/pkgsite/internal/testing/fakedatasource"
 	"golang.org/x/pkgsite/static"
 	thirdparty "golang.org/x/pkgsite/third_party"
@@ -83,6 +88,8 @@ func TestDetailsTTL(t *testing.T) {
 		{mustRequest("/host.com/module@v1.2.3/suffix?tab=overview", t), longTTL},
 		{mustRequest("/host.com/module@v1.2.3/suffix?tab=versions", t), defaultTTL},
 		{mustRequest("/host.com/module@v1.2.3/suffix?tab=importedby", t), defaultTTL},
+		{mustRequest("/host.com/module@v1.2.3/suffix?tab=deprecated", t), defaultTTL},
+		{mustRequest("/host.com/module@v1.2.3/suffix?tab=licensereport", t), defaultTTL},
 		{
 			func() *http.Request {
 				r := mustRequest("/host.com/module@v1.2.3/suffix?tab=overview", t)
@@ -131,7 +138,7 @@ func TestTagRoute(t *testing.T) {
 
 func TestInstallFS(t *testing.T) {
 	s, handler := newTestServer(t, nil)
-	s.InstallFS("/dir", os.DirFS("."))
+	s.InstallFS("/dir", os.DirFS("."), nil)
 	// Request this file.
 	w := httptest.NewRecorder()
 	handler.ServeHTTP(w, httptest.NewRequest("GET", "/files/dir/frontend_test.go", nil))
@@ -142,3 +149,99 @@ func TestInstallFS(t *testing.T) {
 		t.Errorf("body does not contain %q", want)
 	}
 }
+
+func TestInstallFSSourceView(t *testing.T) {
+	s, handler := newTestServer(t, nil)
+	s.InstallFS("/dir", fstest.MapFS{
+		"a/a.go": {Data: []byte("package a\n\n// F calls g.\nfunc F() { g() }\n")},
+		"a/g.go": {Data: []byte("package a\n\nfunc g() {}\n")},
+	}, func(dir string) string { return "example.com/" + dir })
+
+	for _, test := range []struct {
+		url, want, dontWant string
+	}{
+		{
+			"/files/dir/a/a.go",
+			`<a href="/example.com/a#F" title="Documentation for F">F</a>() { <a href="g.go#L3" title="func g() {}">g</a>() }`,
+			"",
+		},
+		{"/files/dir/a/a.go", `id="L4"`, ""},
+		{"/files/dir/a/a.go?raw=1", "func F() { g() }", "<html"},
+	} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", test.url, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: got status code = %d, want %d", test.url, w.Code, http.StatusOK)
+		}
+		body := w.Body.String()
+		if !strings.Contains(body, test.want) {
+			t.Errorf("%s: body does not contain %q:\n%s", test.url, test.want, body)
+		}
+		if test.dontWant != "" && strings.Contains(body, test.dontWant) {
+			t.Errorf("%s: body contains %q", test.url, test.dontWant)
+		}
+	}
+
+	for _, url := range []string{"/files/dir/a/missing.go", "/files/dir/b/b.go"} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s: got status code = %d, want %d", url, w.Code, http.StatusNotFound)
+		}
+	}
+}
+
+func TestFSError(t *testing.T) {
+	for _, test := range []struct {
+		err  error
+		want int
+	}{
+		{fs.ErrNotExist, http.StatusNotFound},
+		{&fs.PathError{Op: "open", Path: "../a.go", Err: fs.ErrInvalid}, http.StatusNotFound},
+		{fs.ErrPermission, 0},
+	} {
+		var serr *serrors.ServerError
+		got := 0
+		if errors.As(fsError(test.err), &serr) {
+			got = serr.Status
+		}
+		if got != test.want {
+			t.Errorf("fsError(%v): got status %d, want %d", test.err, got, test.want)
+		}
+	}
+}
+
+type fakeClearer struct{ cleared bool }
+
+func (c *fakeClearer) Clear(context.Context) error {
+	c.cleared = true
+	return nil
+}
+
+func TestClearCacheHandler(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		localMode bool
+		auth      string
+		want      bool
+	}{
+		{"no auth", false, "", false},
+		{"wrong auth", false, "no", false},
+		{"auth", false, "yes", true},
+		{"local", true, "", true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			s := &Server{localMode: test.localMode}
+			c := &fakeClearer{}
+			r := httptest.NewRequest("GET", "/clear-cache", nil)
+			if test.auth != "" {
+				r.Header.Set(config.BypassCacheAuthHeader, test.auth)
+			}
+			w := httptest.NewRecorder()
+			s.clearCacheHandler(c, []string{"yes"}).ServeHTTP(w, r)
+			if c.cleared != test.want {
+				t.Errorf("cleared = %t, want %t (status %d)", c.cleared, test.want, w.Code)
+			}
+		})
+	}
+}
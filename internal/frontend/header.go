@@ -20,6 +20,7 @@ const (
 	pageTypeCommand   = "command"
 	pageTypeModuleStd = "std"
 	pageTypeStdlib    = "standard library"
+	pageTypeGopStdlib = "Go+ standard library"
 )
 
 // pageTitle determines the pageTitles for a given unit.
@@ -28,6 +29,8 @@ func pageTitle(um *internal.UnitMeta) string {
 	switch {
 	case um.Path == stdlib.ModulePath:
 		return "Standard library"
+	case um.Path == stdlib.GopModulePath:
+		return "Go+ standard library"
 	case um.IsCommand():
 		return effectiveName(um.Path, um.Name)
 	case um.IsPackage():
@@ -42,7 +45,7 @@ func pageTitle(um *internal.UnitMeta) string {
 
 // pageType determines the pageType for a given unit.
 func pageType(um *internal.UnitMeta) string {
-	if um.Path == stdlib.ModulePath {
+	if um.Path == stdlib.ModulePath || um.Path == stdlib.GopModulePath {
 		return pageTypeModuleStd
 	}
 	if um.IsCommand() {
@@ -61,7 +64,7 @@ func pageType(um *internal.UnitMeta) string {
 // See TestPageTitlesAndTypes for examples.
 func pageLabels(um *internal.UnitMeta) []string {
 	var pageTypes []string
-	if um.Path == stdlib.ModulePath {
+	if um.Path == stdlib.ModulePath || um.Path == stdlib.GopModulePath {
 		return nil
 	}
 	if um.IsCommand() {
@@ -78,6 +81,9 @@ func pageLabels(um *internal.UnitMeta) []string {
 	if stdlib.Contains(um.Path) {
 		pageTypes = append(pageTypes, pageTypeStdlib)
 	}
+	if stdlib.GopContains(um.Path) {
+		pageTypes = append(pageTypes, pageTypeGopStdlib)
+	}
 	return pageTypes
 }
 
@@ -68,6 +68,19 @@ func TestPageTitlePageTypePageLabels(t *testing.T) {
 		}
 	}
 
+	gop := sample.Module(stdlib.GopModulePath, "v1.2.6", "builtin")
+	for _, u := range gop.Units {
+		um := &u.UnitMeta
+		switch um.Path {
+		case stdlib.GopModulePath:
+			tests = append(tests, &testUnitPage{um, "module " + stdlib.GopModulePath, "Go+ standard library", pageTypeModuleStd, nil})
+		case stdlib.GopModulePath + "/builtin":
+			tests = append(tests, &testUnitPage{um, "package " + um.Path, "builtin", pageTypePackage, []string{pageTypePackage, pageTypeGopStdlib}})
+		default:
+			t.Fatalf("Unexpected path: %q", um.Path)
+		}
+	}
+
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			gotTitle := pageTitle(test.unit)
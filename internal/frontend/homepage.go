@@ -49,8 +49,13 @@ type Homepage struct {
 	SearchTips []searchTip
 
 	// LocalModules holds locally-hosted modules, for quick navigation.
+	// Modules that belong to one of LocalWorkspaces are not included.
 	// Empty in production.
 	LocalModules []LocalModule
+
+	// LocalWorkspaces holds locally-hosted go.work workspaces.
+	// Empty in production.
+	LocalWorkspaces []LocalWorkspace
 }
 
 // LocalModule holds information about a locally-hosted module.
@@ -61,11 +66,37 @@ type LocalModule struct {
 	Dir        string `json:"Dir"`
 }
 
+// LocalWorkspace holds information about a locally-hosted go.work
+// workspace.
+type LocalWorkspace struct {
+	GoWork  string        // absolute path to the go.work file
+	Modules []LocalModule // modules in the workspace's use directives
+}
+
 func (s *Server) serveHomepage(ctx context.Context, w http.ResponseWriter, r *http.Request) {
 	s.servePage(ctx, w, "homepage", Homepage{
-		BasePage:     s.newBasePage(r, "Go Packages"),
-		SearchTips:   searchTips,
-		TipIndex:     rand.Intn(len(searchTips)),
-		LocalModules: s.localModules,
+		BasePage:        s.newBasePage(r, "Go Packages"),
+		SearchTips:      searchTips,
+		TipIndex:        rand.Intn(len(searchTips)),
+		LocalModules:    modulesOutsideWorkspaces(s.localModules, s.localWorkspaces),
+		LocalWorkspaces: s.localWorkspaces,
 	})
 }
+
+// modulesOutsideWorkspaces returns the modules in mods that do not belong to
+// any of the given workspaces.
+func modulesOutsideWorkspaces(mods []LocalModule, workspaces []LocalWorkspace) []LocalModule {
+	inWorkspace := make(map[LocalModule]bool)
+	for _, w := range workspaces {
+		for _, m := range w.Modules {
+			inWorkspace[m] = true
+		}
+	}
+	var r []LocalModule
+	for _, m := range mods {
+		if !inWorkspace[m] {
+			r = append(r, m)
+		}
+	}
+	return r
+}
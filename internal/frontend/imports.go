@@ -6,12 +6,15 @@ package frontend
 
 import (
 	"context"
+	"errors"
 	"strings"
 
 	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/frontend/serrors"
 	"golang.org/x/pkgsite/internal/log"
 	"golang.org/x/pkgsite/internal/stdlib"
+	"golang.org/x/pkgsite/internal/vuln"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
 )
@@ -31,11 +34,17 @@ type ImportsDetails struct {
 	// StdLib is an array of packages representing the package's imports
 	// that are in the Go standard library.
 	StdLib []string
+
+	// Vulns are the vulnerabilities that affect the imported packages, keyed
+	// by import path, with the symbols of the packages that they affect.
+	Vulns map[string][]*vuln.SymbolVuln
 }
 
 // fetchImportsDetails fetches imports for the package version specified by
 // pkgPath, modulePath and version from the database and returns a ImportsDetails.
-func fetchImportsDetails(ctx context.Context, ds internal.DataSource, pkgPath, modulePath, resolvedVersion string) (_ *ImportsDetails, err error) {
+// If vc is not nil, the vulnerabilities of the imported packages are included.
+func fetchImportsDetails(ctx context.Context, ds internal.DataSource, pkgPath, modulePath, resolvedVersion string,
+	vc *vuln.Client) (_ *ImportsDetails, err error) {
 	u, err := ds.GetUnit(ctx, &internal.UnitMeta{
 		Path: pkgPath,
 		ModuleInfo: internal.ModuleInfo{
@@ -58,14 +67,91 @@ func fetchImportsDetails(ctx context.Context, ds internal.DataSource, pkgPath, m
 		}
 	}
 
+	var vulns map[string][]*vuln.SymbolVuln
+	if vc != nil {
+		vulns, err = importVulns(ctx, ds, vc, modulePath, resolvedVersion, u.Imports)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return &ImportsDetails{
 		ModulePath:      modulePath,
 		ExternalImports: externalImports,
 		InternalImports: moduleImports,
 		StdLib:          std,
+		Vulns:           vulns,
 	}, nil
 }
 
+// importVulns returns the vulnerabilities that affect the imports of a
+// package in the given module version, keyed by import path.
+//
+// Imports from the same module are checked at its version, and imports from
+// other modules at the versions required by its go.mod file. Imports from the
+// standard library are only checked for packages of the standard library,
+// because the version of Go that other packages are built with isn't known.
+// Errors from the vulnerability database are logged, so that the imports can
+// be displayed without vulnerabilities.
+func importVulns(ctx context.Context, ds internal.DataSource, vc *vuln.Client, modulePath, resolvedVersion string, imports []string) (_ map[string][]*vuln.SymbolVuln, err error) {
+	defer derrors.Wrap(&err, "importVulns(%q, %q)", modulePath, resolvedVersion)
+
+	var reqs []*internal.Requirement
+	if modulePath != stdlib.ModulePath {
+		reqs, err = ds.GetModuleRequirements(ctx, modulePath, resolvedVersion)
+		if err != nil && !errors.Is(err, derrors.NotFound) && !errors.Is(err, derrors.RequirementsUnknown) {
+			return nil, err
+		}
+	}
+	// importModule returns the module and version that provide the import
+	// path p, or false if they aren't known.
+	importModule := func(p string) (string, string, bool) {
+		if stdlib.Contains(p) {
+			return stdlib.ModulePath, resolvedVersion, modulePath == stdlib.ModulePath
+		}
+		if strings.HasPrefix(p+"/", modulePath+"/") {
+			return modulePath, resolvedVersion, true
+		}
+		req := requirementForImport(p, reqs)
+		if req == nil {
+			return "", "", false
+		}
+		return req.ModulePath, req.Version, true
+	}
+
+	vulns := map[string][]*vuln.SymbolVuln{}
+	for _, p := range imports {
+		mod, v, ok := importModule(p)
+		if !ok {
+			continue
+		}
+		svs, err := vuln.SymbolVulnsForPackage(ctx, mod, v, p, vc)
+		if err != nil {
+			log.Errorf(ctx, "importVulns: %v", err)
+			return nil, nil
+		}
+		if len(svs) > 0 {
+			vulns[p] = svs
+		}
+	}
+	if len(vulns) == 0 {
+		return nil, nil
+	}
+	return vulns, nil
+}
+
+// requirementForImport returns the requirement in reqs that provides the
+// package with import path p, or nil if there is none. The requirement with
+// the longest matching module path provides the package.
+func requirementForImport(p string, reqs []*internal.Requirement) *internal.Requirement {
+	var req *internal.Requirement
+	for _, r := range reqs {
+		if strings.HasPrefix(p+"/", r.ModulePath+"/") && (req == nil || len(r.ModulePath) > len(req.ModulePath)) {
+			req = r
+		}
+	}
+	return req
+}
+
 // ImportedByDetails contains information for the collection of packages that
 // import a given package.
 type ImportedByDetails struct {
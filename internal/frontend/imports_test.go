@@ -11,8 +11,10 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/osv"
 	"golang.org/x/pkgsite/internal/testing/fakedatasource"
 	"golang.org/x/pkgsite/internal/testing/sample"
+	"golang.org/x/pkgsite/internal/vuln"
 )
 
 func TestFetchImportsDetails(t *testing.T) {
@@ -55,7 +57,7 @@ func TestFetchImportsDetails(t *testing.T) {
 
 			fds.MustInsertModule(ctx, module)
 
-			got, err := fetchImportsDetails(ctx, fds, pkg.Path, pkg.ModulePath, pkg.Version)
+			got, err := fetchImportsDetails(ctx, fds, pkg.Path, pkg.ModulePath, pkg.Version, nil)
 			if err != nil {
 				t.Fatalf("fetchImportsDetails(ctx, db, %q, %q) = %v err = %v, want %v",
 					module.Units[1].Path, module.Version, got, err, test.wantDetails)
@@ -69,6 +71,68 @@ func TestFetchImportsDetails(t *testing.T) {
 	}
 }
 
+func TestFetchImportsDetailsVulns(t *testing.T) {
+	ctx := context.Background()
+	fds := fakedatasource.New()
+
+	m := sample.Module("example.com/main", "v1.0.0", "a", "b")
+	for _, u := range m.Units {
+		if u.Path == "example.com/main/a" {
+			u.Imports = []string{"example.com/dep/pkg", "example.com/dep/sub/pkg", "example.com/main/b", "net/http"}
+		}
+	}
+	m.Requirements = []*internal.Requirement{
+		{ModulePath: "example.com/dep", Version: "v1.2.0"},
+		{ModulePath: "example.com/dep/sub", Version: "v1.0.0"},
+	}
+	fds.MustInsertModule(ctx, m)
+
+	vc, err := vuln.NewInMemoryClient([]*osv.Entry{{
+		ID: "GO-1990-0001",
+		Affected: []osv.Affected{{
+			Module: osv.Module{Path: "example.com/dep"},
+			Ranges: []osv.Range{{
+				Type:   osv.RangeTypeSemver,
+				Events: []osv.RangeEvent{{Introduced: "0"}, {Fixed: "1.3.0"}},
+			}},
+			EcosystemSpecific: osv.EcosystemSpecific{
+				Packages: []osv.Package{{Path: "example.com/dep/pkg", Symbols: []string{"T.M", "F"}}},
+			},
+		}},
+	}, {
+		// The requirement on example.com/dep/sub provides its packages,
+		// so this doesn't affect example.com/dep/sub/pkg.
+		ID: "GO-1990-0002",
+		Affected: []osv.Affected{{
+			Module: osv.Module{Path: "example.com/dep"},
+			EcosystemSpecific: osv.EcosystemSpecific{
+				Packages: []osv.Package{{Path: "example.com/dep/sub/pkg"}},
+			},
+		}},
+	}, {
+		ID:       "GO-1990-0003",
+		Affected: []osv.Affected{{Module: osv.Module{Path: "example.com/main"}}},
+	}, {
+		// The version of Go isn't known, so this isn't reported.
+		ID:       "GO-1990-0004",
+		Affected: []osv.Affected{{Module: osv.Module{Path: osv.GoStdModulePath}}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := fetchImportsDetails(ctx, fds, "example.com/main/a", "example.com/main", "v1.0.0", vc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]*vuln.SymbolVuln{
+		"example.com/dep/pkg": {{Vuln: vuln.Vuln{ID: "GO-1990-0001"}, Symbols: []string{"F", "T.M"}}},
+		"example.com/main/b":  {{Vuln: vuln.Vuln{ID: "GO-1990-0003"}}},
+	}
+	if diff := cmp.Diff(want, got.Vulns); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
 func TestFetchImportedByDetails(t *testing.T) {
 	fds := fakedatasource.New()
 	ctx := context.Background()
@@ -0,0 +1,312 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/frontend/page"
+	"golang.org/x/pkgsite/internal/frontend/serrors"
+	"golang.org/x/pkgsite/internal/frontend/versions"
+	"golang.org/x/pkgsite/internal/licenses"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/sbom"
+	"golang.org/x/pkgsite/internal/version"
+)
+
+// LicenseReportDetails contains the licenses of a module version and of the
+// modules it requires.
+//
+// Only the requirements in the module's go.mod file are reported. For modules
+// at go 1.17 or higher, those are all the modules needed to build its
+// packages; for older ones, some indirect dependencies may be missing.
+type LicenseReportDetails struct {
+	ModulePath string
+	Version    string
+
+	// Modules are the module itself, followed by its requirements sorted by
+	// path.
+	Modules []*ReportModule
+
+	// NumFlagged is the number of Modules with a problem.
+	NumFlagged int
+
+	// RequirementsUnknown reports whether the requirements of the module
+	// were not recorded when it was processed, so that Modules only holds
+	// the module itself.
+	RequirementsUnknown bool
+}
+
+// A ReportModule describes the licenses of one module in a license report.
+type ReportModule struct {
+	ModulePath string
+	Version    string
+	// Link is the URL of the module's licenses, or empty if the module
+	// was not found.
+	Link string
+	// Main reports whether this is the module the report is for.
+	Main bool
+	// Indirect reports whether the requirement is marked // indirect.
+	Indirect bool
+	// Types are the license types detected in the module's root directory,
+	// sorted.
+	Types []string
+	// Redistributable reports whether the licenses allow redistribution, or
+	// the license policy accepts the module regardless of them.
+	Redistributable bool
+	// Problem describes what is wrong with the module's licenses, or is
+	// empty.
+	Problem string
+}
+
+// Problems reported for the modules of a license report.
+const (
+	reportProblemNotFound         = "module not found"
+	reportProblemNoLicense        = "no license"
+	reportProblemUnknown          = "unrecognized license"
+	reportProblemNotRedistributed = "not redistributable"
+)
+
+// fetchLicenseReportDetails returns a license report for the module of um.
+func fetchLicenseReportDetails(ctx context.Context, ds internal.DataSource, um *internal.UnitMeta) (_ *LicenseReportDetails, err error) {
+	defer derrors.Wrap(&err, "fetchLicenseReportDetails(%q, %q)", um.ModulePath, um.Version)
+
+	d := &LicenseReportDetails{ModulePath: um.ModulePath, Version: um.Version}
+	reqs, err := ds.GetModuleRequirements(ctx, um.ModulePath, um.Version)
+	if errors.Is(err, derrors.RequirementsUnknown) {
+		d.RequirementsUnknown = true
+	} else if err != nil {
+		return nil, err
+	}
+	main, err := reportModule(ctx, ds, &internal.Requirement{ModulePath: um.ModulePath, Version: um.Version})
+	if err != nil {
+		return nil, err
+	}
+	main.Main = true
+	d.Modules = append(d.Modules, main)
+	for _, req := range reqs {
+		rm, err := reportModule(ctx, ds, req)
+		if err != nil {
+			return nil, err
+		}
+		d.Modules = append(d.Modules, rm)
+	}
+	for _, rm := range d.Modules {
+		if rm.Problem != "" {
+			d.NumFlagged++
+		}
+	}
+	return d, nil
+}
+
+// reportModule looks up the licenses of the required module version. A
+// module that can't be found is reported with a problem, not an error.
+func reportModule(ctx context.Context, ds internal.DataSource, req *internal.Requirement) (*ReportModule, error) {
+	rm := &ReportModule{
+		ModulePath: req.ModulePath,
+		Version:    req.Version,
+		Indirect:   req.Indirect,
+	}
+	um, err := ds.GetUnitMeta(ctx, req.ModulePath, req.ModulePath, req.Version)
+	var u *internal.Unit
+	if err == nil {
+		u, err = ds.GetUnit(ctx, um, internal.WithLicenses, internal.BuildContext{})
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		if !errors.Is(err, derrors.NotFound) {
+			log.Errorf(ctx, "license report: %s@%s: %v", req.ModulePath, req.Version, err)
+		}
+		rm.Problem = reportProblemNotFound
+		return rm, nil
+	}
+	rm.Link = versions.ConstructUnitURL(req.ModulePath, req.ModulePath, req.Version) + "?tab=licenses"
+
+	seen := map[string]bool{}
+	for _, l := range u.LicenseContents {
+		for _, t := range l.Types {
+			if !seen[t] {
+				seen[t] = true
+				rm.Types = append(rm.Types, t)
+			}
+		}
+	}
+	sort.Strings(rm.Types)
+
+	// Check the licenses here rather than using the redistributable bit that
+	// was stored with the module, which is always set when the license check
+	// is bypassed, as it is by cmd/pkgsite.
+	if licenses.AllowedByPolicy(req.ModulePath) {
+		// The license policy vouches for the module.
+		rm.Redistributable = true
+		return rm, nil
+	}
+	rm.Redistributable = licenses.Redistributable(rm.Types)
+	switch {
+	case len(rm.Types) == 0:
+		rm.Problem = reportProblemNoLicense
+	case seen[licenses.UnknownLicenseType]:
+		rm.Problem = reportProblemUnknown
+	case !rm.Redistributable:
+		rm.Problem = reportProblemNotRedistributed
+	}
+	return rm, nil
+}
+
+// Formats in which a license report can be exported.
+const (
+	licenseReportCSV  = "csv"
+	licenseReportSPDX = "spdx"
+)
+
+var licenseReportContentTypes = map[string]string{
+	licenseReportCSV:  "text/csv; charset=utf-8",
+	licenseReportSPDX: "application/spdx+json",
+}
+
+// serveLicenseReport serves the license report for the module of um in the
+// given format.
+func serveLicenseReport(ctx context.Context, w http.ResponseWriter, ds internal.DataSource, um *internal.UnitMeta, format string) (err error) {
+	defer derrors.Wrap(&err, "serveLicenseReport(%q, %q)", um.ModulePath, format)
+
+	if licenseReportContentTypes[format] == "" {
+		return &serrors.ServerError{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("unknown license report format %q", format),
+			Epage: &page.ErrorPage{
+				MessageData: fmt.Sprintf(`Unsupported license report format %q. Use "csv" or "spdx".`, format),
+			},
+		}
+	}
+	d, err := fetchLicenseReportDetails(ctx, ds, um)
+	if err != nil {
+		return err
+	}
+	if d.RequirementsUnknown {
+		// A report without the requirements would look like that of a
+		// module without any.
+		return &serrors.ServerError{
+			Status: http.StatusNotFound,
+			Err:    fmt.Errorf("%s@%s: %w", um.ModulePath, um.Version, derrors.RequirementsUnknown),
+			Epage: &page.ErrorPage{
+				MessageData: "The requirements of this module version are unknown, because it was processed before they were recorded. It needs to be reprocessed.",
+			},
+		}
+	}
+	b, err := encodeLicenseReport(d, format, time.Now())
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", licenseReportContentTypes[format])
+	filename := path.Base(d.ModulePath) + "@" + d.Version + licenseReportExt(format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if _, err := w.Write(b); err != nil {
+		return fmt.Errorf("w.Write: %v", err)
+	}
+	return nil
+}
+
+// licenseReportExt returns the file extension for license reports in the
+// given format.
+func licenseReportExt(format string) string {
+	if format == licenseReportSPDX {
+		return ".spdx.json"
+	}
+	return ".licenses.csv"
+}
+
+// encodeLicenseReport encodes d in the given format. Created is the creation
+// time of SPDX documents.
+func encodeLicenseReport(d *LicenseReportDetails, format string, created time.Time) ([]byte, error) {
+	switch format {
+	case licenseReportCSV:
+		return licenseReportToCSV(d)
+	case licenseReportSPDX:
+		return json.MarshalIndent(licenseReportToSPDX(d, created), "", "  ")
+	}
+	return nil, fmt.Errorf("unknown license report format %q", format)
+}
+
+// licenseReportToCSV returns the license report d as CSV, with a header row
+// and a row for each module.
+func licenseReportToCSV(d *LicenseReportDetails) ([]byte, error) {
+	var buf bytes.Buffer
+	cw := csv.NewWriter(&buf)
+	cw.Write([]string{"module", "version", "requirement", "licenses", "redistributable", "problem"})
+	for _, m := range d.Modules {
+		req := "direct"
+		switch {
+		case m.Main:
+			req = "main"
+		case m.Indirect:
+			req = "indirect"
+		}
+		cw.Write([]string{m.ModulePath, m.Version, req, strings.Join(m.Types, " "), strconv.FormatBool(m.Redistributable), m.Problem})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// licenseReportToSPDX returns the license report d as an SPDX document, with
+// a package for each module.
+func licenseReportToSPDX(d *LicenseReportDetails, created time.Time) *sbom.SPDXDocument {
+	namespace := "https://spdx.org/spdxdocs/pkgsite/" + d.ModulePath + "@" + d.Version + "/licenses"
+	return sbom.SPDX(reportToSBOM(d), namespace, created)
+}
+
+// ExportLicenseReports writes the license reports of the server's local
+// modules to dir, as CSV and SPDX files at dir/<module path>.licenses.csv and
+// dir/<module path>.spdx.json. It returns the number of files written.
+func (s *Server) ExportLicenseReports(ctx context.Context, dir string) (n int, err error) {
+	defer derrors.Wrap(&err, "ExportLicenseReports(%q)", dir)
+
+	ds := s.getDataSource(ctx)
+	now := time.Now()
+	for _, lm := range s.localModules {
+		um, err := ds.GetUnitMeta(ctx, lm.ModulePath, lm.ModulePath, version.Latest)
+		if err != nil {
+			return n, err
+		}
+		d, err := fetchLicenseReportDetails(ctx, ds, um)
+		if err != nil {
+			return n, err
+		}
+		for _, format := range []string{licenseReportCSV, licenseReportSPDX} {
+			b, err := encodeLicenseReport(d, format, now)
+			if err != nil {
+				return n, err
+			}
+			filename := filepath.Join(dir, filepath.FromSlash(d.ModulePath)+licenseReportExt(format))
+			if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+				return n, err
+			}
+			if err := os.WriteFile(filename, b, 0o644); err != nil {
+				return n, err
+			}
+			n++
+		}
+	}
+	return n, nil
+}
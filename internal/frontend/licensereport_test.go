@@ -0,0 +1,166 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/frontend/serrors"
+	"golang.org/x/pkgsite/internal/licenses"
+	"golang.org/x/pkgsite/internal/testing/fakedatasource"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestLicenseReport(t *testing.T) {
+	ctx := context.Background()
+	fds := fakedatasource.New()
+
+	// module returns a module whose root directory has a license file with
+	// the given types, or no license file if there are none.
+	module := func(modulePath string, types ...string) *internal.Module {
+		m := sample.Module(modulePath, "v1.0.0")
+		m.Licenses = nil
+		if len(types) > 0 {
+			m.Licenses = []*licenses.License{{Metadata: &licenses.Metadata{Types: types, FilePath: "LICENSE"}}}
+		}
+		for _, u := range m.Units {
+			u.Licenses = nil
+			u.LicenseContents = nil
+			for _, l := range m.Licenses {
+				u.Licenses = append(u.Licenses, l.Metadata)
+			}
+		}
+		return m
+	}
+	main := module("example.com/main", "MIT")
+	main.Requirements = []*internal.Requirement{
+		{ModulePath: "a.com/mit", Version: "v1.0.0"},
+		{ModulePath: "b.com/none", Version: "v1.0.0"},
+		{ModulePath: "c.com/unknown", Version: "v1.0.0", Indirect: true},
+		{ModulePath: "d.com/nc", Version: "v1.0.0"},
+		{ModulePath: "e.com/missing", Version: "v1.0.0"},
+	}
+	for _, m := range []*internal.Module{
+		main,
+		module("a.com/mit", "MIT", "Apache-2.0"),
+		module("b.com/none"),
+		module("c.com/unknown", licenses.UnknownLicenseType),
+		module("d.com/nc", "CC-BY-NC-4.0"),
+	} {
+		fds.MustInsertModule(ctx, m)
+	}
+
+	um := sample.UnitMeta("example.com/main", "example.com/main", "v1.0.0", "", true)
+	got, err := fetchLicenseReportDetails(ctx, fds, um)
+	if err != nil {
+		t.Fatal(err)
+	}
+	link := func(modulePath string) string {
+		return "/" + modulePath + "@v1.0.0?tab=licenses"
+	}
+	want := &LicenseReportDetails{
+		ModulePath: "example.com/main",
+		Version:    "v1.0.0",
+		Modules: []*ReportModule{
+			{ModulePath: "example.com/main", Version: "v1.0.0", Link: link("example.com/main"), Main: true, Types: []string{"MIT"}, Redistributable: true},
+			{ModulePath: "a.com/mit", Version: "v1.0.0", Link: link("a.com/mit"), Types: []string{"Apache-2.0", "MIT"}, Redistributable: true},
+			{ModulePath: "b.com/none", Version: "v1.0.0", Link: link("b.com/none"), Problem: reportProblemNoLicense},
+			{ModulePath: "c.com/unknown", Version: "v1.0.0", Link: link("c.com/unknown"), Indirect: true, Types: []string{"UNKNOWN"}, Problem: reportProblemUnknown},
+			{ModulePath: "d.com/nc", Version: "v1.0.0", Link: link("d.com/nc"), Types: []string{"CC-BY-NC-4.0"}, Problem: reportProblemNotRedistributed},
+			{ModulePath: "e.com/missing", Version: "v1.0.0", Problem: reportProblemNotFound},
+		},
+		NumFlagged: 4,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	csv, err := encodeLicenseReport(got, licenseReportCSV, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCSV := `module,version,requirement,licenses,redistributable,problem
+example.com/main,v1.0.0,main,MIT,true,
+a.com/mit,v1.0.0,direct,Apache-2.0 MIT,true,
+b.com/none,v1.0.0,direct,,false,no license
+c.com/unknown,v1.0.0,indirect,UNKNOWN,false,unrecognized license
+d.com/nc,v1.0.0,direct,CC-BY-NC-4.0,false,not redistributable
+e.com/missing,v1.0.0,direct,,false,module not found
+`
+	if diff := cmp.Diff(wantCSV, string(csv)); diff != "" {
+		t.Errorf("CSV mismatch (-want, +got):\n%s", diff)
+	}
+
+	doc := licenseReportToSPDX(got, time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC))
+	if doc.CreationInfo.Created != "2023-01-02T03:04:05Z" {
+		t.Errorf("created: got %q", doc.CreationInfo.Created)
+	}
+	var gotDeclared []string
+	for _, p := range doc.Packages {
+		gotDeclared = append(gotDeclared, p.Name+": "+p.LicenseDeclared)
+	}
+	wantDeclared := []string{
+		"example.com/main: MIT",
+		"a.com/mit: Apache-2.0 AND MIT",
+		"b.com/none: NOASSERTION",
+		"c.com/unknown: NOASSERTION",
+		"d.com/nc: CC-BY-NC-4.0",
+		"e.com/missing: NOASSERTION",
+	}
+	if diff := cmp.Diff(wantDeclared, gotDeclared); diff != "" {
+		t.Errorf("declared licenses mismatch (-want, +got):\n%s", diff)
+	}
+	if n := len(doc.Relationships); n != len(got.Modules) {
+		t.Errorf("got %d relationships, want %d", n, len(got.Modules))
+	}
+	for _, r := range doc.Relationships[1:] {
+		if r.RelationshipType != "DEPENDS_ON" || !strings.HasPrefix(r.SPDXElementID, "SPDXRef-Module-example.com-main") {
+			t.Errorf("got relationship %+v, want example.com/main DEPENDS_ON", r)
+		}
+	}
+}
+
+// unknownRequirementsDataSource is a DataSource that doesn't know the
+// requirements of any module.
+type unknownRequirementsDataSource struct {
+	internal.DataSource
+}
+
+func (unknownRequirementsDataSource) GetModuleRequirements(context.Context, string, string) ([]*internal.Requirement, error) {
+	return nil, derrors.RequirementsUnknown
+}
+
+func TestLicenseReportRequirementsUnknown(t *testing.T) {
+	ctx := context.Background()
+	fds := fakedatasource.New()
+	m := sample.Module("example.com/main", "v1.0.0")
+	fds.MustInsertModule(ctx, m)
+	ds := unknownRequirementsDataSource{fds}
+	um := sample.UnitMeta("example.com/main", "example.com/main", "v1.0.0", "", true)
+
+	got, err := fetchLicenseReportDetails(ctx, ds, um)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.RequirementsUnknown || len(got.Modules) != 1 {
+		t.Errorf("got RequirementsUnknown = %t with %d modules, want true with 1", got.RequirementsUnknown, len(got.Modules))
+	}
+
+	w := httptest.NewRecorder()
+	err = serveLicenseReport(ctx, w, ds, um, licenseReportCSV)
+	var serr *serrors.ServerError
+	if !errors.As(err, &serr) || serr.Status != http.StatusNotFound {
+		t.Errorf("serveLicenseReport: got %v, want a 404 ServerError", err)
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"sort"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/osv"
+	"golang.org/x/pkgsite/internal/stdlib"
+	"golang.org/x/pkgsite/internal/vuln"
+)
+
+// A LocalBuildList is the build list of a locally hosted module, along with
+// the imports of its packages, for reporting the known vulnerabilities of
+// its dependencies.
+type LocalBuildList struct {
+	ModulePath string
+
+	// GoVersion is the version of the standard library that the module is
+	// built with, like "v1.21.0", or empty if it isn't known.
+	GoVersion string
+
+	// Deps are the modules in the build list other than the main modules,
+	// sorted by path. The version of a replaced module is that of its
+	// replacement, and is empty if it is replaced by a directory.
+	Deps []*internal.Requirement
+
+	// Imports maps the paths of the module's packages to the paths of the
+	// packages they import.
+	Imports map[string][]string
+}
+
+// LocalVulnsDetails lists the known vulnerabilities of the dependencies of a
+// locally hosted module.
+type LocalVulnsDetails struct {
+	// NumChecked is the number of dependencies that were checked, including
+	// the standard library.
+	NumChecked int
+
+	// Vulns are the vulnerabilities, sorted by module path and ID.
+	Vulns []*LocalVuln
+}
+
+// A LocalVuln is a vulnerability that affects a dependency of a local module.
+type LocalVuln struct {
+	ID      string
+	Summary string
+
+	// ModulePath and Version identify the affected dependency. The version
+	// of the standard library is a Go tag, like "go1.21.0".
+	ModulePath string
+	Version    string
+
+	// FixedVersion is the version of the dependency that fixes the
+	// vulnerability, or empty if there is no fix.
+	FixedVersion string
+
+	// Imports are the affected packages of the dependency that the packages
+	// of the local module import, sorted by path. If there are none, the
+	// vulnerability can't affect the module.
+	Imports []*LocalVulnImport
+}
+
+// A LocalVulnImport is an affected package imported by packages of a local
+// module.
+type LocalVulnImport struct {
+	PackagePath string
+	// Importers are the packages of the local module that import the
+	// package, sorted.
+	Importers []string
+}
+
+// localVulnsDetails returns the vulnerabilities of the dependencies of the
+// local module of um, if um is the module's root and the server has its
+// build list. Errors are logged, so that the rest of the page can be
+// displayed without them.
+func (s *Server) localVulnsDetails(ctx context.Context, um *internal.UnitMeta) *LocalVulnsDetails {
+	if s.vulnClient == nil || um.Path != um.ModulePath {
+		return nil
+	}
+	bl := s.localBuildLists[um.ModulePath]
+	if bl == nil {
+		return nil
+	}
+	d, err := fetchLocalVulnsDetails(ctx, bl, s.vulnClient)
+	if err != nil {
+		log.Errorf(ctx, "%v", err)
+		return nil
+	}
+	return d
+}
+
+// fetchLocalVulnsDetails matches each dependency in the build list bl
+// against the vulnerability database, like govulncheck does at the module
+// level, and reports which packages of the module import the affected
+// packages.
+func fetchLocalVulnsDetails(ctx context.Context, bl *LocalBuildList, vc *vuln.Client) (_ *LocalVulnsDetails, err error) {
+	defer derrors.Wrap(&err, "fetchLocalVulnsDetails(%q)", bl.ModulePath)
+
+	importers := map[string][]string{}
+	for pkg, imports := range bl.Imports {
+		for _, p := range imports {
+			importers[p] = append(importers[p], pkg)
+		}
+	}
+	// depImports returns the imported packages provided by dep.
+	depImports := func(dep *internal.Requirement) []string {
+		var ps []string
+		for p := range importers {
+			if dep.ModulePath == stdlib.ModulePath {
+				if stdlib.Contains(p) {
+					ps = append(ps, p)
+				}
+			} else if r := requirementForImport(p, bl.Deps); r != nil && r.ModulePath == dep.ModulePath {
+				ps = append(ps, p)
+			}
+		}
+		return ps
+	}
+
+	deps := bl.Deps
+	if bl.GoVersion != "" {
+		deps = append([]*internal.Requirement{{ModulePath: stdlib.ModulePath, Version: bl.GoVersion}}, deps...)
+	}
+	d := &LocalVulnsDetails{}
+	for _, dep := range deps {
+		if dep.Version == "" {
+			// The module is replaced by a directory.
+			continue
+		}
+		d.NumChecked++
+		vulnPath := dep.ModulePath
+		if vulnPath == stdlib.ModulePath {
+			vulnPath = osv.GoStdModulePath
+		}
+		entries, err := vc.ByPackage(ctx, &vuln.PackageRequest{Module: vulnPath, Version: dep.Version})
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			lv := &LocalVuln{ID: e.ID, Summary: e.Summary, ModulePath: dep.ModulePath, Version: dep.Version}
+			if dep.ModulePath == stdlib.ModulePath {
+				if tag, err := stdlib.TagForVersion(dep.Version); err == nil {
+					lv.Version = tag
+				}
+			}
+			affected := map[string]bool{}
+			for _, a := range e.Affected {
+				if a.Module.Path != vulnPath || !osv.AffectsSemver(a.Ranges, dep.Version) {
+					continue
+				}
+				if fixed := osv.LatestFixedVersion(a.Ranges); fixed != "" {
+					lv.FixedVersion = fixedVersion(dep.ModulePath, fixed)
+				}
+				if len(a.EcosystemSpecific.Packages) == 0 {
+					// All of the packages of the module are affected.
+					for _, p := range depImports(dep) {
+						affected[p] = true
+					}
+				}
+				for _, p := range a.EcosystemSpecific.Packages {
+					if len(importers[p.Path]) > 0 {
+						affected[p.Path] = true
+					}
+				}
+			}
+			for p := range affected {
+				imp := &LocalVulnImport{PackagePath: p, Importers: append([]string(nil), importers[p]...)}
+				sort.Strings(imp.Importers)
+				lv.Imports = append(lv.Imports, imp)
+			}
+			sort.Slice(lv.Imports, func(i, j int) bool { return lv.Imports[i].PackagePath < lv.Imports[j].PackagePath })
+			d.Vulns = append(d.Vulns, lv)
+		}
+	}
+	sort.Slice(d.Vulns, func(i, j int) bool {
+		if d.Vulns[i].ModulePath != d.Vulns[j].ModulePath {
+			return d.Vulns[i].ModulePath < d.Vulns[j].ModulePath
+		}
+		return d.Vulns[i].ID < d.Vulns[j].ID
+	})
+	return d, nil
+}
+
+// fixedVersion returns the version of the module that fixes a vulnerability,
+// given the version from the vulnerability's range events, which has no
+// prefix.
+func fixedVersion(modulePath, v string) string {
+	if modulePath == stdlib.ModulePath {
+		return "go" + v
+	}
+	return "v" + v
+}
@@ -0,0 +1,125 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/osv"
+	"golang.org/x/pkgsite/internal/vuln"
+)
+
+func TestFetchLocalVulnsDetails(t *testing.T) {
+	semver := func(events ...osv.RangeEvent) []osv.Range {
+		return []osv.Range{{Type: osv.RangeTypeSemver, Events: events}}
+	}
+	entries := []*osv.Entry{
+		{
+			ID:      "GO-1999-0001",
+			Summary: "A problem in bad.com/bad.",
+			Affected: []osv.Affected{{
+				Module: osv.Module{Path: "bad.com"},
+				Ranges: semver(osv.RangeEvent{Introduced: "0"}, osv.RangeEvent{Fixed: "1.2.3"}),
+				EcosystemSpecific: osv.EcosystemSpecific{
+					Packages: []osv.Package{{Path: "bad.com/bad"}, {Path: "bad.com/other"}},
+				},
+			}},
+		},
+		{
+			// Fixed before the version in the build list.
+			ID: "GO-1999-0002",
+			Affected: []osv.Affected{{
+				Module: osv.Module{Path: "bad.com"},
+				Ranges: semver(osv.RangeEvent{Introduced: "0"}, osv.RangeEvent{Fixed: "1.0.0"}),
+			}},
+		},
+		{
+			// Affects all packages of the module, with no fix.
+			ID: "GO-1999-0003",
+			Affected: []osv.Affected{{
+				Module: osv.Module{Path: "unfixable.com"},
+				Ranges: semver(osv.RangeEvent{Introduced: "0"}),
+			}},
+		},
+		{
+			ID: "GO-1999-0004",
+			Affected: []osv.Affected{{
+				Module: osv.Module{Path: osv.GoStdModulePath},
+				Ranges: semver(osv.RangeEvent{Introduced: "0"}, osv.RangeEvent{Fixed: "1.21.1"}),
+				EcosystemSpecific: osv.EcosystemSpecific{
+					Packages: []osv.Package{{Path: "net/http"}},
+				},
+			}},
+		},
+		{
+			// Affects a module that is replaced by a directory.
+			ID: "GO-1999-0005",
+			Affected: []osv.Affected{{
+				Module: osv.Module{Path: "replaced.com"},
+				Ranges: semver(osv.RangeEvent{Introduced: "0"}),
+			}},
+		},
+	}
+	vc, err := vuln.NewInMemoryClient(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bl := &LocalBuildList{
+		ModulePath: "example.com/m",
+		GoVersion:  "v1.21.0",
+		Deps: []*internal.Requirement{
+			{ModulePath: "bad.com", Version: "v1.2.0"},
+			{ModulePath: "replaced.com"},
+			{ModulePath: "unfixable.com", Version: "v0.1.0"},
+			{ModulePath: "unfixable.com/nested", Version: "v0.1.0"},
+		},
+		Imports: map[string][]string{
+			"example.com/m":   {"bad.com/bad", "fmt", "net/http", "unfixable.com/a"},
+			"example.com/m/b": {"bad.com/bad", "unfixable.com/nested/c"},
+		},
+	}
+	got, err := fetchLocalVulnsDetails(context.Background(), bl, vc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &LocalVulnsDetails{
+		NumChecked: 4,
+		Vulns: []*LocalVuln{
+			{
+				ID:           "GO-1999-0001",
+				Summary:      "A problem in bad.com/bad.",
+				ModulePath:   "bad.com",
+				Version:      "v1.2.0",
+				FixedVersion: "v1.2.3",
+				Imports: []*LocalVulnImport{
+					{PackagePath: "bad.com/bad", Importers: []string{"example.com/m", "example.com/m/b"}},
+				},
+			},
+			{
+				ID:           "GO-1999-0004",
+				ModulePath:   "std",
+				Version:      "go1.21.0",
+				FixedVersion: "go1.21.1",
+				Imports: []*LocalVulnImport{
+					{PackagePath: "net/http", Importers: []string{"example.com/m"}},
+				},
+			},
+			{
+				ID:         "GO-1999-0003",
+				ModulePath: "unfixable.com",
+				Version:    "v0.1.0",
+				Imports: []*LocalVulnImport{
+					{PackagePath: "unfixable.com/a", Importers: []string{"example.com/m"}},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"golang.org/x/pkgsite/internal/log"
 	"golang.org/x/pkgsite/internal/middleware/stats"
 	"golang.org/x/pkgsite/internal/version"
+	"golang.org/x/pkgsite/internal/vuln"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
 )
@@ -102,6 +103,10 @@ type MainDetails struct {
 
 	// IsRedistributable is whether the unit is redistributable.
 	IsRedistributable bool
+
+	// LocalVulns holds the vulnerabilities of the dependencies of a locally
+	// hosted module. It is set only on the module's root page.
+	LocalVulns *LocalVulnsDetails
 }
 
 // File is a source file for a package.
@@ -111,7 +116,7 @@ type File struct {
 }
 
 func fetchMainDetails(ctx context.Context, ds internal.DataSource, um *internal.UnitMeta,
-	requestedVersion string, expandReadme bool, bc internal.BuildContext) (_ *MainDetails, err error) {
+	requestedVersion string, expandReadme bool, bc internal.BuildContext, vc *vuln.Client) (_ *MainDetails, err error) {
 	defer stats.Elapsed(ctx, "fetchMainDetails")()
 
 	unit, err := ds.GetUnit(ctx, um, internal.WithMain, bc)
@@ -164,6 +169,9 @@ func fetchMainDetails(ctx context.Context, ds internal.DataSource, um *internal.
 			return nil, err
 		}
 
+		docPkg.SymbolVulns = symbolVulnIDs(ctx, um, vc)
+		docPkg.Implementations = doc.Implementations
+		docPkg.ExampleResults = doc.ExampleResults
 		docParts, err = getHTML(ctx, unit, docPkg, unit.SymbolHistory, bc)
 		// If err  is ErrTooLarge, then docBody will have an appropriate message.
 		if err != nil && !errors.Is(err, dochtml.ErrTooLarge) {
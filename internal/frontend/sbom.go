@@ -0,0 +1,198 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/frontend/page"
+	"golang.org/x/pkgsite/internal/frontend/serrors"
+	"golang.org/x/pkgsite/internal/frontend/urlinfo"
+	"golang.org/x/pkgsite/internal/licenses"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/sbom"
+	"golang.org/x/pkgsite/internal/vuln"
+)
+
+// Formats of software bills of materials.
+const (
+	sbomSPDX      = "spdx"
+	sbomCycloneDX = "cyclonedx"
+)
+
+var sbomContentTypes = map[string]string{
+	sbomSPDX:      "application/spdx+json",
+	sbomCycloneDX: "application/vnd.cyclonedx+json",
+}
+
+// serveSBOM serves a software bill of materials for a module version, at
+// /sbom/<module>@<version>. The format query parameter selects an SPDX
+// document ("spdx", the default) or a CycloneDX one ("cyclonedx").
+func (s *Server) serveSBOM(w http.ResponseWriter, r *http.Request, ds internal.DataSource) (err error) {
+	defer derrors.Wrap(&err, "serveSBOM(%q)", r.URL.Path)
+	ctx := r.Context()
+
+	format := r.FormValue("format")
+	if format == "" {
+		format = sbomSPDX
+	}
+	if sbomContentTypes[format] == "" {
+		return &serrors.ServerError{
+			Status: http.StatusBadRequest,
+			Err:    fmt.Errorf("unknown SBOM format %q", format),
+			Epage: &page.ErrorPage{
+				MessageData: fmt.Sprintf(`Unsupported SBOM format %q. Use "spdx" or "cyclonedx".`, format),
+			},
+		}
+	}
+	info, err := urlinfo.ParseDetailsURLPath(strings.TrimPrefix(r.URL.Path, "/sbom"))
+	if err != nil {
+		var epage *page.ErrorPage
+		if uerr := new(urlinfo.UserError); errors.As(err, &uerr) {
+			epage = &page.ErrorPage{MessageData: uerr.UserMessage}
+		}
+		return &serrors.ServerError{Status: http.StatusBadRequest, Err: err, Epage: epage}
+	}
+	if !urlinfo.IsSupportedVersion(info.FullPath, info.RequestedVersion) {
+		return serrors.InvalidVersionError(info.FullPath, info.RequestedVersion)
+	}
+	um, err := ds.GetUnitMeta(ctx, info.FullPath, info.ModulePath, info.RequestedVersion)
+	if errors.Is(err, derrors.NotFound) {
+		return &serrors.ServerError{Status: http.StatusNotFound, Err: err}
+	}
+	if err != nil {
+		return err
+	}
+	if err := checkExcluded(ctx, ds, um.ModulePath, um.Version); err != nil {
+		return err
+	}
+	baseURL := requestBaseURL(r)
+	m, err := fetchSBOMModule(ctx, ds, um, s.vulnClient, baseURL)
+	if err != nil {
+		return err
+	}
+	var doc any
+	switch format {
+	case sbomSPDX:
+		doc = sbom.SPDX(m, baseURL+"/sbom/"+m.Path+"@"+m.Version, time.Now())
+	case sbomCycloneDX:
+		doc = sbom.CycloneDX(m, time.Now())
+	}
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", sbomContentTypes[format])
+	if _, err := w.Write(b); err != nil {
+		return fmt.Errorf("w.Write: %v", err)
+	}
+	return nil
+}
+
+// requestBaseURL returns the scheme and host of the server as seen by the
+// client of r, like "https://pkg.go.dev".
+func requestBaseURL(r *http.Request) string {
+	scheme := r.Header.Get("X-Forwarded-Proto")
+	if scheme == "" {
+		scheme = "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+	}
+	return scheme + "://" + r.Host
+}
+
+// fetchSBOMModule gathers what is known about the module of um for a bill of
+// materials: the licenses of the module and its packages, the modules it
+// requires and their licenses, and, if vc is not nil, the vulnerabilities of
+// all of them. Vulnerabilities link to their pages at baseURL.
+func fetchSBOMModule(ctx context.Context, ds internal.DataSource, um *internal.UnitMeta, vc *vuln.Client, baseURL string) (_ *sbom.Module, err error) {
+	defer derrors.Wrap(&err, "fetchSBOMModule(%q, %q)", um.ModulePath, um.Version)
+
+	report, err := fetchLicenseReportDetails(ctx, ds, um)
+	if err != nil {
+		return nil, err
+	}
+	m := reportToSBOM(report)
+
+	// Find the packages of the module, and the licenses that apply to each.
+	// The subdirectories of the module root hold both, so there is no need
+	// to read each package.
+	root := &internal.UnitMeta{Path: um.ModulePath, ModuleInfo: um.ModuleInfo}
+	u, err := ds.GetUnit(ctx, root, internal.WithMain, internal.BuildContext{})
+	if err != nil {
+		return nil, err
+	}
+	pkgs := map[string][]*licenses.Metadata{} // path to licenses
+	if u.IsPackage() {
+		pkgs[u.Path] = u.Licenses
+	}
+	for _, p := range u.Subdirectories {
+		if p.Name != "" {
+			pkgs[p.Path] = p.Licenses
+		}
+	}
+	for path, lics := range pkgs {
+		c := &sbom.Component{Path: path, Version: um.Version}
+		for _, l := range lics {
+			c.LicenseTypes = append(c.LicenseTypes, l.Types...)
+		}
+		sort.Strings(c.LicenseTypes)
+		m.Packages = append(m.Packages, c)
+	}
+	sort.Slice(m.Packages, func(i, j int) bool { return m.Packages[i].Path < m.Packages[j].Path })
+
+	if vc != nil {
+		for _, rm := range report.Modules {
+			if rm.Problem == reportProblemNotFound {
+				continue
+			}
+			for _, v := range vuln.VulnsForPackage(ctx, rm.ModulePath, rm.Version, "", vc) {
+				if v.ID == "" {
+					// VulnsForPackage describes an error as a Vuln without an ID.
+					log.Errorf(ctx, "SBOM for %s@%s: %s", um.ModulePath, um.Version, v.Details)
+					continue
+				}
+				m.Vulns = append(m.Vulns, &sbom.Vuln{
+					ID:         v.ID,
+					Summary:    v.Details,
+					URL:        baseURL + "/vuln/" + v.ID,
+					ModulePath: rm.ModulePath,
+					Version:    rm.Version,
+				})
+			}
+		}
+	}
+	return m, nil
+}
+
+// reportToSBOM returns the modules of the license report d for a bill of
+// materials. The problems found with their licenses are noted as comments.
+func reportToSBOM(d *LicenseReportDetails) *sbom.Module {
+	m := &sbom.Module{}
+	for _, rm := range d.Modules {
+		c := sbom.Component{
+			Path:         rm.ModulePath,
+			Version:      rm.Version,
+			LicenseTypes: rm.Types,
+			Comment:      rm.Problem,
+		}
+		if rm.Main {
+			m.Component = c
+		} else {
+			m.Dependencies = append(m.Dependencies, &sbom.Dependency{Component: c, Indirect: rm.Indirect})
+		}
+	}
+	return m
+}
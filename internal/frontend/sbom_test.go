@@ -0,0 +1,113 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/licenses"
+	"golang.org/x/pkgsite/internal/osv"
+	"golang.org/x/pkgsite/internal/sbom"
+	"golang.org/x/pkgsite/internal/testing/fakedatasource"
+	"golang.org/x/pkgsite/internal/testing/sample"
+	"golang.org/x/pkgsite/internal/vuln"
+)
+
+// unitCounter is a DataSource that counts calls to GetUnit.
+type unitCounter struct {
+	internal.DataSource
+	n int
+}
+
+func (c *unitCounter) GetUnit(ctx context.Context, um *internal.UnitMeta, fields internal.FieldSet, bc internal.BuildContext) (*internal.Unit, error) {
+	c.n++
+	return c.DataSource.GetUnit(ctx, um, fields, bc)
+}
+
+func TestFetchSBOMModule(t *testing.T) {
+	ctx := context.Background()
+	fds := fakedatasource.New()
+
+	m := sample.Module("example.com/main", "v1.0.0", "", "a", "a/b")
+	// The license of a/b applies to it in addition to the module's.
+	mit := sample.LicenseMetadata()[0]
+	bsd := &licenses.Metadata{Types: []string{"BSD-3-Clause"}, FilePath: "a/b/LICENSE"}
+	m.Licenses = append(m.Licenses, &licenses.License{Metadata: bsd})
+	for _, u := range m.Units {
+		if u.Path == "example.com/main/a/b" {
+			u.Licenses = []*licenses.Metadata{mit, bsd}
+		}
+	}
+	m.Requirements = []*internal.Requirement{
+		{ModulePath: "example.com/dep", Version: "v1.2.0", Indirect: true},
+	}
+	fds.MustInsertModule(ctx, m)
+	fds.MustInsertModule(ctx, sample.Module("example.com/dep", "v1.2.0"))
+
+	vc, err := vuln.NewInMemoryClient([]*osv.Entry{{
+		ID:       "GO-1990-0001",
+		Summary:  "A problem in dep.",
+		Affected: []osv.Affected{{Module: osv.Module{Path: "example.com/dep"}}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	um := sample.UnitMeta("example.com/main/a", "example.com/main", "v1.0.0", "a", true)
+	ds := &unitCounter{DataSource: fds}
+	got, err := fetchSBOMModule(ctx, ds, um, vc, "https://pkg.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each module of the license report is read once, and the packages are
+	// found in the module root instead of being read one by one.
+	if want := 3; ds.n != want {
+		t.Errorf("got %d calls to GetUnit, want %d", ds.n, want)
+	}
+	want := &sbom.Module{
+		Component: sbom.Component{Path: "example.com/main", Version: "v1.0.0", LicenseTypes: []string{"MIT"}},
+		Packages: []*sbom.Component{
+			{Path: "example.com/main", Version: "v1.0.0", LicenseTypes: []string{"MIT"}},
+			{Path: "example.com/main/a", Version: "v1.0.0", LicenseTypes: []string{"MIT"}},
+			{Path: "example.com/main/a/b", Version: "v1.0.0", LicenseTypes: []string{"BSD-3-Clause", "MIT"}},
+		},
+		Dependencies: []*sbom.Dependency{{
+			Component: sbom.Component{Path: "example.com/dep", Version: "v1.2.0", LicenseTypes: []string{"MIT"}},
+			Indirect:  true,
+		}},
+		Vulns: []*sbom.Vuln{{
+			ID:         "GO-1990-0001",
+			Summary:    "A problem in dep.",
+			URL:        "https://pkg.example.com/vuln/GO-1990-0001",
+			ModulePath: "example.com/dep",
+			Version:    "v1.2.0",
+		}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestServeSBOMErrors(t *testing.T) {
+	_, handler := newTestServer(t, nil)
+	for _, test := range []struct {
+		url  string
+		want int
+	}{
+		{"/sbom/example.com/missing@v1.0.0", http.StatusNotFound},
+		{"/sbom/example.com/missing@v1.0.0?format=xml", http.StatusBadRequest},
+		{"/sbom/example.com/missing@v1.x", http.StatusBadRequest},
+	} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", test.url, nil))
+		if w.Code != test.want {
+			t.Errorf("%s: got status %d, want %d", test.url, w.Code, test.want)
+		}
+	}
+}
@@ -325,14 +325,15 @@ func newSearchResult(r *internal.SearchResult, searchSymbols bool, pr *message.P
 		sr.SymbolSynopsis = symbolSynopsis(r)
 		sr.SymbolGOOS = r.SymbolGOOS
 		sr.SymbolGOARCH = r.SymbolGOARCH
-		// If the GOOS is "all" or "linux", it doesn't need to be
-		// specified as a query param. "linux" is the default GOOS when a
-		// package has multiple build contexts, since it is first item
-		// listed in internal.BuildContexts.
-		if r.SymbolGOOS == internal.All || r.SymbolGOOS == "linux" {
+		// The build context doesn't need to be specified as a query param
+		// if it is all/all or the first item listed in
+		// internal.BuildContexts, which is the default when a package has
+		// multiple build contexts.
+		bc := internal.BuildContext{GOOS: r.SymbolGOOS, GOARCH: r.SymbolGOARCH}
+		if q := bc.URLQuery(); q == "" {
 			sr.SymbolLink = fmt.Sprintf("/%s#%s", r.PackagePath, r.SymbolName)
 		} else {
-			sr.SymbolLink = fmt.Sprintf("/%s?GOOS=%s#%s", r.PackagePath, r.SymbolGOOS, r.SymbolName)
+			sr.SymbolLink = fmt.Sprintf("/%s?%s#%s", r.PackagePath, q, r.SymbolName)
 		}
 	}
 	return sr
@@ -15,6 +15,7 @@ import (
 	"net/http"
 	hpprof "net/http/pprof"
 	"os"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -48,8 +49,10 @@ type Server struct {
 	staticFS           fs.FS
 	thirdPartyFS       fs.FS
 	devMode            bool
-	localMode          bool          // running locally (i.e. ./cmd/pkgsite)
-	localModules       []LocalModule // locally hosted modules; empty in production
+	localMode          bool                       // running locally (i.e. ./cmd/pkgsite)
+	localModules       []LocalModule              // locally hosted modules; empty in production
+	localWorkspaces    []LocalWorkspace           // locally hosted workspaces; empty in production
+	localBuildLists    map[string]*LocalBuildList // build lists of locally hosted modules, by module path
 	errorPage          []byte
 	appVersionLabel    string
 	googleTagManagerID string
@@ -91,6 +94,8 @@ type ServerConfig struct {
 	DevMode           bool
 	LocalMode         bool
 	LocalModules      []LocalModule
+	LocalWorkspaces   []LocalWorkspace
+	LocalBuildLists   []*LocalBuildList
 	Reporter          derrors.Reporter
 	VulndbClient      *vuln.Client
 	DepsDevHTTPClient *http.Client
@@ -114,6 +119,8 @@ func NewServer(scfg ServerConfig) (_ *Server, err error) {
 		devMode:           scfg.DevMode,
 		localMode:         scfg.LocalMode,
 		localModules:      scfg.LocalModules,
+		localWorkspaces:   scfg.LocalWorkspaces,
+		localBuildLists:   map[string]*LocalBuildList{},
 		templates:         ts,
 		reporter:          scfg.Reporter,
 		fileMux:           http.NewServeMux(),
@@ -123,6 +130,9 @@ func NewServer(scfg ServerConfig) (_ *Server, err error) {
 	if s.depsDevHTTPClient == nil {
 		s.depsDevHTTPClient = http.DefaultClient
 	}
+	for _, bl := range scfg.LocalBuildLists {
+		s.localBuildLists[bl.ModulePath] = bl
+	}
 	if scfg.Config != nil {
 		s.appVersionLabel = scfg.Config.AppVersionLabel()
 		s.googleTagManagerID = scfg.Config.GoogleTagManagerID
@@ -150,6 +160,11 @@ type Cacher interface {
 	Cache(name string, expirer func(r *http.Request) time.Duration, authValues []string) func(http.Handler) http.Handler
 }
 
+// A cacheClearer is a Cacher that can delete all the responses it has cached.
+type cacheClearer interface {
+	Clear(ctx context.Context) error
+}
+
 // Install registers server routes using the given handler registration func.
 // authValues is the set of values that can be set on authHeader to bypass the
 // cache.
@@ -171,6 +186,9 @@ func (s *Server) Install(handle func(string, http.Handler), cacher Cacher, authV
 		detailHandler = cacher.Cache("details", detailsTTL, authValues)(detailHandler)
 		searchHandler = cacher.Cache("search", searchTTL, authValues)(searchHandler)
 		vulnHandler = cacher.Cache("vuln", vulnTTL, authValues)(vulnHandler)
+		if cc, ok := cacher.(cacheClearer); ok {
+			handle("/clear-cache", s.clearCacheHandler(cc, authValues))
+		}
 	}
 	// Each AppEngine instance is created in response to a start request, which
 	// is an empty HTTP GET request to /_ah/start when scaling is set to manual
@@ -210,7 +228,17 @@ func (s *Server) Install(handle func(string, http.Handler), cacher Cacher, authV
 	}))
 	handle("/golang.org/x", s.staticPageHandler("subrepo", "Sub-repositories"))
 	handle("/files/", http.StripPrefix("/files", s.fileMux))
-	handle("/vuln/", vulnHandler)
+	// Vuln feeds aren't cached: they link to the host they are requested from.
+	vulnFeedHandler := s.errorHandler(s.serveVulnExport)
+	handle("/vuln/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, name, _ := parseVulnExportPath(r.URL.Path); name == vulnFeedName {
+			vulnFeedHandler.ServeHTTP(w, r)
+			return
+		}
+		vulnHandler.ServeHTTP(w, r)
+	}))
+	// SBOMs aren't cached: they link to the host they are requested from.
+	handle("/sbom/", s.errorHandler(s.serveSBOM))
 	handle("/opensearch.xml", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		serveFileFS(w, r, s.staticFS, "shared/opensearch.xml")
 	}))
@@ -226,12 +254,32 @@ func (s *Server) Install(handle func(string, http.Handler), cacher Cacher, authV
 		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(`User-agent: *
 Disallow: /search?*
 Disallow: /fetch/*
+Disallow: /sbom/*
 Sitemap: https://pkg.go.dev/sitemap/index.xml
 `))
 	}))
 	s.installDebugHandlers(handle)
 }
 
+// clearCacheHandler returns a handler that clears the cache of cc. Unless the
+// server is running locally, the request must have a BypassCacheAuthHeader
+// with one of authValues.
+func (s *Server) clearCacheHandler(cc cacheClearer, authValues []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get(config.BypassCacheAuthHeader)
+		if !s.localMode && (auth == "" || !slices.Contains(authValues, auth)) {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		if err := cc.Clear(r.Context()); err != nil {
+			log.Errorf(r.Context(), "clearing cache: %v", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, "Cache cleared.")
+	})
+}
+
 // installDebugHandlers installs handlers for debugging. Most of the handlers
 // are provided by the net/http/pprof package. Although that package installs
 // them on the default ServeMux in its init function, we must install them on
@@ -306,8 +354,25 @@ func (s *Server) installDebugHandlers(handle func(string, http.Handler)) {
 }
 
 // InstallFS adds path under the /files handler, serving the files in fsys.
-func (s *Server) InstallFS(path string, fsys fs.FS) {
-	s.fileMux.Handle(path+"/", http.StripPrefix(path, http.FileServer(http.FS(fsys))))
+// Go and Go+ source files are shown in a source view; see serveSource. If
+// importPath is not nil, it reports the import path of the package in a
+// directory of fsys, so that the source view can link to its documentation.
+func (s *Server) InstallFS(path string, fsys fs.FS, importPath func(dir string) string) {
+	files := http.FileServer(http.FS(fsys))
+	s.fileMux.Handle(path+"/", http.StripPrefix(path, s.errorHandler(func(w http.ResponseWriter, r *http.Request, ds internal.DataSource) error {
+		if isSourceFile(r.URL.Path) && r.FormValue("raw") == "" {
+			name := strings.TrimPrefix(r.URL.Path, "/")
+			fi, err := fs.Stat(fsys, name)
+			if err != nil {
+				return fsError(err)
+			}
+			if !fi.IsDir() {
+				return s.serveSource(w, r, ds, fsys, name, importPath)
+			}
+		}
+		files.ServeHTTP(w, r)
+		return nil
+	})))
 }
 
 const (
@@ -356,7 +421,7 @@ func detailsTTLForPath(ctx context.Context, urlPath, tab string) time.Duration {
 	if info.RequestedVersion == version.Latest {
 		return shortTTL
 	}
-	if tab == "importedby" || tab == "versions" {
+	if tab == "importedby" || tab == "versions" || tab == "deprecated" || tab == "licensereport" {
 		return defaultTTL
 	}
 	return longTTL
@@ -435,15 +500,23 @@ type LicensePolicyPage struct {
 	pagepkg.BasePage
 	LicenseFileNames []string
 	LicenseTypes     []licenses.AcceptedLicenseInfo
+	// ModulePrefixes are the module paths whose modules are displayed
+	// regardless of their licenses, according to the active license policy.
+	ModulePrefixes []string
 }
 
 func (s *Server) licensePolicyHandler() http.HandlerFunc {
 	lics := licenses.AcceptedLicenses()
+	var prefixes []string
+	if p := licenses.ActivePolicy(); p != nil {
+		prefixes = p.ModulePrefixes
+	}
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		page := LicensePolicyPage{
 			BasePage:         s.newBasePage(r, "License Policy"),
 			LicenseFileNames: licenses.FileNames,
 			LicenseTypes:     lics,
+			ModulePrefixes:   prefixes,
 		}
 		s.servePage(r.Context(), w, "license-policy", page)
 	})
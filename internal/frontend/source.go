@@ -0,0 +1,154 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/google/safehtml"
+	"github.com/google/safehtml/uncheckedconversions"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	pagepkg "golang.org/x/pkgsite/internal/frontend/page"
+	"golang.org/x/pkgsite/internal/frontend/serrors"
+	"golang.org/x/pkgsite/internal/godoc"
+	"golang.org/x/pkgsite/internal/godoc/dochtml"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/middleware/stats"
+	"golang.org/x/pkgsite/internal/version"
+)
+
+// SourcePage contains the data for the source view of a file.
+type SourcePage struct {
+	pagepkg.BasePage
+
+	// FileName is the path of the file under its /files directory.
+	FileName string
+
+	// ImportPath is the import path of the package that the file belongs
+	// to, or empty if it is not known.
+	ImportPath string
+
+	Lines []*SourceLine
+}
+
+// A SourceLine is a line of a file in the source view.
+type SourceLine struct {
+	ID     safehtml.Identifier // e.g. "L12"
+	Number int
+	Code   safehtml.HTML
+}
+
+// isSourceFile reports whether the file at urlPath is shown in the source
+// view.
+func isSourceFile(urlPath string) bool {
+	return strings.HasSuffix(urlPath, ".go") || strings.HasSuffix(urlPath, ".gop")
+}
+
+// serveSource serves the source view of the file at name in fsys. Its lines
+// can be linked to with fragments like "#L12", and each identifier links to
+// its definition; see dochtml.RenderSource. Names declared in other packages
+// are resolved with the links determined when the package was fetched, if ds
+// has its documentation.
+func (s *Server) serveSource(w http.ResponseWriter, r *http.Request, ds internal.DataSource, fsys fs.FS, name string, importPath func(string) string) (err error) {
+	defer derrors.Wrap(&err, "serveSource(%q)", name)
+	ctx := r.Context()
+	defer stats.Elapsed(ctx, "serveSource")()
+
+	contents, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return fsError(err)
+	}
+	file := &dochtml.SourceFile{Name: path.Base(name), Contents: contents}
+	dir := path.Dir(name)
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fsError(err)
+	}
+	var pkgFiles []*dochtml.SourceFile
+	for _, e := range entries {
+		if !e.Type().IsRegular() || !strings.HasSuffix(e.Name(), ".go") {
+			continue
+		}
+		contents, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return fsError(err)
+		}
+		pkgFiles = append(pkgFiles, &dochtml.SourceFile{Name: e.Name(), Contents: contents})
+	}
+	var (
+		ip         string
+		identLinks map[int]string
+	)
+	if importPath != nil {
+		ip = importPath(dir)
+	}
+	if ip != "" {
+		identLinks = sourceIdentLinks(ctx, ds, ip, file.Name)
+	}
+
+	page := &SourcePage{
+		BasePage:   s.newBasePage(r, file.Name),
+		FileName:   name,
+		ImportPath: ip,
+	}
+	for i, code := range dochtml.RenderSource(file, pkgFiles, ip, identLinks) {
+		page.Lines = append(page.Lines, &SourceLine{
+			// IdentifierFromConstantPrefix would add a hyphen, but line
+			// fragments are like "#L12" to match source.Info.LineURL.
+			ID:     uncheckedconversions.IdentifierFromStringKnownToSatisfyTypeContract("L" + strconv.Itoa(i+1)),
+			Number: i + 1,
+			Code:   code,
+		})
+	}
+	s.servePage(ctx, w, "source", page)
+	return nil
+}
+
+// sourceIdentLinks returns the links of the identifiers in the file with the
+// given name in the latest version of the package at importPath, keyed by
+// their offsets in the file, as determined when the package was fetched; see
+// godoc.Package.IdentLinks. It returns nil if ds doesn't have the
+// documentation of the package. The file may have changed since, so the
+// links are only hints.
+func sourceIdentLinks(ctx context.Context, ds internal.DataSource, importPath, fileName string) map[int]string {
+	um, err := ds.GetUnitMeta(ctx, importPath, internal.UnknownModulePath, version.Latest)
+	if err != nil {
+		log.Debugf(ctx, "sourceIdentLinks(%q): %v", importPath, err)
+		return nil
+	}
+	u, err := ds.GetUnit(ctx, um, internal.WithMain, internal.BuildContext{})
+	if err != nil || len(u.Documentation) == 0 {
+		log.Debugf(ctx, "sourceIdentLinks(%q): no documentation: %v", importPath, err)
+		return nil
+	}
+	docPkg, err := godoc.DecodePackage(u.Documentation[0].Source)
+	if err != nil {
+		log.Debugf(ctx, "sourceIdentLinks(%q): %v", importPath, err)
+		return nil
+	}
+	links := map[int]string{}
+	for pos, link := range docPkg.IdentLinks {
+		if p := docPkg.Fset.Position(pos); path.Base(p.Filename) == fileName {
+			links[p.Offset] = link
+		}
+	}
+	return links
+}
+
+// fsError returns err, an error from reading a file system, as a 404 if the
+// file doesn't exist or its path is invalid.
+func fsError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrInvalid) {
+		return &serrors.ServerError{Status: http.StatusNotFound, Err: err}
+	}
+	return err
+}
@@ -41,6 +41,9 @@ const (
 	tabImports    = "imports"
 	tabImportedBy = "importedby"
 	tabLicenses   = "licenses"
+	tabDeprecated = "deprecated"
+
+	tabLicenseReport = "licensereport"
 )
 
 var (
@@ -65,6 +68,14 @@ var (
 			Name:         tabLicenses,
 			TemplateName: "unit/licenses",
 		},
+		{
+			Name:         tabDeprecated,
+			TemplateName: "unit/deprecated",
+		},
+		{
+			Name:         tabLicenseReport,
+			TemplateName: "unit/licensereport",
+		},
 	}
 	unitTabLookup = make(map[string]TabSettings, len(unitTabs))
 )
@@ -84,15 +95,19 @@ func fetchDetailsForUnit(ctx context.Context, r *http.Request, tab string, ds in
 	switch tab {
 	case tabMain:
 		_, expandReadme := r.URL.Query()["readme"]
-		return fetchMainDetails(ctx, ds, um, requestedVersion, expandReadme, bc)
+		return fetchMainDetails(ctx, ds, um, requestedVersion, expandReadme, bc, vc)
 	case tabVersions:
 		return versions.FetchVersionsDetails(ctx, ds, um, vc)
 	case tabImports:
-		return fetchImportsDetails(ctx, ds, um.Path, um.ModulePath, um.Version)
+		return fetchImportsDetails(ctx, ds, um.Path, um.ModulePath, um.Version, vc)
 	case tabImportedBy:
 		return fetchImportedByDetails(ctx, ds, um.Path, um.ModulePath)
 	case tabLicenses:
 		return fetchLicensesDetails(ctx, ds, um)
+	case tabDeprecated:
+		return fetchDeprecatedDetails(ctx, ds, um)
+	case tabLicenseReport:
+		return fetchLicenseReportDetails(ctx, ds, um)
 	}
 	return nil, fmt.Errorf("BUG: unable to fetch details: unknown tab %q", tab)
 }
@@ -58,7 +58,10 @@ func ParsePageTemplates(fsys template.TrustedFS) (map[string]*template.Template,
 		{"license-policy"},
 		{"search"},
 		{"search-help"},
+		{"source"},
 		{"subrepo"},
+		{"unit/deprecated", "unit"},
+		{"unit/licensereport", "unit"},
 		{"unit/importedby", "unit"},
 		{"unit/imports", "unit"},
 		{"unit/licenses", "unit"},
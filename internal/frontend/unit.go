@@ -135,6 +135,12 @@ func (s *Server) serveUnitPage(ctx context.Context, w http.ResponseWriter, r *ht
 		}
 		return s.fetchServer.ServePathNotFoundPage(w, r, db, info.FullPath, info.ModulePath, info.RequestedVersion)
 	}
+	if urlPath := gopRedirectURL(r.URL.Path, um, info.RequestedVersion); urlPath != "" {
+		url := *r.URL
+		url.Path = urlPath
+		http.Redirect(w, r, url.String(), http.StatusMovedPermanently)
+		return nil
+	}
 
 	makeDepsDevURL := depsDevURLGenerator(ctx, s.depsDevHTTPClient, um)
 
@@ -144,6 +150,15 @@ func (s *Server) serveUnitPage(ctx context.Context, w http.ResponseWriter, r *ht
 	// It's also okay to provide just one (e.g. GOOS=windows), which will select
 	// the first doc with that value, ignoring the other one.
 	bc := internal.BuildContext{GOOS: r.FormValue("GOOS"), GOARCH: r.FormValue("GOARCH")}
+	// The format query parameter requests the documentation alone, as
+	// Markdown or plain text rather than HTML, or on the license report tab,
+	// the report as CSV or SPDX.
+	if f := r.FormValue("format"); f != "" {
+		if tab == tabLicenseReport {
+			return serveLicenseReport(ctx, w, ds, um, f)
+		}
+		return serveDocText(ctx, w, ds, um, f, bc)
+	}
 	d, err := fetchDetailsForUnit(ctx, r, tab, ds, um, info.RequestedVersion, bc, s.vulnClient)
 	if err != nil {
 		return err
@@ -241,6 +256,7 @@ func (s *Server) serveUnitPage(ctx context.Context, w http.ResponseWriter, r *ht
 	main, ok := d.(*MainDetails)
 	if ok {
 		page.MetaDescription = metaDescription(main.DocSynopsis)
+		main.LocalVulns = s.localVulnsDetails(ctx, um)
 	}
 
 	// Get vulnerability information.
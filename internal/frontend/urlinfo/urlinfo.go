@@ -51,7 +51,11 @@ func (e *UserError) Unwrap() error {
 func ExtractURLPathInfo(urlPath string) (_ *URLPathInfo, err error) {
 	defer derrors.Wrap(&err, "ExtractURLPathInfo(%q)", urlPath)
 
-	if m, _, _ := strings.Cut(strings.TrimPrefix(urlPath, "/"), "@"); stdlib.Contains(m) {
+	m, _, _ := strings.Cut(strings.TrimPrefix(urlPath, "/"), "@")
+	if stdlib.IsGopShortPath(strings.TrimSuffix(m, "/")) {
+		return parseGopURLPath(urlPath)
+	}
+	if stdlib.Contains(m) {
 		return parseStdlibURLPath(urlPath)
 	}
 	return ParseDetailsURLPath(urlPath)
@@ -168,6 +172,44 @@ func parseStdlibURLPath(urlPath string) (_ *URLPathInfo, err error) {
 	return info, nil
 }
 
+// parseGopURLPath parses a URL path that refers to a package of the Go+
+// module by its short path, like /gop/builtin@v1.2.6. The resulting full path
+// is the package's import path. The module path is unknown, because the path
+// may be in a nested module of the Go+ repo.
+func parseGopURLPath(urlPath string) (_ *URLPathInfo, err error) {
+	defer derrors.Wrap(&err, "parseGopURLPath(%q)", urlPath)
+
+	// This splits urlPath into either:
+	//   /<short-path>@<tag> or /<short-path>
+	shortPath, tag, found := strings.Cut(urlPath, "@")
+	shortPath = strings.TrimSuffix(strings.TrimPrefix(shortPath, "/"), "/")
+	fullPath := stdlib.GopPathForShortPath(shortPath)
+	if !IsValidPath(fullPath) {
+		return nil, &UserError{
+			err:         fmt.Errorf("IsValidPath(%q) is false", fullPath),
+			UserMessage: fmt.Sprintf("%q is not a valid import path", shortPath),
+		}
+	}
+
+	info := &URLPathInfo{
+		FullPath:         fullPath,
+		ModulePath:       internal.UnknownModulePath,
+		RequestedVersion: version.Latest,
+	}
+	if !found {
+		return info, nil
+	}
+	tag = strings.TrimSuffix(tag, "/")
+	info.RequestedVersion = stdlib.GopVersionForTag(tag)
+	if info.RequestedVersion == "" || info.RequestedVersion == version.Latest {
+		return nil, &UserError{
+			err:         fmt.Errorf("invalid Go+ tag for url: %q", urlPath),
+			UserMessage: fmt.Sprintf("%q is not a valid tag for Go+", tag),
+		}
+	}
+	return info, nil
+}
+
 // IsValidPath reports whether a requested path could be a valid unit.
 func IsValidPath(fullPath string) bool {
 	if err := module.CheckImportPath(fullPath); err != nil {
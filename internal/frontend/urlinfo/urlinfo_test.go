@@ -91,6 +91,24 @@ func TestExtractURLPathInfo(t *testing.T) {
 				RequestedVersion: "v1.14.0",
 			},
 		},
+		{
+			name: "Go+ module",
+			url:  "/gop",
+			want: &URLPathInfo{
+				ModulePath:       internal.UnknownModulePath,
+				FullPath:         stdlib.GopModulePath,
+				RequestedVersion: version.Latest,
+			},
+		},
+		{
+			name: "Go+ package at version",
+			url:  "/gop/builtin@1.2.6",
+			want: &URLPathInfo{
+				ModulePath:       internal.UnknownModulePath,
+				FullPath:         stdlib.GopModulePath + "/builtin",
+				RequestedVersion: "v1.2.6",
+			},
+		},
 	} {
 		t.Run(test.name, func(t *testing.T) {
 			got, err := ExtractURLPathInfo(test.url)
@@ -134,6 +152,11 @@ func TestExtractURLPathInfo_Errors(t *testing.T) {
 			url:     "/net@go1.14/http",
 			wantErr: true,
 		},
+		{
+			name:    "Go+ with Go tag",
+			url:     "/gop/builtin@go1.14",
+			wantErr: true,
+		},
 	}
 	for _, test := range testCases {
 		t.Run(test.name, func(t *testing.T) {
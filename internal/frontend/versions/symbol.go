@@ -326,18 +326,8 @@ func addSymbol(s *Symbol, v string, sh *internal.SymbolHistory, builds []string)
 		return
 	}
 	for _, b := range builds {
-		goos, _, _ := strings.Cut(b, "/")
-		var build internal.BuildContext
-		switch goos {
-		case "linux":
-			build = internal.BuildContextLinux
-		case "darwin":
-			build = internal.BuildContextDarwin
-		case "windows":
-			build = internal.BuildContextWindows
-		case "js":
-			build = internal.BuildContextJS
-		}
+		// Builds are formatted by symbolsForVersion, so they always parse.
+		build, _ := internal.ParseBuildContext(b)
 		sh.AddSymbol(sm, v, build)
 	}
 }
@@ -87,19 +87,26 @@ type VersionSummary struct {
 	Vulns               []vuln.Vuln
 }
 
+// A versionLister is a data source that can list the versions of a unit.
+// Besides the postgres database, the data source of local mode is one.
+type versionLister interface {
+	GetVersionsForPath(ctx context.Context, path string) ([]*internal.ModuleInfo, error)
+}
+
 func FetchVersionsDetails(ctx context.Context, ds internal.DataSource, um *internal.UnitMeta, vc *vuln.Client) (*VersionsDetails, error) {
-	db, ok := ds.(internal.PostgresDB)
+	vl, ok := ds.(versionLister)
 	if !ok {
-		// The proxydatasource does not support the imported by page.
+		// The proxydatasource does not support the versions page.
 		return nil, serrors.DatasourceNotSupportedError()
 	}
-	versions, err := db.GetVersionsForPath(ctx, um.Path)
+	versions, err := vl.GetVersionsForPath(ctx, um.Path)
 	if err != nil {
 		return nil, err
 	}
 
 	sh := internal.NewSymbolHistory()
-	if !um.IsCommand() {
+	// Only the database records the history of symbols.
+	if db, ok := ds.(internal.PostgresDB); ok && !um.IsCommand() {
 		sh, err = db.GetSymbolHistory(ctx, um.Path, um.ModulePath)
 		if err != nil {
 			return nil, err
@@ -434,11 +441,15 @@ func absoluteTime(date time.Time) string {
 // version. If requestedVersion is "latest", then the resulting path has no
 // version; otherwise, it has requestedVersion.
 func ConstructUnitURL(fullPath, modulePath, requestedVersion string) string {
+	if modulePath == stdlib.GopModulePath {
+		// The Go+ module is served at short paths, like the stdlib.
+		fullPath = stdlib.GopShortPath(fullPath)
+	}
 	if requestedVersion == version.Latest {
 		return "/" + fullPath
 	}
 	v := LinkVersion(modulePath, requestedVersion, requestedVersion)
-	if fullPath == modulePath || modulePath == stdlib.ModulePath {
+	if fullPath == modulePath || modulePath == stdlib.ModulePath || modulePath == stdlib.GopModulePath {
 		return fmt.Sprintf("/%s@%s", fullPath, v)
 	}
 	return fmt.Sprintf("/%s@%s/%s", modulePath, v, strings.TrimPrefix(fullPath, modulePath+"/"))
@@ -460,3 +460,22 @@ func TestLinkVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestConstructUnitURL(t *testing.T) {
+	for _, test := range []struct {
+		fullPath, modulePath, version string
+		want                          string
+	}{
+		{"example.com/a/b", "example.com/a", version.Latest, "/example.com/a/b"},
+		{"example.com/a/b", "example.com/a", "v1.0.0", "/example.com/a@v1.0.0/b"},
+		{"example.com/a", "example.com/a", "v1.0.0", "/example.com/a@v1.0.0"},
+		{"net/http", stdlib.ModulePath, "v1.16.0", "/net/http@go1.16"},
+		{stdlib.GopModulePath, stdlib.GopModulePath, version.Latest, "/gop"},
+		{stdlib.GopModulePath + "/builtin", stdlib.GopModulePath, version.Latest, "/gop/builtin"},
+		{stdlib.GopModulePath + "/builtin", stdlib.GopModulePath, "v1.2.6", "/gop/builtin@v1.2.6"},
+	} {
+		if got := ConstructUnitURL(test.fullPath, test.modulePath, test.version); got != test.want {
+			t.Errorf("ConstructUnitURL(%q, %q, %q) = %q; want %q", test.fullPath, test.modulePath, test.version, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/frontend/serrors"
+	"golang.org/x/pkgsite/internal/osv"
+	"golang.org/x/pkgsite/internal/vuln"
+)
+
+// Names of the files that export vuln entries, at /vuln/<name> for all
+// entries and at /vuln/<module prefix>/<name> for the entries affecting
+// modules or packages with the prefix.
+const (
+	vulnFeedName   = "feed.atom"
+	vulnExportName = "osv.json"
+)
+
+// vulnFeedSize is the number of entries in the feed of all vuln entries.
+// Feeds for a module prefix have all of the entries for the prefix.
+const vulnFeedSize = 50
+
+// serveVulnExport serves the entries of the vulnerability database affecting
+// a module prefix, as an Atom feed at /vuln/<module prefix>/feed.atom or as a
+// JSON array of OSV entries at /vuln/<module prefix>/osv.json. Without a
+// prefix, the feed has the most recent entries, and the export has all of
+// them.
+func (s *Server) serveVulnExport(w http.ResponseWriter, r *http.Request, _ internal.DataSource) (err error) {
+	defer derrors.Wrap(&err, "serveVulnExport(%q)", r.URL.Path)
+
+	if s.vulnClient == nil {
+		return serrors.DatasourceNotSupportedError()
+	}
+	ctx := r.Context()
+	prefix, name, ok := parseVulnExportPath(r.URL.Path)
+	if !ok {
+		return &serrors.ServerError{Status: http.StatusNotFound}
+	}
+	n := -1
+	if prefix == "" && name == vulnFeedName {
+		n = vulnFeedSize
+	}
+	entries, err := vulnEntries(ctx, s.vulnClient, prefix, n)
+	if err != nil {
+		return &serrors.ServerError{Status: derrors.ToStatus(err), Err: err}
+	}
+
+	var (
+		b           []byte
+		contentType string
+	)
+	switch name {
+	case vulnFeedName:
+		feed := newVulnFeed(requestBaseURL(r), prefix, entries, time.Now())
+		b, err = xml.MarshalIndent(feed, "", "  ")
+		if err != nil {
+			return err
+		}
+		b = append([]byte(xml.Header), b...)
+		contentType = "application/atom+xml; charset=utf-8"
+	case vulnExportName:
+		if entries == nil {
+			entries = []*osv.Entry{}
+		}
+		b, err = json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		contentType = "application/json"
+	}
+	w.Header().Set("Content-Type", contentType)
+	if _, err := w.Write(b); err != nil {
+		return fmt.Errorf("w.Write: %v", err)
+	}
+	return nil
+}
+
+// parseVulnExportPath parses the URL path of a vuln export into its module
+// prefix, which may be empty, and its name. It reports whether the path is
+// that of an export.
+func parseVulnExportPath(urlPath string) (prefix, name string, ok bool) {
+	p := strings.TrimPrefix(urlPath, "/vuln/")
+	if i := strings.LastIndex(p, "/"); i >= 0 {
+		prefix, name = p[:i], p[i+1:]
+	} else {
+		name = p
+	}
+	if name != vulnFeedName && name != vulnExportName {
+		return "", "", false
+	}
+	return strings.Trim(prefix, "/"), name, true
+}
+
+// vulnEntries returns the entries affecting modules or packages with the given
+// prefix, or all entries if it is empty, in descending order by ID. If n is
+// not negative, only the first n entries are returned.
+func vulnEntries(ctx context.Context, vc *vuln.Client, prefix string, n int) (_ []*osv.Entry, err error) {
+	var entries []*osv.Entry
+	if prefix == "" {
+		entries, err = vc.Entries(ctx, n)
+	} else {
+		entries, err = vc.ByPackagePrefix(ctx, prefix)
+	}
+	if err != nil {
+		return nil, derrors.VulnDBError
+	}
+	// Entries has been seen to return nil entries.
+	var es []*osv.Entry
+	for _, e := range entries {
+		if e != nil {
+			es = append(es, e)
+		}
+	}
+	if n >= 0 && len(es) > n {
+		es = es[:n]
+	}
+	return es, nil
+}
+
+// An atomFeed is an Atom feed, as described in RFC 4287.
+type atomFeed struct {
+	XMLName xml.Name     `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string       `xml:"id"`
+	Title   string       `xml:"title"`
+	Updated string       `xml:"updated"`
+	Author  atomPerson   `xml:"author"`
+	Links   []atomLink   `xml:"link"`
+	Entries []*atomEntry `xml:"entry"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Links      []atomLink     `xml:"link"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// newVulnFeed returns an Atom feed of the vuln entries affecting the module
+// prefix, or of the most recent entries if it is empty. Entries link to their
+// pages at baseURL. The feed was last updated when its most recently modified
+// entry was, or at now if it has no entries.
+func newVulnFeed(baseURL, prefix string, entries []*osv.Entry, now time.Time) *atomFeed {
+	selfPath := "/vuln/" + vulnFeedName
+	title := "Go Vulnerability Database"
+	alternate := baseURL + "/vuln/list"
+	if prefix != "" {
+		selfPath = "/vuln/" + prefix + "/" + vulnFeedName
+		title += " – " + prefix
+		alternate = baseURL + "/search?q=" + prefix + "&m=vuln"
+	}
+	feed := &atomFeed{
+		ID:     baseURL + selfPath,
+		Title:  title,
+		Author: atomPerson{Name: "Go Vulnerability Database", URI: baseURL + "/vuln/"},
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: baseURL + selfPath},
+			{Rel: "alternate", Type: "text/html", Href: alternate},
+		},
+	}
+	var updated time.Time
+	for _, e := range entries {
+		if e.Modified.After(updated) {
+			updated = e.Modified
+		}
+		feed.Entries = append(feed.Entries, newVulnFeedEntry(baseURL, e))
+	}
+	if updated.IsZero() {
+		updated = now
+	}
+	feed.Updated = atomTime(updated)
+	return feed
+}
+
+// newVulnFeedEntry returns the Atom entry for a vuln entry, linking to its
+// page at baseURL.
+func newVulnFeedEntry(baseURL string, e *osv.Entry) *atomEntry {
+	href := baseURL + "/vuln/" + e.ID
+	title := e.ID
+	if e.Summary != "" {
+		title += ": " + e.Summary
+	}
+	ae := &atomEntry{
+		ID:      href,
+		Title:   title,
+		Updated: atomTime(e.Modified),
+		Links:   []atomLink{{Rel: "alternate", Type: "text/html", Href: href}},
+	}
+	if !e.Published.IsZero() {
+		ae.Published = atomTime(e.Published)
+	}
+	if e.Details != "" {
+		ae.Summary = &atomText{Type: "text", Body: e.Details}
+	}
+	seen := map[string]bool{}
+	for _, a := range e.Affected {
+		if !seen[a.Module.Path] {
+			seen[a.Module.Path] = true
+			ae.Categories = append(ae.Categories, atomCategory{Term: a.Module.Path})
+		}
+	}
+	sort.Slice(ae.Categories, func(i, j int) bool { return ae.Categories[i].Term < ae.Categories[j].Term })
+	return ae
+}
+
+// atomTime formats t as an Atom date construct.
+func atomTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,159 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/osv"
+	"golang.org/x/pkgsite/internal/vuln"
+)
+
+func TestParseVulnExportPath(t *testing.T) {
+	for _, test := range []struct {
+		path, wantPrefix, wantName string
+		wantOK                     bool
+	}{
+		{"/vuln/feed.atom", "", "feed.atom", true},
+		{"/vuln/osv.json", "", "osv.json", true},
+		{"/vuln/golang.org/x/net/feed.atom", "golang.org/x/net", "feed.atom", true},
+		{"/vuln/net/http/osv.json", "net/http", "osv.json", true},
+		{"/vuln/", "", "", false},
+		{"/vuln/list", "", "", false},
+		{"/vuln/GO-2021-0001", "", "", false},
+		{"/vuln/golang.org/x/net/feed.rss", "", "", false},
+	} {
+		prefix, name, ok := parseVulnExportPath(test.path)
+		if prefix != test.wantPrefix || name != test.wantName || ok != test.wantOK {
+			t.Errorf("parseVulnExportPath(%q) = %q, %q, %t; want %q, %q, %t",
+				test.path, prefix, name, ok, test.wantPrefix, test.wantName, test.wantOK)
+		}
+	}
+}
+
+var feedEntries = []*osv.Entry{
+	{
+		ID:        "GO-1990-0002",
+		Summary:   "A problem in net.",
+		Details:   "More about the problem in net.",
+		Published: time.Date(1990, 2, 1, 0, 0, 0, 0, time.UTC),
+		Modified:  time.Date(1990, 3, 1, 0, 0, 0, 0, time.UTC),
+		Affected: []osv.Affected{
+			{Module: osv.Module{Path: "golang.org/x/net"}},
+			{Module: osv.Module{Path: "example.com/fork/net"}},
+		},
+	},
+	{
+		ID:       "GO-1990-0001",
+		Modified: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+		Affected: []osv.Affected{{Module: osv.Module{Path: "example.com/mod"}}},
+	},
+}
+
+func TestNewVulnFeed(t *testing.T) {
+	got := newVulnFeed("https://pkg.example.com", "golang.org/x/net", feedEntries[:1], time.Now())
+	want := &atomFeed{
+		ID:     "https://pkg.example.com/vuln/golang.org/x/net/feed.atom",
+		Title:  "Go Vulnerability Database – golang.org/x/net",
+		Author: atomPerson{Name: "Go Vulnerability Database", URI: "https://pkg.example.com/vuln/"},
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: "https://pkg.example.com/vuln/golang.org/x/net/feed.atom"},
+			{Rel: "alternate", Type: "text/html", Href: "https://pkg.example.com/search?q=golang.org/x/net&m=vuln"},
+		},
+		Updated: "1990-03-01T00:00:00Z",
+		Entries: []*atomEntry{{
+			ID:        "https://pkg.example.com/vuln/GO-1990-0002",
+			Title:     "GO-1990-0002: A problem in net.",
+			Updated:   "1990-03-01T00:00:00Z",
+			Published: "1990-02-01T00:00:00Z",
+			Links:     []atomLink{{Rel: "alternate", Type: "text/html", Href: "https://pkg.example.com/vuln/GO-1990-0002"}},
+			Summary:   &atomText{Type: "text", Body: "More about the problem in net."},
+			Categories: []atomCategory{
+				{Term: "example.com/fork/net"},
+				{Term: "golang.org/x/net"},
+			},
+		}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	if got := newVulnFeed("https://pkg.example.com", "example.com/none", nil, now); got.Updated != "2000-01-01T00:00:00Z" {
+		t.Errorf("empty feed updated at %s, want now", got.Updated)
+	}
+}
+
+func TestServeVulnExport(t *testing.T) {
+	vc, err := vuln.NewInMemoryClient(feedEntries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, handler := newTestServer(t, nil)
+	s.vulnClient = vc
+
+	serve := func(url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: got status %d, want %d", url, w.Code, http.StatusOK)
+		}
+		return w
+	}
+
+	for _, test := range []struct {
+		url     string
+		wantIDs []string
+	}{
+		{"/vuln/feed.atom", []string{"GO-1990-0002", "GO-1990-0001"}},
+		{"/vuln/golang.org/x/net/feed.atom", []string{"GO-1990-0002"}},
+		{"/vuln/golang.org/x/text/feed.atom", nil},
+	} {
+		w := serve(test.url)
+		if got, want := w.Header().Get("Content-Type"), "application/atom+xml; charset=utf-8"; got != want {
+			t.Errorf("%s: Content-Type = %q, want %q", test.url, got, want)
+		}
+		var feed atomFeed
+		if err := xml.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+			t.Fatalf("%s: %v", test.url, err)
+		}
+		var gotIDs []string
+		for _, e := range feed.Entries {
+			gotIDs = append(gotIDs, e.ID[len("http://example.com/vuln/"):])
+		}
+		if diff := cmp.Diff(test.wantIDs, gotIDs); diff != "" {
+			t.Errorf("%s: mismatch (-want, +got):\n%s", test.url, diff)
+		}
+	}
+
+	for _, test := range []struct {
+		url     string
+		wantIDs []string
+	}{
+		{"/vuln/osv.json", []string{"GO-1990-0002", "GO-1990-0001"}},
+		{"/vuln/example.com/osv.json", []string{"GO-1990-0002", "GO-1990-0001"}},
+		{"/vuln/example.com/mod/osv.json", []string{"GO-1990-0001"}},
+		{"/vuln/golang.org/x/text/osv.json", []string{}},
+	} {
+		w := serve(test.url)
+		var entries []*osv.Entry
+		if err := json.Unmarshal(w.Body.Bytes(), &entries); err != nil {
+			t.Fatalf("%s: %v", test.url, err)
+		}
+		gotIDs := []string{}
+		for _, e := range entries {
+			gotIDs = append(gotIDs, e.ID)
+		}
+		if diff := cmp.Diff(test.wantIDs, gotIDs); diff != "" {
+			t.Errorf("%s: mismatch (-want, +got):\n%s", test.url, diff)
+		}
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/frontend/page"
 	"golang.org/x/pkgsite/internal/frontend/serrors"
+	"golang.org/x/pkgsite/internal/log"
 	"golang.org/x/pkgsite/internal/osv"
 	"golang.org/x/pkgsite/internal/vuln"
 )
@@ -45,6 +46,9 @@ func (s *Server) serveVuln(w http.ResponseWriter, r *http.Request, _ internal.Da
 	if s.vulnClient == nil {
 		return serrors.DatasourceNotSupportedError()
 	}
+	if _, _, ok := parseVulnExportPath(r.URL.Path); ok {
+		return s.serveVulnExport(w, r, nil)
+	}
 
 	vp, err := newVulnPage(r.Context(), r.URL, s.vulnClient)
 	if err != nil {
@@ -162,3 +166,26 @@ func advisoryLinks(e *osv.Entry) []link {
 	}
 	return links
 }
+
+// symbolVulnIDs returns the IDs of the vulnerabilities that affect the
+// symbols of the package of um, keyed by symbol name, for highlighting them
+// in its documentation. Vulnerabilities of the whole package are left to the
+// banner at the top of the page. Errors are logged, so that the
+// documentation can be displayed without vulnerabilities.
+func symbolVulnIDs(ctx context.Context, um *internal.UnitMeta, vc *vuln.Client) map[string][]string {
+	svs, err := vuln.SymbolVulnsForPackage(ctx, um.ModulePath, um.Version, um.Path, vc)
+	if err != nil {
+		log.Errorf(ctx, "symbolVulnIDs(%q, %q): %v", um.Path, um.Version, err)
+		return nil
+	}
+	var ids map[string][]string
+	for _, sv := range svs {
+		for _, s := range sv.Symbols {
+			if ids == nil {
+				ids = map[string][]string{}
+			}
+			ids[s] = append(ids[s], sv.ID)
+		}
+	}
+	return ids
+}
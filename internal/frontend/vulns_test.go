@@ -12,6 +12,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/google/safehtml"
+	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/osv"
 	"golang.org/x/pkgsite/internal/vuln"
 )
@@ -200,3 +201,49 @@ func Test_advisoryLinks(t *testing.T) {
 		})
 	}
 }
+
+func TestSymbolVulnIDs(t *testing.T) {
+	ctx := context.Background()
+	pkg := osv.Package{Path: "example.com/mod/pkg", Symbols: []string{"F", "T.M"}}
+	vc, err := vuln.NewInMemoryClient([]*osv.Entry{
+		{
+			ID: "GO-1990-0001",
+			Affected: []osv.Affected{{
+				Module:            osv.Module{Path: "example.com/mod"},
+				EcosystemSpecific: osv.EcosystemSpecific{Packages: []osv.Package{pkg}},
+			}},
+		},
+		{
+			ID: "GO-1990-0002",
+			Affected: []osv.Affected{{
+				Module: osv.Module{Path: "example.com/mod"},
+				EcosystemSpecific: osv.EcosystemSpecific{Packages: []osv.Package{
+					{Path: "example.com/mod/pkg", Symbols: []string{"T.M"}},
+				}},
+			}},
+		},
+		{
+			// Vulnerabilities of the whole package aren't shown by symbol.
+			ID:       "GO-1990-0003",
+			Affected: []osv.Affected{{Module: osv.Module{Path: "example.com/mod"}}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	um := &internal.UnitMeta{
+		Path:       "example.com/mod/pkg",
+		ModuleInfo: internal.ModuleInfo{ModulePath: "example.com/mod", Version: "v1.0.0"},
+	}
+	got := symbolVulnIDs(ctx, um, vc)
+	want := map[string][]string{
+		"F":   {"GO-1990-0001"},
+		"T.M": {"GO-1990-0001", "GO-1990-0002"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if got := symbolVulnIDs(ctx, um, nil); got != nil {
+		t.Errorf("with no vuln client: got %v, want nil", got)
+	}
+}
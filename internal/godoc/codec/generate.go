@@ -142,8 +142,13 @@ func (g *generator) generate() ([]byte, error) {
 		t := g.todo[0]
 		g.todo = g.todo[1:]
 		if !g.done[t] {
-			if t.PkgPath() != "" {
-				importMap[t.PkgPath()] = true
+			// Types from other packages may be reached only through pointers.
+			nt := t
+			if nt.Kind() == reflect.Ptr {
+				nt = nt.Elem()
+			}
+			if nt.PkgPath() != "" && !strings.HasPrefix(nt.String(), g.pkg+".") {
+				importMap[nt.PkgPath()] = true
 			}
 			piece, err := g.gen(t)
 			if err != nil {
@@ -28,3 +28,29 @@ func valueIsDeprecated(v *doc.Value) bool {
 func funcIsDeprecated(f *doc.Func) bool {
 	return isDeprecated(f.Doc)
 }
+
+// replacementRx matches the suggestion of a replacement in a deprecation
+// paragraph, like "Use NewReader instead" or "Use [io.ReadAll]". Only names
+// that are exported or qualified are accepted, to avoid matching ordinary
+// words, as in "use generics".
+var replacementRx = regexp.MustCompile(`(?i:\b(?:use|prefer|replaced by|superseded by|see))\s+(?:the\s+)?\[?((?:[A-Za-z_]\w*\.)*[A-Z]\w*|[a-z]\w*(?:\.[A-Za-z_]\w*)+)(?:\(\))?\]?`)
+
+// deprecation reports whether the doc comment has a "Deprecated" paragraph,
+// and returns the replacement suggested by that paragraph, if any.
+func deprecation(s string) (deprecated bool, replacement string) {
+	loc := deprecatedRx.FindStringIndex(s)
+	if loc == nil {
+		return false, ""
+	}
+	para := s[loc[1]:]
+	if i := paragraphEndRx.FindStringIndex(para); i != nil {
+		para = para[:i[0]]
+	}
+	if m := replacementRx.FindStringSubmatch(para); m != nil {
+		replacement = m[1]
+	}
+	return true, replacement
+}
+
+// The end of a paragraph.
+var paragraphEndRx = regexp.MustCompile(`\n\s*\n`)
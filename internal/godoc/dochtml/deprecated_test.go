@@ -25,3 +25,26 @@ func TestIsDeprecated(t *testing.T) {
 		}
 	}
 }
+
+func TestDeprecation(t *testing.T) {
+	for _, test := range []struct {
+		text            string
+		wantDeprecated  bool
+		wantReplacement string
+	}{
+		{"A comment", false, ""},
+		{"Deprecated: do not use.", true, ""},
+		{"Deprecated: Use NewReader instead.", true, "NewReader"},
+		{"Deprecated: use [io.ReadAll].", true, "io.ReadAll"},
+		{"Deprecated: Use the Client.Do method.", true, "Client.Do"},
+		{"Deprecated: use strings.Builder()", true, "strings.Builder"},
+		{"Deprecated: use generics.", true, ""},
+		{"F does things.\n\nDeprecated: this was a mistake.\n\nUse G instead.", true, ""},
+		{"F does things.\n\nDeprecated: replaced by\nG.", true, "G"},
+	} {
+		dep, repl := deprecation(test.text)
+		if dep != test.wantDeprecated || repl != test.wantReplacement {
+			t.Errorf("%q: got (%t, %q), want (%t, %q)", test.text, dep, repl, test.wantDeprecated, test.wantReplacement)
+		}
+	}
+}
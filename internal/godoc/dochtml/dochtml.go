@@ -59,6 +59,23 @@ type RenderOptions struct {
 	FileLinkFunc     func(file string) (url string)
 	SourceLinkFunc   func(ast.Node) string
 	SinceVersionFunc func(name string) string
+	// ResolveIdentFunc optionally resolves identifiers in declarations to
+	// the declarations they refer to. See render.Options.ResolveIdent.
+	ResolveIdentFunc func(id *ast.Ident) (pkgPath, anchorID string, ok bool)
+	// PromotedMethods optionally lists methods promoted to the package's
+	// types from embedded types in other packages, which cannot be determined
+	// from the package alone.
+	PromotedMethods []*PromotedMethod
+	// Implementations optionally lists the interfaces that the package's
+	// types implement and the types that implement its interfaces.
+	Implementations []*Implementation
+	// ExampleResults optionally maps the names of example functions, like
+	// "ExampleT_M", to whether their output matched when they were run.
+	ExampleResults map[string]bool
+	// SymbolVulns optionally maps the full names of the package's functions,
+	// types and methods, like "T.M", to the IDs of the vulnerabilities that
+	// affect them.
+	SymbolVulns map[string][]string
 	// ModInfo optionally specifies information about the module the package
 	// belongs to in order to render module-related documentation.
 	ModInfo      *ModuleInfo
@@ -96,7 +113,7 @@ func Render(ctx context.Context, fset *token.FileSet, p *doc.Package, opt Render
 		opt.Limit = 10 * megabyte
 	}
 
-	funcs, data, links := renderInfo(ctx, fset, p, opt)
+	r, funcs, data := renderInfo(ctx, fset, p, opt)
 	p = data.Package
 	if docIsEmpty(p) {
 		return &Parts{}, nil
@@ -116,9 +133,9 @@ func Render(ctx context.Context, fset *token.FileSet, p *doc.Package, opt Render
 		Body:          exec(bodyTemplate),
 		Outline:       exec(outlineTemplate),
 		MobileOutline: exec(sidenavTemplate),
-		// links must be called after body, because the call to
+		// r.Links must be called after body, because the call to
 		// render_doc_extract_links in body.tmpl creates the links.
-		Links: links(),
+		Links: r.Links(),
 	}
 	if err != nil {
 		return nil, err
@@ -137,12 +154,22 @@ type item struct {
 	HeaderStart                  string     // text of header, before source link
 	Examples                     []*example // for types and functions; empty for vars and consts
 	IsDeprecated                 bool
-	Consts, Vars, Funcs, Methods []*item // for types
+	Consts, Vars, Funcs, Methods []*item         // for types
+	PromotedMethods              []*promotedItem // for types
+	Implements, ImplementedBy    []*typeLink     // for types
 	// HTML-specific values, for types and functions
 	Kind        string // for data-kind attribute
 	HeaderClass string // class for header
 }
 
+// A promotedItem is a method promoted from an embedded type, rendered as a link
+// to the method's declaration.
+type promotedItem struct {
+	Synopsis string
+	From     string
+	Href     string
+}
+
 func packageToItems(p *doc.Package, exmap map[string][]*example) (consts, vars, funcs, types []*item) {
 	consts = valuesToItems(p.Consts)
 	vars = valuesToItems(p.Vars)
@@ -212,7 +239,7 @@ func typeToItem(t *doc.Type, exmap map[string][]*example) *item {
 		Consts:       valuesToItems(t.Consts),
 		Vars:         valuesToItems(t.Vars),
 		Funcs:        funcsToItems(t.Funcs, "Documentation-typeFuncHeader", "", exmap),
-		Methods:      funcsToItems(t.Methods, "Documentation-typeMethodHeader", t.Name, exmap),
+		Methods:      funcsToItems(declaredMethods(t), "Documentation-typeMethodHeader", t.Name, exmap),
 	}
 }
 
@@ -225,8 +252,9 @@ func docIsEmpty(p *doc.Package) bool {
 		len(p.Funcs) == 0
 }
 
-// renderInfo returns the functions and data needed to render the doc.
-func renderInfo(ctx context.Context, fset *token.FileSet, p *doc.Package, opt RenderOptions) (map[string]any, TemplateData, func() []render.Link) {
+// renderInfo returns the renderer, functions and data needed to render the
+// doc.
+func renderInfo(ctx context.Context, fset *token.FileSet, p *doc.Package, opt RenderOptions) (*render.Renderer, map[string]any, TemplateData) {
 	// Make a copy to avoid modifying caller's *doc.Package.
 	p2 := *p
 	p = &p2
@@ -251,20 +279,22 @@ func renderInfo(ctx context.Context, fset *token.FileSet, p *doc.Package, opt Re
 		delete(p.Notes, k)
 	}
 
+	packageURL := func(path string) string {
+		// Use the same module version for imported packages that belong to
+		// the same module.
+		versionedPath := path
+		if opt.ModInfo != nil {
+			versionedPath = versionedPkgPath(path, opt.ModInfo)
+		}
+		var search string
+		if opt.BuildContext.GOOS != "" && opt.BuildContext.GOOS != "all" {
+			search = "?GOOS=" + opt.BuildContext.GOOS
+		}
+		return "/" + versionedPath + search
+	}
 	r := render.New(ctx, fset, p, &render.Options{
-		PackageURL: func(path string) string {
-			// Use the same module version for imported packages that belong to
-			// the same module.
-			versionedPath := path
-			if opt.ModInfo != nil {
-				versionedPath = versionedPkgPath(path, opt.ModInfo)
-			}
-			var search string
-			if opt.BuildContext.GOOS != "" && opt.BuildContext.GOOS != "all" {
-				search = "?GOOS=" + opt.BuildContext.GOOS
-			}
-			return "/" + versionedPath + search
-		},
+		PackageURL:   packageURL,
+		ResolveIdent: opt.ResolveIdentFunc,
 	})
 
 	fileLink := func(name string) safehtml.HTML {
@@ -276,6 +306,9 @@ func renderInfo(ctx context.Context, fset *token.FileSet, p *doc.Package, opt Re
 	sinceVersion := func(name string) safehtml.HTML {
 		return safehtml.HTMLEscaped(opt.SinceVersionFunc(name))
 	}
+	symbolVulns := func(name string) []string {
+		return opt.SymbolVulns[name]
+	}
 	funcs := map[string]any{
 		"render_short_synopsis":    r.ShortSynopsis,
 		"render_synopsis":          r.Synopsis,
@@ -286,8 +319,9 @@ func renderInfo(ctx context.Context, fset *token.FileSet, p *doc.Package, opt Re
 		"file_link":                fileLink,
 		"source_link":              sourceLink,
 		"since_version":            sinceVersion,
+		"symbol_vulns":             symbolVulns,
 	}
-	examples := collectExamples(p)
+	examples := collectExamples(p, opt.ExampleResults)
 	data := TemplateData{
 		Package:     p,
 		RootURL:     "/pkg",
@@ -295,7 +329,21 @@ func renderInfo(ctx context.Context, fset *token.FileSet, p *doc.Package, opt Re
 		NoteHeaders: buildNoteHeaders(p.Notes),
 	}
 	data.Consts, data.Vars, data.Funcs, data.Types = packageToItems(p, examples.Map)
-	return funcs, data, r.Links
+	for i, t := range p.Types {
+		for _, pm := range promotedMethods(p, t, fset, opt.PromotedMethods) {
+			href := "#" + pm.anchorID()
+			if pm.PkgPath != p.ImportPath {
+				href = packageURL(pm.PkgPath) + href
+			}
+			data.Types[i].PromotedMethods = append(data.Types[i].PromotedMethods, &promotedItem{
+				Synopsis: pm.Synopsis,
+				From:     pm.From,
+				Href:     href,
+			})
+		}
+		data.Types[i].Implements, data.Types[i].ImplementedBy = implementationLinks(p, t, opt.Implementations, packageURL)
+	}
+	return r, funcs, data
 }
 
 // executeToHTMLWithLimit executes tmpl on data and returns the result as a safehtml.HTML.
@@ -337,6 +385,9 @@ type example struct {
 	ID       safehtml.Identifier // ID of example
 	ParentID string              // ID of top-level declaration this example is attached to
 	Suffix   string              // optional suffix name in title case
+	// OutputStatus is "verified" if the example was run and its output
+	// matched, "mismatch" if it did not, and empty if it was not run.
+	OutputStatus string
 }
 
 // Code returns an printer.CommentedNode if ex.Comments is non-nil,
@@ -378,7 +429,8 @@ func WalkExamples(p *doc.Package, fn func(id string, ex *doc.Example)) {
 
 // collectExamples extracts examples from p
 // into the internal examples representation.
-func collectExamples(p *doc.Package) *examples {
+// results maps example function names to whether their output matched.
+func collectExamples(p *doc.Package, results map[string]bool) *examples {
 	exs := &examples{
 		List: nil,
 		Map:  make(map[string][]*example),
@@ -391,6 +443,12 @@ func collectExamples(p *doc.Package) *examples {
 			ParentID: id,
 			Suffix:   suffix,
 		}
+		if passed, ok := results["Example"+ex.Name]; ok {
+			ex0.OutputStatus = "mismatch"
+			if passed {
+				ex0.OutputStatus = "verified"
+			}
+		}
 		exs.List = append(exs.List, ex0)
 		exs.Map[id] = append(exs.Map[id], ex0)
 	})
@@ -186,6 +186,60 @@ func main() {
 	}
 }
 
+func TestExampleRenderOutputStatus(t *testing.T) {
+	LoadTemplates(templateFS)
+	fset, d := mustLoadPackage("example_test")
+	opts := testRenderOptions
+	opts.ExampleResults = map[string]bool{
+		"Example_stringsCompare": true,
+		"Example_appRunNoAction": false,
+	}
+	parts, err := Render(context.Background(), fset, d, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := parts.Body.String()
+	for _, want := range []string{
+		`<span class="Documentation-exampleOutputStatus Documentation-exampleOutputStatus--verified" title="The output was checked by running the example">verified output</span>`,
+		`<span class="Documentation-exampleOutputStatus Documentation-exampleOutputStatus--mismatch" title="Running the example produced different output">output mismatch</span>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("body does not contain %s", want)
+		}
+	}
+}
+
+func TestRenderSymbolVulns(t *testing.T) {
+	LoadTemplates(templateFS)
+	fset, d := mustLoadPackage("deprecated")
+	opts := testRenderOptions
+	opts.SymbolVulns = map[string][]string{
+		"GoodF":       {"GO-2023-0001"},
+		"BadF":        {"GO-2023-0002"},
+		"GoodT.GoodM": {"GO-2023-0001", "GO-2023-0003"},
+	}
+	parts, err := Render(context.Background(), fset, d, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := parts.Body.String()
+	vulnTag := func(id string) string {
+		return fmt.Sprintf(`<a class="Documentation-vulnTag" href="/vuln/%[1]s" title="Affected by vulnerability %[1]s">%[1]s</a>`, id)
+	}
+	for _, test := range []struct {
+		id   string
+		want int
+	}{
+		{"GO-2023-0001", 2},
+		{"GO-2023-0002", 1},
+		{"GO-2023-0003", 1},
+	} {
+		if n := strings.Count(got, vulnTag(test.id)); n != test.want {
+			t.Errorf("%s tagged %d times, want %d", test.id, n, test.want)
+		}
+	}
+}
+
 func TestLinkHTML(t *testing.T) {
 	for _, test := range []struct {
 		name string
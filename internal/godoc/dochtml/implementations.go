@@ -0,0 +1,46 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dochtml
+
+import (
+	"go/doc"
+	"strings"
+
+	"golang.org/x/pkgsite/internal"
+)
+
+// An Implementation records that a type implements an interface, where at
+// least one of them is declared in the package being rendered.
+type Implementation = internal.Implementation
+
+// A typeLink is a link to the documentation of a type.
+type typeLink struct {
+	Name string
+	Href string
+}
+
+// implementationLinks returns links to the interfaces that the type t
+// implements and the types that implement it, using packageURL to link to
+// other packages.
+func implementationLinks(p *doc.Package, t *doc.Type, impls []*Implementation, packageURL func(string) string) (implements, implementedBy []*typeLink) {
+	link := func(name, pkgPath string) *typeLink {
+		id := strings.TrimPrefix(name, "*")
+		id = id[strings.LastIndexByte(id, '.')+1:]
+		href := "#" + id
+		if pkgPath != p.ImportPath {
+			href = packageURL(pkgPath) + href
+		}
+		return &typeLink{Name: name, Href: href}
+	}
+	for _, impl := range impls {
+		if impl.TypePkgPath == p.ImportPath && strings.TrimPrefix(impl.Type, "*") == t.Name {
+			implements = append(implements, link(impl.Interface, impl.InterfacePkgPath))
+		}
+		if impl.InterfacePkgPath == p.ImportPath && impl.Interface == t.Name {
+			implementedBy = append(implementedBy, link(impl.Type, impl.TypePkgPath))
+		}
+	}
+	return implements, implementedBy
+}
@@ -0,0 +1,40 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dochtml
+
+import (
+	"go/doc"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestImplementationLinks(t *testing.T) {
+	p := &doc.Package{ImportPath: "example.com/a"}
+	impls := []*Implementation{
+		{Type: "T", TypePkgPath: "example.com/a", Interface: "Doer", InterfacePkgPath: "example.com/a"},
+		{Type: "*sub.S", TypePkgPath: "example.com/a/sub", Interface: "Doer", InterfacePkgPath: "example.com/a"},
+		{Type: "*T", TypePkgPath: "example.com/a", Interface: "io.Reader", InterfacePkgPath: "io"},
+	}
+	packageURL := func(path string) string { return "/" + path }
+
+	implements, implementedBy := implementationLinks(p, &doc.Type{Name: "T"}, impls, packageURL)
+	want := []*typeLink{{Name: "Doer", Href: "#Doer"}, {Name: "io.Reader", Href: "/io#Reader"}}
+	if diff := cmp.Diff(want, implements); diff != "" {
+		t.Errorf("T implements mismatch (-want +got):\n%s", diff)
+	}
+	if implementedBy != nil {
+		t.Errorf("T implemented by: got %v, want nil", implementedBy)
+	}
+
+	implements, implementedBy = implementationLinks(p, &doc.Type{Name: "Doer"}, impls, packageURL)
+	want = []*typeLink{{Name: "T", Href: "#T"}, {Name: "*sub.S", Href: "/example.com/a/sub#S"}}
+	if diff := cmp.Diff(want, implementedBy); diff != "" {
+		t.Errorf("Doer implemented by mismatch (-want +got):\n%s", diff)
+	}
+	if implements != nil {
+		t.Errorf("Doer implements: got %v, want nil", implements)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"go/ast"
 	"go/doc"
 	"go/token"
+	"strings"
 
 	"github.com/google/safehtml/template"
 )
@@ -53,6 +54,11 @@ func forEachPackageDecl(pkg *doc.Package, fnc func(decl ast.Decl)) {
 			fnc(f.Decl)
 		}
 		for _, m := range t.Methods {
+			// Methods promoted from exported embedded types are
+			// documented with those types.
+			if m.Level > 0 && token.IsExported(strings.TrimPrefix(m.Orig, "*")) {
+				continue
+			}
 			fnc(m.Decl)
 		}
 	}
@@ -191,6 +197,12 @@ type identifierResolver struct {
 	//
 	// E.g., packageURL("builtin") == "/pkg/builtin/index.html"
 	packageURL func(string) string
+
+	// resolveIdent optionally resolves an identifier to the declaration
+	// it refers to. See Options.ResolveIdent.
+	//
+	// E.g., resolveIdent(Reader) == "io", "Reader", true
+	resolveIdent func(*ast.Ident) (pkgPath, id string, ok bool)
 }
 
 // toURL returns a URL to locate the given package, and
@@ -378,7 +378,7 @@ func (r *Renderer) declHTML(doc string, decl ast.Decl, extractLinks bool) (out s
 		out.Doc = r.formatDocHTML(doc, extractLinks)
 	}
 	if decl != nil {
-		idr := &identifierResolver{r.pids, newDeclIDs(decl), r.packageURL}
+		idr := &identifierResolver{r.pids, newDeclIDs(decl), r.packageURL, r.resolveIdent}
 		out.Decl = r.formatDeclHTML(decl, idr)
 	}
 	return out
@@ -578,10 +578,6 @@ func (r *Renderer) formatDeclHTML(decl ast.Decl, idr *identifierResolver) safe.H
 	})
 
 	// Trim large string literals and composite literals.
-	const (
-		maxStringSize = 125
-		maxElements   = 100
-	)
 	decl = rewriteDecl(decl, maxStringSize, maxElements)
 	// Format decl as Go source code file.
 	p := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 4}
@@ -691,6 +687,12 @@ scan:
 
 var anchorTemplate = template.Must(template.New("anchor").Parse(`<span id="{{.ID}}" data-kind="{{.Kind}}">`))
 
+// Limits on the size of literals in rendered declarations.
+const (
+	maxStringSize = 125
+	maxElements   = 100
+)
+
 // rewriteDecl rewrites n by removing strings longer than maxStringSize and
 // composite literals longer than maxElements.
 func rewriteDecl(n ast.Decl, maxStringSize, maxElements int) ast.Decl {
@@ -852,6 +854,16 @@ func generateAnchorLinks(idr *identifierResolver, decl ast.Decl) map[*ast.Ident]
 		if ignore[node] {
 			return false
 		}
+		// Prefer the exact declaration found by type-checking, if any.
+		if id, ok := node.(*ast.Ident); ok && idr.resolveIdent != nil {
+			if path, anchor, ok := idr.resolveIdent(id); ok {
+				if path == idr.impPaths[idr.name] && anchor != "" {
+					path = ""
+				}
+				m[id] = idr.toURL(path, anchor)
+				return false
+			}
+		}
 		switch node := node.(type) {
 		case *ast.SelectorExpr:
 			// Package qualified identifier (e.g., "io.EOF").
@@ -325,6 +325,65 @@ func TestDeclHTML(t *testing.T) {
 	}
 }
 
+func TestDeclHTMLResolveIdent(t *testing.T) {
+	const src = `package p
+
+import . "io"
+
+type T struct {
+	Reader
+	W Writer
+}
+
+var ReadT = T.Read
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := doc.NewFromFiles(fset, []*ast.File{file}, "example.com/p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// What type-checking would report, by identifier name.
+	links := map[string][2]string{
+		"Reader": {"io", "Reader"},
+		"Writer": {"io", "Writer"},
+		"T":      {"example.com/p", "T"},
+		"Read":   {"io", "Reader.Read"},
+	}
+	r := New(context.Background(), fset, pkg, &Options{
+		ResolveIdent: func(id *ast.Ident) (string, string, bool) {
+			l, ok := links[id.Name]
+			return l[0], l[1], ok
+		},
+	})
+	for _, test := range []struct {
+		symbol string
+		want   string
+	}{
+		{
+			symbol: "T",
+			want: `type T struct {
+<span id="T.Reader" data-kind="field">	<a href="/io#Reader">Reader</a>
+</span><span id="T.W" data-kind="field">	W <a href="/io#Writer">Writer</a>
+</span>}`,
+		},
+		{
+			symbol: "ReadT",
+			want:   `<span id="ReadT" data-kind="variable">var ReadT = <a href="#T">T</a>.<a href="/io#Reader.Read">Read</a></span>`,
+		},
+	} {
+		t.Run(test.symbol, func(t *testing.T) {
+			got := r.DeclHTML("", declForName(t, pkg, test.symbol)).Decl.String()
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
 func declForName(t *testing.T, pkg *doc.Package, symbol string) ast.Decl {
 
 	inVals := func(vals []*doc.Value) ast.Decl {
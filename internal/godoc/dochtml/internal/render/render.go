@@ -28,6 +28,7 @@ type Renderer struct {
 	fset          *token.FileSet
 	pids          *packageIDs
 	packageURL    func(string) string
+	resolveIdent  func(*ast.Ident) (pkgPath, id string, ok bool)
 	ctx           context.Context
 	docTmpl       *template.Template
 	exampleTmpl   *template.Template
@@ -48,6 +49,15 @@ type Options struct {
 	//
 	// Only relevant for HTML formatting.
 	PackageURL func(pkgPath string) (url string)
+
+	// ResolveIdent optionally reports the import path of the package
+	// declaring the object that an identifier in a declaration refers to,
+	// along with the anchor ID of the object's documentation, which is empty
+	// for a package name. It reports false for identifiers it knows nothing
+	// about, which are then linked using syntactic heuristics.
+	//
+	// Only relevant for HTML formatting.
+	ResolveIdent func(id *ast.Ident) (pkgPath, anchorID string, ok bool)
 }
 
 // docDataTmpl renders documentation. It expects a docData.
@@ -85,6 +95,7 @@ var exampleTmpl = template.Must(template.New("").Parse(`
 func New(ctx context.Context, fset *token.FileSet, pkg *doc.Package, opts *Options) *Renderer {
 	var others []*doc.Package
 	var packageURL func(string) string
+	var resolveIdent func(*ast.Ident) (string, string, bool)
 	if opts != nil {
 		if len(opts.RelatedPackages) > 0 {
 			others = opts.RelatedPackages
@@ -92,6 +103,7 @@ func New(ctx context.Context, fset *token.FileSet, pkg *doc.Package, opts *Optio
 		if opts.PackageURL != nil {
 			packageURL = opts.PackageURL
 		}
+		resolveIdent = opts.ResolveIdent
 	}
 	pids := newPackageIDs(pkg, others...)
 
@@ -99,6 +111,7 @@ func New(ctx context.Context, fset *token.FileSet, pkg *doc.Package, opts *Optio
 		fset:          fset,
 		pids:          pids,
 		packageURL:    packageURL,
+		resolveIdent:  resolveIdent,
 		docTmpl:       docDataTmpl,
 		exampleTmpl:   exampleTmpl,
 		ctx:           ctx,
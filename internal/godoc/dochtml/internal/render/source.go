@@ -0,0 +1,435 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"path"
+	"strconv"
+	"strings"
+
+	safe "github.com/google/safehtml"
+	"github.com/google/safehtml/template"
+	"golang.org/x/mod/module"
+)
+
+/*
+This logic renders whole source files as HTML for the source view. Identifiers
+are resolved by type-checking the file with the other files of its package:
+uses of names declared in the package jump to their declarations, in the file
+or in another file of the package, and package-qualified names and predeclared
+identifiers link to their documentation, as in declarations rendered by
+formatDeclHTML. Imported packages are not loaded, so where the links
+determined when the package was fetched are available, they are preferred for
+names declared in other packages.
+*/
+
+// A SourceDef is the definition of a name declared at package level.
+type SourceDef struct {
+	Href    string // URL of the definition, e.g. "file.go#L12"
+	Summary string // one-line summary of the definition, shown on hover
+}
+
+// SourceOptions are options for SourceHTML.
+type SourceOptions struct {
+	// ImportPath is the import path of the package that the file belongs
+	// to. If it is empty, exported declarations are not linked to their
+	// documentation.
+	ImportPath string
+
+	// PackageDecls maps names declared at package level in the other files
+	// of the package to their definitions. It is only used for files
+	// without syntax.
+	PackageDecls map[string]SourceDef
+
+	// Uses maps the identifiers in the file to the objects they denote, as
+	// recorded by type-checking the file with the other files of its
+	// package. Objects declared in other files of the package are linked
+	// to the line of their declaration in that file, whose contents are in
+	// PackageFiles.
+	Uses map[*ast.Ident]types.Object
+
+	// PackageFiles maps the names of the files of the package to their
+	// contents.
+	PackageFiles map[string][]byte
+
+	// IdentLinks maps the offsets of identifiers in the file to the
+	// declarations they refer to, in the form of godoc.Package.IdentLinks,
+	// as determined when the package was fetched. Links at offsets where
+	// the file no longer has a matching identifier are ignored.
+	IdentLinks map[int]string
+
+	// PackageURL is a function that given a package path,
+	// returns a URL for navigating to the godoc for that package.
+	PackageURL func(pkgPath string) (url string)
+}
+
+// sourceLink is the data passed to sourceLinkTemplate.
+type sourceLink struct {
+	Href, Title, Text string
+}
+
+var (
+	sourceLinkTemplate = template.Must(template.New("sourceLink").Parse(
+		`<a href="{{.Href}}" title="{{.Title}}">{{.Text}}</a>`))
+	sourceSpanTemplate = template.Must(template.New("sourceSpan").Parse(
+		`<span class="{{.Class}}">{{.Text}}</span>`))
+)
+
+// SourceHTML returns src, the contents of a source file, as HTML, one element
+// per line. Comments, string literals and keywords are highlighted. If file,
+// the result of parsing src, is nil, src is only tokenized, as is done for
+// Go+ files; then only names in opts.PackageDecls are linked.
+func SourceHTML(src []byte, fset *token.FileSet, file *ast.File, opts SourceOptions) []safe.HTML {
+	links := sourceLinks(src, fset, file, opts)
+
+	var (
+		lines []safe.HTML
+		line  []safe.HTML
+	)
+	// add adds text to the output, splitting it into lines and wrapping
+	// each non-empty piece with wrap.
+	add := func(text string, wrap func(string) safe.HTML) {
+		for {
+			piece, rest, more := strings.Cut(text, "\n")
+			if piece != "" {
+				line = append(line, wrap(piece))
+			}
+			if !more {
+				return
+			}
+			lines = append(lines, safe.HTMLConcat(line...))
+			line = nil
+			text = rest
+		}
+	}
+	span := func(class string) func(string) safe.HTML {
+		return func(text string) safe.HTML {
+			return ExecuteToHTML(sourceSpanTemplate, struct{ Class, Text string }{class, text})
+		}
+	}
+
+	var s scanner.Scanner
+	tfile := token.NewFileSet().AddFile("", -1, len(src))
+	s.Init(tfile, src, func(token.Position, string) {}, scanner.ScanComments)
+	var last int // last offset of src copied to the output
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		offset := tfile.Offset(pos)
+		if offset < last {
+			continue // automatically inserted semicolon
+		}
+		var wrap func(string) safe.HTML
+		end := offset + len(lit)
+		switch {
+		case tok == token.COMMENT:
+			wrap = span("comment")
+			end = commentEnd(src, offset)
+		case tok == token.STRING || tok == token.CHAR:
+			wrap = span("string")
+			if src[offset] == '`' {
+				end = rawStringEnd(src, offset)
+			}
+		case tok.IsKeyword():
+			wrap = span("keyword")
+		case tok == token.IDENT:
+			l, ok := links[offset]
+			if !ok {
+				continue
+			}
+			wrap = func(text string) safe.HTML {
+				return ExecuteToHTML(sourceLinkTemplate, sourceLink{Href: l.Href, Title: l.Summary, Text: text})
+			}
+		default:
+			continue
+		}
+		add(string(src[last:offset]), safe.HTMLEscaped)
+		add(string(src[offset:end]), wrap)
+		last = end
+	}
+	add(string(src[last:]), safe.HTMLEscaped)
+	if len(line) > 0 {
+		lines = append(lines, safe.HTMLConcat(line...))
+	}
+	return lines
+}
+
+// commentEnd returns the offset just past the comment starting at offset.
+func commentEnd(src []byte, offset int) int {
+	if bytes.HasPrefix(src[offset:], []byte("/*")) {
+		if i := bytes.Index(src[offset+2:], []byte("*/")); i >= 0 {
+			return offset + 2 + i + 2
+		}
+		return len(src)
+	}
+	if i := bytes.IndexByte(src[offset:], '\n'); i >= 0 {
+		return offset + i
+	}
+	return len(src)
+}
+
+// rawStringEnd returns the offset just past the raw string literal starting
+// at offset. Unlike the literal reported by the scanner, the source may
+// contain carriage returns.
+func rawStringEnd(src []byte, offset int) int {
+	if i := bytes.IndexByte(src[offset+1:], '`'); i >= 0 {
+		return offset + 1 + i + 1
+	}
+	return len(src)
+}
+
+// sourceLinks returns the links for the identifiers in src, keyed by their
+// offsets.
+func sourceLinks(src []byte, fset *token.FileSet, file *ast.File, opts SourceOptions) map[int]SourceDef {
+	links := map[int]SourceDef{}
+	if file == nil {
+		// Without syntax, link every identifier that names a package-level
+		// declaration.
+		var s scanner.Scanner
+		tfile := token.NewFileSet().AddFile("", -1, len(src))
+		s.Init(tfile, src, func(token.Position, string) {}, 0)
+		for {
+			pos, tok, lit := s.Scan()
+			if tok == token.EOF {
+				break
+			}
+			if def, ok := opts.PackageDecls[lit]; ok && tok == token.IDENT {
+				links[tfile.Offset(pos)] = def
+			}
+		}
+		return links
+	}
+
+	idr := identifierResolver{packageURL: opts.PackageURL}
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+	fileName := fset.Position(file.Pos()).Filename
+	// objectDef returns a link to the line declaring obj in the package,
+	// summarized by the text of that line.
+	objectDef := func(obj types.Object) (SourceDef, bool) {
+		p := fset.Position(obj.Pos())
+		if p.Filename == fileName {
+			return SourceDef{Href: fmt.Sprintf("#L%d", p.Line), Summary: sourceLine(src, p.Offset)}, true
+		}
+		fsrc, ok := opts.PackageFiles[p.Filename]
+		if !ok || p.Offset >= len(fsrc) {
+			return SourceDef{}, false
+		}
+		return SourceDef{Href: fmt.Sprintf("%s#L%d", p.Filename, p.Line), Summary: sourceLine(fsrc, p.Offset)}, true
+	}
+	// identLink returns the link determined when the package was fetched
+	// for id, if it still refers to a declaration with the name of id.
+	identLink := func(id *ast.Ident) (SourceDef, bool) {
+		link, ok := opts.IdentLinks[offset(id.Pos())]
+		if !ok {
+			return SourceDef{}, false
+		}
+		pkgPath, anchor, _ := strings.Cut(link, "#")
+		if anchor == "" {
+			if _, ok := opts.Uses[id].(*types.PkgName); !ok {
+				return SourceDef{}, false
+			}
+			return SourceDef{Href: idr.toURL(pkgPath, ""), Summary: strconv.Quote(pkgPath)}, true
+		}
+		if anchor != id.Name && !strings.HasSuffix(anchor, "."+id.Name) {
+			return SourceDef{}, false
+		}
+		return SourceDef{Href: idr.toURL(pkgPath, anchor), Summary: pkgPath + "." + anchor}, true
+	}
+
+	// Link exported declarations to their documentation.
+	if opts.ImportPath != "" {
+		for _, decl := range file.Decls {
+			for id, ik := range generateAnchorPoints(decl) {
+				if id == nil || !isExportedID(ik.ID.String()) {
+					continue
+				}
+				links[offset(id.Pos())] = SourceDef{
+					Href:    idr.toURL(opts.ImportPath, ik.ID.String()),
+					Summary: "Documentation for " + ik.ID.String(),
+				}
+			}
+		}
+	}
+
+	// Imported packages are not loaded, so selectors of their members are
+	// not resolved by the type checker.
+	for id, obj := range opts.Uses {
+		pn, ok := obj.(*types.PkgName)
+		if !ok {
+			continue
+		}
+		p := pn.Imported().Path()
+		links[offset(id.Pos())] = SourceDef{Href: idr.toURL(p, ""), Summary: strconv.Quote(p)}
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if x, ok := sel.X.(*ast.Ident); ok {
+			if pn, ok := opts.Uses[x].(*types.PkgName); ok && opts.Uses[sel.Sel] == nil {
+				p := pn.Imported().Path()
+				links[offset(sel.Sel.Pos())] = SourceDef{Href: idr.toURL(p, sel.Sel.Name), Summary: p + "." + sel.Sel.Name}
+			}
+		}
+		return true
+	})
+
+	for id, obj := range opts.Uses {
+		off := offset(id.Pos())
+		if _, ok := links[off]; ok {
+			continue
+		}
+		switch {
+		case obj.Pkg() == nil || obj.Pkg() == types.Unsafe:
+			// Predeclared, like int, or a member of package unsafe.
+			if def, ok := identLink(id); ok {
+				links[off] = def
+			} else if obj.Parent() == types.Universe && doc.IsPredeclared(id.Name) {
+				links[off] = SourceDef{Href: idr.toURL("builtin", id.Name), Summary: "builtin." + id.Name}
+			} else if obj.Pkg() == types.Unsafe {
+				links[off] = SourceDef{Href: idr.toURL("unsafe", id.Name), Summary: "unsafe." + id.Name}
+			}
+		case obj.Pos().IsValid():
+			// Declared in the package.
+			if def, ok := objectDef(obj); ok {
+				links[off] = def
+			}
+		default:
+			if def, ok := identLink(id); ok {
+				links[off] = def
+			}
+		}
+	}
+	// Links determined when the package was fetched cover names that the
+	// type checker could not resolve without the imported packages, like
+	// those of embedded fields.
+	ast.Inspect(file, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			if _, ok := links[offset(id.Pos())]; !ok {
+				if def, ok := identLink(id); ok {
+					links[offset(id.Pos())] = def
+				}
+			}
+		}
+		return true
+	})
+	return links
+}
+
+// isExportedID reports whether every component of the anchor ID, like
+// "T.M", is exported.
+func isExportedID(id string) bool {
+	for _, name := range strings.Split(id, ".") {
+		if !token.IsExported(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// SourceUses type-checks files, the files of the package at importPath, and
+// returns the objects denoted by the identifiers in them. Imported packages
+// are not loaded, so names declared in them are not resolved, and type errors
+// are ignored.
+func SourceUses(importPath string, fset *token.FileSet, files []*ast.File) map[*ast.Ident]types.Object {
+	info := &types.Info{Uses: map[*ast.Ident]types.Object{}}
+	conf := types.Config{
+		Importer:    emptyImporter{},
+		Error:       func(error) {},
+		FakeImportC: true,
+	}
+	conf.Check(importPath, fset, files, info)
+	return info.Uses
+}
+
+// emptyImporter is a types.Importer that returns an empty package for each
+// import path, with the conventional name for the path.
+type emptyImporter struct{}
+
+func (emptyImporter) Import(importPath string) (*types.Package, error) {
+	if importPath == "unsafe" {
+		return types.Unsafe, nil
+	}
+	pkg := types.NewPackage(importPath, importName(importPath))
+	// An incomplete package is not declared in the importing file.
+	pkg.MarkComplete()
+	return pkg, nil
+}
+
+// importName returns the conventional package name for the import path: its
+// last element, skipping a major version suffix.
+func importName(importPath string) string {
+	if prefix, _, ok := module.SplitPathVersion(importPath); ok && prefix != "" {
+		importPath = prefix
+	}
+	return path.Base(importPath)
+}
+
+// maxSummary is the maximum length of a summary of a definition.
+const maxSummary = 100
+
+// sourceLine returns the trimmed text of the line of src containing offset,
+// for summarizing a definition.
+func sourceLine(src []byte, offset int) string {
+	start := bytes.LastIndexByte(src[:offset], '\n') + 1
+	end := len(src)
+	if i := bytes.IndexByte(src[offset:], '\n'); i >= 0 {
+		end = offset + i
+	}
+	s := strings.TrimSpace(string(src[start:end]))
+	s = strings.TrimSuffix(s, "{")
+	s = strings.TrimSpace(s)
+	if len(s) > maxSummary {
+		s = s[:maxSummary] + "…"
+	}
+	return s
+}
+
+// PackageSourceDefs returns the definitions of the names declared at package
+// level in file, whose source view is at fileURL. Methods are omitted, since
+// they are only referred to by selectors.
+func PackageSourceDefs(src []byte, fset *token.FileSet, file *ast.File, fileURL string) map[string]SourceDef {
+	defs := map[string]SourceDef{}
+	add := func(id *ast.Ident) {
+		if id == nil || id.Name == "_" {
+			return
+		}
+		p := fset.Position(id.Pos())
+		defs[id.Name] = SourceDef{
+			Href:    fmt.Sprintf("%s#L%d", fileURL, p.Line),
+			Summary: sourceLine(src, p.Offset),
+		}
+	}
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv == nil {
+				add(decl.Name)
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					add(spec.Name)
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						add(name)
+					}
+				}
+			}
+		}
+	}
+	return defs
+}
@@ -0,0 +1,113 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package render
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestSourceHTML(t *testing.T) {
+	const src = `// Package p is a package.
+package p
+
+import (
+	"fmt"
+	str "strings"
+)
+
+// T is a type.
+type T struct{ F int }
+
+func (t T) M() string {
+	s := fmt.Sprint(t.F, Other)
+	return str.ToUpper(s) + ` + "`raw\nstring`" + `
+}
+`
+	const other = "package p\n\nvar Other = 1\n"
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherFile, err := parser.ParseFile(fset, "other.go", other, parser.SkipObjectResolution)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := SourceHTML([]byte(src), fset, file, SourceOptions{
+		ImportPath:   "example.com/p",
+		Uses:         SourceUses("example.com/p", fset, []*ast.File{file, otherFile}),
+		PackageFiles: map[string][]byte{"other.go": []byte(other)},
+		// Stale links, at identifiers with other names, are ignored.
+		IdentLinks: map[int]string{
+			strings.Index(src, "string {"): "builtin#string",
+			strings.Index(src, "Sprint"):   "fmt#Print",
+		},
+	})
+	if got, want := len(lines), strings.Count(src, "\n"); got != want {
+		t.Fatalf("got %d lines, want %d", got, want)
+	}
+	for _, test := range []struct {
+		line int
+		want string
+	}{
+		{1, `<span class="comment">// Package p is a package.</span>`},
+		{5, `<span class="string">&#34;fmt&#34;</span>`},
+		{10, `<span class="keyword">type</span> <a href="/example.com/p#T" title="Documentation for T">T</a> <span class="keyword">struct</span>{ <a href="/example.com/p#T.F" title="Documentation for T.F">F</a> <a href="/builtin#int" title="builtin.int">int</a> }`},
+		{12, `(t <a href="#L10" title="type T struct{ F int }">T</a>) <a href="/example.com/p#T.M" title="Documentation for T.M">M</a>() <a href="/builtin#string" title="builtin.string">string</a> {`},
+		{13, `<a href="/fmt" title="&#34;fmt&#34;">fmt</a>.<a href="/fmt#Sprint" title="fmt.Sprint">Sprint</a>(<a href="#L12" title="func (t T) M() string">t</a>.<a href="#L10" title="type T struct{ F int }">F</a>, <a href="other.go#L3" title="var Other = 1">Other</a>)`},
+		{14, `<a href="/strings#ToUpper" title="strings.ToUpper">ToUpper</a>`},
+		{14, "<span class=\"string\">`raw</span>"},
+		{15, "<span class=\"string\">string`</span>"},
+	} {
+		if got := lines[test.line-1].String(); !strings.Contains(got, test.want) {
+			t.Errorf("line %d:\ngot  %s\nwant it to contain %s", test.line, got, test.want)
+		}
+	}
+}
+
+func TestSourceHTMLWithoutSyntax(t *testing.T) {
+	const src = "println F(x)\n"
+	lines := SourceHTML([]byte(src), token.NewFileSet(), nil, SourceOptions{
+		PackageDecls: map[string]SourceDef{"F": {Href: "a.go#L1", Summary: "func F(int)"}},
+	})
+	want := `println <a href="a.go#L1" title="func F(int)">F</a>(x)`
+	if len(lines) != 1 || lines[0].String() != want {
+		t.Errorf("got %v, want [%s]", lines, want)
+	}
+}
+
+func TestSourceHTMLIdentLinks(t *testing.T) {
+	// Reader is declared in a dot-imported package, so only the links
+	// determined when the package was fetched resolve it.
+	const src = "package p\n\nimport . \"io\"\n\ntype T struct{ Reader }\n\nfunc (t T) R() Reader { return t.Reader }\n"
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, parser.SkipObjectResolution)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := SourceHTML([]byte(src), fset, file, SourceOptions{
+		Uses: SourceUses("example.com/p", fset, []*ast.File{file}),
+		IdentLinks: map[int]string{
+			strings.Index(src, "Reader }"):   "io#Reader",
+			strings.Index(src, "Reader {"):   "io#Reader",
+			strings.Index(src, "t.Reader }"): "io#Reader", // stale
+		},
+	})
+	for _, test := range []struct {
+		line int
+		want string
+	}{
+		{5, `{ <a href="/io#Reader" title="io.Reader">Reader</a> }`},
+		{7, `<a href="/io#Reader" title="io.Reader">Reader</a> { <span class="keyword">return</span> <a href="#L7" title="func (t T) R() Reader { return t.Reader }">t</a>.<a href="#L5" title="type T struct{ Reader }">Reader</a> }`},
+	} {
+		if got := lines[test.line-1].String(); !strings.Contains(got, test.want) {
+			t.Errorf("line %d:\ngot  %s\nwant it to contain %s", test.line, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package render
+
+import (
+	"bytes"
+	"go/ast"
+	"go/doc"
+	"go/doc/comment"
+	"go/printer"
+)
+
+// DocMarkdown formats the doc comment as Markdown. Headings start at the
+// given level, and doc links are resolved as they are by DocHTML.
+func (r *Renderer) DocMarkdown(doc string, headingLevel int) string {
+	p := r.commentPrinter()
+	p.HeadingLevel = headingLevel
+	// Heading IDs are a Markdown extension that many renderers lack.
+	p.HeadingID = func(*comment.Heading) string { return "" }
+	return string(p.Markdown(r.commentParser.Parse(doc)))
+}
+
+// DocText formats the doc comment as plain text, with each line starting
+// with prefix.
+func (r *Renderer) DocText(doc, prefix string) string {
+	p := r.commentPrinter()
+	p.TextPrefix = prefix
+	p.TextCodePrefix = prefix + "\t"
+	return string(p.Text(r.commentParser.Parse(doc)))
+}
+
+func (r *Renderer) commentPrinter() *comment.Printer {
+	return &comment.Printer{DocLinkURL: r.docLinkURL}
+}
+
+// DeclText formats the decl as Go source code, trimming large literals as
+// DeclHTML does.
+func (r *Renderer) DeclText(decl ast.Decl) string {
+	decl = rewriteDecl(decl, maxStringSize, maxElements)
+	p := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 4}
+	var b bytes.Buffer
+	p.Fprint(&b, r.fset, decl)
+	return b.String()
+}
+
+// ExampleCode returns the code of the example as Go source code.
+func (r *Renderer) ExampleCode(ex *doc.Example) (string, error) {
+	return r.codeString(ex)
+}
@@ -0,0 +1,83 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dochtml
+
+import (
+	"go/ast"
+	"go/doc"
+	"go/token"
+	"sort"
+	"strings"
+
+	"golang.org/x/pkgsite/internal/godoc/dochtml/internal/render"
+)
+
+// A PromotedMethod is a method that a type has because one of its embedded
+// fields has it.
+type PromotedMethod struct {
+	TypeName string // the type with the method, e.g. "File"
+	Name     string // the name of the method, e.g. "Read"
+	Synopsis string // e.g. "func (File) Read(p []byte) (n int, err error)"
+	From     string // the type declaring the method, e.g. "io.Reader"
+	PkgPath  string // the import path of the package declaring From, e.g. "io"
+}
+
+// anchorID returns the ID of the documentation of the method in the package
+// declaring it.
+func (pm *PromotedMethod) anchorID() string {
+	return pm.From[strings.LastIndexByte(pm.From, '.')+1:] + "." + pm.Name
+}
+
+// isPromoted reports whether m is a method promoted from an embedded type
+// that is documented on its own. Methods promoted from unexported embedded
+// types are documented as methods of t.
+func isPromoted(m *doc.Func) bool {
+	return m.Level > 0 && token.IsExported(strings.TrimPrefix(m.Orig, "*"))
+}
+
+// declaredMethods returns the methods of t that are documented as its own.
+func declaredMethods(t *doc.Type) []*doc.Func {
+	var ms []*doc.Func
+	for _, m := range t.Methods {
+		if !isPromoted(m) {
+			ms = append(ms, m)
+		}
+	}
+	return ms
+}
+
+// promotedMethods returns the methods promoted to t from exported embedded
+// types, sorted by name. Those from types of p are found by go/doc (with
+// doc.AllMethods); others must be listed in extra.
+func promotedMethods(p *doc.Package, t *doc.Type, fset *token.FileSet, extra []*PromotedMethod) []*PromotedMethod {
+	var pms []*PromotedMethod
+	seen := map[string]bool{}
+	for _, m := range t.Methods {
+		seen[m.Name] = true
+		if !isPromoted(m) {
+			continue
+		}
+		// Omit the receiver name, which belongs to the original declaration.
+		decl := *m.Decl
+		recv := *decl.Recv.List[0]
+		recv.Names = nil
+		decl.Recv = &ast.FieldList{List: []*ast.Field{&recv}}
+		pms = append(pms, &PromotedMethod{
+			TypeName: t.Name,
+			Name:     m.Name,
+			Synopsis: render.OneLineNodeDepth(fset, &decl, 0),
+			From:     strings.TrimPrefix(m.Orig, "*"),
+			PkgPath:  p.ImportPath,
+		})
+	}
+	for _, pm := range extra {
+		if pm.TypeName == t.Name && !seen[pm.Name] {
+			seen[pm.Name] = true
+			pms = append(pms, pm)
+		}
+	}
+	sort.Slice(pms, func(i, j int) bool { return pms[i].Name < pms[j].Name })
+	return pms
+}
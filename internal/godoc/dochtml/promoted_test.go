@@ -0,0 +1,78 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dochtml
+
+import (
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPromotedMethods(t *testing.T) {
+	const src = `
+package p
+
+import "io"
+
+type Base struct{}
+
+func (b *Base) Close() error { return nil }
+
+type inner struct{}
+
+func (inner) Hidden() {}
+
+type T struct {
+	*Base
+	inner
+	io.Reader
+}
+
+func (T) Own() {}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := doc.NewFromFiles(fset, []*ast.File{f}, "example.com/p", doc.AllMethods)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var typ *doc.Type
+	for _, dt := range p.Types {
+		if dt.Name == "T" {
+			typ = dt
+		}
+	}
+	extra := []*PromotedMethod{
+		{TypeName: "T", Name: "Read", Synopsis: "func (T) Read(p []byte) (n int, err error)", From: "io.Reader", PkgPath: "io"},
+		{TypeName: "T", Name: "Close", Synopsis: "func (T) Close() error", From: "Base", PkgPath: "example.com/p"},
+		{TypeName: "U", Name: "Write", Synopsis: "func (U) Write(p []byte) (n int, err error)", From: "io.Writer", PkgPath: "io"},
+	}
+	got := promotedMethods(p, typ, fset, extra)
+	want := []*PromotedMethod{
+		{TypeName: "T", Name: "Close", Synopsis: "func (T) Close() error", From: "Base", PkgPath: "example.com/p"},
+		extra[0],
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("promotedMethods mismatch (-want +got):\n%s", diff)
+	}
+
+	var names []string
+	for _, m := range declaredMethods(typ) {
+		names = append(names, m.Name)
+	}
+	if diff := cmp.Diff([]string{"Hidden", "Own"}, names); diff != "" {
+		t.Errorf("declaredMethods mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := got[1].anchorID(), "Reader.Read"; got != want {
+		t.Errorf("anchorID: got %q, want %q", got, want)
+	}
+}
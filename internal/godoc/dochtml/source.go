@@ -0,0 +1,80 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dochtml
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+
+	"github.com/google/safehtml"
+	"golang.org/x/pkgsite/internal/godoc/dochtml/internal/render"
+)
+
+// A SourceFile is a source file of a package, for RenderSource.
+type SourceFile struct {
+	Name     string // base name, e.g. "file.go"
+	Contents []byte
+}
+
+// RenderSource renders file as HTML for a source view, one element per line.
+//
+// Comments, string literals and keywords are highlighted. Each use of an
+// identifier links to its definition, whether in file or in one of pkgFiles,
+// the other files in the directory of file, as resolved by type-checking
+// them together, and each exported declaration links to its documentation in
+// the package at importPath, if that is not empty. identLinks maps the
+// offsets of identifiers in file to the declarations they refer to, in the
+// form of godoc.Package.IdentLinks; it resolves names declared in imported
+// packages, which are not loaded here. Go+ files, whose names end in ".gop",
+// are not parsed: only the names declared by the Go files of the package are
+// linked.
+func RenderSource(file *SourceFile, pkgFiles []*SourceFile, importPath string, identLinks map[int]string) []safehtml.HTML {
+	fset := token.NewFileSet()
+	var f *ast.File
+	if strings.HasSuffix(file.Name, ".go") {
+		// Render what can be parsed of a file with syntax errors.
+		f, _ = parser.ParseFile(fset, file.Name, file.Contents, parser.ParseComments|parser.SkipObjectResolution)
+	}
+
+	files := []*ast.File{}
+	if f != nil {
+		files = append(files, f)
+	}
+	contents := map[string][]byte{}
+	decls := map[string]render.SourceDef{}
+	for _, pf := range pkgFiles {
+		if pf.Name == file.Name || !strings.HasSuffix(pf.Name, ".go") {
+			continue
+		}
+		g, err := parser.ParseFile(fset, pf.Name, pf.Contents, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		if (f != nil && g.Name.Name != f.Name.Name) || (f == nil && strings.HasSuffix(g.Name.Name, "_test")) {
+			continue // a different package, like an external test package
+		}
+		files = append(files, g)
+		contents[pf.Name] = pf.Contents
+		if f != nil {
+			continue
+		}
+		for name, def := range render.PackageSourceDefs(pf.Contents, fset, g, pf.Name) {
+			if _, ok := decls[name]; !ok {
+				decls[name] = def
+			}
+		}
+	}
+	if f == nil {
+		return render.SourceHTML(file.Contents, fset, nil, render.SourceOptions{PackageDecls: decls})
+	}
+	return render.SourceHTML(file.Contents, fset, f, render.SourceOptions{
+		ImportPath:   importPath,
+		Uses:         render.SourceUses(importPath, fset, files),
+		PackageFiles: contents,
+		IdentLinks:   identLinks,
+	})
+}
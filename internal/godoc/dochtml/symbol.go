@@ -20,12 +20,16 @@ import (
 //
 // If any of the rendered documentation part HTML sizes exceeds the specified
 // limit, an error with ErrTooLarge in its chain will be returned.
-func GetSymbols(p *doc.Package, fset *token.FileSet) (_ []*internal.Symbol, err error) {
+//
+// Methods promoted to the types of p from embedded types are included as
+// methods of those types. Those from other packages must be listed in
+// promoted.
+func GetSymbols(p *doc.Package, fset *token.FileSet, promoted []*PromotedMethod) (_ []*internal.Symbol, err error) {
 	defer derrors.Wrap(&err, "GetSymbols for %q", p.ImportPath)
 	if docIsEmpty(p) {
 		return nil, nil
 	}
-	typs, err := types(p, fset)
+	typs, err := types(p, fset, promoted)
 	if err != nil {
 		return nil, err
 	}
@@ -44,14 +48,16 @@ func constants(consts []*doc.Value) []*internal.Symbol {
 			if n == "_" {
 				continue
 			}
-			syms = append(syms, &internal.Symbol{
+			s := &internal.Symbol{
 				SymbolMeta: internal.SymbolMeta{
 					Name:     n,
 					Synopsis: "const " + n,
 					Section:  internal.SymbolSectionConstants,
 					Kind:     internal.SymbolKindConstant,
 				},
-			})
+			}
+			setDeprecation(&s.SymbolMeta, c.Doc)
+			syms = append(syms, s)
 		}
 	}
 	return syms
@@ -73,15 +79,16 @@ func variables(vars []*doc.Value, fset *token.FileSet) (_ []*internal.Symbol, er
 					vs.Names = []*ast.Ident{ident}
 				}
 				syn := render.ConstOrVarSynopsis(&vs, fset, token.VAR, "", 0, 0)
-				syms = append(syms,
-					&internal.Symbol{
-						SymbolMeta: internal.SymbolMeta{
-							Name:     ident.Name,
-							Synopsis: syn,
-							Section:  internal.SymbolSectionVariables,
-							Kind:     internal.SymbolKindVariable,
-						},
-					})
+				s := &internal.Symbol{
+					SymbolMeta: internal.SymbolMeta{
+						Name:     ident.Name,
+						Synopsis: syn,
+						Section:  internal.SymbolSectionVariables,
+						Kind:     internal.SymbolKindVariable,
+					},
+				}
+				setDeprecation(&s.SymbolMeta, v.Doc)
+				syms = append(syms, s)
 			}
 
 		}
@@ -92,19 +99,21 @@ func variables(vars []*doc.Value, fset *token.FileSet) (_ []*internal.Symbol, er
 func functions(p *doc.Package, fset *token.FileSet) []*internal.Symbol {
 	var syms []*internal.Symbol
 	for _, f := range p.Funcs {
-		syms = append(syms, &internal.Symbol{
+		s := &internal.Symbol{
 			SymbolMeta: internal.SymbolMeta{
 				Name:     f.Name,
 				Synopsis: render.OneLineNodeDepth(fset, f.Decl, 0),
 				Section:  internal.SymbolSectionFunctions,
 				Kind:     internal.SymbolKindFunction,
 			},
-		})
+		}
+		setDeprecation(&s.SymbolMeta, f.Doc)
+		syms = append(syms, s)
 	}
 	return syms
 }
 
-func types(p *doc.Package, fset *token.FileSet) ([]*internal.Symbol, error) {
+func types(p *doc.Package, fset *token.FileSet, promoted []*PromotedMethod) ([]*internal.Symbol, error) {
 	var syms []*internal.Symbol
 	for _, typ := range p.Types {
 		specs := typ.Decl.Specs
@@ -115,7 +124,7 @@ func types(p *doc.Package, fset *token.FileSet) ([]*internal.Symbol, error) {
 		if !ok {
 			return nil, fmt.Errorf("unexpected type for Spec node: %q", typ.Name)
 		}
-		mthds, err := methodsForType(typ, spec, fset)
+		mthds, err := methodsForType(p, typ, spec, fset, promoted)
 		if err != nil {
 			return nil, err
 		}
@@ -127,6 +136,7 @@ func types(p *doc.Package, fset *token.FileSet) ([]*internal.Symbol, error) {
 				Kind:     internal.SymbolKindType,
 			},
 		}
+		setDeprecation(&t.SymbolMeta, typ.Doc)
 		fields := fieldsForType(typ.Name, spec, fset)
 		if err != nil {
 			return nil, err
@@ -177,13 +187,15 @@ func variablesForType(t *doc.Type, fset *token.FileSet) (_ []*internal.SymbolMet
 func functionsForType(t *doc.Type, fset *token.FileSet) []*internal.SymbolMeta {
 	var syms []*internal.SymbolMeta
 	for _, f := range t.Funcs {
-		syms = append(syms, &internal.SymbolMeta{
+		sm := &internal.SymbolMeta{
 			Name:       f.Name,
 			ParentName: t.Name,
 			Kind:       internal.SymbolKindFunction,
 			Synopsis:   render.OneLineNodeDepth(fset, f.Decl, 0),
 			Section:    internal.SymbolSectionTypes,
-		})
+		}
+		setDeprecation(sm, f.Doc)
+		syms = append(syms, sm)
 	}
 	return syms
 }
@@ -201,27 +213,40 @@ func fieldsForType(typName string, spec *ast.TypeSpec, fset *token.FileSet) []*i
 		for _, n := range f.Names {
 			synopsis := fmt.Sprintf("%s %s", n, render.OneLineNodeDepth(fset, f.Type, 0))
 			name := typName + "." + n.Name
-			syms = append(syms, &internal.SymbolMeta{
+			sm := &internal.SymbolMeta{
 				Name:       name,
 				ParentName: typName,
 				Kind:       internal.SymbolKindField,
 				Synopsis:   synopsis,
 				Section:    internal.SymbolSectionTypes,
-			})
+			}
+			setDeprecation(sm, f.Doc.Text())
+			syms = append(syms, sm)
 		}
 	}
 	return syms
 }
 
-func methodsForType(t *doc.Type, spec *ast.TypeSpec, fset *token.FileSet) ([]*internal.SymbolMeta, error) {
+func methodsForType(p *doc.Package, t *doc.Type, spec *ast.TypeSpec, fset *token.FileSet, promoted []*PromotedMethod) ([]*internal.SymbolMeta, error) {
 	var syms []*internal.SymbolMeta
-	for _, m := range t.Methods {
-		syms = append(syms, &internal.SymbolMeta{
+	for _, m := range declaredMethods(t) {
+		sm := &internal.SymbolMeta{
 			Name:       t.Name + "." + m.Name,
 			ParentName: t.Name,
 			Kind:       internal.SymbolKindMethod,
 			Synopsis:   render.OneLineNodeDepth(fset, m.Decl, 0),
 			Section:    internal.SymbolSectionTypes,
+		}
+		setDeprecation(sm, m.Doc)
+		syms = append(syms, sm)
+	}
+	for _, pm := range promotedMethods(p, t, fset, promoted) {
+		syms = append(syms, &internal.SymbolMeta{
+			Name:       t.Name + "." + pm.Name,
+			ParentName: t.Name,
+			Kind:       internal.SymbolKindMethod,
+			Synopsis:   pm.Synopsis,
+			Section:    internal.SymbolSectionTypes,
 		})
 	}
 	if st, ok := spec.Type.(*ast.InterfaceType); ok {
@@ -235,15 +260,22 @@ func methodsForType(t *doc.Type, spec *ast.TypeSpec, fset *token.FileSet) ([]*in
 			for _, n := range m.Names {
 				name := t.Name + "." + n.Name
 				synopsis := render.OneLineField(fset, m, 0)
-				syms = append(syms, &internal.SymbolMeta{
+				sm := &internal.SymbolMeta{
 					Name:       name,
 					ParentName: t.Name,
 					Kind:       internal.SymbolKindMethod,
 					Synopsis:   synopsis,
 					Section:    internal.SymbolSectionTypes,
-				})
+				}
+				setDeprecation(sm, m.Doc.Text())
+				syms = append(syms, sm)
 			}
 		}
 	}
 	return syms, nil
 }
+
+// setDeprecation sets the deprecation fields of sm from its doc comment.
+func setDeprecation(sm *internal.SymbolMeta, doc string) {
+	sm.Deprecated, sm.Replacement = deprecation(doc)
+}
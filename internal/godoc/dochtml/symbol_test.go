@@ -17,7 +17,7 @@ func TestGetSymbols(t *testing.T) {
 		d := mustLoadPackage("symbols")
 	got,
 		err := GetSymbols(d,
-		fset)
+		fset, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,273 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dochtml
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/doc"
+	"go/token"
+	"strings"
+
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/godoc/dochtml/internal/render"
+)
+
+// A Format is a format for documentation other than HTML.
+type Format string
+
+const (
+	// FormatMarkdown is Markdown, with declarations and examples in fenced
+	// code blocks and doc links turned into Markdown links.
+	FormatMarkdown Format = "md"
+	// FormatText is plain text laid out like the output of "go doc -all".
+	FormatText Format = "txt"
+)
+
+// ParseFormat returns the Format named by s, which is "md" or "txt".
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatMarkdown, FormatText:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown documentation format %q", s)
+}
+
+// RenderText renders package documentation for the provided file set and
+// package in the given format. It covers the same declarations, examples and
+// links as Render.
+//
+// If the size of the result exceeds the limit, an error with ErrTooLarge in
+// its chain will be returned.
+func RenderText(ctx context.Context, fset *token.FileSet, p *doc.Package, opt RenderOptions, format Format) (_ []byte, err error) {
+	defer derrors.Wrap(&err, "dochtml.RenderText(%q)", format)
+
+	if opt.Limit == 0 {
+		const megabyte = 1000 * 1000
+		opt.Limit = 10 * megabyte
+	}
+	r, _, data := renderInfo(ctx, fset, p, opt)
+	var w textWriter
+	switch format {
+	case FormatMarkdown:
+		w = &markdownWriter{r: r}
+	case FormatText:
+		w = &plainWriter{r: r}
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	w.header(data.Package)
+	for _, ex := range data.Examples.Map[""] {
+		w.example(ex, 2)
+	}
+	if len(data.Consts) > 0 {
+		w.section("Constants")
+		for _, c := range data.Consts {
+			w.item(c, 0)
+		}
+	}
+	if len(data.Vars) > 0 {
+		w.section("Variables")
+		for _, v := range data.Vars {
+			w.item(v, 0)
+		}
+	}
+	if len(data.Funcs) > 0 {
+		w.section("Functions")
+		for _, f := range data.Funcs {
+			w.item(f, 3)
+		}
+	}
+	if len(data.Types) > 0 {
+		w.section("Types")
+		for _, t := range data.Types {
+			w.item(t, 3)
+			for _, c := range t.Consts {
+				w.item(c, 0)
+			}
+			for _, v := range t.Vars {
+				w.item(v, 0)
+			}
+			for _, f := range t.Funcs {
+				w.item(f, 3)
+			}
+			for _, m := range t.Methods {
+				w.item(m, 3)
+			}
+		}
+	}
+	b := w.bytes()
+	if int64(len(b)) > opt.Limit {
+		return nil, ErrTooLarge
+	}
+	return b, nil
+}
+
+// A textWriter writes documentation in a Format.
+type textWriter interface {
+	header(p *doc.Package)
+	section(title string)
+	// item writes the item, with a heading of the given level if it is
+	// positive.
+	item(it *item, level int)
+	// example writes the example, with a heading one level below the given
+	// level.
+	example(ex *example, level int)
+	bytes() []byte
+}
+
+// itemHeading returns the heading of an item, like "func (T) M".
+func itemHeading(it *item) string {
+	return it.HeaderStart + " " + it.Name
+}
+
+// exampleTitle returns the title of an example, like "Example (Suffix)".
+func exampleTitle(ex *example) string {
+	if ex.Suffix != "" {
+		return "Example (" + ex.Suffix + ")"
+	}
+	return "Example"
+}
+
+// outputLabel returns the label of the output of an example, which notes
+// whether the output was verified.
+func outputLabel(ex *example) string {
+	switch ex.OutputStatus {
+	case "verified":
+		return "Output (verified):"
+	case "mismatch":
+		return "Output (mismatch):"
+	}
+	return "Output:"
+}
+
+type markdownWriter struct {
+	r   *render.Renderer
+	buf bytes.Buffer
+}
+
+func (w *markdownWriter) header(p *doc.Package) {
+	fmt.Fprintf(&w.buf, "# package %s\n\n", p.Name)
+	fmt.Fprintf(&w.buf, "```go\nimport %q\n```\n\n", p.ImportPath)
+	if p.Doc != "" {
+		w.buf.WriteString(w.r.DocMarkdown(p.Doc, 3))
+		w.buf.WriteString("\n")
+	}
+	if bugs := p.Notes["BUG"]; len(bugs) > 0 {
+		w.section("Bugs")
+		for _, n := range bugs {
+			w.buf.WriteString("- " + strings.ReplaceAll(strings.TrimSpace(n.Body), "\n", "\n  ") + "\n")
+		}
+		w.buf.WriteString("\n")
+	}
+}
+
+func (w *markdownWriter) section(title string) {
+	fmt.Fprintf(&w.buf, "## %s\n\n", title)
+}
+
+func (w *markdownWriter) item(it *item, level int) {
+	if level > 0 {
+		fmt.Fprintf(&w.buf, "%s %s\n\n", strings.Repeat("#", level), itemHeading(it))
+	}
+	fmt.Fprintf(&w.buf, "```go\n%s\n```\n\n", w.r.DeclText(it.Decl))
+	if it.Doc != "" {
+		w.buf.WriteString(w.r.DocMarkdown(it.Doc, level+1))
+		w.buf.WriteString("\n")
+	}
+	for _, ex := range it.Examples {
+		w.example(ex, level)
+	}
+}
+
+func (w *markdownWriter) example(ex *example, level int) {
+	code, err := w.r.ExampleCode(ex.Example)
+	if err != nil {
+		return
+	}
+	if level < 2 {
+		level = 2
+	}
+	fmt.Fprintf(&w.buf, "%s %s\n\n", strings.Repeat("#", level+1), exampleTitle(ex))
+	if ex.Doc != "" {
+		w.buf.WriteString(w.r.DocMarkdown(ex.Doc, level+2))
+		w.buf.WriteString("\n")
+	}
+	fmt.Fprintf(&w.buf, "```go\n%s\n```\n\n", strings.TrimSpace(code))
+	if ex.Output != "" || ex.EmptyOutput {
+		fmt.Fprintf(&w.buf, "%s\n\n```\n%s\n```\n\n", outputLabel(ex), strings.TrimSuffix(ex.Output, "\n"))
+	}
+}
+
+func (w *markdownWriter) bytes() []byte { return w.buf.Bytes() }
+
+// A plainWriter writes documentation like "go doc -all": declarations
+// followed by their indented documentation, under upper-case section titles.
+type plainWriter struct {
+	r   *render.Renderer
+	buf bytes.Buffer
+}
+
+const plainIndent = "    "
+
+func (w *plainWriter) header(p *doc.Package) {
+	fmt.Fprintf(&w.buf, "package %s // import %q\n\n", p.Name, p.ImportPath)
+	if p.Doc != "" {
+		w.buf.WriteString(w.r.DocText(p.Doc, ""))
+		w.buf.WriteString("\n")
+	}
+	if bugs := p.Notes["BUG"]; len(bugs) > 0 {
+		w.section("Bugs")
+		for _, n := range bugs {
+			w.buf.WriteString(indent(strings.TrimSpace(n.Body), plainIndent) + "\n\n")
+		}
+	}
+}
+
+func (w *plainWriter) section(title string) {
+	fmt.Fprintf(&w.buf, "%s\n\n", strings.ToUpper(title))
+}
+
+func (w *plainWriter) item(it *item, level int) {
+	w.buf.WriteString(w.r.DeclText(it.Decl))
+	w.buf.WriteString("\n")
+	if it.Doc != "" {
+		w.buf.WriteString(w.r.DocText(it.Doc, plainIndent))
+	}
+	w.buf.WriteString("\n")
+	for _, ex := range it.Examples {
+		w.example(ex, level)
+	}
+}
+
+func (w *plainWriter) example(ex *example, level int) {
+	code, err := w.r.ExampleCode(ex.Example)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(&w.buf, "%s%s:\n", plainIndent, exampleTitle(ex))
+	if ex.Doc != "" {
+		w.buf.WriteString(w.r.DocText(ex.Doc, plainIndent+plainIndent))
+	}
+	w.buf.WriteString(indent(strings.TrimSpace(code), plainIndent+plainIndent) + "\n\n")
+	if ex.Output != "" || ex.EmptyOutput {
+		fmt.Fprintf(&w.buf, "%s%s\n", plainIndent, outputLabel(ex))
+		w.buf.WriteString(indent(strings.TrimSuffix(ex.Output, "\n"), plainIndent+plainIndent) + "\n\n")
+	}
+}
+
+func (w *plainWriter) bytes() []byte { return w.buf.Bytes() }
+
+// indent prefixes each non-empty line of s with prefix.
+func indent(s, prefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = prefix + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dochtml
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRenderText(t *testing.T) {
+	fset, d := mustLoadPackage("everydecl")
+	for _, test := range []struct {
+		format Format
+		want   []string
+	}{
+		{
+			FormatMarkdown,
+			[]string{
+				"# package everydecl\n\n```go\nimport \"everydecl\"\n```\n",
+				"\n### Links\n",
+				"[https://play-with-go.dev](https://play-with-go.dev)",
+				"## Constants\n\n```go\nconst C = 1\n```\n\nconst\n",
+				"## Types\n\n### type A\n",
+				"### func (T) M\n\n```go\nfunc (T) M()\n```\n",
+			},
+		},
+		{
+			FormatText,
+			[]string{
+				"package everydecl // import \"everydecl\"\n",
+				"CONSTANTS\n\nconst C = 1\n    const\n",
+				"TYPES\n\ntype A int\n",
+				"func TF() T\n    typeFunc\n",
+			},
+		},
+	} {
+		t.Run(string(test.format), func(t *testing.T) {
+			b, err := RenderText(context.Background(), fset, d, testRenderOptions, test.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := string(b)
+			for _, w := range test.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("output does not contain %q:\n%s", w, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderTextExamples(t *testing.T) {
+	fset, d := mustLoadPackage("example_test")
+	b, err := RenderText(context.Background(), fset, d, testRenderOptions, FormatMarkdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(b)
+	for _, w := range []string{
+		"### Example (StringsCompare)\n\nexecutable example\n\n```go\npackage main\n",
+		"Output:\n\n```\n-1\n0\n1\n```\n",
+	} {
+		if !strings.Contains(got, w) {
+			t.Errorf("output does not contain %q:\n%s", w, got)
+		}
+	}
+}
+
+func TestRenderTextOutputStatus(t *testing.T) {
+	fset, d := mustLoadPackage("example_test")
+	opts := testRenderOptions
+	opts.ExampleResults = map[string]bool{"Example_stringsCompare": true}
+	b, err := RenderText(context.Background(), fset, d, opts, FormatText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "    Output (verified):\n        -1\n"; !strings.Contains(string(b), want) {
+		t.Errorf("output does not contain %q:\n%s", want, b)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, s := range []string{"md", "txt"} {
+		if f, err := ParseFormat(s); err != nil || string(f) != s {
+			t.Errorf("ParseFormat(%q) = %q, %v", s, f, err)
+		}
+	}
+	if _, err := ParseFormat("html"); err == nil {
+		t.Error("ParseFormat(\"html\"): got nil error")
+	}
+}
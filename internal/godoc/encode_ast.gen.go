@@ -10,6 +10,7 @@ import (
 	"go/ast"
 	"go/token"
 	"golang.org/x/pkgsite/internal/godoc/codec"
+	"golang.org/x/pkgsite/internal/godoc/dochtml"
 )
 
 // Fields of ast_ArrayType: Lbrack Len Elt
@@ -3493,7 +3494,7 @@ func init() {
 		})
 }
 
-// Fields of encPackage: GOOS GOARCH Files ModulePackagePaths
+// Fields of encPackage: GOOS GOARCH Files ModulePackagePaths IdentLinks PromotedMethods Implementations ExampleResults
 
 func encode_encPackage(e *codec.Encoder, x *encPackage) {
 	if !e.StartStruct(x == nil, x) {
@@ -3508,6 +3509,15 @@ func encode_encPackage(e *codec.Encoder, x *encPackage) {
 		e.EncodeUint(3)
 		encode_map_string_bool(e, x.ModulePackagePaths)
 	}
+	if x.IdentLinks != nil {
+		e.EncodeUint(4)
+		encode_map_token_Pos_string(e, x.IdentLinks)
+	}
+	if x.PromotedMethods != nil {
+		e.EncodeUint(5)
+		encode_slice_dochtml_PromotedMethod(e, x.PromotedMethods)
+	}
+
 	e.EndStruct()
 }
 
@@ -3532,6 +3542,10 @@ func decode_encPackage(d *codec.Decoder, p **encPackage) {
 			decode_slice_File(d, &x.Files)
 		case 3:
 			decode_map_string_bool(d, &x.ModulePackagePaths)
+		case 4:
+			decode_map_token_Pos_string(d, &x.IdentLinks)
+		case 5:
+			decode_slice_dochtml_PromotedMethod(d, &x.PromotedMethods)
 		default:
 			d.UnknownField("encPackage", n)
 		}
@@ -3899,6 +3913,74 @@ func init() {
 		func(d *codec.Decoder) any { var x map[string]bool; decode_map_string_bool(d, &x); return x })
 }
 
+func encode_map_token_Pos_string(e *codec.Encoder, m map[token.Pos]string) {
+	if m == nil {
+		e.EncodeNil()
+		return
+	}
+	e.StartList(2 * len(m))
+	for k, v := range m {
+		e.EncodeInt(int64(k))
+		e.EncodeString(v)
+	}
+}
+
+func decode_map_token_Pos_string(d *codec.Decoder, p *map[token.Pos]string) {
+	n2 := d.StartList()
+	if n2 < 0 {
+		return
+	}
+	n := n2 / 2
+	m := make(map[token.Pos]string, n)
+	var k token.Pos
+	var v string
+	for i := 0; i < n; i++ {
+		k = token.Pos(d.DecodeInt())
+		v = d.DecodeString()
+		m[k] = v
+	}
+	*p = m
+}
+
+func init() {
+	codec.Register(map[token.Pos]string(nil),
+		func(e *codec.Encoder, x any) { encode_map_token_Pos_string(e, x.(map[token.Pos]string)) },
+		func(d *codec.Decoder) any { var x map[token.Pos]string; decode_map_token_Pos_string(d, &x); return x })
+}
+
+func encode_slice_dochtml_PromotedMethod(e *codec.Encoder, s []*dochtml.PromotedMethod) {
+	if s == nil {
+		e.EncodeNil()
+		return
+	}
+	e.StartList(len(s))
+	for _, x := range s {
+		encode_dochtml_PromotedMethod(e, x)
+	}
+}
+
+func decode_slice_dochtml_PromotedMethod(d *codec.Decoder, p *[]*dochtml.PromotedMethod) {
+	n := d.StartList()
+	if n < 0 {
+		return
+	}
+	s := make([]*dochtml.PromotedMethod, n)
+	for i := 0; i < n; i++ {
+		decode_dochtml_PromotedMethod(d, &s[i])
+	}
+	*p = s
+}
+
+func init() {
+	codec.Register([]*dochtml.PromotedMethod(nil),
+		func(e *codec.Encoder, x any) { encode_slice_dochtml_PromotedMethod(e, x.([]*dochtml.PromotedMethod)) },
+		func(d *codec.Decoder) any {
+			var x []*dochtml.PromotedMethod
+			decode_slice_dochtml_PromotedMethod(d, &x)
+			return x
+		})
+}
+
 // Fields of File: Name AST
 
 func encode_File(e *codec.Encoder, x *File) {
@@ -3954,6 +4036,79 @@ func init() {
 		})
 }
 
+// Fields of dochtml_PromotedMethod: TypeName Name Synopsis From PkgPath
+
+func encode_dochtml_PromotedMethod(e *codec.Encoder, x *dochtml.PromotedMethod) {
+	if !e.StartStruct(x == nil, x) {
+		return
+	}
+	if x.TypeName != "" {
+		e.EncodeUint(0)
+		e.EncodeString(x.TypeName)
+	}
+	if x.Name != "" {
+		e.EncodeUint(1)
+		e.EncodeString(x.Name)
+	}
+	if x.Synopsis != "" {
+		e.EncodeUint(2)
+		e.EncodeString(x.Synopsis)
+	}
+	if x.From != "" {
+		e.EncodeUint(3)
+		e.EncodeString(x.From)
+	}
+	if x.PkgPath != "" {
+		e.EncodeUint(4)
+		e.EncodeString(x.PkgPath)
+	}
+	e.EndStruct()
+}
+
+func decode_dochtml_PromotedMethod(d *codec.Decoder, p **dochtml.PromotedMethod) {
+	proceed, ref := d.StartStruct()
+	if !proceed {
+		return
+	}
+	if ref != nil {
+		*p = ref.(*dochtml.PromotedMethod)
+		return
+	}
+	var x dochtml.PromotedMethod
+	d.StoreRef(&x)
+	for {
+		n := d.NextStructField()
+		if n < 0 {
+			break
+		}
+		switch n {
+		case 0:
+			x.TypeName = d.DecodeString()
+		case 1:
+			x.Name = d.DecodeString()
+		case 2:
+			x.Synopsis = d.DecodeString()
+		case 3:
+			x.From = d.DecodeString()
+		case 4:
+			x.PkgPath = d.DecodeString()
+		default:
+			d.UnknownField("dochtml.PromotedMethod", n)
+		}
+		*p = &x
+	}
+}
+
+func init() {
+	codec.Register(&dochtml.PromotedMethod{},
+		func(e *codec.Encoder, x any) { encode_dochtml_PromotedMethod(e, x.(*dochtml.PromotedMethod)) },
+		func(d *codec.Decoder) any {
+			var x *dochtml.PromotedMethod
+			decode_dochtml_PromotedMethod(d, &x)
+			return x
+		})
+}
+
 // Fields of ast_File: Doc Package Name Decls Scope Imports Unresolved Comments
 
 func encode_ast_File(e *codec.Encoder, x *ast.File) {
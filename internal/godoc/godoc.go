@@ -17,11 +17,33 @@ var ErrTooLarge = dochtml.ErrTooLarge
 
 type ModuleInfo = dochtml.ModuleInfo
 
+type PromotedMethod = dochtml.PromotedMethod
+
+type Implementation = dochtml.Implementation
+
+type Format = dochtml.Format
+
 // A Package contains package-level information needed to render Go documentation.
 type Package struct {
 	Fset *token.FileSet
 	encPackage
-	renderCalled bool
+	// SymbolVulns optionally maps the full names of the package's symbols
+	// to the IDs of the vulnerabilities that affect them. Unlike the fields
+	// of encPackage, it is not encoded: vulnerabilities are looked up when
+	// the package is rendered.
+	SymbolVulns map[string][]string
+	// Implementations optionally lists the interfaces that the package's
+	// types implement and the types of the module that implement the
+	// package's interfaces, as determined by type-checking. It is not
+	// encoded either: it is stored with the package's documentation.
+	Implementations []*Implementation
+	// ExampleResults optionally maps the names of the package's example
+	// functions, like "ExampleT_M", to whether their output matched their
+	// output comments when they were run. Examples that were not run are
+	// omitted. Like Implementations, it is stored with the package's
+	// documentation.
+	ExampleResults map[string]bool
+	renderCalled   bool
 }
 
 // encPackage holds the fields of Package that can be directly encoded.
@@ -39,6 +61,16 @@ type Package struct {
 type encPackage struct { // fields that can be directly encoded
 	Files              []*File
 	ModulePackagePaths map[string]bool
+	// IdentLinks optionally maps the positions of identifiers in Files to
+	// the declarations they refer to, as determined by type-checking the
+	// package. Each value is the import path of the declaring package,
+	// followed by "#" and the anchor ID of the declaration unless the
+	// identifier is a package name: for example, "io#Reader.Read".
+	IdentLinks map[token.Pos]string
+	// PromotedMethods optionally lists the methods that the package's types
+	// have because of their embedded fields, as determined by type-checking.
+	// Methods promoted from types in the package itself need not be listed.
+	PromotedMethods []*PromotedMethod
 }
 
 // A File contains everything needed about a source file to render documentation.
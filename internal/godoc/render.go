@@ -31,7 +31,8 @@ const (
 	maxImportsPerPackage = 5000
 
 	// Exported for tests.
-	DocTooLargeReplacement = `<p>Documentation is too large to display.</p>`
+	DocTooLargeReplacement     = `<p>Documentation is too large to display.</p>`
+	DocTooLargeTextReplacement = "Documentation is too large to display.\n"
 )
 
 // MaxDocumentationHTML is a limit on the rendered documentation HTML size.
@@ -55,7 +56,7 @@ func (p *Package) DocInfo(ctx context.Context, innerPath string, sourceInfo *sou
 		return "", nil, nil, err
 	}
 
-	api, err = dochtml.GetSymbols(d, p.Fset)
+	api, err = dochtml.GetSymbols(d, p.Fset, p.PromotedMethods)
 	if err != nil {
 		return "", nil, nil, err
 	}
@@ -111,7 +112,9 @@ func (p *Package) DocPackage(innerPath string, modInfo *ModuleInfo) (_ *doc.Pack
 	}
 
 	// Compute package documentation.
-	var m doc.Mode
+	// Include methods promoted from exported embedded types, so that they
+	// can be listed with the embedding type.
+	m := doc.AllMethods
 	if noFiltering {
 		m |= doc.AllDecls
 	}
@@ -171,11 +174,27 @@ func (p *Package) renderOptions(innerPath string, sourceInfo *source.Info, modIn
 		SourceLinkFunc:   sourceLinkFunc,
 		ModInfo:          modInfo,
 		SinceVersionFunc: sinceVersionFunc(modInfo.ModulePath, nameToVersion),
+		ResolveIdentFunc: p.resolveIdent,
+		PromotedMethods:  p.PromotedMethods,
+		Implementations:  p.Implementations,
+		ExampleResults:   p.ExampleResults,
+		SymbolVulns:      p.SymbolVulns,
 		Limit:            int64(MaxDocumentationHTML),
 		BuildContext:     bc,
 	}
 }
 
+// resolveIdent reports the declaration that id refers to, if it was
+// determined when the package was loaded. See encPackage.IdentLinks.
+func (p *Package) resolveIdent(id *ast.Ident) (pkgPath, anchorID string, ok bool) {
+	link, ok := p.IdentLinks[id.Pos()]
+	if !ok {
+		return "", "", false
+	}
+	pkgPath, anchorID, _ = strings.Cut(link, "#")
+	return pkgPath, anchorID, true
+}
+
 // sinceVersionFunc returns a func that reports the version when the symbol
 // with name was first introduced.  nameToVersion is a map of symbol name to
 // the first version that symbol name was seen in the package.
@@ -241,24 +260,69 @@ func (p *Package) Render(ctx context.Context, innerPath string,
 	return parts, nil
 }
 
+// RenderText renders the documentation for the package in the given format.
+// Rendering destroys p's AST; do not call any methods of p after it returns.
+func (p *Package) RenderText(ctx context.Context, innerPath string,
+	sourceInfo *source.Info, modInfo *ModuleInfo, nameToVersion map[string]string,
+	bc internal.BuildContext, format Format) (_ []byte, err error) {
+	p.renderCalled = true
+
+	d, err := p.DocPackage(innerPath, modInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := p.renderOptions(innerPath, sourceInfo, modInfo, nameToVersion, bc)
+	b, err := dochtml.RenderText(ctx, p.Fset, d, opts, format)
+	if errors.Is(err, ErrTooLarge) {
+		return []byte(DocTooLargeTextReplacement), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dochtml.RenderText: %v", err)
+	}
+	return b, nil
+}
+
 // RenderFromUnit is a convenience function that first decodes the source
 // in the unit, which must exist, and then calls Render.
 func RenderFromUnit(ctx context.Context, u *internal.Unit,
 	bc internal.BuildContext) (_ *dochtml.Parts, err error) {
-	docPkg, err := DecodePackage(u.Documentation[0].Source)
+	docPkg, innerPath, modInfo, err := decodeUnit(u)
+	if err != nil {
+		return nil, err
+	}
+	return docPkg.Render(ctx, innerPath, u.SourceInfo, modInfo, nil, bc)
+}
+
+// RenderTextFromUnit is a convenience function that first decodes the source
+// in the unit, which must exist, and then calls RenderText.
+func RenderTextFromUnit(ctx context.Context, u *internal.Unit,
+	bc internal.BuildContext, format Format) (_ []byte, err error) {
+	docPkg, innerPath, modInfo, err := decodeUnit(u)
 	if err != nil {
 		return nil, err
 	}
+	return docPkg.RenderText(ctx, innerPath, u.SourceInfo, modInfo, nil, bc, format)
+}
+
+// decodeUnit decodes the documentation source of u, and returns it along with
+// the arguments for rendering it.
+func decodeUnit(u *internal.Unit) (_ *Package, innerPath string, _ *ModuleInfo, err error) {
+	docPkg, err := DecodePackage(u.Documentation[0].Source)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	docPkg.Implementations = u.Documentation[0].Implementations
+	docPkg.ExampleResults = u.Documentation[0].ExampleResults
 	modInfo := &ModuleInfo{
 		ModulePath:      u.ModulePath,
 		ResolvedVersion: u.Version,
 		ModulePackages:  nil, // will be provided by docPkg
 	}
-	var innerPath string
 	if u.ModulePath == stdlib.ModulePath {
 		innerPath = u.Path
 	} else if u.Path != u.ModulePath {
 		innerPath = u.Path[len(u.ModulePath)+1:]
 	}
-	return docPkg.Render(ctx, innerPath, u.SourceInfo, modInfo, nil, bc)
+	return docPkg, innerPath, modInfo, nil
 }
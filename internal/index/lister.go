@@ -0,0 +1,296 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package index
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/proxy"
+)
+
+// A Source provides the module versions that have been published since a
+// given time, like the module index.
+type Source interface {
+	// GetVersions returns up to limit versions published after since, oldest
+	// first.
+	GetVersions(ctx context.Context, since time.Time, limit int) ([]*internal.IndexVersion, error)
+}
+
+var (
+	_ Source = (*Client)(nil)
+	_ Source = (*ProxyLister)(nil)
+	_ Source = (*DirLister)(nil)
+)
+
+// A ProxyLister is a Source for proxies that don't serve an index, like most
+// private proxies. It discovers the versions of the modules under a set of
+// module path prefixes by listing them on the proxy.
+//
+// A proxy can list the versions of a module, but not the modules under a
+// prefix. So a prefix covers the module whose path is the prefix, if there is
+// one, and the modules under it that the worker already knows about, because
+// it has processed a version of them. A new module under a prefix is
+// discovered once a version of it has been fetched some other way, for
+// instance by requesting it on the frontend.
+//
+// A version is new if the worker has no state for it, so a ProxyLister
+// doesn't depend on since or on anything it remembers between calls. The
+// timestamp of a version is its commit time from the proxy's .info endpoint,
+// since a proxy doesn't say when it first served a version.
+type ProxyLister struct {
+	proxyClient    *proxy.Client
+	modulePrefixes []string
+	known          KnownVersionsGetter
+}
+
+// A KnownVersionsGetter reports the module versions that the worker has
+// processed or is going to process.
+type KnownVersionsGetter interface {
+	// GetKnownVersions returns the known versions of the modules whose paths
+	// are modulePrefix or begin with modulePrefix followed by a slash, by
+	// module path.
+	GetKnownVersions(ctx context.Context, modulePrefix string) (map[string][]string, error)
+}
+
+// NewProxyLister returns a ProxyLister that lists the versions of the modules
+// under modulePrefixes using proxyClient, and skips the versions that known
+// reports.
+func NewProxyLister(proxyClient *proxy.Client, modulePrefixes []string, known KnownVersionsGetter) *ProxyLister {
+	return &ProxyLister{
+		proxyClient:    proxyClient,
+		modulePrefixes: modulePrefixes,
+		known:          known,
+	}
+}
+
+// GetVersions lists the versions of the ProxyLister's modules and returns up
+// to limit of the unknown ones, oldest first. It ignores since.
+func (l *ProxyLister) GetVersions(ctx context.Context, since time.Time, limit int) (_ []*internal.IndexVersion, err error) {
+	defer derrors.Wrap(&err, "ProxyLister.GetVersions(ctx, %s, %d)", since, limit)
+
+	var versions []*internal.IndexVersion
+	done := map[string]bool{}
+	for _, prefix := range l.modulePrefixes {
+		prefix = strings.TrimSuffix(prefix, "/")
+		known, err := l.known.GetKnownVersions(ctx, prefix)
+		if err != nil {
+			return nil, err
+		}
+		modulePaths := []string{prefix}
+		for p := range known {
+			if p != prefix {
+				modulePaths = append(modulePaths, p)
+			}
+		}
+		sort.Strings(modulePaths[1:])
+		for _, modulePath := range modulePaths {
+			if done[modulePath] {
+				// Covered by more than one prefix.
+				continue
+			}
+			done[modulePath] = true
+			vs, err := l.listUnknown(ctx, modulePath, known[modulePath])
+			if err != nil {
+				return nil, err
+			}
+			versions = append(versions, vs...)
+		}
+	}
+	return oldestFirst(versions, limit), nil
+}
+
+// listUnknown returns the versions of the module that the proxy lists and
+// that aren't in known.
+func (l *ProxyLister) listUnknown(ctx context.Context, modulePath string, known []string) ([]*internal.IndexVersion, error) {
+	vs, err := l.proxyClient.Versions(ctx, modulePath)
+	if errors.Is(err, derrors.NotFound) {
+		// A prefix need not be a module, and a module may not be published
+		// yet. Don't let either hold up the others.
+		log.Debugf(ctx, "ProxyLister: %v", err)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	isKnown := map[string]bool{}
+	for _, v := range known {
+		isKnown[v] = true
+	}
+	var versions []*internal.IndexVersion
+	for _, v := range vs {
+		if isKnown[v] {
+			continue
+		}
+		info, err := l.proxyClient.Info(ctx, modulePath, v)
+		if err != nil {
+			return nil, err
+		}
+		if isKnown[info.Version] {
+			continue
+		}
+		versions = append(versions, &internal.IndexVersion{
+			Path:      modulePath,
+			Version:   info.Version,
+			Timestamp: info.Time,
+		})
+	}
+	return versions, nil
+}
+
+// A DirLister is a Source that discovers versions in the storage directory of
+// a proxy that keeps its modules on disk. It understands two layouts: that of
+// the module download cache, which is also that of a file:// GOPROXY, where
+// the versions of a module are described by MODULE/@v/VERSION.info files, and
+// that of the Athens proxy, where they are described by
+// MODULE/VERSION/version.info files.
+//
+// The timestamp of a version is the modification time of its .info file,
+// which is when the proxy stored it. Since storing a new version adds a file
+// to its @v directory, a DirLister skips @v directories that haven't been
+// modified after since.
+type DirLister struct {
+	dir string
+}
+
+// NewDirLister returns a DirLister for the storage directory dir.
+func NewDirLister(dir string) *DirLister {
+	return &DirLister{dir: dir}
+}
+
+// GetVersions returns up to limit of the versions stored after since, oldest
+// first.
+func (l *DirLister) GetVersions(ctx context.Context, since time.Time, limit int) (_ []*internal.IndexVersion, err error) {
+	defer derrors.Wrap(&err, "DirLister.GetVersions(ctx, %s, %d)", since, limit)
+	var versions []*internal.IndexVersion
+	err = filepath.WalkDir(l.dir, func(filename string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == "@v" && filename != l.dir {
+				fi, err := d.Info()
+				if err != nil {
+					return err
+				}
+				if !fi.ModTime().Truncate(time.Microsecond).After(since) {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		if !strings.HasSuffix(d.Name(), ".info") {
+			return nil
+		}
+		rel, err := filepath.Rel(l.dir, filename)
+		if err != nil {
+			return err
+		}
+		modulePath, version, ok := parseInfoPath(filepath.ToSlash(rel))
+		if !ok {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		// Postgres stores timestamps in microseconds. Truncate so that a
+		// version isn't considered newer than the timestamp stored for it.
+		mtime := fi.ModTime().Truncate(time.Microsecond)
+		if !mtime.After(since) {
+			return nil
+		}
+		// Prefer the version in the file, which is canonical.
+		if data, err := os.ReadFile(filename); err == nil {
+			var info proxy.VersionInfo
+			if json.Unmarshal(data, &info) == nil && info.Version != "" {
+				version = info.Version
+			}
+		}
+		versions = append(versions, &internal.IndexVersion{
+			Path:      modulePath,
+			Version:   version,
+			Timestamp: mtime,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return oldestFirst(versions, limit), nil
+}
+
+// parseInfoPath returns the module path and version described by the .info
+// file at the slash-separated path rel, or false if rel isn't the path of
+// one.
+func parseInfoPath(rel string) (modulePath, version string, ok bool) {
+	dir, file := path.Split(rel)
+	dir = strings.TrimSuffix(dir, "/")
+	if file == "version.info" {
+		// Athens: MODULE/VERSION/version.info.
+		modDir, version := path.Split(dir)
+		modDir = strings.TrimSuffix(modDir, "/")
+		if modDir == "" {
+			return "", "", false
+		}
+		return unescapePath(modDir), version, true
+	}
+	// Download cache: MODULE/@v/VERSION.info, with the module path and
+	// version escaped.
+	modDir, at := path.Split(dir)
+	modDir = strings.TrimSuffix(modDir, "/")
+	if at != "@v" || modDir == "" {
+		return "", "", false
+	}
+	version, err := module.UnescapeVersion(strings.TrimSuffix(file, ".info"))
+	if err != nil {
+		return "", "", false
+	}
+	return unescapePath(modDir), version, true
+}
+
+// unescapePath unescapes p if it is an escaped module path, and otherwise
+// returns it unchanged.
+func unescapePath(p string) string {
+	if u, err := module.UnescapePath(p); err == nil {
+		return u
+	}
+	return p
+}
+
+// oldestFirst sorts versions by timestamp, and returns the first limit of
+// them, or all of them if limit is not positive.
+//
+// The next poll asks for the versions after the latest timestamp returned,
+// so versions with the same timestamp are never split: if the limit falls
+// among them, all of them are returned.
+func oldestFirst(versions []*internal.IndexVersion, limit int) []*internal.IndexVersion {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].Timestamp.Before(versions[j].Timestamp)
+	})
+	if limit <= 0 || len(versions) <= limit {
+		return versions
+	}
+	n := limit
+	for n < len(versions) && versions[n].Timestamp.Equal(versions[limit-1].Timestamp) {
+		n++
+	}
+	return versions[:n]
+}
@@ -0,0 +1,196 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package index
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/proxy/proxytest"
+)
+
+func modvers(ivs []*internal.IndexVersion) []string {
+	var s []string
+	for _, iv := range ivs {
+		s = append(s, iv.Path+"@"+iv.Version)
+	}
+	return s
+}
+
+// fakeKnown is a KnownVersionsGetter that knows the versions in a map from
+// module path to versions.
+type fakeKnown map[string][]string
+
+func (k fakeKnown) GetKnownVersions(ctx context.Context, modulePrefix string) (map[string][]string, error) {
+	m := map[string][]string{}
+	for p, vs := range k {
+		if p == modulePrefix || strings.HasPrefix(p, modulePrefix+"/") {
+			m[p] = vs
+		}
+	}
+	return m, nil
+}
+
+func TestProxyLister(t *testing.T) {
+	ctx := context.Background()
+	mod := func(modulePath, version string) *proxytest.Module {
+		return &proxytest.Module{
+			ModulePath: modulePath,
+			Version:    version,
+			Files:      map[string]string{"p.go": "package p"},
+		}
+	}
+	s := proxytest.NewServer([]*proxytest.Module{
+		mod("example.com/private", "v1.0.0"),
+		mod("example.com/private", "v1.1.0"),
+		mod("example.com/private/nested", "v0.1.0"),
+		mod("example.com/private/nested", "v0.2.0"),
+		mod("example.com/private/unknown", "v0.1.0"),
+		mod("example.com/other", "v1.0.0"),
+	})
+	client, teardown, err := proxytest.NewClientForServer(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+
+	known := fakeKnown{}
+	check := func(l *ProxyLister, limit int, want []string) {
+		t.Helper()
+		got, err := l.GetVersions(ctx, time.Time{}, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, modvers(got)); diff != "" {
+			t.Errorf("mismatch (-want, +got):\n%s", diff)
+		}
+		// Record the versions, as the worker does.
+		for _, v := range got {
+			known[v.Path] = append(known[v.Path], v.Version)
+		}
+	}
+
+	l := NewProxyLister(client, []string{"example.com/private/", "example.com/missing"}, known)
+	// A module under a prefix isn't listed until it is known.
+	check(l, 0, []string{"example.com/private@v1.0.0", "example.com/private@v1.1.0"})
+	check(l, 0, nil)
+	known["example.com/private/nested"] = []string{"v0.1.0"}
+	check(l, 0, []string{"example.com/private/nested@v0.2.0"})
+
+	// The limit doesn't split versions with the same commit time.
+	s.AddModule(mod("example.com/private", "v1.2.0"))
+	s.AddModule(mod("example.com/private/nested", "v0.3.0"))
+	check(l, 1, []string{"example.com/private@v1.2.0", "example.com/private/nested@v0.3.0"})
+	// A new lister, as after a restart, doesn't return known versions.
+	s.AddModule(mod("example.com/private", "v1.3.0"))
+	l = NewProxyLister(client, []string{"example.com/private"}, known)
+	check(l, 0, []string{"example.com/private@v1.3.0"})
+	check(l, 0, nil)
+}
+
+func TestDirLister(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, f := range []string{
+		"example.com/!upper/@v/v1.0.0.info",      // download cache
+		"example.com/a/@v/v1.0.0.info",           // download cache
+		"example.com/a/@v/v1.0.0.mod",            // ignored
+		"example.com/athens/v0.1.0/version.info", // Athens
+		"example.com/athens/v0.1.0/go.mod",       // ignored
+		"cache/vcs/0123/info",                    // ignored
+		"example.com/a/@v/v1.1.0-!r!c1.info",     // newest
+	} {
+		filename := filepath.Join(dir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte("{}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := start.Add(time.Duration(i) * time.Hour)
+		if err := os.Chtimes(filename, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	l := NewDirLister(dir)
+	for _, test := range []struct {
+		since time.Time
+		limit int
+		want  []string
+	}{
+		{time.Time{}, 0, []string{
+			"example.com/Upper@v1.0.0",
+			"example.com/a@v1.0.0",
+			"example.com/athens@v0.1.0",
+			"example.com/a@v1.1.0-RC1",
+		}},
+		{time.Time{}, 2, []string{"example.com/Upper@v1.0.0", "example.com/a@v1.0.0"}},
+		{start.Add(time.Hour), 0, []string{"example.com/athens@v0.1.0", "example.com/a@v1.1.0-RC1"}},
+		{start.Add(6 * time.Hour), 0, nil},
+	} {
+		got, err := l.GetVersions(ctx, test.since, test.limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(test.want, modvers(got)); diff != "" {
+			t.Errorf("since %s, limit %d: mismatch (-want, +got):\n%s", test.since, test.limit, diff)
+		}
+	}
+}
+
+func TestDirListerTies(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	mtime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	write := func(f string, mtime time.Time) {
+		t.Helper()
+		filename := filepath.Join(dir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte("{}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(filename, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("example.com/a/@v/v1.0.0.info", mtime)
+	write("example.com/b/@v/v1.0.0.info", mtime)
+	write("example.com/c/@v/v1.0.0.info", mtime.Add(time.Hour))
+
+	l := NewDirLister(dir)
+	// The limit doesn't split versions with the same timestamp, which the
+	// next call, with since at that timestamp, would skip.
+	got, err := l.GetVersions(ctx, time.Time{}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"example.com/a@v1.0.0", "example.com/b@v1.0.0"}
+	if diff := cmp.Diff(want, modvers(got)); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	// An @v directory that wasn't modified after since is skipped.
+	vdir := filepath.Join(dir, "example.com", "c", "@v")
+	if err := os.Chtimes(vdir, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	got, err = l.GetVersions(ctx, mtime, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got %v, want none", modvers(got))
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/stdlib"
+	"golang.org/x/pkgsite/internal/version"
+)
+
+// This file holds the parts of inserting a module into a database that do not
+// depend on the database, so that the postgres and sqlite packages store
+// modules the same way.
+
+// PrepareModuleForInsert validates m with ValidateModuleForInsert and puts it
+// in the form in which it is stored: non-redistributable data is removed
+// unless bypassLicenseCheck is true, and units are sorted by path, with
+// sorted imports. Sorting ensures that rows are locked in the same order when
+// two versions of a module are inserted at once, avoiding deadlocks.
+func PrepareModuleForInsert(m *Module, bypassLicenseCheck bool) error {
+	if err := ValidateModuleForInsert(m); err != nil {
+		return err
+	}
+	if !bypassLicenseCheck {
+		m.RemoveNonRedistributableData()
+	}
+	for _, u := range m.Units {
+		for _, d := range u.Documentation {
+			if d.Source == nil {
+				return fmt.Errorf("unit %q missing source files for %q, %q", u.Path, d.GOOS, d.GOARCH)
+			}
+		}
+	}
+	sort.Slice(m.Units, func(i, j int) bool {
+		return m.Units[i].Path < m.Units[j].Path
+	})
+	for _, u := range m.Units {
+		sort.Strings(u.Imports)
+	}
+	return nil
+}
+
+// ValidateModuleForInsert checks that fields needed to insert a module into a
+// database are present. Otherwise, it returns an error listing the reasons the
+// module cannot be inserted. Since the problems it looks for are most likely
+// on our end, the underlying error it returns is always DBModuleInsertInvalid,
+// meaning that this module should be reprocessed.
+func ValidateModuleForInsert(m *Module) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("%v: %w", err, derrors.DBModuleInsertInvalid)
+			if m != nil {
+				derrors.WrapStack(&err, "ValidateModuleForInsert(%q, %q)", m.ModulePath, m.Version)
+			}
+		}
+	}()
+
+	if m == nil {
+		return fmt.Errorf("nil module")
+	}
+	var errReasons []string
+	if m.Version == "" {
+		errReasons = append(errReasons, "no specified version")
+	}
+	if m.ModulePath == "" {
+		errReasons = append(errReasons, "no module path")
+	}
+	if m.ModulePath != stdlib.ModulePath {
+		if err := module.CheckPath(m.ModulePath); err != nil {
+			errReasons = append(errReasons, fmt.Sprintf("invalid module path (%s)", err))
+		}
+		if !semver.IsValid(m.Version) {
+			errReasons = append(errReasons, "invalid version")
+		}
+	}
+	if len(m.Packages()) == 0 {
+		errReasons = append(errReasons, "module does not have any packages")
+	}
+	if len(errReasons) != 0 {
+		return fmt.Errorf("cannot insert module %q: %s", m.Version, strings.Join(errReasons, ", "))
+	}
+	return nil
+}
+
+// LicenseTypesAndPaths returns the license types of the unit, each with the
+// path of the license file it was detected in, as they are stored with the
+// unit.
+func (u *Unit) LicenseTypesAndPaths() (types, paths []string) {
+	for _, l := range u.Licenses {
+		if len(l.Types) == 0 {
+			// If a license file has no detected license types, we still need to
+			// record it as applicable to the package, because we want to fail
+			// closed (meaning if there is a LICENSE file containing unknown
+			// licenses, we assume them not to be permissive of redistribution.)
+			types = append(types, "")
+			paths = append(paths, l.FilePath)
+		} else {
+			for _, typ := range l.Types {
+				types = append(types, typ)
+				paths = append(paths, l.FilePath)
+			}
+		}
+	}
+	return types, paths
+}
+
+// GoodVersionsIncludeIncompatible reports whether incompatible versions of a
+// module are candidates for its latest good version, given the latest
+// versions of the module, which may be nil. If the cooked latest version is
+// incompatible, they are. If it isn't, then either there are no incompatible
+// versions, or there are but the latest compatible version has a go.mod file.
+// Either way, incompatible versions are ignored.
+func GoodVersionsIncludeIncompatible(lmv *LatestModuleVersions) bool {
+	return lmv == nil || version.IsIncompatible(lmv.CookedVersion)
+}
+
+// LatestGoodVersion returns the latest good version among the stored versions
+// vs of a module, given the latest versions of the module, which may be nil:
+// retracted versions and versions later than the cooked latest version are
+// not good. It returns the empty string if there is none.
+func LatestGoodVersion(vs []string, lmv *LatestModuleVersions) string {
+	if lmv != nil {
+		// Remove retracted versions.
+		vs = version.RemoveIf(vs, lmv.IsRetracted)
+		// The good version should never be later than the cooked version.
+		if lmv.CookedVersion != "" {
+			vs = version.RemoveIf(vs, func(v string) bool {
+				return version.Later(v, lmv.CookedVersion)
+			})
+		}
+	}
+	return version.LatestOf(vs)
+}
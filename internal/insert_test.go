@@ -0,0 +1,71 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/licenses"
+)
+
+func TestLicenseTypesAndPaths(t *testing.T) {
+	u := &Unit{
+		Licenses: []*licenses.Metadata{
+			{Types: []string{"MIT", "BSD-3-Clause"}, FilePath: "LICENSE"},
+			{FilePath: "UNKNOWN"},
+		},
+	}
+	gotTypes, gotPaths := u.LicenseTypesAndPaths()
+	if want := []string{"MIT", "BSD-3-Clause", ""}; !cmp.Equal(gotTypes, want) {
+		t.Errorf("types: got %v, want %v", gotTypes, want)
+	}
+	if want := []string{"LICENSE", "LICENSE", "UNKNOWN"}; !cmp.Equal(gotPaths, want) {
+		t.Errorf("paths: got %v, want %v", gotPaths, want)
+	}
+}
+
+func TestLatestGoodVersion(t *testing.T) {
+	const modulePath = "example.com/m"
+	lmv := func(cooked, goMod string) *LatestModuleVersions {
+		t.Helper()
+		l, err := NewLatestModuleVersions(modulePath, cooked, cooked, "", []byte(goMod))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return l
+	}
+	vs := []string{"v1.0.0", "v1.1.0", "v1.2.0", "v1.3.0-pre"}
+	for _, test := range []struct {
+		name string
+		lmv  *LatestModuleVersions
+		want string
+	}{
+		{"no latest versions", nil, "v1.2.0"},
+		{"retracted", lmv("v1.2.0", "module example.com/m\nretract v1.2.0"), "v1.1.0"},
+		{"later than cooked", lmv("v1.1.0", "module example.com/m"), "v1.1.0"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := LatestGoodVersion(vs, test.lmv); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPrepareModuleForInsertMissingSource(t *testing.T) {
+	m := &Module{
+		ModuleInfo: ModuleInfo{ModulePath: "example.com/m", Version: "v1.0.0"},
+		Units: []*Unit{{
+			UnitMeta:      UnitMeta{Path: "example.com/m", Name: "m"},
+			Documentation: []*Documentation{{GOOS: "linux", GOARCH: "amd64"}},
+		}},
+	}
+	err := PrepareModuleForInsert(m, true)
+	if err == nil || !strings.Contains(err.Error(), "missing source files") {
+		t.Errorf("got %v, want missing source files error", err)
+	}
+}
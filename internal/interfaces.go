@@ -16,6 +16,7 @@ type PostgresDB interface {
 	GetImportedBy(ctx context.Context, pkgPath, modulePath string, limit int) (paths []string, err error)
 	GetImportedByCount(ctx context.Context, pkgPath, modulePath string) (_ int, err error)
 	GetLatestMajorPathForV1Path(ctx context.Context, v1path string) (_ string, _ int, err error)
+	GetDeprecatedSymbols(ctx context.Context, modulePath, version string) (_ []*DeprecatedSymbol, err error)
 	GetStdlibPathsWithSuffix(ctx context.Context, suffix string) (paths []string, err error)
 	GetSymbolHistory(ctx context.Context, packagePath, modulePath string) (_ *SymbolHistory, err error)
 	GetVersionMap(ctx context.Context, modulePath, requestedVersion string) (_ *VersionMap, err error)
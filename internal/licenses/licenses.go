@@ -46,8 +46,8 @@ const (
 	// license text.
 	coverageThreshold = 75
 
-	// unknownLicenseType is for text in a license file that's not recognized.
-	unknownLicenseType = "UNKNOWN"
+	// UnknownLicenseType is for text in a license file that's not recognized.
+	UnknownLicenseType = "UNKNOWN"
 )
 
 // maxLicenseSize is the maximum allowable size (in bytes) for a license file.
@@ -270,9 +270,11 @@ type AcceptedLicenseInfo struct {
 }
 
 // AcceptedLicenses returns a sorted slice of license types that are accepted as
-// redistributable. Its result is intended to be displayed to users.
+// redistributable, including those accepted by the active policy. Its result
+// is intended to be displayed to users.
 func AcceptedLicenses() []AcceptedLicenseInfo {
 	var lics []AcceptedLicenseInfo
+	seen := map[string]bool{}
 	for _, identifier := range standardRedistributableLicenseTypes {
 		var link string
 		if nonOSILicenses[identifier] {
@@ -281,6 +283,24 @@ func AcceptedLicenses() []AcceptedLicenseInfo {
 			link = fmt.Sprintf("https://opensource.org/licenses/%s", identifier)
 		}
 		lics = append(lics, AcceptedLicenseInfo{identifier, link})
+		seen[identifier] = true
+	}
+	if policy != nil {
+		customURLs := map[string]string{}
+		for _, l := range policy.Licenses {
+			customURLs[l.Type] = l.URL
+		}
+		for _, identifier := range policy.AcceptedTypes {
+			if seen[identifier] {
+				continue
+			}
+			seen[identifier] = true
+			link, ok := customURLs[identifier]
+			if !ok {
+				link = fmt.Sprintf("https://spdx.org/licenses/%s.html", identifier)
+			}
+			lics = append(lics, AcceptedLicenseInfo{identifier, link})
+		}
 	}
 	sort.Slice(lics, func(i, j int) bool { return lics[i].Name < lics[j].Name })
 	return lics
@@ -302,7 +322,8 @@ func scanner() *licensecheck.Scanner {
 			exceptionLicenses = nil
 		}
 		var err error
-		_scanner, err = licensecheck.NewScanner(append(exceptionLicenses, licensecheck.BuiltinLicenses()...))
+		lics := append(customLicenses(policy), exceptionLicenses...)
+		_scanner, err = licensecheck.NewScanner(append(lics, licensecheck.BuiltinLicenses()...))
 		if err != nil {
 			log.Fatalf(context.Background(), "licensecheck.NewScanner: %v", err)
 		}
@@ -317,6 +338,7 @@ type Detector struct {
 	fsys           fs.FS
 	logf           func(string, ...any)
 	moduleRedist   bool
+	allowed        bool       // module is redistributable by policy
 	moduleLicenses []*License // licenses at module root directory, or list from exceptions
 	allLicenses    []*License
 	licsByDir      map[string][]*License // from directory to list of licenses
@@ -395,7 +417,7 @@ func (d *Detector) PackageInfo(dir string) (isRedistributable bool, lics []*Lice
 	// redistributable. A module that is granted an exception (see DetectFiles)
 	// may have licenses that are non-redistributable.
 	ltypes := types(lics)
-	isRedistributable = d.ModuleIsRedistributable() && (len(ltypes) == 0 || Redistributable(ltypes))
+	isRedistributable = d.allowed || d.ModuleIsRedistributable() && (len(ltypes) == 0 || Redistributable(ltypes))
 	// A package's licenses include the ones we've already computed, as well
 	// as the module licenses.
 	return isRedistributable, append(lics, d.moduleLicenses...)
@@ -405,7 +427,8 @@ func (d *Detector) PackageInfo(dir string) (isRedistributable bool, lics []*Lice
 func (d *Detector) computeModuleInfo() {
 	// Check that all licenses in the contents directory are redistributable.
 	d.moduleLicenses = d.detectFiles(d.paths(RootFiles))
-	d.moduleRedist = Redistributable(types(d.moduleLicenses))
+	d.allowed = AllowedByPolicy(d.modulePath)
+	d.moduleRedist = d.allowed || Redistributable(types(d.moduleLicenses))
 }
 
 // computeAllLicenseInfo collects all the detected licenses in the zip and
@@ -514,7 +537,7 @@ func (d *Detector) detectFiles(pathnames []string) []*License {
 			d.logf("reading file %s: %v", p, err)
 			licenses = append(licenses, &License{
 				Metadata: &Metadata{
-					Types:    []string{unknownLicenseType},
+					Types:    []string{UnknownLicenseType},
 					FilePath: p,
 				},
 			})
@@ -559,7 +582,7 @@ func DetectFile(contents []byte, filename string, logf func(string, ...any)) ([]
 	cov := scanner().Scan(contents)
 	if cov.Percent < float64(coverageThreshold) {
 		logf("%s license coverage too low (%+v), skipping", filename, cov)
-		return []string{unknownLicenseType}, cov
+		return []string{UnknownLicenseType}, cov
 	}
 	types := make(map[string]bool)
 	for _, m := range cov.Match {
@@ -573,7 +596,7 @@ func DetectFile(contents []byte, filename string, logf func(string, ...any)) ([]
 	}
 	if len(types) == 0 {
 		logf("%s failed to classify license (%+v), skipping", filename, cov)
-		return []string{unknownLicenseType}, cov
+		return []string{UnknownLicenseType}, cov
 	}
 	return setToSortedSlice(types), cov
 }
@@ -588,7 +611,7 @@ func Redistributable(licenseTypes []string) bool {
 		if ignorableLicenseTypes[t] {
 			continue
 		}
-		if !redistributableLicenseTypes[t] {
+		if !redistributableLicenseTypes[t] && !policyAcceptedTypes[t] {
 			return false
 		}
 		sawRedist = true
@@ -317,7 +317,7 @@ func TestRedistributable(t *testing.T) {
 		want  bool
 	}{
 		{nil, false},
-		{[]string{unknownLicenseType}, false},
+		{[]string{UnknownLicenseType}, false},
 		{[]string{"MIT"}, true},
 		{[]string{"MIT", "Unlicense"}, true},
 		{[]string{"MIT", "JSON"}, true},
@@ -601,7 +601,7 @@ func TestPackageInfo(t *testing.T) {
 				"dir/pkg/License.md": unknownLicense,
 			},
 			wantRedist: false,
-			wantMetas:  []*Metadata{meta("MIT", "LICENSE"), meta(unknownLicenseType, "dir/pkg/License.md")},
+			wantMetas:  []*Metadata{meta("MIT", "LICENSE"), meta(UnknownLicenseType, "dir/pkg/License.md")},
 		},
 		{
 			name: "package is but module is not",
@@ -611,7 +611,7 @@ func TestPackageInfo(t *testing.T) {
 				"dir/pkg/License.md": mitLicense,
 			},
 			wantRedist: false,
-			wantMetas:  []*Metadata{meta(unknownLicenseType, "LICENSE"), meta("MIT", "dir/pkg/License.md")},
+			wantMetas:  []*Metadata{meta(UnknownLicenseType, "LICENSE"), meta("MIT", "dir/pkg/License.md")},
 		},
 		{
 			name: "intermediate directories",
@@ -624,7 +624,7 @@ func TestPackageInfo(t *testing.T) {
 			wantRedist: false,
 			wantMetas: []*Metadata{
 				meta("MIT", "LICENSE"),
-				meta(unknownLicenseType, "dir/LICENSE.txt"),
+				meta(UnknownLicenseType, "dir/LICENSE.txt"),
 				meta("MIT", "dir/pkg/License.md"),
 			},
 		},
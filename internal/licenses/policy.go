@@ -0,0 +1,164 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licenses
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/licensecheck"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// A Policy adds to the built-in rules that decide whether a module is
+// redistributable. It is meant for self-hosted instances, whose operators can
+// accept licenses that pkg.go.dev doesn't, and can vouch for their own
+// modules.
+type Policy struct {
+	// AcceptedTypes are license types that allow redistribution, in addition
+	// to the built-in ones. They can be types reported by licensecheck, like
+	// "LGPL-2.1", or the types of Licenses.
+	AcceptedTypes []string
+
+	// ModulePrefixes are module paths whose modules, and the modules below
+	// them, are redistributable whatever their licenses, or without any.
+	ModulePrefixes []string
+
+	// Licenses are custom license texts to recognize.
+	Licenses []*CustomLicense
+}
+
+// A CustomLicense is a license text that is not known to licensecheck.
+type CustomLicense struct {
+	// Type is the license type that is reported when the text is detected.
+	Type string
+
+	// URL, if set, links to the license on the license policy page.
+	URL string
+
+	// Text is the text of the license, in the license regular expression
+	// syntax of licensecheck. Most plain license texts are valid as is;
+	// variable parts, like a copyright holder, can be written as __N__ to
+	// match up to N words.
+	Text string
+
+	// File is a file to read Text from, relative to the directory of the
+	// policy file. It is only used by ReadPolicy.
+	File string
+}
+
+var (
+	// policy is the active policy, or nil.
+	policy *Policy
+
+	// policyAcceptedTypes is the set of AcceptedTypes of policy.
+	policyAcceptedTypes = map[string]bool{}
+)
+
+// ReadPolicy reads a policy from a JSON file. The texts of custom licenses
+// that are given by file are read too.
+func ReadPolicy(filename string) (_ *Policy, err error) {
+	defer derrors.Wrap(&err, "ReadPolicy(%q)", filename)
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var p Policy
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return nil, err
+	}
+	for _, l := range p.Licenses {
+		if l.File == "" {
+			continue
+		}
+		if l.Text != "" {
+			return nil, fmt.Errorf("license %q has both Text and File", l.Type)
+		}
+		f := l.File
+		if !filepath.IsAbs(f) {
+			f = filepath.Join(filepath.Dir(filename), f)
+		}
+		text, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		l.Text = string(text)
+	}
+	return &p, nil
+}
+
+// SetPolicy makes p the active policy. Like OmitExceptions, it must be called
+// before the first use of this package.
+func SetPolicy(p *Policy) (err error) {
+	defer derrors.Wrap(&err, "SetPolicy")
+	accepted := map[string]bool{}
+	for _, t := range p.AcceptedTypes {
+		if t == "" {
+			return errors.New("empty accepted type")
+		}
+		accepted[t] = true
+	}
+	for _, prefix := range p.ModulePrefixes {
+		if strings.Trim(prefix, "/") == "" {
+			return fmt.Errorf("bad module prefix %q", prefix)
+		}
+	}
+	// Check the custom licenses here, rather than when the scanner is built.
+	types := map[string]bool{}
+	for _, l := range p.Licenses {
+		if l.Type == "" || l.Text == "" {
+			return fmt.Errorf("custom license %q needs a type and a text", l.Type)
+		}
+		if types[l.Type] || exceptionTypes[l.Type] != nil {
+			return fmt.Errorf("duplicate license type %q", l.Type)
+		}
+		types[l.Type] = true
+	}
+	if _, err := licensecheck.NewScanner(customLicenses(p)); err != nil {
+		return err
+	}
+	policy = p
+	policyAcceptedTypes = accepted
+	return nil
+}
+
+// ActivePolicy returns the active policy, or nil if there is none.
+func ActivePolicy() *Policy {
+	return policy
+}
+
+// customLicenses returns the custom licenses of p, for licensecheck.
+func customLicenses(p *Policy) []licensecheck.License {
+	if p == nil {
+		return nil
+	}
+	var lics []licensecheck.License
+	for _, l := range p.Licenses {
+		lics = append(lics, licensecheck.License{ID: l.Type, LRE: l.Text})
+	}
+	return lics
+}
+
+// AllowedByPolicy reports whether the active policy makes the module with the
+// given path redistributable, regardless of its licenses.
+func AllowedByPolicy(modulePath string) bool {
+	if policy == nil {
+		return false
+	}
+	for _, prefix := range policy.ModulePrefixes {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if modulePath == prefix || strings.HasPrefix(modulePath, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licenses
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const acmeLicense = `The Acme Corporation Internal Source License
+
+This software may be used, copied and shared by employees of the Acme Corporation
+and its subsidiaries for any purpose, and it may not be given to anyone else.`
+
+// proprietaryLicense is not detected as any license type.
+const proprietaryLicense = `Copyright 2023 Acme Corporation. All rights reserved.`
+
+// setTestPolicy makes p the active policy for the duration of the test.
+func setTestPolicy(t *testing.T, p *Policy) {
+	t.Helper()
+	if err := SetPolicy(p); err != nil {
+		t.Fatal(err)
+	}
+	scannerOnce = sync.Once{}
+	t.Cleanup(func() {
+		policy = nil
+		policyAcceptedTypes = map[string]bool{}
+		scannerOnce = sync.Once{}
+	})
+}
+
+func TestReadPolicy(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "acme.txt"), []byte(acmeLicense), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, "policy.json")
+	if err := os.WriteFile(filename, []byte(`{
+	"AcceptedTypes": ["LGPL-2.1", "Acme"],
+	"ModulePrefixes": ["acme.com/internal"],
+	"Licenses": [{
+		"Type": "Acme",
+		"URL": "https://acme.com/license",
+		"File": "acme.txt"
+	}]
+}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadPolicy(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Policy{
+		AcceptedTypes:  []string{"LGPL-2.1", "Acme"},
+		ModulePrefixes: []string{"acme.com/internal"},
+		Licenses: []*CustomLicense{{
+			Type: "Acme",
+			URL:  "https://acme.com/license",
+			Text: acmeLicense,
+			File: "acme.txt",
+		}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestSetPolicyErrors(t *testing.T) {
+	for _, p := range []*Policy{
+		{AcceptedTypes: []string{""}},
+		{ModulePrefixes: []string{"/"}},
+		{Licenses: []*CustomLicense{{Type: "Acme"}}},
+		{Licenses: []*CustomLicense{{Type: "Acme", Text: "a"}, {Type: "Acme", Text: "b"}}},
+		{Licenses: []*CustomLicense{{Type: "Acme", Text: "(( unbalanced"}}},
+	} {
+		if err := SetPolicy(p); err == nil {
+			t.Errorf("SetPolicy(%+v) succeeded, want error", p)
+		}
+	}
+	if policy != nil {
+		t.Error("failed SetPolicy changed the active policy")
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	setTestPolicy(t, &Policy{
+		AcceptedTypes:  []string{"Acme"},
+		ModulePrefixes: []string{"acme.com/internal/"},
+		Licenses:       []*CustomLicense{{Type: "Acme", URL: "https://acme.com/license", Text: acmeLicense}},
+	})
+
+	for _, test := range []struct {
+		modulePath string
+		files      map[string]string
+		want       bool
+	}{
+		{"acme.com/lib", map[string]string{"LICENSE": acmeLicense}, true},
+		{"acme.com/lib", map[string]string{"LICENSE": proprietaryLicense}, false},
+		{"acme.com/lib", nil, false},
+		{"acme.com/internal", nil, true},
+		{"acme.com/internal/x", map[string]string{"LICENSE": proprietaryLicense}, true},
+		{"acme.com/internalx", nil, false},
+	} {
+		fsys := fstest.MapFS{}
+		for name, contents := range test.files {
+			fsys[name] = &fstest.MapFile{Data: []byte(contents)}
+		}
+		d := NewDetectorFS(test.modulePath, "v1.0.0", fsys, nil)
+		if got := d.ModuleIsRedistributable(); got != test.want {
+			t.Errorf("%s with %v: ModuleIsRedistributable() = %t, want %t", test.modulePath, test.files, got, test.want)
+		}
+	}
+
+	// A package with an unknown license in an allowed module is
+	// redistributable.
+	fsys := fstest.MapFS{"p/LICENSE": &fstest.MapFile{Data: []byte(proprietaryLicense)}}
+	d := NewDetectorFS("acme.com/internal/x", "v1.0.0", fsys, nil)
+	if redist, _ := d.PackageInfo("p"); !redist {
+		t.Error("PackageInfo: got not redistributable, want redistributable")
+	}
+
+	var got *AcceptedLicenseInfo
+	for _, l := range AcceptedLicenses() {
+		if l.Name == "Acme" {
+			l := l
+			got = &l
+		}
+	}
+	want := &AcceptedLicenseInfo{Name: "Acme", URL: "https://acme.com/license"}
+	if !cmp.Equal(got, want) {
+		t.Errorf("AcceptedLicenses: got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,348 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package localcache implements an in-process page cache, for deployments
+// with a single frontend and for cmd/pkgsite.
+//
+// Unlike package cache, it depends only on the standard library and
+// pkgsite, so that cmd/pkgsite can use it.
+package localcache
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/lru"
+)
+
+// A Store keeps cache entries in memory, evicting the least recently used
+// ones when their total size exceeds a limit. If it has a directory, evicted
+// entries are written to files there instead of being discarded, and are
+// read back when they are requested.
+//
+// A Store implements cache.Store and caching.Store.
+type Store struct {
+	dir string // directory for spilled entries, or empty
+
+	// mu protects the fields below, and the files in dir. Holding it while
+	// spilling keeps an entry that is deleted or cleared from coming back
+	// from disk.
+	mu        sync.Mutex
+	lru       *lru.Cache[string, *entry]
+	evicted   []*entry // entries evicted from lru, to be spilled
+	lastSweep time.Time
+}
+
+type entry struct {
+	key     string
+	data    []byte
+	expires time.Time // zero if the entry does not expire
+}
+
+func (e *entry) expired() bool {
+	return !e.expires.IsZero() && time.Now().After(e.expires)
+}
+
+// New creates a new Store that keeps up to maxBytes bytes of keys and data
+// in memory. If dir is not empty, entries evicted from memory are kept in
+// files in dir.
+func New(maxBytes int64, dir string) (_ *Store, err error) {
+	defer derrors.Wrap(&err, "localcache.New(%d, %q)", maxBytes, dir)
+	if maxBytes < 1 {
+		return nil, fmt.Errorf("non-positive maxBytes %d", maxBytes)
+	}
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	s := &Store{dir: dir}
+	s.lru = lru.NewSized(maxBytes,
+		func(key string, e *entry) int64 { return int64(len(key) + len(e.data)) },
+		func(_ string, e *entry) { s.evicted = append(s.evicted, e) })
+	return s, nil
+}
+
+// Get returns the value for key, or nil if the key does not exist.
+func (s *Store) Get(ctx context.Context, key string) (_ []byte, err error) {
+	defer derrors.Wrap(&err, "localcache.Get(%q)", key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.lru.Get(key); ok {
+		if e.expired() {
+			s.lru.Delete(key)
+			return nil, nil
+		}
+		return e.data, nil
+	}
+	if s.dir == "" {
+		return nil, nil
+	}
+	e, err := s.readFile(key)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Whether the entry is live or not, it no longer belongs on disk: move it
+	// back into memory or drop it.
+	if err := s.removeFile(key); err != nil {
+		return nil, err
+	}
+	if e.expired() {
+		return nil, nil
+	}
+	s.insert(ctx, e)
+	return e.data, nil
+}
+
+// Put inserts the key with the given data and time-to-live. A time-to-live
+// of zero means the entry does not expire.
+func (s *Store) Put(ctx context.Context, key string, data []byte, ttl time.Duration) (err error) {
+	defer derrors.Wrap(&err, "localcache.Put(%q, data, %s)", key, ttl)
+	e := &entry{key: key, data: data}
+	if ttl > 0 {
+		e.expires = time.Now().Add(ttl)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.dir != "" {
+		// Don't let an older spilled entry reappear after this one is evicted
+		// and dropped.
+		if err := s.removeFile(key); err != nil {
+			return err
+		}
+	}
+	s.insert(ctx, e)
+	s.maybeSweep(ctx)
+	return nil
+}
+
+// Clear deletes all entries.
+func (s *Store) Clear(ctx context.Context) (err error) {
+	defer derrors.Wrap(&err, "localcache.Clear()")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lru.Clear()
+	return s.removeFiles(func(string, *entry) bool { return true })
+}
+
+// Delete deletes the given keys. It does not return an error if a key does
+// not exist.
+func (s *Store) Delete(ctx context.Context, keys ...string) (err error) {
+	defer derrors.Wrap(&err, "localcache.Delete(%q)", keys)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, k := range keys {
+		s.lru.Delete(k)
+		if s.dir != "" {
+			if err := s.removeFile(k); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// DeletePrefix deletes all keys beginning with prefix.
+func (s *Store) DeletePrefix(ctx context.Context, prefix string) (err error) {
+	defer derrors.Wrap(&err, "localcache.DeletePrefix(%q)", prefix)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, k := range s.lru.Keys() {
+		if strings.HasPrefix(k, prefix) {
+			s.lru.Delete(k)
+		}
+	}
+	return s.removeFiles(func(k string, _ *entry) bool { return strings.HasPrefix(k, prefix) })
+}
+
+// insert adds e to memory as the most recently used entry, then spills the
+// entries that the LRU cache evicted to make room for it. An entry that is
+// larger than maxBytes by itself is spilled or dropped right away. s.mu must
+// be held.
+func (s *Store) insert(ctx context.Context, e *entry) {
+	s.lru.Put(e.key, e)
+	for _, e := range s.evicted {
+		s.spill(ctx, e)
+	}
+	s.evicted = nil
+}
+
+// spill writes an entry evicted from memory to disk. s.mu must be held.
+func (s *Store) spill(ctx context.Context, e *entry) {
+	if s.dir == "" || e.expired() {
+		return
+	}
+	if err := s.writeFile(e); err != nil {
+		log.Errorf(ctx, "localcache: spilling %q: %v", e.key, err)
+	}
+}
+
+// sweepInterval is how often spilled entries are checked for expiration.
+const sweepInterval = 10 * time.Minute
+
+// maybeSweep removes the expired spilled entries, if it hasn't done so for
+// sweepInterval. s.mu must be held.
+func (s *Store) maybeSweep(ctx context.Context) {
+	if s.dir == "" || time.Since(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = time.Now()
+	if err := s.removeFiles(func(_ string, e *entry) bool { return e.expired() }); err != nil {
+		log.Errorf(ctx, "localcache: removing expired entries: %v", err)
+	}
+}
+
+// A spilled entry is stored in a file named for the hash of its key. The file
+// starts with two lines holding the quoted key and the expiration time in Unix
+// nanoseconds, followed by the data.
+const spillSuffix = ".entry"
+
+func (s *Store) filename(key string) string {
+	h := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(h[:])+spillSuffix)
+}
+
+func (s *Store) writeFile(e *entry) (err error) {
+	f, err := os.CreateTemp(s.dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(f.Name())
+		}
+	}()
+	var expires int64
+	if !e.expires.IsZero() {
+		expires = e.expires.UnixNano()
+	}
+	if _, err := fmt.Fprintf(f, "%s\n%d\n", strconv.Quote(e.key), expires); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(e.data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), s.filename(e.key))
+}
+
+// readFile reads the entry for key from disk.
+func (s *Store) readFile(key string) (*entry, error) {
+	f, err := os.Open(s.filename(key))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	e, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	if e.key != key {
+		// A hash collision; treat it as a miss.
+		return nil, fs.ErrNotExist
+	}
+	e.data, err = io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// readHeader reads the key and expiration time of a spilled entry.
+func readHeader(r *bufio.Reader) (*entry, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	e := &entry{}
+	e.key, err = strconv.Unquote(strings.TrimSuffix(line, "\n"))
+	if err != nil {
+		return nil, err
+	}
+	line, err = r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	ns, err := strconv.ParseInt(strings.TrimSuffix(line, "\n"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	if ns != 0 {
+		e.expires = time.Unix(0, ns)
+	}
+	return e, nil
+}
+
+func (s *Store) removeFile(key string) error {
+	err := os.Remove(s.filename(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// removeFiles removes the spilled entries for which remove returns true. The
+// data of the entries passed to remove is not populated. s.mu must be held.
+func (s *Store) removeFiles(remove func(key string, e *entry) bool) error {
+	if s.dir == "" {
+		return nil
+	}
+	des, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	for _, de := range des {
+		if !strings.HasSuffix(de.Name(), spillSuffix) {
+			continue
+		}
+		filename := filepath.Join(s.dir, de.Name())
+		e, err := readHeaderFile(filename)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil || remove(e.key, e) {
+			// Remove unreadable files too.
+			if err := os.Remove(filename); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func readHeaderFile(filename string) (*entry, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readHeader(bufio.NewReader(f))
+}
@@ -0,0 +1,126 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localcache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	for _, dir := range []string{"", t.TempDir()} {
+		name := "memory"
+		if dir != "" {
+			name = "spill"
+		}
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			// Room for two entries with two-byte keys and one-byte values.
+			s, err := New(6, dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			check := func(key, want string) {
+				t.Helper()
+				got, err := s.Get(ctx, key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != want {
+					t.Errorf("Get(%q) = %q, want %q", key, got, want)
+				}
+			}
+
+			must(t, s.Put(ctx, "/a", []byte("A"), 0))
+			must(t, s.Put(ctx, "/b", []byte("B"), time.Hour))
+			must(t, s.Put(ctx, "/c", []byte("C"), 0)) // evicts /a
+			check("/c", "C")
+			check("/b", "B")
+			// Without a directory, /a is gone; with one, it comes back
+			// (and evicts /c).
+			if dir == "" {
+				check("/a", "")
+			} else {
+				check("/a", "A")
+				check("/c", "C")
+			}
+
+			// An entry larger than the limit is not kept in memory.
+			must(t, s.Put(ctx, "/big", []byte("too big"), 0))
+			if dir == "" {
+				check("/big", "")
+			} else {
+				check("/big", "too big")
+			}
+
+			must(t, s.Put(ctx, "/x", []byte("X"), time.Nanosecond))
+			time.Sleep(time.Millisecond)
+			check("/x", "")
+
+			must(t, s.Put(ctx, "/p1", []byte("1"), 0))
+			must(t, s.Put(ctx, "/p2", []byte("2"), 0))
+			must(t, s.Put(ctx, "/q", []byte("Q"), 0))
+			must(t, s.DeletePrefix(ctx, "/p"))
+			check("/p1", "")
+			check("/p2", "")
+			check("/q", "Q")
+
+			must(t, s.Delete(ctx, "/q"))
+			check("/q", "")
+
+			must(t, s.Put(ctx, "/a", []byte("A2"), 0))
+			must(t, s.Clear(ctx))
+			for _, k := range []string{"/a", "/b", "/c", "/big"} {
+				check(k, "")
+			}
+		})
+	}
+}
+
+// TestConcurrentPutClear checks that none of the entries spilled by
+// concurrent Puts come back after a Clear.
+func TestConcurrentPutClear(t *testing.T) {
+	ctx := context.Background()
+	s, err := New(10, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if err := s.Put(ctx, fmt.Sprintf("/%d/%d", i, j), []byte("data"), 0); err != nil {
+					t.Error(err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	must(t, s.Clear(ctx))
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 50; j++ {
+			key := fmt.Sprintf("/%d/%d", i, j)
+			got, err := s.Get(ctx, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != nil {
+				t.Errorf("Get(%q) = %q after Clear, want nil", key, got)
+			}
+		}
+	}
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
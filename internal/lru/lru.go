@@ -14,7 +14,10 @@ import (
 // Cache is an LRU cache.
 type Cache[K comparable, V any] struct {
 	mu      sync.Mutex
-	size    int
+	size    int64 // maximum total size of the entries
+	used    int64 // total size of the entries
+	sizeOf  func(K, V) int64
+	onEvict func(K, V) // called for each evicted entry, or nil
 	entries map[K]*entry[V]
 	tick    uint // increases every time an entry is used
 }
@@ -24,13 +27,27 @@ type entry[V any] struct {
 	v        V
 }
 
-// New returns a new Cache. Size must be positive or it will panic.
+// New returns a new Cache that holds up to size entries. Size must be
+// positive or it will panic.
 func New[K comparable, V any](size int) *Cache[K, V] {
+	return NewSized[K, V](int64(size), nil, nil)
+}
+
+// NewSized returns a new Cache that holds entries whose total size, as
+// computed by sizeOf, is at most size. If sizeOf is nil, every entry has size
+// 1. If onEvict is not nil, Put calls it with each entry that it evicts, after
+// unlocking the Cache. Size must be positive or it will panic.
+func NewSized[K comparable, V any](size int64, sizeOf func(K, V) int64, onEvict func(K, V)) *Cache[K, V] {
 	if size < 1 {
 		panic(fmt.Errorf("lru.New called with non-positive size %v", size))
 	}
+	if sizeOf == nil {
+		sizeOf = func(K, V) int64 { return 1 }
+	}
 	return &Cache[K, V]{
 		size:    size,
+		sizeOf:  sizeOf,
+		onEvict: onEvict,
 		entries: map[K]*entry[V]{},
 	}
 }
@@ -49,22 +66,34 @@ func (c *Cache[K, V]) Get(k K) (V, bool) {
 	return entry.v, true
 }
 
-// Put puts in an entry for k, v in Cache, evicting
-// the least recently used entry if necessary.
+// Put puts in an entry for k, v in Cache, evicting the least recently used
+// entries if necessary. An entry that is larger than the Cache by itself is
+// evicted right away.
 func (c *Cache[K, V]) Put(k K, v V) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	_, ok := c.entries[k]
-	if !ok {
-		// k not already in c.entries. We need to evict the least recently
-		// used entry.
+	type kv struct {
+		k K
+		v V
+	}
+	var evicted []kv
+	func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
 		if c.size < 1 {
 			panic("attempting to insert into an uninitialized cache.")
 		}
-		if len(c.entries) > c.size {
-			panic(fmt.Errorf("size of cache, %d, has grown beyond size limit %d", len(c.entries), c.size))
+		if e, ok := c.entries[k]; ok {
+			c.used -= c.sizeOf(k, e.v)
+			delete(c.entries, k)
+		}
+		size := c.sizeOf(k, v)
+		if size > c.size {
+			evicted = append(evicted, kv{k, v})
+			return
 		}
-		if len(c.entries) == c.size {
+		c.tick++
+		c.entries[k] = &entry[V]{lastUsed: c.tick, v: v}
+		c.used += size
+		for c.used > c.size {
 			// evict least recently used element.
 			var oldestTick uint = math.MaxUint
 			var oldestKey K
@@ -74,9 +103,44 @@ func (c *Cache[K, V]) Put(k K, v V) {
 					oldestKey = k
 				}
 			}
+			e := c.entries[oldestKey]
+			c.used -= c.sizeOf(oldestKey, e.v)
 			delete(c.entries, oldestKey)
+			evicted = append(evicted, kv{oldestKey, e.v})
+		}
+	}()
+	if c.onEvict != nil {
+		for _, e := range evicted {
+			c.onEvict(e.k, e.v)
 		}
 	}
-	c.tick++
-	c.entries[k] = &entry[V]{lastUsed: c.tick, v: v}
+}
+
+// Delete removes the entry for k, if any, from the Cache.
+func (c *Cache[K, V]) Delete(k K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[k]; ok {
+		c.used -= c.sizeOf(k, e.v)
+		delete(c.entries, k)
+	}
+}
+
+// Clear removes all entries from the Cache.
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[K]*entry[V]{}
+	c.used = 0
+}
+
+// Keys returns the keys of the entries in the Cache, in no particular order.
+func (c *Cache[K, V]) Keys() []K {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys := make([]K, 0, len(c.entries))
+	for k := range c.entries {
+		keys = append(keys, k)
+	}
+	return keys
 }
@@ -4,7 +4,10 @@
 
 package lru
 
-import "testing"
+import (
+	"slices"
+	"testing"
+)
 
 func TestSizeOne(t *testing.T) {
 	c := New[int, int](1)
@@ -94,3 +97,35 @@ func TestSizeFive(t *testing.T) {
 	getHasKey(13, true)
 	getHasKey(14, true)
 }
+
+func TestSized(t *testing.T) {
+	var evicted []string
+	c := NewSized[string, string](10,
+		func(k, v string) int64 { return int64(len(v)) },
+		func(k, v string) { evicted = append(evicted, k) })
+	c.Put("a", "aaaa")
+	c.Put("b", "bbbb")
+	c.Get("a")
+	c.Put("c", "cccc") // b gets evicted
+	if _, ok := c.Get("b"); ok {
+		t.Error("b was not evicted")
+	}
+	c.Put("d", "ddddddddddd") // larger than the cache, so evicted at once
+	if _, ok := c.Get("d"); ok {
+		t.Error("d was not evicted")
+	}
+	c.Delete("a")
+	c.Put("e", "eeeeee") // fits after a is deleted
+	for _, k := range []string{"c", "e"} {
+		if _, ok := c.Get(k); !ok {
+			t.Errorf("%s was evicted", k)
+		}
+	}
+	if want := []string{"b", "d"}; !slices.Equal(evicted, want) {
+		t.Errorf("evicted %v, want %v", evicted, want)
+	}
+	c.Clear()
+	if keys := c.Keys(); len(keys) != 0 {
+		t.Errorf("after Clear, got keys %v", keys)
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright 2019 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package caching provides a middleware that caches responses in a Store.
+//
+// It depends only on the standard library and pkgsite, so that cmd/pkgsite
+// can use it. Package middleware provides Cachers that store responses in
+// Redis and record metrics.
+package caching
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"golang.org/x/pkgsite/internal/config"
+	"golang.org/x/pkgsite/internal/cookie"
+	"golang.org/x/pkgsite/internal/log"
+)
+
+// To avoid test flakiness, when TestMode is true, cache writes are
+// synchronous.
+var TestMode = false
+
+// A Store holds cached responses. Both cache.Cache and localcache.Store are
+// Stores.
+type Store interface {
+	// Get returns the value for key, or nil if the key does not exist.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Put inserts the key with the given data and time-to-live.
+	Put(ctx context.Context, key string, data []byte, ttl time.Duration) error
+	// Clear deletes all entries.
+	Clear(ctx context.Context) error
+}
+
+// A Recorder records the results of cache lookups and the errors of cache
+// operations, for monitoring.
+type Recorder interface {
+	// RecordResult records whether a lookup in the named cache was a hit,
+	// and how long it took.
+	RecordResult(ctx context.Context, name string, hit bool, latency time.Duration)
+	// RecordError records an error in an operation on the named cache.
+	RecordError(ctx context.Context, name, operation string)
+}
+
+// A Cacher creates middleware that caches responses in a Store.
+type Cacher struct {
+	store    Store
+	recorder Recorder
+}
+
+// NewCacher returns a new Cacher, used for creating a middleware that caches
+// each request in s. If r is not nil, the results of cache operations are
+// recorded with it.
+func NewCacher(s Store, r Recorder) *Cacher {
+	return &Cacher{store: s, recorder: r}
+}
+
+// Clear deletes all cached responses.
+func (c *Cacher) Clear(ctx context.Context) error {
+	return c.store.Clear(ctx)
+}
+
+// Cache returns a new Middleware that caches every request.
+// The name of the cache is used only for metrics.
+// The expirer is a func that is used to map a new request to its TTL.
+// authHeader is the header key used by the cache to know that a
+// request should bypass the cache.
+// authValues is the set of values that could be set on the authHeader in
+// order to bypass the cache.
+func (c *Cacher) Cache(name string, expirer func(r *http.Request) time.Duration, authValues []string) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return &cache{
+			name:       name,
+			authValues: authValues,
+			cacher:     c,
+			delegate:   h,
+			expirer:    expirer,
+		}
+	}
+}
+
+type cache struct {
+	name       string
+	authValues []string
+	cacher     *Cacher
+	delegate   http.Handler
+	expirer    func(r *http.Request) time.Duration
+}
+
+func (c *cache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Check auth header to see if request should bypass cache.
+	authVal := r.Header.Get(config.BypassCacheAuthHeader)
+	for _, wantVal := range c.authValues {
+		if authVal == wantVal {
+			c.delegate.ServeHTTP(w, r)
+			return
+		}
+	}
+	// If the flash cookie is set, bypass the cache.
+	if _, err := r.Cookie(cookie.AlternativeModuleFlash); err == nil {
+		c.delegate.ServeHTTP(w, r)
+		return
+	}
+	ctx := r.Context()
+	key := r.URL.String()
+	start := time.Now()
+	reader, hit := c.get(ctx, key)
+	if c.cacher.recorder != nil {
+		c.cacher.recorder.RecordResult(ctx, c.name, hit, time.Since(start))
+	}
+	if hit {
+		log.Debugf(ctx, "serving %q from cache", key)
+		if _, err := io.Copy(w, reader); err != nil {
+			log.Errorf(ctx, "error copying zip bytes: %v", err)
+		}
+		return
+	}
+	rec := newRecorder(w)
+	c.delegate.ServeHTTP(rec, r)
+	if rec.bufErr == nil && (rec.statusCode == 0 || rec.statusCode == http.StatusOK) {
+		ttl := c.expirer(r)
+		if TestMode {
+			c.put(ctx, key, rec, ttl)
+		} else {
+			go c.put(ctx, key, rec, ttl)
+		}
+	}
+}
+
+func (c *cache) recordError(ctx context.Context, operation string) {
+	if c.cacher.recorder != nil {
+		c.cacher.recorder.RecordError(ctx, c.name, operation)
+	}
+}
+
+func (c *cache) get(ctx context.Context, key string) (io.Reader, bool) {
+	// Set a short timeout for redis requests, so that we can quickly
+	// fall back to un-cached serving if redis is unavailable.
+	getCtx, cancelGet := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancelGet()
+	val, err := c.cacher.store.Get(getCtx, key)
+	if err != nil {
+		select {
+		case <-getCtx.Done():
+			log.Infof(ctx, "cache get(%q): context timed out", key)
+		default:
+			log.Infof(ctx, "cache get(%q): %v", key, err)
+		}
+		c.recordError(ctx, "GET")
+		return nil, false
+	}
+	if val == nil {
+		return nil, false
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(val))
+	if err != nil {
+		log.Errorf(ctx, "cache: gzip.NewReader: %v", err)
+		c.recordError(ctx, "UNZIP")
+		return nil, false
+	}
+	return zr, true
+}
+
+func (c *cache) put(ctx context.Context, key string, rec *cacheRecorder, ttl time.Duration) {
+	if err := rec.zipWriter.Close(); err != nil {
+		log.Errorf(ctx, "cache: error closing zip for %q: %v", key, err)
+		return
+	}
+	log.Infof(ctx, "caching response of length %d for %s", rec.buf.Len(), key)
+	setCtx, cancelSet := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancelSet()
+	if err := c.cacher.store.Put(setCtx, key, rec.buf.Bytes(), ttl); err != nil {
+		c.recordError(ctx, "SET")
+		log.Warningf(ctx, "cache set %q: %v", key, err)
+	}
+}
+
+func newRecorder(w http.ResponseWriter) *cacheRecorder {
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	return &cacheRecorder{ResponseWriter: w, buf: buf, zipWriter: zw}
+}
+
+// cacheRecorder is an http.ResponseWriter that collects http bytes for later
+// writing to the cache. Along the way it collects any error, along with the
+// resulting HTTP status code. We only cache 200 OK responses.
+type cacheRecorder struct {
+	http.ResponseWriter
+	statusCode int
+
+	bufErr    error
+	buf       *bytes.Buffer
+	zipWriter *gzip.Writer
+}
+
+func (r *cacheRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	// Only try writing to the buffer if we haven't yet encountered an error.
+	if r.bufErr == nil {
+		if err == nil {
+			zn, bufErr := r.zipWriter.Write(b)
+			if bufErr != nil {
+				r.bufErr = bufErr
+			}
+			if zn != n {
+				r.bufErr = fmt.Errorf("wrote %d to zip, but wanted %d", zn, n)
+			}
+		} else {
+			r.bufErr = fmt.Errorf("ResponseWriter.Write failed: %v", err)
+		}
+	}
+	return n, err
+}
+
+func (r *cacheRecorder) WriteHeader(statusCode int) {
+	if statusCode > r.statusCode {
+		// Defensively take the largest status code that's written, so if any
+		// middleware thinks the response is not OK, we will capture this.
+		r.statusCode = statusCode
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
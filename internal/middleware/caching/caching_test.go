@@ -0,0 +1,116 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package caching
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/pkgsite/internal/config"
+)
+
+// mapStore is a Store that keeps entries in a map and ignores their
+// time-to-live.
+type mapStore struct {
+	mu sync.Mutex
+	m  map[string][]byte
+}
+
+func (s *mapStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m[key], nil
+}
+
+func (s *mapStore) Put(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[key] = data
+	return nil
+}
+
+func (s *mapStore) Clear(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m = map[string][]byte{}
+	return nil
+}
+
+type countingRecorder struct {
+	hits, misses, errors int
+}
+
+func (r *countingRecorder) RecordResult(_ context.Context, _ string, hit bool, _ time.Duration) {
+	if hit {
+		r.hits++
+	} else {
+		r.misses++
+	}
+}
+
+func (r *countingRecorder) RecordError(context.Context, string, string) {
+	r.errors++
+}
+
+func TestCacher(t *testing.T) {
+	defer func(m bool) { TestMode = m }(TestMode)
+	TestMode = true
+
+	rec := &countingRecorder{}
+	c := NewCacher(&mapStore{m: map[string][]byte{}}, rec)
+	calls := 0
+	h := c.Cache("test", func(*http.Request) time.Duration { return time.Hour }, []string{"secret"})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if r.URL.Path == "/missing" {
+				http.Error(w, "not found", http.StatusNotFound)
+				return
+			}
+			fmt.Fprintf(w, "response %d", calls)
+		}))
+
+	get := func(path, auth string) string {
+		t.Helper()
+		r := httptest.NewRequest("GET", path, nil)
+		if auth != "" {
+			r.Header.Set(config.BypassCacheAuthHeader, auth)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Body.String()
+	}
+
+	for _, test := range []struct {
+		path, auth, want string
+	}{
+		{"/a", "", "response 1"},
+		{"/a", "", "response 1"},        // cached
+		{"/a", "secret", "response 2"},  // bypassed
+		{"/a", "wrong", "response 1"},   // cached
+		{"/missing", "", "not found\n"}, // call 3, not cached
+		{"/missing", "", "not found\n"}, // call 4
+	} {
+		if got := get(test.path, test.auth); got != test.want {
+			t.Errorf("%s (auth %q): got %q, want %q", test.path, test.auth, got, test.want)
+		}
+	}
+	if calls != 4 {
+		t.Errorf("handler called %d times, want 4", calls)
+	}
+	if rec.hits != 2 || rec.misses != 3 || rec.errors != 0 {
+		t.Errorf("got %d hits, %d misses and %d errors, want 2, 3 and 0", rec.hits, rec.misses, rec.errors)
+	}
+	if err := c.Clear(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := get("/a", ""), "response 5"; got != want {
+		t.Errorf("after Clear: got %q, want %q", got, want)
+	}
+}
@@ -23,11 +23,12 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"go.opencensus.io/stats/view"
 	"golang.org/x/pkgsite/internal/config"
+	"golang.org/x/pkgsite/internal/middleware/caching"
 )
 
 func TestCache(t *testing.T) {
 	// force cache writes to be synchronous
-	TestMode = true
+	caching.TestMode = true
 	// These variables are mutated before each test case to control the handler
 	// response.
 	var (
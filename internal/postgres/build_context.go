@@ -0,0 +1,51 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// CheckBuildContexts returns an error if documentation cannot be stored for
+// one of bcs, because its GOOS or GOARCH is not a value of the goos or goarch
+// enum of the database. To support another GOOS or GOARCH, add it to the enum
+// in a migration.
+func (db *DB) CheckBuildContexts(ctx context.Context, bcs []internal.BuildContext) (err error) {
+	defer derrors.WrapStack(&err, "DB.CheckBuildContexts(ctx, %v)", bcs)
+
+	enumValues := func(typ string) (map[string]bool, error) {
+		vals, err := database.Collect1[string](ctx, db.db, fmt.Sprintf(`SELECT unnest(enum_range(NULL::%s))::text`, typ))
+		if err != nil {
+			return nil, err
+		}
+		m := map[string]bool{}
+		for _, v := range vals {
+			m[v] = true
+		}
+		return m, nil
+	}
+	goos, err := enumValues("goos")
+	if err != nil {
+		return err
+	}
+	goarch, err := enumValues("goarch")
+	if err != nil {
+		return err
+	}
+	for _, bc := range bcs {
+		if !goos[bc.GOOS] {
+			return fmt.Errorf("build context %s: GOOS %q is not in the goos enum", bc, bc.GOOS)
+		}
+		if !goarch[bc.GOARCH] {
+			return fmt.Errorf("build context %s: GOARCH %q is not in the goarch enum", bc, bc.GOARCH)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"golang.org/x/pkgsite/internal"
+)
+
+func TestCheckBuildContexts(t *testing.T) {
+	t.Parallel()
+	testDB, release := acquire(t)
+	defer release()
+	ctx := context.Background()
+
+	if err := testDB.CheckBuildContexts(ctx, internal.BuildContexts); err != nil {
+		t.Errorf("default build contexts: %v", err)
+	}
+	if err := testDB.CheckBuildContexts(ctx, []internal.BuildContext{{GOOS: "ios", GOARCH: "arm64"}}); err != nil {
+		t.Errorf("ios/arm64: %v", err)
+	}
+	for _, bc := range []internal.BuildContext{{GOOS: "beos", GOARCH: "amd64"}, {GOOS: "linux", GOARCH: "z80"}} {
+		if err := testDB.CheckBuildContexts(ctx, []internal.BuildContext{bc}); err == nil {
+			t.Errorf("%s: got no error, want one", bc)
+		}
+	}
+}
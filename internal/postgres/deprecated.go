@@ -0,0 +1,119 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Masterminds/squirrel"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/middleware/stats"
+	"golang.org/x/pkgsite/internal/symbol"
+)
+
+// GetDeprecatedSymbols returns the deprecated symbols of the packages of the
+// module version, sorted by package path and symbol name. A symbol that is
+// deprecated in some build contexts is listed once.
+//
+// The Since field of each symbol is computed from the symbol history of its
+// package, which only covers release versions.
+func (db *DB) GetDeprecatedSymbols(ctx context.Context, modulePath, version string) (_ []*internal.DeprecatedSymbol, err error) {
+	defer derrors.WrapStack(&err, "GetDeprecatedSymbols(ctx, %q, %q)", modulePath, version)
+	defer stats.Elapsed(ctx, "GetDeprecatedSymbols")()
+
+	var (
+		syms         []*internal.DeprecatedSymbol
+		pkgToNames   = map[string][]string{}
+		seen         = map[[2]string]bool{} // package path and symbol name
+		pathsInOrder []string
+	)
+	collect := func(rows *sql.Rows) error {
+		ds := &internal.DeprecatedSymbol{}
+		if err := rows.Scan(&ds.PackagePath, &ds.Name, &ds.ParentName,
+			&ds.Section, &ds.Kind, &ds.Synopsis, &ds.Replacement); err != nil {
+			return err
+		}
+		key := [2]string{ds.PackagePath, ds.Name}
+		if seen[key] {
+			return nil
+		}
+		seen[key] = true
+		if ds.ParentName == ds.Name {
+			ds.ParentName = ""
+		}
+		ds.Deprecated = true
+		syms = append(syms, ds)
+		if _, ok := pkgToNames[ds.PackagePath]; !ok {
+			pathsInOrder = append(pathsInOrder, ds.PackagePath)
+		}
+		pkgToNames[ds.PackagePath] = append(pkgToNames[ds.PackagePath], ds.Name)
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, `
+		SELECT p.path, s1.name, s2.name, ps.section, ps.type, ps.synopsis, ds.replacement
+		FROM modules m
+		INNER JOIN units u ON u.module_id = m.id
+		INNER JOIN paths p ON p.id = u.path_id
+		INNER JOIN documentation d ON d.unit_id = u.id
+		INNER JOIN documentation_symbols ds ON ds.documentation_id = d.id
+		INNER JOIN package_symbols ps ON ps.id = ds.package_symbol_id
+		INNER JOIN symbol_names s1 ON ps.symbol_name_id = s1.id
+		INNER JOIN symbol_names s2 ON ps.parent_symbol_name_id = s2.id
+		WHERE m.module_path = $1 AND m.version = $2 AND ds.deprecated
+		ORDER BY p.path, s1.name, d.goos, d.goarch`, collect, modulePath, version); err != nil {
+		return nil, err
+	}
+
+	pkgToSince := map[string]map[string]string{}
+	for _, path := range pathsInOrder {
+		sh, err := db.getDeprecationHistory(ctx, path, modulePath, pkgToNames[path])
+		if err != nil {
+			return nil, err
+		}
+		pkgToSince[path] = symbol.DeprecatedSince(sh)
+	}
+	for _, ds := range syms {
+		ds.Since = pkgToSince[ds.PackagePath][ds.Name]
+	}
+	return syms, nil
+}
+
+// getDeprecationHistory returns the symbol history of the named symbols of the
+// package, with only the names and deprecation of the symbols filled in.
+func (db *DB) getDeprecationHistory(ctx context.Context, packagePath, modulePath string, names []string) (_ *internal.SymbolHistory, err error) {
+	defer derrors.WrapStack(&err, "getDeprecationHistory(ctx, %q, %q)", packagePath, modulePath)
+
+	query := packageSymbolQueryJoin(
+		squirrel.Select(
+			"s1.name",
+			"ds.deprecated",
+			"m.version",
+			"d.goos",
+			"d.goarch"), packagePath, modulePath).
+		Where(squirrel.Eq{"s1.name": names})
+	q, args, err := query.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if err != nil {
+		return nil, err
+	}
+	sh := internal.NewSymbolHistory()
+	collect := func(rows *sql.Rows) error {
+		var (
+			sm    internal.SymbolMeta
+			v     string
+			build internal.BuildContext
+		)
+		if err := rows.Scan(&sm.Name, &sm.Deprecated, &v, &build.GOOS, &build.GOARCH); err != nil {
+			return err
+		}
+		sh.AddSymbol(sm, v, build)
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, q, collect, args...); err != nil {
+		return nil, err
+	}
+	return sh, nil
+}
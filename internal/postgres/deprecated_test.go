@@ -0,0 +1,68 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestGetDeprecatedSymbols(t *testing.T) {
+	t.Parallel()
+	testDB, release := acquire(t)
+	defer release()
+	ctx := context.Background()
+
+	fn := func(name string, deprecated bool, replacement string) *internal.Symbol {
+		return &internal.Symbol{
+			SymbolMeta: internal.SymbolMeta{
+				Name:        name,
+				Synopsis:    "func " + name + "()",
+				Section:     internal.SymbolSectionFunctions,
+				Kind:        internal.SymbolKindFunction,
+				ParentName:  name,
+				Deprecated:  deprecated,
+				Replacement: replacement,
+			},
+		}
+	}
+	// F is deprecated in v1.1.0, G in v1.0.0 but not v1.1.0, and H from
+	// v1.0.0 on.
+	for _, m := range []*internal.Module{
+		moduleWithSymbols(t, "v1.0.0", []*internal.Symbol{
+			fn("F", false, ""), fn("G", true, ""), fn("H", true, "F")}),
+		moduleWithSymbols(t, "v1.1.0", []*internal.Symbol{
+			fn("F", true, "H"), fn("G", false, ""), fn("H", true, "F")}),
+	} {
+		MustInsertModule(ctx, t, testDB, m)
+	}
+
+	got, err := testDB.GetDeprecatedSymbols(ctx, sample.ModulePath, "v1.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*internal.DeprecatedSymbol{
+		{
+			SymbolMeta:  fn("F", true, "H").SymbolMeta,
+			PackagePath: sample.ModulePath,
+			Since:       "v1.1.0",
+		},
+		{
+			SymbolMeta:  fn("H", true, "F").SymbolMeta,
+			PackagePath: sample.ModulePath,
+			Since:       "v1.0.0",
+		},
+	}
+	for _, w := range want {
+		w.ParentName = ""
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// insertExampleResults replaces the rows of the
+// documentation_example_results table for the documentation in pathToDocs.
+// Should be run inside a transaction.
+func insertExampleResults(ctx context.Context, tx *database.DB,
+	pathToUnitID map[string]int,
+	pathToDocs map[string][]*internal.Documentation) (err error) {
+	defer derrors.WrapStack(&err, "insertExampleResults")
+	defer internal.RequestState(ctx, "inserting into documentation_example_results table")()
+
+	pathToDocIDToDoc, err := getDocIDsForPath(ctx, tx, pathToUnitID, pathToDocs)
+	if err != nil {
+		return err
+	}
+	var (
+		docIDs []int
+		values []any
+	)
+	for _, docIDToDoc := range pathToDocIDToDoc {
+		for docID, doc := range docIDToDoc {
+			docIDs = append(docIDs, docID)
+			for name, passed := range doc.ExampleResults {
+				values = append(values, docID, name, passed)
+			}
+		}
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM documentation_example_results WHERE documentation_id = ANY($1)`, pq.Array(docIDs)); err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	cols := []string{"documentation_id", "name", "passed"}
+	return tx.BulkInsert(ctx, "documentation_example_results", cols, values, database.OnConflictDoNothing)
+}
+
+// getExampleResults returns the example results recorded for the
+// documentation with the given ID.
+func getExampleResults(ctx context.Context, db *database.DB, docID int) (_ map[string]bool, err error) {
+	defer derrors.WrapStack(&err, "getExampleResults(ctx, %d)", docID)
+
+	var results map[string]bool
+	collect := func(rows *sql.Rows) error {
+		var (
+			name   string
+			passed bool
+		)
+		if err := rows.Scan(&name, &passed); err != nil {
+			return err
+		}
+		if results == nil {
+			results = map[string]bool{}
+		}
+		results[name] = passed
+		return nil
+	}
+	if err := db.RunQuery(ctx, `
+		SELECT name, passed
+		FROM documentation_example_results
+		WHERE documentation_id = $1`, collect, docID); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestExampleResults(t *testing.T) {
+	t.Parallel()
+	testDB, release := acquire(t)
+	defer release()
+	ctx := context.Background()
+
+	m := sample.Module(sample.ModulePath, sample.VersionString, "")
+	results := map[string]bool{"ExampleF": true, "ExampleT_M": false}
+	m.Units[0].Documentation[0].ExampleResults = results
+	MustInsertModule(ctx, t, testDB, m)
+
+	get := func() map[string]bool {
+		t.Helper()
+		um := newUnitMeta(sample.ModulePath, sample.ModulePath, sample.VersionString)
+		u, err := testDB.GetUnit(ctx, um, internal.WithMain, internal.BuildContext{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u.Documentation[0].ExampleResults
+	}
+	if diff := cmp.Diff(results, get()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	// Reinserting the module replaces its results.
+	m.Units[0].Documentation[0].ExampleResults = nil
+	MustInsertModule(ctx, t, testDB, m)
+	if got := get(); got != nil {
+		t.Errorf("after reinsertion: got %v, want nil", got)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// insertImplementations replaces the rows of the
+// documentation_implementations table for the documentation in pathToDocs.
+// Should be run inside a transaction.
+func insertImplementations(ctx context.Context, tx *database.DB,
+	pathToUnitID map[string]int,
+	pathToDocs map[string][]*internal.Documentation) (err error) {
+	defer derrors.WrapStack(&err, "insertImplementations")
+	defer internal.RequestState(ctx, "inserting into documentation_implementations table")()
+
+	pathToDocIDToDoc, err := getDocIDsForPath(ctx, tx, pathToUnitID, pathToDocs)
+	if err != nil {
+		return err
+	}
+	var (
+		docIDs []int
+		values []any
+	)
+	for _, docIDToDoc := range pathToDocIDToDoc {
+		for docID, doc := range docIDToDoc {
+			docIDs = append(docIDs, docID)
+			for _, impl := range doc.Implementations {
+				values = append(values, docID, impl.Type, impl.TypePkgPath, impl.Interface, impl.InterfacePkgPath)
+			}
+		}
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM documentation_implementations WHERE documentation_id = ANY($1)`, pq.Array(docIDs)); err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	cols := []string{"documentation_id", "type", "type_package_path", "interface", "interface_package_path"}
+	return tx.BulkInsert(ctx, "documentation_implementations", cols, values, database.OnConflictDoNothing)
+}
+
+// getImplementations returns the implementations recorded for the
+// documentation with the given ID.
+func getImplementations(ctx context.Context, db *database.DB, docID int) (_ []*internal.Implementation, err error) {
+	defer derrors.WrapStack(&err, "getImplementations(ctx, %d)", docID)
+
+	var impls []*internal.Implementation
+	collect := func(rows *sql.Rows) error {
+		var impl internal.Implementation
+		if err := rows.Scan(&impl.Type, &impl.TypePkgPath, &impl.Interface, &impl.InterfacePkgPath); err != nil {
+			return err
+		}
+		impls = append(impls, &impl)
+		return nil
+	}
+	if err := db.RunQuery(ctx, `
+		SELECT type, type_package_path, interface, interface_package_path
+		FROM documentation_implementations
+		WHERE documentation_id = $1
+		ORDER BY interface_package_path, interface, type_package_path, type`, collect, docID); err != nil {
+		return nil, err
+	}
+	return impls, nil
+}
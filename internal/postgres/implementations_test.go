@@ -0,0 +1,49 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestImplementations(t *testing.T) {
+	t.Parallel()
+	testDB, release := acquire(t)
+	defer release()
+	ctx := context.Background()
+
+	m := sample.Module(sample.ModulePath, sample.VersionString, "")
+	impls := []*internal.Implementation{
+		{Type: "T", TypePkgPath: sample.ModulePath, Interface: "fmt.Stringer", InterfacePkgPath: "fmt"},
+		{Type: "*sub.S", TypePkgPath: sample.ModulePath + "/sub", Interface: "I", InterfacePkgPath: sample.ModulePath},
+	}
+	m.Units[0].Documentation[0].Implementations = impls
+	MustInsertModule(ctx, t, testDB, m)
+
+	get := func() []*internal.Implementation {
+		t.Helper()
+		um := newUnitMeta(sample.ModulePath, sample.ModulePath, sample.VersionString)
+		u, err := testDB.GetUnit(ctx, um, internal.WithMain, internal.BuildContext{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u.Documentation[0].Implementations
+	}
+	if diff := cmp.Diff(impls, get()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	// Reinserting the module replaces its implementations.
+	m.Units[0].Documentation[0].Implementations = impls[1:]
+	MustInsertModule(ctx, t, testDB, m)
+	if diff := cmp.Diff(impls[1:], get()); diff != "" {
+		t.Errorf("after reinsertion: mismatch (-want, +got):\n%s", diff)
+	}
+}
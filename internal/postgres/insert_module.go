@@ -12,20 +12,14 @@ import (
 	"fmt"
 	"hash/fnv"
 	"io"
-	"sort"
-	"strings"
-	"unicode/utf8"
 
 	"github.com/lib/pq"
 	"go.opencensus.io/trace"
-	"golang.org/x/mod/module"
-	"golang.org/x/mod/semver"
 	"golang.org/x/pkgsite/internal"
 	"golang.org/x/pkgsite/internal/database"
 	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/licenses"
 	"golang.org/x/pkgsite/internal/log"
-	"golang.org/x/pkgsite/internal/stdlib"
 	"golang.org/x/pkgsite/internal/version"
 )
 
@@ -42,7 +36,7 @@ func (db *DB) InsertModule(ctx context.Context, m *internal.Module, lmv *interna
 	}()
 	defer internal.RequestState(ctx, "inserting module into DB")()
 
-	if err := validateModule(m); err != nil {
+	if err := internal.PrepareModuleForInsert(m, db.bypassLicenseCheck); err != nil {
 		return false, err
 	}
 	// Compare existing data from the database, and the module to be
@@ -52,10 +46,6 @@ func (db *DB) InsertModule(ctx context.Context, m *internal.Module, lmv *interna
 	if err := db.comparePaths(ctx, m); err != nil {
 		return false, err
 	}
-	if !db.bypassLicenseCheck {
-		// If we are not bypassing license checking, remove data for non-redistributable modules.
-		m.RemoveNonRedistributableData()
-	}
 	return db.saveModule(ctx, m, lmv)
 }
 
@@ -104,10 +94,19 @@ func (db *DB) saveModule(ctx context.Context, m *internal.Module, lmv *internal.
 		if err := insertLicenses(ctx, tx, m, moduleID); err != nil {
 			return err
 		}
+		if err := insertRequirements(ctx, tx, m, moduleID); err != nil {
+			return err
+		}
 		pathToUnitID, pathToDocs, err := db.insertUnits(ctx, tx, m, moduleID, pathToID)
 		if err != nil {
 			return err
 		}
+		if err := insertImplementations(ctx, tx, pathToUnitID, pathToDocs); err != nil {
+			return err
+		}
+		if err := insertExampleResults(ctx, tx, pathToUnitID, pathToDocs); err != nil {
+			return err
+		}
 
 		// Obtain a transaction-scoped exclusive advisory lock on the module
 		// path. The transaction that holds the lock is the only one that can
@@ -286,7 +285,7 @@ func insertLicenses(ctx context.Context, db *database.DB, m *internal.Module, mo
 			return fmt.Errorf("marshalling %+v: %v", l.Coverage, err)
 		}
 		licenseValues = append(licenseValues, l.FilePath,
-			makeValidUnicode(string(l.Contents)), pq.Array(l.Types), covJSON,
+			database.MakeValidUnicode(string(l.Contents)), pq.Array(l.Types), covJSON,
 			moduleID)
 	}
 	if len(licenseValues) > 0 {
@@ -303,6 +302,29 @@ func insertLicenses(ctx context.Context, db *database.DB, m *internal.Module, mo
 	return nil
 }
 
+// insertRequirements replaces the requirements of the module with those of m,
+// and records that they are known.
+func insertRequirements(ctx context.Context, db *database.DB, m *internal.Module, moduleID int) (err error) {
+	defer internal.RequestState(ctx, "inserting into module_requirements table")()
+	defer derrors.WrapStack(&err, "insertRequirements(ctx, %q, %q)", m.ModulePath, m.Version)
+
+	if _, err := db.Exec(ctx, `DELETE FROM module_requirements WHERE module_id = $1`, moduleID); err != nil {
+		return err
+	}
+	if _, err := db.Exec(ctx, `UPDATE modules SET requirements_recorded = TRUE WHERE id = $1`, moduleID); err != nil {
+		return err
+	}
+	var values []any
+	for _, r := range m.Requirements {
+		values = append(values, moduleID, r.ModulePath, r.Version, r.Indirect)
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	cols := []string{"module_id", "required_path", "required_version", "indirect"}
+	return db.BulkInsert(ctx, "module_requirements", cols, values, database.OnConflictDoNothing)
+}
+
 // insertImportsUnique inserts and removes rows from the imports_unique table. It should only
 // be called if the given module's version is the latest.
 func insertImportsUnique(ctx context.Context, tx *database.DB, m *internal.Module) (err error) {
@@ -343,15 +365,6 @@ func (pdb *DB) insertUnits(ctx context.Context, tx *database.DB,
 	ctx, span := trace.StartSpan(ctx, "insertUnits")
 	defer span.End()
 
-	// Sort to ensure proper lock ordering, avoiding deadlocks. We have seen
-	// deadlocks on package_imports and documentation. They can occur when
-	// processing two versions of the same module, which happens regularly.
-	sort.Slice(m.Units, func(i, j int) bool {
-		return m.Units[i].Path < m.Units[j].Path
-	})
-	for _, u := range m.Units {
-		sort.Strings(u.Imports)
-	}
 	var (
 		paths         []string
 		unitValues    []any
@@ -362,22 +375,7 @@ func (pdb *DB) insertUnits(ctx context.Context, tx *database.DB,
 	)
 	pathToPkgDocs = map[string][]*internal.Documentation{}
 	for _, u := range m.Units {
-		var licenseTypes, licensePaths []string
-		for _, l := range u.Licenses {
-			if len(l.Types) == 0 {
-				// If a license file has no detected license types, we still need to
-				// record it as applicable to the package, because we want to fail
-				// closed (meaning if there is a LICENSE file containing unknown
-				// licenses, we assume them not to be permissive of redistribution.)
-				licenseTypes = append(licenseTypes, "")
-				licensePaths = append(licensePaths, l.FilePath)
-			} else {
-				for _, typ := range l.Types {
-					licenseTypes = append(licenseTypes, typ)
-					licensePaths = append(licensePaths, l.FilePath)
-				}
-			}
-		}
+		licenseTypes, licensePaths := u.LicenseTypesAndPaths()
 		v1path := internal.V1Path(u.Path, m.ModulePath)
 		pathID, ok := pathToID[u.Path]
 		if !ok {
@@ -396,11 +394,6 @@ func (pdb *DB) insertUnits(ctx context.Context, tx *database.DB,
 		if u.Readme != nil {
 			pathToReadme[u.Path] = u.Readme
 		}
-		for _, d := range u.Documentation {
-			if d.Source == nil {
-				return nil, nil, fmt.Errorf("insertUnits: unit %q missing source files for %q, %q", u.Path, d.GOOS, d.GOARCH)
-			}
-		}
 		pathToAllDocs[u.Path] = u.Documentation
 		if !u.IsCommand() {
 			// We don't care about symbols for commands, since they won't
@@ -619,7 +612,7 @@ func insertReadmes(ctx context.Context, db *database.DB,
 		}
 
 		// Do not add a readme with empty or zero contents.
-		readmeContents := makeValidUnicode(readme.Contents)
+		readmeContents := database.MakeValidUnicode(readme.Contents)
 		if len(readmeContents) == 0 {
 			continue
 		}
@@ -796,48 +789,6 @@ func lock(ctx context.Context, tx *database.DB, modulePath string) (err error) {
 	return nil
 }
 
-// validateModule checks that fields needed to insert a module into the database
-// are present. Otherwise, it returns an error listing the reasons the module
-// cannot be inserted. Since the problems it looks for are most likely on our
-// end, the underlying error it returns is always DBModuleInsertInvalid, meaning
-// that this module should be reprocessed.
-func validateModule(m *internal.Module) (err error) {
-	defer func() {
-		if err != nil {
-			err = fmt.Errorf("%v: %w", err, derrors.DBModuleInsertInvalid)
-			if m != nil {
-				derrors.WrapStack(&err, "validateModule(%q, %q)", m.ModulePath, m.Version)
-			}
-		}
-	}()
-
-	if m == nil {
-		return fmt.Errorf("nil module")
-	}
-	var errReasons []string
-	if m.Version == "" {
-		errReasons = append(errReasons, "no specified version")
-	}
-	if m.ModulePath == "" {
-		errReasons = append(errReasons, "no module path")
-	}
-	if m.ModulePath != stdlib.ModulePath {
-		if err := module.CheckPath(m.ModulePath); err != nil {
-			errReasons = append(errReasons, fmt.Sprintf("invalid module path (%s)", err))
-		}
-		if !semver.IsValid(m.Version) {
-			errReasons = append(errReasons, "invalid version")
-		}
-	}
-	if len(m.Packages()) == 0 {
-		errReasons = append(errReasons, "module does not have any packages")
-	}
-	if len(errReasons) != 0 {
-		return fmt.Errorf("cannot insert module %q: %s", m.Version, strings.Join(errReasons, ", "))
-	}
-	return nil
-}
-
 // compareLicenses compares m.Licenses with the existing licenses for
 // m.ModulePath and m.Version in the database. It returns an error if there
 // are licenses in the licenses table that are not present in m.Licenses.
@@ -880,29 +831,3 @@ func (db *DB) comparePaths(ctx context.Context, m *internal.Module) (err error)
 	}
 	return nil
 }
-
-// makeValidUnicode removes null runes from a string that will be saved in a
-// column of type TEXT, because pq doesn't like them. It also replaces non-unicode
-// characters with the Unicode replacement character, which is the behavior of
-// for ... range on strings.
-func makeValidUnicode(s string) string {
-	// If s is valid and has no zeroes, don't copy it.
-	hasZeroes := false
-	for _, r := range s {
-		if r == 0 {
-			hasZeroes = true
-			break
-		}
-	}
-	if !hasZeroes && utf8.ValidString(s) {
-		return s
-	}
-
-	var b strings.Builder
-	for _, r := range s {
-		if r != 0 {
-			b.WriteRune(r)
-		}
-	}
-	return b.String()
-}
@@ -0,0 +1,59 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/middleware/stats"
+)
+
+// GetModuleRequirements returns the requirements in the go.mod file of the
+// module version, sorted by module path and version. It returns a
+// derrors.NotFound error if the module version is not in the database, and a
+// derrors.RequirementsUnknown error if it was inserted before its
+// requirements were recorded.
+func (db *DB) GetModuleRequirements(ctx context.Context, modulePath, resolvedVersion string) (_ []*internal.Requirement, err error) {
+	defer derrors.WrapStack(&err, "GetModuleRequirements(ctx, %q, %q)", modulePath, resolvedVersion)
+	defer stats.Elapsed(ctx, "GetModuleRequirements")()
+
+	var (
+		moduleID int
+		recorded bool
+	)
+	err = db.db.QueryRow(ctx, `
+		SELECT id, requirements_recorded FROM modules WHERE module_path = $1 AND version = $2`,
+		modulePath, resolvedVersion).Scan(&moduleID, &recorded)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, derrors.NotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !recorded {
+		return nil, derrors.RequirementsUnknown
+	}
+	var reqs []*internal.Requirement
+	collect := func(rows *sql.Rows) error {
+		var r internal.Requirement
+		if err := rows.Scan(&r.ModulePath, &r.Version, &r.Indirect); err != nil {
+			return err
+		}
+		reqs = append(reqs, &r)
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, `
+		SELECT required_path, required_version, indirect
+		FROM module_requirements
+		WHERE module_id = $1
+		ORDER BY required_path, required_version`, collect, moduleID); err != nil {
+		return nil, err
+	}
+	return reqs, nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+func TestGetModuleRequirements(t *testing.T) {
+	t.Parallel()
+	testDB, release := acquire(t)
+	defer release()
+	ctx := context.Background()
+
+	m := sample.Module(sample.ModulePath, sample.VersionString, "")
+	m.Requirements = []*internal.Requirement{
+		{ModulePath: "golang.org/x/text", Version: "v0.3.0", Indirect: true},
+		{ModulePath: "example.com/dep", Version: "v1.2.3"},
+	}
+	MustInsertModule(ctx, t, testDB, m)
+
+	got, err := testDB.GetModuleRequirements(ctx, sample.ModulePath, sample.VersionString)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*internal.Requirement{
+		{ModulePath: "example.com/dep", Version: "v1.2.3"},
+		{ModulePath: "golang.org/x/text", Version: "v0.3.0", Indirect: true},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	// Reinserting the module replaces its requirements.
+	m.Requirements = m.Requirements[1:]
+	MustInsertModule(ctx, t, testDB, m)
+	got, err = testDB.GetModuleRequirements(ctx, sample.ModulePath, sample.VersionString)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want[:1], got); diff != "" {
+		t.Errorf("after reinsertion: mismatch (-want, +got):\n%s", diff)
+	}
+
+	if _, err := testDB.GetModuleRequirements(ctx, sample.ModulePath, "v9.9.9"); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("got error %v for missing module, want NotFound", err)
+	}
+
+	// The requirements of modules inserted before they were recorded are
+	// unknown, not empty.
+	if _, err := testDB.db.Exec(ctx, `UPDATE modules SET requirements_recorded = FALSE`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := testDB.GetModuleRequirements(ctx, sample.ModulePath, sample.VersionString); !errors.Is(err, derrors.RequirementsUnknown) {
+		t.Errorf("got error %v for module without recorded requirements, want RequirementsUnknown", err)
+	}
+}
@@ -670,7 +670,7 @@ type UpsertSearchDocumentArgs struct {
 
 // UpsertSearchDocument inserts a row in search_documents for the given package.
 // The given module should have already been validated via a call to
-// validateModule.
+// internal.ValidateModuleForInsert.
 func UpsertSearchDocument(ctx context.Context, ddb *database.DB, args UpsertSearchDocumentArgs) (err error) {
 	defer derrors.WrapStack(&err, "DB.UpsertSearchDocument(ctx, ddb, %q, %q)", args.PackagePath, args.ModulePath)
 
@@ -681,8 +681,9 @@ func UpsertSearchDocument(ctx context.Context, ddb *database.DB, args UpsertSear
 		args.ReadmeContents = ""
 	}
 	pathTokens := strings.Join(GeneratePathTokens(args.PackagePath), " ")
-	sectionB, sectionC, sectionD := SearchDocumentSections(args.Synopsis, args.ReadmeFilePath, args.ReadmeContents)
-	_, err = ddb.Exec(ctx, upsertSearchStatement, args.PackagePath, args.ModulePath, args.Version, pathTokens, sectionB, sectionC, sectionD)
+	sectionB, sectionC, sectionD := search.DocumentSections(args.Synopsis, args.ReadmeFilePath, args.ReadmeContents)
+	_, err = ddb.Exec(ctx, upsertSearchStatement, args.PackagePath, args.ModulePath, args.Version, pathTokens,
+		database.MakeValidUnicode(sectionB), database.MakeValidUnicode(sectionC), database.MakeValidUnicode(sectionD))
 	return err
 }
 
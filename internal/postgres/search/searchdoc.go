@@ -0,0 +1,59 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package search
+
+import (
+	"github.com/russross/blackfriday/v2"
+	"golang.org/x/pkgsite/internal/search/text"
+)
+
+// DocumentSections computes the B, C and D sections of a search document from a
+// package synopsis and a README. See text.DocumentSections for their contents.
+// If the README is markdown, only its text is used.
+func DocumentSections(synopsis, readmeFilename, readme string) (b, c, d string) {
+	return documentSections(synopsis, readmeFilename, readme, text.MaxSectionWords, text.MaxReadmeFraction)
+}
+
+func documentSections(synopsis, readmeFilename, readme string, maxSecWords int, maxReadmeFrac float64) (b, c, d string) {
+	if text.IsMarkdown(readmeFilename) {
+		readme = processMarkdown(readme)
+	}
+	return text.DocumentSections(synopsis, readme, maxSecWords, maxReadmeFrac)
+}
+
+// processMarkdown returns the text of a markdown document.
+// It omits all formatting and images.
+func processMarkdown(s string) string {
+	parser := blackfriday.New(blackfriday.WithExtensions(blackfriday.CommonExtensions))
+	root := parser.Parse([]byte(s))
+	buf := walkMarkdown(root, nil, 0)
+	return string(buf)
+}
+
+// walkMarkdown traverses a blackfriday parse tree, extracting text.
+func walkMarkdown(n *blackfriday.Node, buf []byte, level int) []byte {
+	if n == nil {
+		return buf
+	}
+	switch n.Type {
+	case blackfriday.Image:
+		// Skip images because they usually are irrelevant to the package
+		// (badges and such).
+		return buf
+	case blackfriday.CodeBlock:
+		// Skip code blocks because they have a wide variety of unrelated symbols.
+		return buf
+	case blackfriday.Paragraph, blackfriday.Heading:
+		if len(buf) > 0 {
+			buf = append(buf, ' ')
+		}
+	default:
+		buf = append(buf, n.Literal...)
+	}
+	for c := n.FirstChild; c != nil; c = c.Next {
+		buf = walkMarkdown(c, buf, level+1)
+	}
+	return buf
+}
@@ -2,15 +2,13 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-package postgres
+package search
 
 import (
 	"testing"
-
-	"github.com/google/go-cmp/cmp"
 )
 
-func TestSearchDocumentSections(t *testing.T) {
+func TestDocumentSections(t *testing.T) {
 	t.Parallel()
 	for _, test := range []struct {
 		name                string
@@ -59,7 +57,7 @@ Many Go projects are built using Viper including:`,
 			"many go projects are",
 		},
 	} {
-		gotB, gotC, gotD := searchDocumentSections(test.synopsis, test.readmeFilename, test.readmeContents, 6, 0.5)
+		gotB, gotC, gotD := documentSections(test.synopsis, test.readmeFilename, test.readmeContents, 6, 0.5)
 		if gotB != test.wantB {
 			t.Errorf("%s, B: got %q, want %q", test.name, gotB, test.wantB)
 		}
@@ -72,29 +70,6 @@ Many Go projects are built using Viper including:`,
 	}
 }
 
-func TestProcessWords(t *testing.T) {
-	t.Parallel()
-	for _, test := range []struct {
-		in   string
-		want []string
-	}{
-		{"", nil},
-		{"foo", []string{"foo"}},
-		{" foo \t bar\n", []string{"foo", "bar"}},
-		{"http://foo/bar/baz?x=1", []string{"http://foo/bar/baz?x=1"}},
-		{"This, however, shall. not; stand?", []string{"this", "however", "shall", "not", "stand"}},
-		{"a postgres and NATS server over HTTP", []string{
-			"a", "postgres", "postgresql", "and", "nats", "server", "over", "http"}},
-		{"http://a-b-c.com full-text chart-parser", []string{
-			"http://a-b-c.com", "full-text", "chart-parser", "parser", "parse"}},
-	} {
-		got := processWords(test.in)
-		if !cmp.Equal(got, test.want) {
-			t.Errorf("%q:\ngot  %#v\nwant %#v", test.in, got, test.want)
-		}
-	}
-}
-
 func TestProcessMarkdown(t *testing.T) {
 	t.Parallel()
 	const (
@@ -115,23 +90,3 @@ _Blackfriday_ is a [Markdown][1] *processor* implemented in [Go](https://golang.
 		t.Errorf("got\n%s\nwant\n%s", got, want)
 	}
 }
-
-func TestSentenceEndIndex(t *testing.T) {
-	t.Parallel()
-	for _, test := range []struct {
-		in   string
-		want int
-	}{
-		{"", -1},
-		{"Hello. What's up?", 5},
-		{"unicode π∆!", 13},
-		{"D. C. Fontana?", 13},
-		{"D. c. Fontana?", 4},
-		{"no end", -1},
-	} {
-		got := sentenceEndIndex(test.in)
-		if got != test.want {
-			t.Errorf("%s: got %d, want %d", test.in, got, test.want)
-		}
-	}
-}
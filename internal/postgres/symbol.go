@@ -84,15 +84,22 @@ type packageSymbol struct {
 	parentName string
 }
 
+// symbolDeprecation is the deprecation of a symbol in a documentation, as
+// stored in the documentation_symbols table.
+type symbolDeprecation struct {
+	deprecated  bool
+	replacement string
+}
+
 func upsertDocumentationSymbols(ctx context.Context, db *database.DB,
 	pathToPkgsymID map[string]map[packageSymbol]int,
 	pathToDocIDToDoc map[string]map[int]*internal.Documentation) (err error) {
 	defer derrors.WrapStack(&err, "upsertDocumentationSymbols(ctx, db, pathToPkgsymID, pathToDocIDToDoc)")
 
-	// Create a map of documentation_id TO package_symbol_id set.
+	// Create a map of documentation_id TO package_symbol_id TO deprecation.
 	// This will be used to verify that all package_symbols for the unit have
 	// been inserted.
-	docIDToPkgsymIDs := map[int]map[int]bool{}
+	docIDToPkgsymIDs := map[int]map[int]symbolDeprecation{}
 	for path, docIDToDoc := range pathToDocIDToDoc {
 		for docID, doc := range docIDToDoc {
 			err := updateSymbols(doc.API, func(sm *internal.SymbolMeta) error {
@@ -107,9 +114,9 @@ func upsertDocumentationSymbols(ctx context.Context, db *database.DB,
 				}
 				_, ok = docIDToPkgsymIDs[docID]
 				if !ok {
-					docIDToPkgsymIDs[docID] = map[int]bool{}
+					docIDToPkgsymIDs[docID] = map[int]symbolDeprecation{}
 				}
-				docIDToPkgsymIDs[docID][pkgsymID] = true
+				docIDToPkgsymIDs[docID][pkgsymID] = symbolDeprecation{sm.Deprecated, sm.Replacement}
 				return nil
 			})
 			if err != nil {
@@ -128,11 +135,14 @@ func upsertDocumentationSymbols(ctx context.Context, db *database.DB,
 	}
 	gotDocIDToPkgsymIDs := map[int]map[int]bool{}
 	collect := func(rows *sql.Rows) error {
-		var id, docID, pkgsymID int
-		if err := rows.Scan(&id, &docID, &pkgsymID); err != nil {
+		var (
+			id, docID, pkgsymID int
+			dep                 symbolDeprecation
+		)
+		if err := rows.Scan(&id, &docID, &pkgsymID, &dep.deprecated, &dep.replacement); err != nil {
 			return fmt.Errorf("row.Scan(): %v", err)
 		}
-		if !docIDToPkgsymIDs[docID][pkgsymID] {
+		if want, ok := docIDToPkgsymIDs[docID][pkgsymID]; !ok || want != dep {
 			// The package_symbol_id in the documentation_symbols table does
 			// not match the one we want to insert. This can happen if we
 			// change the package_symbol_id. In that case, do not add this to
@@ -141,6 +151,10 @@ func upsertDocumentationSymbols(ctx context.Context, db *database.DB,
 			// See https://go-review.googlesource.com/c/pkgsite/+/315309
 			// and https://go-review.googlesource.com/c/pkgsite/+/315310
 			// where the package_symbol_id was potentially changed.
+			//
+			// Likewise, upsert rows whose deprecation has changed, as it
+			// does when a module is reprocessed after deprecations were
+			// first recorded.
 			return nil
 		}
 		if _, ok := gotDocIDToPkgsymIDs[docID]; !ok {
@@ -153,7 +167,9 @@ func upsertDocumentationSymbols(ctx context.Context, db *database.DB,
         SELECT
             ds.id,
             ds.documentation_id,
-            ds.package_symbol_id
+            ds.package_symbol_id,
+            ds.deprecated,
+            ds.replacement
         FROM documentation_symbols ds
         WHERE documentation_id = ANY($1);`, collect, pq.Array(documentationIDs)); err != nil {
 		return err
@@ -172,22 +188,24 @@ func upsertDocumentationSymbols(ctx context.Context, db *database.DB,
 	var values []any
 	for _, docID := range docIDs {
 		gotSet := gotDocIDToPkgsymIDs[docID]
-		for pkgsymID := range docIDToPkgsymIDs[docID] {
+		for pkgsymID, dep := range docIDToPkgsymIDs[docID] {
 			if !gotSet[pkgsymID] {
-				values = append(values, docID, pkgsymID)
+				values = append(values, docID, pkgsymID, dep.deprecated, dep.replacement)
 			}
 		}
 	}
 	// Upsert the rows.
 	// Note that the order of pkgsymcols must match that of the SELECT query in
 	// the collect function.
-	docsymcols := []string{"documentation_id", "package_symbol_id"}
+	docsymcols := []string{"documentation_id", "package_symbol_id", "deprecated", "replacement"}
 	if err := db.BulkInsert(ctx, "documentation_symbols", docsymcols,
 		values, `
 			ON CONFLICT (documentation_id, package_symbol_id)
 			DO UPDATE SET
 				documentation_id=excluded.documentation_id,
-				package_symbol_id=excluded.package_symbol_id`); err != nil {
+				package_symbol_id=excluded.package_symbol_id,
+				deprecated=excluded.deprecated,
+				replacement=excluded.replacement`); err != nil {
 		return err
 	}
 	return nil
@@ -89,7 +89,7 @@ func GetSymbolHistoryForBuildContext(ctx context.Context, ddb *database.DB, path
 	defer stats.Elapsed(ctx, "GetSymbolHistoryForBuildContext")()
 
 	if bc == internal.BuildContextAll {
-		bc = internal.BuildContextLinux
+		bc = internal.BuildContexts[0]
 	}
 
 	q := squirrel.Select(
@@ -72,13 +72,9 @@ func upsertSymbolSearchDocuments(ctx context.Context, tx *database.DB,
 		ORDER BY
 			sd.package_path_id,
 			ps.symbol_name_id,
-			-- Order should match internal.BuildContexts.
-			CASE WHEN d.goos = 'all' THEN 0
-			WHEN d.goos = 'linux' THEN 1
-			WHEN d.goos = 'windows' THEN 2
-			WHEN d.goos = 'darwin' THEN 3
-			WHEN d.goos = 'js' THEN 4
-			END
+			-- Prefer all/all, then the order of internal.BuildContexts.
+			-- Unknown build contexts sort last.
+			array_position($3::text[], d.goos || '/' || d.goarch)
 		ON CONFLICT (package_path_id, symbol_name_id)
 		DO UPDATE SET
 			unit_id = excluded.unit_id,
@@ -89,7 +85,11 @@ func upsertSymbolSearchDocuments(ctx context.Context, tx *database.DB,
 			package_path = excluded.package_path,
 			imported_by_count = excluded.imported_by_count,
 			symbol_name = excluded.symbol_name;`
-	_, err = tx.Exec(ctx, q, modulePath, v)
+	bcs := []string{internal.BuildContextAll.String()}
+	for _, bc := range internal.BuildContexts {
+		bcs = append(bcs, bc.String())
+	}
+	_, err = tx.Exec(ctx, q, modulePath, v, pq.Array(bcs))
 	return err
 }
 
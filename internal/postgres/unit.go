@@ -472,6 +472,7 @@ func (db *DB) getUnitWithAllFields(ctx context.Context, um *internal.UnitMeta, b
         SELECT
 			r.file_path,
 			r.contents,
+			d.id,
 			d.synopsis,
 			d.source,
 			COALESCE((
@@ -492,7 +493,7 @@ func (db *DB) getUnitWithAllFields(ctx context.Context, um *internal.UnitMeta, b
 		ON r.unit_id = u.id
 
 		LEFT JOIN (
-			SELECT synopsis, source, goos, goarch, unit_id
+			SELECT id, synopsis, source, goos, goarch, unit_id
 			FROM documentation d
 			WHERE d.GOOS = $3 AND d.GOARCH = $4
         ) d
@@ -500,8 +501,9 @@ func (db *DB) getUnitWithAllFields(ctx context.Context, um *internal.UnitMeta, b
 		WHERE u.id = $2
 	`
 	var (
-		r internal.Readme
-		u internal.Unit
+		r     internal.Readme
+		u     internal.Unit
+		docID sql.NullInt64
 	)
 	u.BuildContexts = bcs
 	var goos, goarch any
@@ -514,6 +516,7 @@ func (db *DB) getUnitWithAllFields(ctx context.Context, um *internal.UnitMeta, b
 	err = db.db.QueryRow(ctx, query, pathID, unitID, goos, goarch).Scan(
 		database.NullIsEmpty(&r.Filepath),
 		database.NullIsEmpty(&r.Contents),
+		&docID,
 		database.NullIsEmpty(&doc.Synopsis),
 		&doc.Source,
 		&u.NumImports,
@@ -548,6 +551,14 @@ func (db *DB) getUnitWithAllFields(ctx context.Context, um *internal.UnitMeta, b
 		if err != nil {
 			return nil, err
 		}
+		doc.Implementations, err = getImplementations(ctx, db.db, int(docID.Int64))
+		if err != nil {
+			return nil, err
+		}
+		doc.ExampleResults, err = getExampleResults(ctx, db.db, int(docID.Int64))
+		if err != nil {
+			return nil, err
+		}
 	}
 	return &u, nil
 }
@@ -377,15 +377,11 @@ func getLatestGoodVersion(ctx context.Context, tx *database.DB, modulePath strin
 	defer derrors.WrapStack(&err, "getLatestGoodVersion(%q)", modulePath)
 
 	// Read the versions from the modules table.
-	// If the cooked latest version is incompatible, then include
-	// incompatible versions. If it isn't, then either there are no
-	// incompatible versions, or there are but the latest compatible version
-	// has a go.mod file. Either way, ignore incompatible versions.
 	q := squirrel.Select("version").
 		From("modules").
 		Where(squirrel.Eq{"module_path": modulePath}).
 		PlaceholderFormat(squirrel.Dollar)
-	if lmv != nil && !version.IsIncompatible(lmv.CookedVersion) {
+	if !internal.GoodVersionsIncludeIncompatible(lmv) {
 		q = q.Where("NOT incompatible")
 	}
 	query, args, err := q.ToSql()
@@ -396,19 +392,7 @@ func getLatestGoodVersion(ctx context.Context, tx *database.DB, modulePath strin
 	if err != nil {
 		return "", err
 	}
-	// Choose the latest good version from a filtered list of versions.
-	if lmv != nil {
-		// Remove retracted versions.
-		vs = version.RemoveIf(vs, lmv.IsRetracted)
-		// The good version should never be later than the cooked version.
-		if lmv.CookedVersion != "" {
-			vs = version.RemoveIf(vs, func(v string) bool {
-				return version.Later(v, lmv.CookedVersion)
-			})
-		}
-	}
-
-	return version.LatestOf(vs), nil
+	return internal.LatestGoodVersion(vs, lmv), nil
 }
 
 // GetLatestModuleVersions returns the row of the latest_module_versions table for modulePath.
@@ -10,8 +10,10 @@ import (
 	"fmt"
 	"net/http"
 	"sort"
+	"strings"
 	"time"
 
+	"github.com/Masterminds/squirrel"
 	"github.com/lib/pq"
 	"go.opencensus.io/trace"
 	"golang.org/x/pkgsite/internal"
@@ -381,6 +383,90 @@ func (db *DB) GetRecentVersions(ctx context.Context, limit int) (_ []*internal.M
 	return db.queryModuleVersionStates(ctx, queryFormat, limit)
 }
 
+// ModuleVersionStateFilter selects rows of the module_version_states table.
+// Zero fields select every row.
+type ModuleVersionStateFilter struct {
+	Statuses      []int  // status codes
+	ErrorContains string // substring of the error
+	ModulePrefix  string // module path, or a componentwise prefix of it
+}
+
+// GetModuleVersionStates returns up to limit module version states that match
+// filter, most recently processed first.
+func (db *DB) GetModuleVersionStates(ctx context.Context, filter ModuleVersionStateFilter, limit int) (_ []*internal.ModuleVersionState, err error) {
+	defer derrors.WrapStack(&err, "GetModuleVersionStates(ctx, %+v, %d)", filter, limit)
+
+	q := squirrel.Select(moduleVersionStateColumns).
+		From("module_version_states").
+		OrderBy("last_processed_at DESC NULLS LAST", "module_path", "sort_version DESC").
+		Limit(uint64(limit))
+	if len(filter.Statuses) > 0 {
+		q = q.Where("status = ANY(?)", pq.Array(filter.Statuses))
+	}
+	if filter.ErrorContains != "" {
+		q = q.Where("strpos(error, ?) > 0", filter.ErrorContains)
+	}
+	if p := strings.TrimSuffix(filter.ModulePrefix, "/"); p != "" {
+		q = q.Where("(module_path = ? OR starts_with(module_path, ?))", p, p+"/")
+	}
+	query, args, err := q.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if err != nil {
+		return nil, err
+	}
+	var versions []*internal.ModuleVersionState
+	err = db.db.RunQuery(ctx, query, func(rows *sql.Rows) error {
+		v, err := scanModuleVersionState(rows.Scan)
+		if err != nil {
+			return fmt.Errorf("rows.Scan(): %v", err)
+		}
+		versions = append(versions, v)
+		return nil
+	}, args...)
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// GetKnownVersions returns the versions in the module_version_states table of
+// the modules whose paths are modulePrefix or begin with modulePrefix followed
+// by a slash, by module path.
+func (db *DB) GetKnownVersions(ctx context.Context, modulePrefix string) (_ map[string][]string, err error) {
+	defer derrors.WrapStack(&err, "GetKnownVersions(ctx, %q)", modulePrefix)
+
+	p := strings.TrimSuffix(modulePrefix, "/")
+	versions := map[string][]string{}
+	err = db.db.RunQuery(ctx, `
+		SELECT module_path, version
+		FROM module_version_states
+		WHERE module_path = $1 OR starts_with(module_path, $2)`,
+		func(rows *sql.Rows) error {
+			var modulePath, version string
+			if err := rows.Scan(&modulePath, &version); err != nil {
+				return err
+			}
+			versions[modulePath] = append(versions[modulePath], version)
+			return nil
+		}, p, p+"/")
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// DeleteModuleVersionState deletes the module version state for modulePath
+// and version, along with its package version states.
+func (db *DB) DeleteModuleVersionState(ctx context.Context, modulePath, version string) (err error) {
+	defer derrors.WrapStack(&err, "DeleteModuleVersionState(ctx, %q, %q)", modulePath, version)
+
+	// Package version states are deleted by an ON DELETE CASCADE constraint.
+	_, err = db.db.Exec(ctx, `
+		DELETE FROM module_version_states
+		WHERE module_path = $1 AND version = $2
+	`, modulePath, version)
+	return err
+}
+
 // GetModuleVersionState returns the current module version state for
 // modulePath and version.
 func (db *DB) GetModuleVersionState(ctx context.Context, modulePath, resolvedVersion string) (_ *internal.ModuleVersionState, err error) {
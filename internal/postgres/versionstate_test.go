@@ -10,6 +10,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -363,6 +365,85 @@ func TestUpdateModuleVersionStatus(t *testing.T) {
 	}
 }
 
+func TestGetModuleVersionStates(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testDB, release := acquire(t)
+	defer release()
+
+	for _, mvs := range []*ModuleVersionStateForUpdate{
+		{ModulePath: "a.com", Version: "v1.0.0", Status: 200},
+		{ModulePath: "a.com/b", Version: "v1.0.0", Status: 500, FetchErr: errors.New("proxy timed out")},
+		{ModulePath: "ab.com", Version: "v1.0.0", Status: 500, FetchErr: errors.New("bad zip")},
+		{ModulePath: "c.com", Version: "v1.0.0", Status: 404, FetchErr: errors.New("not found")},
+	} {
+		must(t, testDB.InsertIndexVersions(ctx, []*internal.IndexVersion{{Path: mvs.ModulePath, Version: mvs.Version, Timestamp: time.Now()}}))
+		must(t, testDB.UpdateModuleVersionState(ctx, mvs))
+	}
+
+	for _, test := range []struct {
+		name   string
+		filter ModuleVersionStateFilter
+		want   []string
+	}{
+		{"all", ModuleVersionStateFilter{}, []string{"a.com", "a.com/b", "ab.com", "c.com"}},
+		{"statuses", ModuleVersionStateFilter{Statuses: []int{404, 500}}, []string{"a.com/b", "ab.com", "c.com"}},
+		{"error", ModuleVersionStateFilter{ErrorContains: "timed out"}, []string{"a.com/b"}},
+		{"prefix", ModuleVersionStateFilter{ModulePrefix: "a.com"}, []string{"a.com", "a.com/b"}},
+		{"all filters", ModuleVersionStateFilter{Statuses: []int{500}, ErrorContains: "zip", ModulePrefix: "ab.com/"}, []string{"ab.com"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			mvs, err := testDB.GetModuleVersionStates(ctx, test.filter, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, m := range mvs {
+				got = append(got, m.ModulePath)
+			}
+			// The rows were processed at about the same time.
+			sort.Strings(got)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+
+	must(t, testDB.DeleteModuleVersionState(ctx, "c.com", "v1.0.0"))
+	if _, err := testDB.GetModuleVersionState(ctx, "c.com", "v1.0.0"); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("after deletion, got error %v, want NotFound", err)
+	}
+}
+
+func TestGetKnownVersions(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testDB, release := acquire(t)
+	defer release()
+
+	var ivs []*internal.IndexVersion
+	for _, mv := range []string{"a.com@v1.0.0", "a.com@v1.1.0", "a.com/b@v0.1.0", "ab.com@v1.0.0"} {
+		p, v, _ := strings.Cut(mv, "@")
+		ivs = append(ivs, &internal.IndexVersion{Path: p, Version: v, Timestamp: time.Now()})
+	}
+	must(t, testDB.InsertIndexVersions(ctx, ivs))
+
+	got, err := testDB.GetKnownVersions(ctx, "a.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, vs := range got {
+		sort.Strings(vs)
+	}
+	want := map[string][]string{
+		"a.com":   {"v1.0.0", "v1.1.0"},
+		"a.com/b": {"v0.1.0"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
 func TestHasGoMod(t *testing.T) {
 	ptr := func(b bool) *bool { return &b }
 
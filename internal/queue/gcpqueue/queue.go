@@ -10,9 +10,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"hash/fnv"
-	"io"
-	"math"
 	"strings"
 	"time"
 
@@ -141,7 +138,7 @@ func (q *gcp) ScheduleFetch(ctx context.Context, modulePath, version string, opt
 }
 
 func (q *gcp) newTaskRequest(modulePath, version string, opts *queue.Options) *taskspb.CreateTaskRequest {
-	taskID := newTaskID(modulePath, version)
+	taskID := queue.NewTaskID(modulePath, version)
 	relativeURI := fmt.Sprintf("/fetch/%s/@v/%s", modulePath, version)
 	var params []string
 	if opts.Source != "" {
@@ -177,37 +174,6 @@ func (q *gcp) newTaskRequest(modulePath, version string, opts *queue.Options) *t
 	return req
 }
 
-// Create a task ID for the given module path and version.
-// Task IDs can contain only letters ([A-Za-z]), numbers ([0-9]), hyphens (-), or underscores (_).
-func newTaskID(modulePath, version string) string {
-	mv := modulePath + "@" + version
-	// Compute a hash to use as a prefix, so the task IDs are distributed uniformly.
-	// See https://cloud.google.com/tasks/docs/reference/rpc/google.cloud.tasks.v2#task
-	// under "Task De-duplication".
-	hasher := fnv.New32()
-	io.WriteString(hasher, mv)
-	hash := hasher.Sum32() % math.MaxUint16
-	// Escape the name so it contains only valid characters. Do our best to make it readable.
-	var b strings.Builder
-	for _, r := range mv {
-		switch {
-		case r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-':
-			b.WriteRune(r)
-		case r == '_':
-			b.WriteString("__")
-		case r == '/':
-			b.WriteString("_-")
-		case r == '@':
-			b.WriteString("_v")
-		case r == '.':
-			b.WriteString("_o")
-		default:
-			fmt.Fprintf(&b, "_%04x", r)
-		}
-	}
-	return fmt.Sprintf("%04x-%s", hash, &b)
-}
-
 // Maximum timeout for HTTP tasks.
 // See https://cloud.google.com/tasks/docs/creating-http-target-tasks.
 const maxCloudTasksTimeout = 30 * time.Minute
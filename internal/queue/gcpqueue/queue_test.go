@@ -15,22 +15,6 @@ import (
 	"google.golang.org/protobuf/types/known/durationpb"
 )
 
-func TestNewTaskID(t *testing.T) {
-	for _, test := range []struct {
-		modulePath, version string
-		want                string
-	}{
-		{"m-1", "v2", "acc5-m-1_vv2"},
-		{"my_module", "v1.2.3", "0cb9-my__module_vv1_o2_o3"},
-		{"µπΩ/github.com", "v2.3.4-ß", "a49c-_00b5_03c0_03a9_-github_ocom_vv2_o3_o4-_00df"},
-	} {
-		got := newTaskID(test.modulePath, test.version)
-		if got != test.want {
-			t.Errorf("%s@%s: got %s, want %s", test.modulePath, test.version, got, test.want)
-		}
-	}
-}
-
 func TestNewTaskRequest(t *testing.T) {
 	cfg := config.Config{
 		ProjectID:      "Project",
@@ -0,0 +1,315 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package pgqueue provides a queue implementation backed by a Postgres table,
+// for running the worker without Google Cloud Tasks.
+//
+// Unlike queue.InMemory, tasks survive restarts and failed tasks are retried
+// with exponential backoff. Tasks that keep failing are marked as failed and
+// kept in the table, where they can be inspected, until they are scheduled
+// again. Any number of worker processes can share one queue.
+package pgqueue
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/experiment"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/middleware"
+	"golang.org/x/pkgsite/internal/queue"
+)
+
+// Status is the status of a task in the queue.
+type Status string
+
+const (
+	// StatusPending is the status of a task waiting to be processed, either
+	// for the first time or for a retry.
+	StatusPending Status = "pending"
+	// StatusRunning is the status of a task being processed.
+	StatusRunning Status = "running"
+	// StatusDone is the status of a task that was processed.
+	StatusDone Status = "done"
+	// StatusFailed is the status of a task that ran out of attempts.
+	StatusFailed Status = "failed"
+)
+
+const (
+	// maxAttempts is the number of times a task is processed before it is
+	// marked as failed.
+	maxAttempts = 10
+
+	// minBackoff and maxBackoff bound the delay before a task is retried.
+	// The delay doubles with each attempt.
+	minBackoff = 10 * time.Second
+	maxBackoff = time.Hour
+
+	// dedupInterval is how long a done or failed task prevents a task with
+	// the same ID from being scheduled. It matches the de-duplication
+	// window of Cloud Tasks.
+	dedupInterval = time.Hour
+
+	// fetchTimeout bounds the time to process a task. A task that has been
+	// running for longer than leaseTimeout is assumed to belong to a worker
+	// that died, and is claimed again.
+	fetchTimeout = 10 * time.Minute
+	leaseTimeout = fetchTimeout + 5*time.Minute
+
+	// pollInterval is how often an idle worker looks for tasks.
+	pollInterval = 5 * time.Second
+)
+
+// A ProcessFunc processes a module version, returning an HTTP status code
+// like the worker's /fetch endpoint.
+type ProcessFunc func(ctx context.Context, modulePath, version string, opts *queue.Options) (int, error)
+
+// Queue is a queue.Queue whose tasks are stored in the queue_tasks table.
+type Queue struct {
+	db          *database.DB
+	experiments []string
+	processFunc ProcessFunc
+}
+
+// New returns a Queue that stores its tasks in db and processes them with
+// numWorkers concurrent calls to processFunc, until ctx is done.
+func New(ctx context.Context, db *database.DB, numWorkers int, expGetter middleware.ExperimentGetter, processFunc ProcessFunc) (_ *Queue, err error) {
+	defer derrors.Wrap(&err, "pgqueue.New(%d)", numWorkers)
+	experiments, err := expGetter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q := &Queue{db: db, processFunc: processFunc}
+	for _, e := range experiments {
+		if e.Rollout > 0 {
+			q.experiments = append(q.experiments, e.Name)
+		}
+	}
+	for i := 0; i < numWorkers; i++ {
+		go q.work(ctx)
+	}
+	return q, nil
+}
+
+// ScheduleFetch inserts a task to fetch the given module version. As with
+// Cloud Tasks, it returns (false, nil) if a task with the same ID was scheduled
+// recently; opts.Suffix can be used to force a new task.
+func (q *Queue) ScheduleFetch(ctx context.Context, modulePath, version string, opts *queue.Options) (enqueued bool, err error) {
+	defer derrors.WrapStack(&err, "pgqueue.ScheduleFetch(%q, %q, %v)", modulePath, version, opts)
+	if opts == nil {
+		opts = &queue.Options{}
+	}
+	if modulePath == internal.UnknownModulePath {
+		return false, errors.New("given unknown module path")
+	}
+	taskID := queue.NewTaskID(modulePath, version)
+	if opts.Suffix != "" {
+		taskID += "-" + opts.Suffix
+	}
+	// Replace a done or failed task only once it is older than the
+	// de-duplication interval.
+	n, err := q.db.Exec(ctx, `
+		INSERT INTO queue_tasks (task_id, module_path, version, disable_proxy_fetch, source)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (task_id) DO UPDATE
+		SET
+			disable_proxy_fetch = excluded.disable_proxy_fetch,
+			source = excluded.source,
+			status = 'pending',
+			num_attempts = 0,
+			next_attempt_at = CURRENT_TIMESTAMP,
+			last_status = 0,
+			last_error = '',
+			created_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE queue_tasks.status IN ('done', 'failed')
+		AND queue_tasks.updated_at < CURRENT_TIMESTAMP - make_interval(secs => $6)`,
+		taskID, modulePath, version, opts.DisableProxyFetch, opts.Source, dedupInterval.Seconds())
+	if err != nil {
+		return false, err
+	}
+	if n == 0 {
+		log.Debugf(ctx, "ignoring duplicate task ID %s: %s@%s", taskID, modulePath, version)
+		return false, nil
+	}
+	return true, nil
+}
+
+// work processes tasks until ctx is done.
+func (q *Queue) work(ctx context.Context) {
+	for {
+		processed, err := q.processNext(ctx)
+		if err != nil {
+			log.Errorf(ctx, "pgqueue: %v", err)
+		}
+		if processed && err == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// processNext claims a task and processes it. It reports whether there was a
+// task to process.
+func (q *Queue) processNext(ctx context.Context) (_ bool, err error) {
+	defer derrors.Wrap(&err, "processNext")
+	t, err := q.claim(ctx)
+	if err != nil || t == nil {
+		return false, err
+	}
+	log.Infof(ctx, "Fetch requested: %s@%s (attempt %d)", t.ModulePath, t.Version, t.NumAttempts)
+
+	fetchCtx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+	fetchCtx = experiment.NewContext(fetchCtx, q.experiments...)
+	opts := &queue.Options{DisableProxyFetch: t.DisableProxyFetch, Source: t.Source}
+	code, perr := q.processFunc(fetchCtx, t.ModulePath, t.Version, opts)
+	if perr != nil {
+		log.Errorf(ctx, "processing %s@%s: %v", t.ModulePath, t.Version, perr)
+	}
+	return true, q.finish(ctx, t, code, perr)
+}
+
+// claim marks the next pending task as running and returns it, or returns nil
+// if there are none. Workers skip the tasks that other workers are claiming.
+func (q *Queue) claim(ctx context.Context) (_ *Task, err error) {
+	defer derrors.Wrap(&err, "claim")
+	var t Task
+	err = q.db.QueryRow(ctx, `
+		UPDATE queue_tasks
+		SET
+			status = 'running',
+			num_attempts = num_attempts + 1,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE task_id = (
+			SELECT task_id
+			FROM queue_tasks
+			WHERE (status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP)
+			OR (status = 'running' AND updated_at < CURRENT_TIMESTAMP - make_interval(secs => $1))
+			ORDER BY next_attempt_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+taskColumns,
+		leaseTimeout.Seconds()).Scan(database.StructScanner[Task]()(&t)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// finish records the result of processing t, unless another worker has
+// claimed t since, because processing it took longer than leaseTimeout. Each
+// claim increments the number of attempts, which serves as the lease.
+func (q *Queue) finish(ctx context.Context, t *Task, code int, perr error) (err error) {
+	defer derrors.Wrap(&err, "finish(%q, %d)", t.ID, code)
+	var errMsg string
+	if perr != nil {
+		errMsg = perr.Error()
+	}
+	status := StatusDone
+	if retryable(code) {
+		status = StatusPending
+		if t.NumAttempts >= maxAttempts {
+			status = StatusFailed
+		}
+	}
+	n, err := q.db.Exec(ctx, `
+		UPDATE queue_tasks
+		SET
+			status = $2,
+			next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $3),
+			last_status = $4,
+			last_error = $5,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE task_id = $1
+		AND status = 'running'
+		AND num_attempts = $6`,
+		t.ID, status, backoff(t.NumAttempts).Seconds(), code, errMsg, t.NumAttempts)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		log.Warningf(ctx, "pgqueue: lost task %s (attempt %d) to another worker; dropping its result", t.ID, t.NumAttempts)
+		return nil
+	}
+	// Done tasks are only needed for de-duplication. Remove old ones while
+	// we're here.
+	_, err = q.db.Exec(ctx, `
+		DELETE FROM queue_tasks
+		WHERE status = 'done'
+		AND updated_at < CURRENT_TIMESTAMP - make_interval(secs => $1)`,
+		dedupInterval.Seconds())
+	return err
+}
+
+// retryable reports whether a task that resulted in code should be retried.
+// It matches the codes for which the worker's /fetch endpoint asks Cloud
+// Tasks to retry.
+func retryable(code int) bool {
+	return code == http.StatusInternalServerError ||
+		code == http.StatusServiceUnavailable ||
+		code == derrors.ToStatus(derrors.ProxyTimedOut)
+}
+
+// backoff returns the delay before retrying a task that has been attempted n
+// times.
+func backoff(n int) time.Duration {
+	d := minBackoff
+	for i := 1; i < n; i++ {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return d
+}
+
+// A Task is a task in the queue.
+type Task struct {
+	ID                string
+	ModulePath        string
+	Version           string
+	DisableProxyFetch bool
+	Source            string
+	Status            Status
+	NumAttempts       int
+	NextAttempt       time.Time
+	LastStatus        int
+	LastError         string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// taskColumns are the columns of queue_tasks, in the order of the fields of
+// Task.
+const taskColumns = `
+	task_id, module_path, version, disable_proxy_fetch, source, status,
+	num_attempts, next_attempt_at, last_status, last_error, created_at, updated_at`
+
+// Tasks returns up to limit tasks with the given status, most recently updated
+// first.
+func (q *Queue) Tasks(ctx context.Context, status Status, limit int) (_ []*Task, err error) {
+	defer derrors.Wrap(&err, "Tasks(%q, %d)", status, limit)
+	return database.CollectStructPtrs[Task](ctx, q.db, fmt.Sprintf(`
+		SELECT %s
+		FROM queue_tasks
+		WHERE status = $1
+		ORDER BY updated_at DESC
+		LIMIT $2`, taskColumns), status, limit)
+}
@@ -0,0 +1,191 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pgqueue
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/queue"
+)
+
+var testDB *postgres.DB
+
+func TestMain(m *testing.M) {
+	postgres.RunDBTests("discovery_pgqueue_test", m, &testDB)
+}
+
+func TestBackoff(t *testing.T) {
+	for _, test := range []struct {
+		n    int
+		want time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 40 * time.Second},
+		{9, 2560 * time.Second},
+		{10, time.Hour},
+		{100, time.Hour},
+	} {
+		if got := backoff(test.n); got != test.want {
+			t.Errorf("backoff(%d) = %s, want %s", test.n, got, test.want)
+		}
+	}
+}
+
+func TestScheduleFetch(t *testing.T) {
+	ctx := context.Background()
+	defer postgres.ResetTestDB(testDB, t)
+	q := &Queue{db: testDB.Underlying()}
+
+	for _, test := range []struct {
+		opts *queue.Options
+		want bool
+	}{
+		{nil, true},
+		{nil, false},                        // duplicate
+		{&queue.Options{Suffix: "x"}, true}, // forced
+		{&queue.Options{Source: "worker"}, false}, // duplicate
+	} {
+		got, err := q.ScheduleFetch(ctx, "m.com", "v1.0.0", test.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("ScheduleFetch(%+v) = %t, want %t", test.opts, got, test.want)
+		}
+	}
+	tasks, err := q.Tasks(ctx, StatusPending, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 {
+		t.Errorf("got %d pending tasks, want 2", len(tasks))
+	}
+}
+
+func TestProcess(t *testing.T) {
+	ctx := context.Background()
+	defer postgres.ResetTestDB(testDB, t)
+
+	var (
+		code  int
+		gotMV string
+	)
+	q := &Queue{
+		db: testDB.Underlying(),
+		processFunc: func(ctx context.Context, modulePath, version string, opts *queue.Options) (int, error) {
+			gotMV = modulePath + "@" + version
+			if code != http.StatusOK {
+				return code, errors.New("bad")
+			}
+			return code, nil
+		},
+	}
+	if _, err := q.ScheduleFetch(ctx, "m.com", "v1.0.0", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// A retryable failure leaves the task pending, but not ready for a
+	// while.
+	code = http.StatusInternalServerError
+	processed, err := q.processNext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !processed || gotMV != "m.com@v1.0.0" {
+		t.Fatalf("got (%t, %q), want (true, m.com@v1.0.0)", processed, gotMV)
+	}
+	checkTask(ctx, t, q, StatusPending, 1, http.StatusInternalServerError)
+	processed, err = q.processNext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if processed {
+		t.Error("processed task before its next attempt time")
+	}
+
+	// After the last attempt, the task fails.
+	if _, err := testDB.Underlying().Exec(ctx, `
+		UPDATE queue_tasks SET num_attempts = $1, next_attempt_at = CURRENT_TIMESTAMP`,
+		maxAttempts-1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.processNext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	checkTask(ctx, t, q, StatusFailed, maxAttempts, http.StatusInternalServerError)
+
+	// A terminal error is not retried.
+	code = http.StatusNotFound
+	if _, err := q.ScheduleFetch(ctx, "m.com", "v1.0.0", &queue.Options{Suffix: "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.processNext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	tasks, err := q.Tasks(ctx, StatusDone, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].LastStatus != http.StatusNotFound {
+		t.Errorf("got done tasks %+v, want one with status 404", tasks)
+	}
+}
+
+func TestFinishLostLease(t *testing.T) {
+	ctx := context.Background()
+	defer postgres.ResetTestDB(testDB, t)
+	q := &Queue{db: testDB.Underlying()}
+
+	if _, err := q.ScheduleFetch(ctx, "m.com", "v1.0.0", nil); err != nil {
+		t.Fatal(err)
+	}
+	stale, err := q.claim(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The lease times out, and another worker claims the task.
+	if _, err := testDB.Underlying().Exec(ctx, `
+		UPDATE queue_tasks SET updated_at = CURRENT_TIMESTAMP - make_interval(secs => $1)`,
+		2*leaseTimeout.Seconds()); err != nil {
+		t.Fatal(err)
+	}
+	current, err := q.claim(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current == nil || current.NumAttempts != 2 {
+		t.Fatalf("got %+v, want the task at attempt 2", current)
+	}
+
+	// The first worker's result is dropped.
+	if err := q.finish(ctx, stale, http.StatusOK, nil); err != nil {
+		t.Fatal(err)
+	}
+	checkTask(ctx, t, q, StatusRunning, 2, 0)
+	if err := q.finish(ctx, current, http.StatusNotFound, nil); err != nil {
+		t.Fatal(err)
+	}
+	checkTask(ctx, t, q, StatusDone, 2, http.StatusNotFound)
+}
+
+func checkTask(ctx context.Context, t *testing.T, q *Queue, status Status, attempts, lastStatus int) {
+	t.Helper()
+	tasks, err := q.Tasks(ctx, status, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 {
+		t.Fatalf("got %d tasks with status %s, want 1", len(tasks), status)
+	}
+	if got := tasks[0]; got.NumAttempts != attempts || got.LastStatus != lastStatus {
+		t.Errorf("got (attempts, last status) = (%d, %d), want (%d, %d)", got.NumAttempts, got.LastStatus, attempts, lastStatus)
+	}
+}
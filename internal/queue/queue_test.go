@@ -0,0 +1,23 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package queue
+
+import "testing"
+
+func TestNewTaskID(t *testing.T) {
+	for _, test := range []struct {
+		modulePath, version string
+		want                string
+	}{
+		{"m-1", "v2", "acc5-m-1_vv2"},
+		{"my_module", "v1.2.3", "0cb9-my__module_vv1_o2_o3"},
+		{"µπΩ/github.com", "v2.3.4-ß", "a49c-_00b5_03c0_03a9_-github_ocom_vv2_o3_o4-_00df"},
+	} {
+		got := NewTaskID(test.modulePath, test.version)
+		if got != test.want {
+			t.Errorf("%s@%s: got %s, want %s", test.modulePath, test.version, got, test.want)
+		}
+	}
+}
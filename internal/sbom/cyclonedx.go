@@ -0,0 +1,185 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sbom
+
+import (
+	"sort"
+	"time"
+)
+
+// CycloneDXSpecVersion is the version of the CycloneDX specification of
+// CycloneDXDocuments.
+const CycloneDXSpecVersion = "1.5"
+
+// A CycloneDXDocument is a CycloneDX bill of materials, in its JSON form.
+type CycloneDXDocument struct {
+	BOMFormat       string                    `json:"bomFormat"`
+	SpecVersion     string                    `json:"specVersion"`
+	Version         int                       `json:"version"`
+	Metadata        CycloneDXMetadata         `json:"metadata"`
+	Components      []*CycloneDXComponent     `json:"components,omitempty"`
+	Dependencies    []*CycloneDXDependency    `json:"dependencies,omitempty"`
+	Vulnerabilities []*CycloneDXVulnerability `json:"vulnerabilities,omitempty"`
+}
+
+// CycloneDXMetadata says when and by what a CycloneDXDocument was created,
+// and which component it describes.
+type CycloneDXMetadata struct {
+	Timestamp string              `json:"timestamp"` // RFC 3339, in UTC
+	Tools     CycloneDXTools      `json:"tools"`
+	Component *CycloneDXComponent `json:"component"`
+}
+
+// CycloneDXTools are the tools that created a CycloneDXDocument.
+type CycloneDXTools struct {
+	Components []*CycloneDXComponent `json:"components"`
+}
+
+// A CycloneDXComponent is a module, a package or a tool.
+type CycloneDXComponent struct {
+	Type       string                    `json:"type"`
+	BOMRef     string                    `json:"bom-ref,omitempty"`
+	Name       string                    `json:"name"`
+	Version    string                    `json:"version,omitempty"`
+	Purl       string                    `json:"purl,omitempty"`
+	Licenses   []*CycloneDXLicenseChoice `json:"licenses,omitempty"`
+	Properties []*CycloneDXProperty      `json:"properties,omitempty"`
+	Components []*CycloneDXComponent     `json:"components,omitempty"`
+}
+
+// A CycloneDXLicenseChoice is one of the licenses of a component.
+type CycloneDXLicenseChoice struct {
+	License *CycloneDXLicense `json:"license"`
+}
+
+// A CycloneDXLicense is identified by its SPDX license identifier, or
+// otherwise by its name.
+type CycloneDXLicense struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// A CycloneDXProperty is a name-value pair of information about a component
+// that CycloneDX has no field for.
+type CycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// A CycloneDXDependency lists the components that a component depends on.
+type CycloneDXDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// A CycloneDXVulnerability is a vulnerability affecting components.
+type CycloneDXVulnerability struct {
+	ID          string             `json:"id"`
+	Source      *CycloneDXSource   `json:"source,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Affects     []*CycloneDXAffect `json:"affects"`
+}
+
+// A CycloneDXSource is where a vulnerability is described.
+type CycloneDXSource struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+// A CycloneDXAffect refers to a component affected by a vulnerability.
+type CycloneDXAffect struct {
+	Ref string `json:"ref"`
+}
+
+// Names of the properties of CycloneDXComponents.
+const (
+	propertyComment  = "pkgsite:comment"
+	propertyIndirect = "pkgsite:indirect"
+)
+
+// CycloneDX returns a CycloneDX bill of materials describing m. The module
+// is the component of the metadata, with its packages as subcomponents; the
+// modules it requires are the components of the document. Created is the
+// time the document was created.
+func CycloneDX(m *Module, created time.Time) *CycloneDXDocument {
+	component := func(c *Component, typ, bomRef, purl string) *CycloneDXComponent {
+		cc := &CycloneDXComponent{
+			Type:     typ,
+			BOMRef:   bomRef,
+			Name:     c.Path,
+			Version:  c.Version,
+			Purl:     purl,
+			Licenses: cycloneDXLicenses(c.LicenseTypes),
+		}
+		if c.Comment != "" {
+			cc.Properties = append(cc.Properties, &CycloneDXProperty{Name: propertyComment, Value: c.Comment})
+		}
+		return cc
+	}
+	mainRef := PackageURL(m.Path, m.Version)
+	main := component(&m.Component, "library", mainRef, mainRef)
+	for _, p := range m.Packages {
+		pc := *p
+		pc.Version = m.Version
+		main.Components = append(main.Components, component(&pc, "library", "package:"+p.Path+"@"+m.Version, ""))
+	}
+	doc := &CycloneDXDocument{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: CycloneDXSpecVersion,
+		Version:     1,
+		Metadata: CycloneDXMetadata{
+			Timestamp: created.UTC().Format(time.RFC3339),
+			Tools: CycloneDXTools{
+				Components: []*CycloneDXComponent{{Type: "application", Name: "pkgsite"}},
+			},
+			Component: main,
+		},
+	}
+	dep := &CycloneDXDependency{Ref: mainRef}
+	for _, d := range m.Dependencies {
+		ref := PackageURL(d.Path, d.Version)
+		dc := component(&d.Component, "library", ref, ref)
+		if d.Indirect {
+			dc.Properties = append(dc.Properties, &CycloneDXProperty{Name: propertyIndirect, Value: "true"})
+		}
+		doc.Components = append(doc.Components, dc)
+		dep.DependsOn = append(dep.DependsOn, ref)
+	}
+	doc.Dependencies = []*CycloneDXDependency{dep}
+
+	byID := map[string]*CycloneDXVulnerability{}
+	for _, v := range m.Vulns {
+		cv := byID[v.ID]
+		if cv == nil {
+			cv = &CycloneDXVulnerability{
+				ID:          v.ID,
+				Source:      &CycloneDXSource{Name: "Go Vulnerability Database", URL: v.URL},
+				Description: v.Summary,
+			}
+			byID[v.ID] = cv
+			doc.Vulnerabilities = append(doc.Vulnerabilities, cv)
+		}
+		cv.Affects = append(cv.Affects, &CycloneDXAffect{Ref: PackageURL(v.ModulePath, v.Version)})
+	}
+	sort.Slice(doc.Vulnerabilities, func(i, j int) bool {
+		return doc.Vulnerabilities[i].ID < doc.Vulnerabilities[j].ID
+	})
+	return doc
+}
+
+// cycloneDXLicenses returns the licenses with the given types. Those with
+// SPDX license identifiers are identified by them, and the others by their
+// types, including "UNKNOWN" for unrecognized license texts.
+func cycloneDXLicenses(types []string) []*CycloneDXLicenseChoice {
+	var lcs []*CycloneDXLicenseChoice
+	for _, t := range uniqueTypes(&Component{LicenseTypes: types}) {
+		l := &CycloneDXLicense{Name: t}
+		if IsSPDXLicense(t) {
+			l = &CycloneDXLicense{ID: t}
+		}
+		lcs = append(lcs, &CycloneDXLicenseChoice{License: l})
+	}
+	return lcs
+}
@@ -0,0 +1,124 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sbom describes modules and their dependencies in the standard
+// software bill of materials formats, SPDX and CycloneDX.
+package sbom
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"golang.org/x/pkgsite/internal/licenses"
+)
+
+// A Module is a module version to describe, along with what is known about
+// its packages and dependencies.
+type Module struct {
+	Component
+
+	// Packages are the packages of the module, sorted by path. Their
+	// versions are the module's version.
+	Packages []*Component
+
+	// Dependencies are the modules required by the module.
+	Dependencies []*Dependency
+
+	// Vulns are the known vulnerabilities of the module and its
+	// dependencies.
+	Vulns []*Vuln
+}
+
+// A Component is a module or a package.
+type Component struct {
+	Path    string
+	Version string
+
+	// LicenseTypes are the types of the licenses that apply to the
+	// component, as reported by the licenses package.
+	LicenseTypes []string
+
+	// Comment is a note about the component, like a problem with its
+	// licenses, or empty.
+	Comment string
+}
+
+// A Dependency is a module required by a Module.
+type Dependency struct {
+	Component
+
+	// Indirect reports whether the requirement is marked // indirect.
+	Indirect bool
+}
+
+// A Vuln is a vulnerability that affects a module.
+type Vuln struct {
+	// ID is the ID of the vulnerability in the Go vulnerability database.
+	ID string
+
+	// Summary is a short description of the vulnerability.
+	Summary string
+
+	// URL is the URL of the vulnerability's description.
+	URL string
+
+	// ModulePath and Version identify the affected module, which is the
+	// described Module or one of its Dependencies.
+	ModulePath string
+	Version    string
+}
+
+// PackageURL returns the package URL (purl) of the module version with the
+// given path and version, like "pkg:golang/golang.org/x/text@v0.3.0". The
+// version may be empty.
+func PackageURL(modulePath, version string) string {
+	parts := strings.Split(modulePath, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	purl := "pkg:golang/" + strings.Join(parts, "/")
+	if version != "" {
+		purl += "@" + url.PathEscape(version)
+	}
+	return purl
+}
+
+// customLicenseTexts returns the texts of the custom licenses of the active
+// license policy, by type.
+func customLicenseTexts() map[string]string {
+	texts := map[string]string{}
+	if p := licenses.ActivePolicy(); p != nil {
+		for _, l := range p.Licenses {
+			texts[l.Type] = l.Text
+		}
+	}
+	return texts
+}
+
+// uniqueTypes returns the license types of cs, without duplicates, sorted.
+func uniqueTypes(cs ...*Component) []string {
+	seen := map[string]bool{}
+	var types []string
+	for _, c := range cs {
+		for _, t := range c.LicenseTypes {
+			if !seen[t] {
+				seen[t] = true
+				types = append(types, t)
+			}
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+// components returns the module, its packages and its dependencies.
+func (m *Module) components() []*Component {
+	cs := []*Component{&m.Component}
+	cs = append(cs, m.Packages...)
+	for _, d := range m.Dependencies {
+		cs = append(cs, &d.Component)
+	}
+	return cs
+}
@@ -0,0 +1,157 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sbom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var testModule = &Module{
+	Component: Component{Path: "example.com/m", Version: "v1.0.0", LicenseTypes: []string{"MIT"}},
+	Packages: []*Component{
+		{Path: "example.com/m", LicenseTypes: []string{"MIT"}},
+		{Path: "example.com/m/p", LicenseTypes: []string{"MIT", "Acme"}},
+	},
+	Dependencies: []*Dependency{
+		{Component: Component{Path: "example.com/dep", Version: "v0.1.0", LicenseTypes: []string{"UNKNOWN"}, Comment: "unrecognized license"}, Indirect: true},
+	},
+	Vulns: []*Vuln{
+		{ID: "GO-2023-0001", Summary: "bad", URL: "https://pkg.go.dev/vuln/GO-2023-0001", ModulePath: "example.com/dep", Version: "v0.1.0"},
+	},
+}
+
+var testTime = time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func TestSPDX(t *testing.T) {
+	got := SPDX(testModule, "https://example.com/sbom/example.com/m@v1.0.0", testTime)
+	want := &SPDXDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              "example.com/m@v1.0.0",
+		DocumentNamespace: "https://example.com/sbom/example.com/m@v1.0.0",
+		CreationInfo:      SPDXCreationInfo{Created: "2023-01-02T03:04:05Z", Creators: []string{"Tool: pkgsite"}},
+		Packages: []*SPDXPackage{
+			{
+				Name:             "example.com/m",
+				SPDXID:           "SPDXRef-Module-example.com-m-v1.0.0",
+				VersionInfo:      "v1.0.0",
+				DownloadLocation: NoAssertion,
+				LicenseConcluded: NoAssertion,
+				LicenseDeclared:  "MIT",
+				CopyrightText:    NoAssertion,
+				ExternalRefs:     []*SPDXExternalRef{PurlRef("example.com/m", "v1.0.0")},
+			},
+			{
+				Name:             "example.com/m",
+				SPDXID:           "SPDXRef-Package-example.com-m-v1.0.0",
+				VersionInfo:      "v1.0.0",
+				DownloadLocation: NoAssertion,
+				LicenseConcluded: NoAssertion,
+				LicenseDeclared:  "MIT",
+				CopyrightText:    NoAssertion,
+			},
+			{
+				Name:             "example.com/m/p",
+				SPDXID:           "SPDXRef-Package-example.com-m-p-v1.0.0",
+				VersionInfo:      "v1.0.0",
+				DownloadLocation: NoAssertion,
+				LicenseConcluded: NoAssertion,
+				LicenseDeclared:  "LicenseRef-Acme AND MIT",
+				CopyrightText:    NoAssertion,
+			},
+			{
+				Name:             "example.com/dep",
+				SPDXID:           "SPDXRef-Module-example.com-dep-v0.1.0",
+				VersionInfo:      "v0.1.0",
+				DownloadLocation: NoAssertion,
+				LicenseConcluded: NoAssertion,
+				LicenseDeclared:  NoAssertion,
+				CopyrightText:    NoAssertion,
+				Comment:          "unrecognized license",
+				ExternalRefs: []*SPDXExternalRef{
+					PurlRef("example.com/dep", "v0.1.0"),
+					{ReferenceCategory: "SECURITY", ReferenceType: "advisory", ReferenceLocator: "https://pkg.go.dev/vuln/GO-2023-0001"},
+				},
+			},
+		},
+		Relationships: []*SPDXRelationship{
+			{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-Module-example.com-m-v1.0.0"},
+			{SPDXElementID: "SPDXRef-Module-example.com-m-v1.0.0", RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-Package-example.com-m-v1.0.0"},
+			{SPDXElementID: "SPDXRef-Module-example.com-m-v1.0.0", RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-Package-example.com-m-p-v1.0.0"},
+			{SPDXElementID: "SPDXRef-Module-example.com-m-v1.0.0", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Module-example.com-dep-v0.1.0", Comment: "indirect"},
+		},
+		ExtractedLicenses: []*SPDXExtractedLicense{{LicenseID: "LicenseRef-Acme", ExtractedText: NoAssertion, Name: "Acme"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestCycloneDX(t *testing.T) {
+	got := CycloneDX(testModule, testTime)
+	mainRef := "pkg:golang/example.com/m@v1.0.0"
+	depRef := "pkg:golang/example.com/dep@v0.1.0"
+	mit := []*CycloneDXLicenseChoice{{License: &CycloneDXLicense{ID: "MIT"}}}
+	want := &CycloneDXDocument{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+		Metadata: CycloneDXMetadata{
+			Timestamp: "2023-01-02T03:04:05Z",
+			Tools:     CycloneDXTools{Components: []*CycloneDXComponent{{Type: "application", Name: "pkgsite"}}},
+			Component: &CycloneDXComponent{
+				Type:     "library",
+				BOMRef:   mainRef,
+				Name:     "example.com/m",
+				Version:  "v1.0.0",
+				Purl:     mainRef,
+				Licenses: mit,
+				Components: []*CycloneDXComponent{
+					{Type: "library", BOMRef: "package:example.com/m@v1.0.0", Name: "example.com/m", Version: "v1.0.0", Licenses: mit},
+					{
+						Type:    "library",
+						BOMRef:  "package:example.com/m/p@v1.0.0",
+						Name:    "example.com/m/p",
+						Version: "v1.0.0",
+						Licenses: []*CycloneDXLicenseChoice{
+							{License: &CycloneDXLicense{Name: "Acme"}},
+							{License: &CycloneDXLicense{ID: "MIT"}},
+						},
+					},
+				},
+			},
+		},
+		Components: []*CycloneDXComponent{{
+			Type:     "library",
+			BOMRef:   depRef,
+			Name:     "example.com/dep",
+			Version:  "v0.1.0",
+			Purl:     depRef,
+			Licenses: []*CycloneDXLicenseChoice{{License: &CycloneDXLicense{Name: "UNKNOWN"}}},
+			Properties: []*CycloneDXProperty{
+				{Name: "pkgsite:comment", Value: "unrecognized license"},
+				{Name: "pkgsite:indirect", Value: "true"},
+			},
+		}},
+		Dependencies: []*CycloneDXDependency{{Ref: mainRef, DependsOn: []string{depRef}}},
+		Vulnerabilities: []*CycloneDXVulnerability{{
+			ID:          "GO-2023-0001",
+			Source:      &CycloneDXSource{Name: "Go Vulnerability Database", URL: "https://pkg.go.dev/vuln/GO-2023-0001"},
+			Description: "bad",
+			Affects:     []*CycloneDXAffect{{Ref: depRef}},
+		}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if _, err := json.Marshal(got); err != nil {
+		t.Fatal(err)
+	}
+}
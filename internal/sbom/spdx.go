@@ -0,0 +1,258 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sbom
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/licensecheck"
+	"golang.org/x/pkgsite/internal/licenses"
+)
+
+// SPDXVersion is the version of the SPDX specification of SPDXDocuments.
+const SPDXVersion = "SPDX-2.3"
+
+// NoAssertion is the SPDX value for information that wasn't determined.
+const NoAssertion = "NOASSERTION"
+
+// An SPDXDocument is an SPDX document, in its JSON form.
+type SPDXDocument struct {
+	SPDXVersion       string                  `json:"spdxVersion"`
+	DataLicense       string                  `json:"dataLicense"`
+	SPDXID            string                  `json:"SPDXID"`
+	Name              string                  `json:"name"`
+	DocumentNamespace string                  `json:"documentNamespace"`
+	CreationInfo      SPDXCreationInfo        `json:"creationInfo"`
+	Packages          []*SPDXPackage          `json:"packages"`
+	Relationships     []*SPDXRelationship     `json:"relationships,omitempty"`
+	ExtractedLicenses []*SPDXExtractedLicense `json:"hasExtractedLicensingInfos,omitempty"`
+}
+
+// SPDXCreationInfo says when and by what an SPDXDocument was created.
+type SPDXCreationInfo struct {
+	Created  string   `json:"created"` // RFC 3339, in UTC
+	Creators []string `json:"creators"`
+}
+
+// An SPDXPackage describes a module or a package. SPDX packages are units of
+// distribution, so in SPDX terms a Go module is a package too.
+type SPDXPackage struct {
+	Name             string             `json:"name"`
+	SPDXID           string             `json:"SPDXID"`
+	VersionInfo      string             `json:"versionInfo,omitempty"`
+	DownloadLocation string             `json:"downloadLocation"`
+	FilesAnalyzed    bool               `json:"filesAnalyzed"`
+	LicenseConcluded string             `json:"licenseConcluded"`
+	LicenseDeclared  string             `json:"licenseDeclared"`
+	CopyrightText    string             `json:"copyrightText"`
+	Comment          string             `json:"comment,omitempty"`
+	ExternalRefs     []*SPDXExternalRef `json:"externalRefs,omitempty"`
+}
+
+// An SPDXExternalRef identifies a package outside of SPDX, like by its
+// package URL.
+type SPDXExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+// An SPDXRelationship relates two elements of a document, like a module and
+// one of its dependencies.
+type SPDXRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+	Comment            string `json:"comment,omitempty"`
+}
+
+// An SPDXExtractedLicense describes a license that has no SPDX identifier,
+// and is referred to as LicenseRef-<name> in license expressions.
+type SPDXExtractedLicense struct {
+	LicenseID     string `json:"licenseId"`
+	ExtractedText string `json:"extractedText"`
+	Name          string `json:"name,omitempty"`
+}
+
+// PurlRef returns the external reference to the module version with the given
+// path and version by its package URL.
+func PurlRef(modulePath, version string) *SPDXExternalRef {
+	return &SPDXExternalRef{
+		ReferenceCategory: "PACKAGE-MANAGER",
+		ReferenceType:     "purl",
+		ReferenceLocator:  PackageURL(modulePath, version),
+	}
+}
+
+var badIDChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// SPDXID returns an SPDX element identifier made from the given parts, like
+// "SPDXRef-Module-golang.org-x-text-v0.3.0".
+func SPDXID(parts ...string) string {
+	return "SPDXRef-" + badIDChars.ReplaceAllString(strings.Join(parts, "-"), "-")
+}
+
+// LicenseRef returns the SPDX reference for a license type that is not an
+// SPDX license identifier.
+func LicenseRef(licenseType string) string {
+	return "LicenseRef-" + badIDChars.ReplaceAllString(licenseType, "-")
+}
+
+// nonSPDXTypes are the license types reported by licensecheck that aren't
+// SPDX license identifiers.
+var nonSPDXTypes = map[string]bool{
+	"Anti996":            true,
+	"CommonsClause":      true,
+	"GPL-2.0-or-3.0":     true,
+	"GooglePatentClause": true,
+	"GooglePatentsFile":  true,
+}
+
+var (
+	spdxTypesOnce sync.Once
+	spdxTypes     map[string]bool
+)
+
+// IsSPDXLicense reports whether the license type is an SPDX license
+// identifier. Most of the types reported by licensecheck are, but the
+// types of custom licenses are not.
+func IsSPDXLicense(licenseType string) bool {
+	spdxTypesOnce.Do(func() {
+		spdxTypes = map[string]bool{}
+		for _, l := range licensecheck.BuiltinLicenses() {
+			if !nonSPDXTypes[l.ID] {
+				spdxTypes[l.ID] = true
+			}
+		}
+	})
+	return spdxTypes[licenseType]
+}
+
+// LicenseExpression returns the SPDX license expression for a module or
+// package with the given license types. Types that are not SPDX license
+// identifiers are written as LicenseRefs.
+//
+// The license terms of all the license files apply, so the expression is
+// the conjunction of the types. It is NOASSERTION if there are no types, or
+// if one of them is unknown, since then the expression can't be complete.
+func LicenseExpression(types []string) string {
+	seen := map[string]bool{}
+	var ids []string
+	for _, t := range types {
+		if t == licenses.UnknownLicenseType {
+			return NoAssertion
+		}
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		if IsSPDXLicense(t) {
+			ids = append(ids, t)
+		} else {
+			ids = append(ids, LicenseRef(t))
+		}
+	}
+	if len(ids) == 0 {
+		return NoAssertion
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, " AND ")
+}
+
+// SPDX returns an SPDX document describing m. The module, its packages and
+// its dependencies are SPDX packages, whose declared licenses are their
+// license types; pkgsite doesn't conclude anything about them. A
+// vulnerability is a security reference of the module it affects.
+//
+// The namespace is a URI unique to the document, and created is the time the
+// document was created.
+func SPDX(m *Module, namespace string, created time.Time) *SPDXDocument {
+	doc := &SPDXDocument{
+		SPDXVersion:       SPDXVersion,
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              m.Path + "@" + m.Version,
+		DocumentNamespace: namespace,
+		CreationInfo: SPDXCreationInfo{
+			Created:  created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: pkgsite"},
+		},
+	}
+	advisories := map[string][]*SPDXExternalRef{}
+	for _, v := range m.Vulns {
+		if v.URL == "" {
+			continue
+		}
+		key := v.ModulePath + "@" + v.Version
+		advisories[key] = append(advisories[key], &SPDXExternalRef{
+			ReferenceCategory: "SECURITY",
+			ReferenceType:     "advisory",
+			ReferenceLocator:  v.URL,
+		})
+	}
+	addPackage := func(c *Component, id, version string, isModule bool) {
+		p := &SPDXPackage{
+			Name:             c.Path,
+			SPDXID:           id,
+			VersionInfo:      version,
+			DownloadLocation: NoAssertion,
+			LicenseConcluded: NoAssertion,
+			CopyrightText:    NoAssertion,
+			LicenseDeclared:  LicenseExpression(c.LicenseTypes),
+			Comment:          c.Comment,
+		}
+		if isModule {
+			p.ExternalRefs = append([]*SPDXExternalRef{PurlRef(c.Path, version)}, advisories[c.Path+"@"+version]...)
+		}
+		doc.Packages = append(doc.Packages, p)
+	}
+	relate := func(from, typ, to, comment string) {
+		doc.Relationships = append(doc.Relationships, &SPDXRelationship{
+			SPDXElementID:      from,
+			RelationshipType:   typ,
+			RelatedSPDXElement: to,
+			Comment:            comment,
+		})
+	}
+
+	mainID := SPDXID("Module", m.Path, m.Version)
+	addPackage(&m.Component, mainID, m.Version, true)
+	relate(doc.SPDXID, "DESCRIBES", mainID, "")
+	for _, p := range m.Packages {
+		id := SPDXID("Package", p.Path, m.Version)
+		addPackage(p, id, m.Version, false)
+		relate(mainID, "CONTAINS", id, "")
+	}
+	for _, d := range m.Dependencies {
+		id := SPDXID("Module", d.Path, d.Version)
+		addPackage(&d.Component, id, d.Version, true)
+		var comment string
+		if d.Indirect {
+			comment = "indirect"
+		}
+		relate(mainID, "DEPENDS_ON", id, comment)
+	}
+
+	texts := customLicenseTexts()
+	for _, t := range uniqueTypes(m.components()...) {
+		if t == licenses.UnknownLicenseType || IsSPDXLicense(t) {
+			continue
+		}
+		text := texts[t]
+		if text == "" {
+			text = NoAssertion
+		}
+		doc.ExtractedLicenses = append(doc.ExtractedLicenses, &SPDXExtractedLicense{
+			LicenseID:     LicenseRef(t),
+			ExtractedText: text,
+			Name:          t,
+		})
+	}
+	return doc
+}
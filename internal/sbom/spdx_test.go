@@ -0,0 +1,44 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sbom
+
+import "testing"
+
+func TestLicenseExpression(t *testing.T) {
+	for _, test := range []struct {
+		types []string
+		want  string
+	}{
+		{nil, NoAssertion},
+		{[]string{"MIT"}, "MIT"},
+		{[]string{"MIT", "Apache-2.0", "MIT"}, "Apache-2.0 AND MIT"},
+		{[]string{"MIT", "UNKNOWN"}, NoAssertion},
+		{[]string{"BSD-3-Clause", "Acme Internal", "CommonsClause"}, "BSD-3-Clause AND LicenseRef-Acme-Internal AND LicenseRef-CommonsClause"},
+	} {
+		if got := LicenseExpression(test.types); got != test.want {
+			t.Errorf("LicenseExpression(%q) = %q, want %q", test.types, got, test.want)
+		}
+	}
+}
+
+func TestPackageURL(t *testing.T) {
+	for _, test := range []struct {
+		path, version, want string
+	}{
+		{"golang.org/x/text", "v0.3.0", "pkg:golang/golang.org/x/text@v0.3.0"},
+		{"example.com/m", "", "pkg:golang/example.com/m"},
+		{"example.com/m", "v0.0.0-20230101000000-abcdef123456+incompatible", "pkg:golang/example.com/m@v0.0.0-20230101000000-abcdef123456+incompatible"},
+	} {
+		if got := PackageURL(test.path, test.version); got != test.want {
+			t.Errorf("PackageURL(%q, %q) = %q, want %q", test.path, test.version, got, test.want)
+		}
+	}
+}
+
+func TestSPDXID(t *testing.T) {
+	if got, want := SPDXID("Module", "golang.org/x/text", "v0.3.0"), "SPDXRef-Module-golang.org-x-text-v0.3.0"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -2,23 +2,31 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-package postgres
+// Package text processes text for full-text search: it splits text into
+// words, applies synonyms, and divides documents into weighted sections.
+// It has no dependencies outside the standard library, so that both the
+// Postgres search documents and the in-memory search index of cmd/pkgsite
+// can use it.
+package text
 
 import (
 	"path/filepath"
 	"strings"
 	"unicode"
-
-	"github.com/russross/blackfriday/v2"
 )
 
 const (
-	maxSectionWords   = 50
-	maxReadmeFraction = 0.5
+	// MaxSectionWords is the maximum number of words in a section of a
+	// search document.
+	MaxSectionWords = 50
+
+	// MaxReadmeFraction is the maximum fraction of a README that is
+	// included in the D section of a search document.
+	MaxReadmeFraction = 0.5
 )
 
-// SearchDocumentSections computes the B and C sections of a Postgres search
-// document from a package synopsis and a README.
+// DocumentSections computes the B, C and D sections of a search document from a
+// package synopsis and the plain text of a README.
 // By "B section" and "C section" we mean the portion of the tsvector with weight
 // "B" and "C", respectively.
 //
@@ -26,26 +34,19 @@ const (
 // The C section consists of the first sentence of the README.
 // The D section consists of the remainder of the README.
 // All sections are split into words and processed for replacements.
-// Each section is limited to maxSectionWords words, and in addition the
+// Each section is limited to maxSecWords words, and in addition the
 // D section is limited to an initial fraction of the README, determined
-// by maxReadmeFraction.
-func SearchDocumentSections(synopsis, readmeFilename, readme string) (b, c, d string) {
-	return searchDocumentSections(synopsis, readmeFilename, readme, maxSectionWords, maxReadmeFraction)
-}
-
-func searchDocumentSections(synopsis, readmeFilename, readme string, maxSecWords int, maxReadmeFrac float64) (b, c, d string) {
+// by maxReadmeFrac.
+func DocumentSections(synopsis, readme string, maxSecWords int, maxReadmeFrac float64) (b, c, d string) {
 	var readmeFirst, readmeRest string
-	if isMarkdown(readmeFilename) {
-		readme = processMarkdown(readme)
-	}
 	if i := sentenceEndIndex(readme); i > 0 {
 		readmeFirst, readmeRest = readme[:i+1], readme[i+1:]
 	} else {
 		readmeRest = readme
 	}
-	sw := processWords(synopsis)
-	rwf := processWords(readmeFirst)
-	rwr := processWords(readmeRest)
+	sw := ProcessWords(synopsis)
+	rwf := ProcessWords(readmeFirst)
+	rwr := ProcessWords(readmeRest)
 
 	sectionB, _ := split(sw, maxSecWords)
 	sectionC, rwfd := split(rwf, maxSecWords)
@@ -66,11 +67,7 @@ func searchDocumentSections(synopsis, readmeFilename, readme string, maxSecWords
 		sectionC = nil
 	}
 
-	prep := func(ws []string) string {
-		return makeValidUnicode(strings.Join(ws, " "))
-	}
-
-	return prep(sectionB), prep(sectionC), prep(sectionD)
+	return strings.Join(sectionB, " "), strings.Join(sectionC, " "), strings.Join(sectionD, " ")
 }
 
 // split splits a slice of strings into two parts. The first has length <= n,
@@ -107,8 +104,8 @@ func sentenceEndIndex(s string) int {
 	return -1
 }
 
-// processWords splits s into words at whitespace, then processes each word.
-func processWords(s string) []string {
+// ProcessWords splits s into words at whitespace, then processes each word.
+func ProcessWords(s string) []string {
 	fields := strings.Fields(strings.ToLower(s))
 	var words []string
 	for _, f := range fields {
@@ -171,44 +168,9 @@ func hyphenSplit(s string) bool {
 	return !(strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://"))
 }
 
-// isMarkdown reports whether filename says that the file contains markdown.
-func isMarkdown(filename string) bool {
+// IsMarkdown reports whether filename says that the file contains markdown.
+func IsMarkdown(filename string) bool {
 	ext := strings.ToLower(filepath.Ext(filename))
 	// https://tools.ietf.org/html/rfc7763 mentions both extensions.
 	return ext == ".md" || ext == ".markdown"
 }
-
-// processMarkdown returns the text of a markdown document.
-// It omits all formatting and images.
-func processMarkdown(s string) string {
-	parser := blackfriday.New(blackfriday.WithExtensions(blackfriday.CommonExtensions))
-	root := parser.Parse([]byte(s))
-	buf := walkMarkdown(root, nil, 0)
-	return string(buf)
-}
-
-// walkMarkdown traverses a blackfriday parse tree, extracting text.
-func walkMarkdown(n *blackfriday.Node, buf []byte, level int) []byte {
-	if n == nil {
-		return buf
-	}
-	switch n.Type {
-	case blackfriday.Image:
-		// Skip images because they usually are irrelevant to the package
-		// (badges and such).
-		return buf
-	case blackfriday.CodeBlock:
-		// Skip code blocks because they have a wide variety of unrelated symbols.
-		return buf
-	case blackfriday.Paragraph, blackfriday.Heading:
-		if len(buf) > 0 {
-			buf = append(buf, ' ')
-		}
-	default:
-		buf = append(buf, n.Literal...)
-	}
-	for c := n.FirstChild; c != nil; c = c.Next {
-		buf = walkMarkdown(c, buf, level+1)
-	}
-	return buf
-}
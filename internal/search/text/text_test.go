@@ -0,0 +1,54 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestProcessWords(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"foo", []string{"foo"}},
+		{" foo \t bar\n", []string{"foo", "bar"}},
+		{"http://foo/bar/baz?x=1", []string{"http://foo/bar/baz?x=1"}},
+		{"This, however, shall. not; stand?", []string{"this", "however", "shall", "not", "stand"}},
+		{"a postgres and NATS server over HTTP", []string{
+			"a", "postgres", "postgresql", "and", "nats", "server", "over", "http"}},
+		{"http://a-b-c.com full-text chart-parser", []string{
+			"http://a-b-c.com", "full-text", "chart-parser", "parser", "parse"}},
+	} {
+		got := ProcessWords(test.in)
+		if !cmp.Equal(got, test.want) {
+			t.Errorf("%q:\ngot  %#v\nwant %#v", test.in, got, test.want)
+		}
+	}
+}
+
+func TestSentenceEndIndex(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		in   string
+		want int
+	}{
+		{"", -1},
+		{"Hello. What's up?", 5},
+		{"unicode π∆!", 13},
+		{"D. C. Fontana?", 13},
+		{"D. c. Fontana?", 4},
+		{"no end", -1},
+	} {
+		got := sentenceEndIndex(test.in)
+		if got != test.want {
+			t.Errorf("%s: got %d, want %d", test.in, got, test.want)
+		}
+	}
+}
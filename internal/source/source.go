@@ -932,8 +932,8 @@ func FilesInfo(dir string) *Info {
 			Repo:      "{repo}/",
 			Directory: "{repo}/{dir}/",
 			File:      "{repo}/{file}",
-			Line:      "{repo}/{file}#L{line}", // not supported now, but maybe someday
-			Raw:       "{repo}/{file}",
+			Line:      "{repo}/{file}#L{line}",
+			Raw:       "{repo}/{file}?raw=1", // Go files are otherwise shown in the source view
 		},
 	}
 }
@@ -586,4 +586,6 @@ func TestFilesInfo(t *testing.T) {
 	check(info.RepoURL(), "/files/Users/bob/")
 	check(info.ModuleURL(), "/files/Users/bob/")
 	check(info.FileURL("dir/a.go"), "/files/Users/bob/dir/a.go")
+	check(info.LineURL("dir/a.go", 12), "/files/Users/bob/dir/a.go#L12")
+	check(info.RawURL("dir/a.go"), "/files/Users/bob/dir/a.go?raw=1")
 }
@@ -0,0 +1,271 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/version"
+)
+
+// InsertModule inserts a version into the database, replacing any data
+// already stored for it. It follows the semantics of postgres.DB.InsertModule:
+// non-redistributable data is removed unless the DB bypasses license checks,
+// the latest good version of the module is updated, and the imports of the
+// module are recorded for imported-by lookups only if it is the latest version.
+// It returns whether the version inserted was the latest for the given module path.
+func (db *DB) InsertModule(ctx context.Context, m *internal.Module, lmv *internal.LatestModuleVersions) (isLatest bool, err error) {
+	defer func() {
+		if m == nil {
+			derrors.WrapStack(&err, "DB.InsertModule(ctx, nil)")
+			return
+		}
+		derrors.WrapStack(&err, "DB.InsertModule(ctx, Module(%q, %q))", m.ModulePath, m.Version)
+	}()
+	defer internal.RequestState(ctx, "inserting module into DB")()
+
+	if err := internal.PrepareModuleForInsert(m, db.bypassLicenseCheck); err != nil {
+		return false, err
+	}
+	err = db.db.Transact(ctx, sql.LevelDefault, func(tx *database.DB) error {
+		moduleID, err := insertModule(ctx, tx, m)
+		if err != nil {
+			return err
+		}
+		// Replace whatever was stored for this module version before. The
+		// units' documentation, READMEs and imports are deleted with them.
+		for _, table := range []string{"units", "licenses", "module_requirements"} {
+			if _, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE module_id = ?`, moduleID); err != nil {
+				return err
+			}
+		}
+		if err := insertLicenses(ctx, tx, m, moduleID); err != nil {
+			return err
+		}
+		if err := insertRequirements(ctx, tx, m, moduleID); err != nil {
+			return err
+		}
+		if err := insertUnits(ctx, tx, m, moduleID); err != nil {
+			return err
+		}
+		latest, err := getLatestGoodVersion(ctx, tx, m.ModulePath, lmv)
+		if err != nil {
+			return err
+		}
+		// Update the DB with the latest version, even if we are not the latest.
+		// (Perhaps we just learned of a retraction that affects the good latest
+		// version.)
+		if err := updateLatestGoodVersion(ctx, tx, m.ModulePath, latest); err != nil {
+			return err
+		}
+		isLatest = m.Version == latest
+		if !isLatest {
+			return nil
+		}
+		return insertImportsUnique(ctx, tx, m)
+	})
+	if err != nil {
+		return false, err
+	}
+	return isLatest, nil
+}
+
+func insertModule(ctx context.Context, tx *database.DB, m *internal.Module) (_ int, err error) {
+	defer derrors.WrapStack(&err, "insertModule(ctx, %q, %q)", m.ModulePath, m.Version)
+
+	sourceInfoJSON, err := json.Marshal(m.SourceInfo)
+	if err != nil {
+		return 0, err
+	}
+	versionType, err := version.ParseType(m.Version)
+	if err != nil {
+		return 0, err
+	}
+	var moduleID int
+	err = tx.QueryRow(ctx, `
+		INSERT INTO modules(
+			module_path,
+			version,
+			commit_time,
+			sort_version,
+			version_type,
+			series_path,
+			source_info,
+			redistributable,
+			has_go_mod,
+			incompatible)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (module_path, version)
+		DO UPDATE SET
+			commit_time=excluded.commit_time,
+			source_info=excluded.source_info,
+			redistributable=excluded.redistributable,
+			has_go_mod=excluded.has_go_mod
+		RETURNING id`,
+		m.ModulePath,
+		m.Version,
+		m.CommitTime.UnixNano(),
+		version.ForSorting(m.Version),
+		versionType.String(),
+		m.SeriesPath(),
+		string(sourceInfoJSON),
+		m.IsRedistributable,
+		m.HasGoMod,
+		version.IsIncompatible(m.Version),
+	).Scan(&moduleID)
+	if err != nil {
+		return 0, err
+	}
+	return moduleID, nil
+}
+
+func insertLicenses(ctx context.Context, tx *database.DB, m *internal.Module, moduleID int) (err error) {
+	defer derrors.WrapStack(&err, "insertLicenses(ctx, %q, %q)", m.ModulePath, m.Version)
+
+	for _, l := range m.Licenses {
+		covJSON, err := json.Marshal(l.Coverage)
+		if err != nil {
+			return fmt.Errorf("marshalling %+v: %v", l.Coverage, err)
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO licenses (module_id, file_path, contents, types, coverage)
+			VALUES (?, ?, ?, ?, ?)`,
+			moduleID, l.FilePath, []byte(database.MakeValidUnicode(string(l.Contents))),
+			jsonStrings(l.Types), string(covJSON)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertRequirements(ctx context.Context, tx *database.DB, m *internal.Module, moduleID int) (err error) {
+	defer derrors.WrapStack(&err, "insertRequirements(ctx, %q, %q)", m.ModulePath, m.Version)
+
+	for _, r := range m.Requirements {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO module_requirements (module_id, required_path, required_version, indirect)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT DO NOTHING`,
+			moduleID, r.ModulePath, r.Version, r.Indirect); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertUnits(ctx context.Context, tx *database.DB, m *internal.Module, moduleID int) (err error) {
+	defer derrors.WrapStack(&err, "insertUnits(ctx, tx, %q, %q)", m.ModulePath, m.Version)
+
+	for _, u := range m.Units {
+		licenseTypes, licensePaths := u.LicenseTypesAndPaths()
+		var unitID int
+		err := tx.QueryRow(ctx, `
+			INSERT INTO units (module_id, path, v1path, name, license_types, license_paths, redistributable)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			RETURNING id`,
+			moduleID, u.Path, internal.V1Path(u.Path, m.ModulePath), u.Name,
+			jsonStrings(licenseTypes), jsonStrings(licensePaths), u.IsRedistributable,
+		).Scan(&unitID)
+		if err != nil {
+			return err
+		}
+		if u.Readme != nil {
+			if _, err := tx.Exec(ctx, `INSERT INTO readmes (unit_id, file_path, contents) VALUES (?, ?, ?)`,
+				unitID, u.Readme.Filepath, database.MakeValidUnicode(u.Readme.Contents)); err != nil {
+				return err
+			}
+		}
+		for _, d := range u.Documentation {
+			if err := insertDoc(ctx, tx, unitID, d, !u.IsCommand()); err != nil {
+				return err
+			}
+		}
+		for _, i := range u.Imports {
+			if _, err := tx.Exec(ctx, `INSERT OR IGNORE INTO imports (unit_id, to_path) VALUES (?, ?)`,
+				unitID, i); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// insertDoc inserts the documentation d for a unit, along with its
+// implementations and example results. If withSymbols is true, it also inserts the symbols of d's
+// API, which are used for symbol history.
+func insertDoc(ctx context.Context, tx *database.DB, unitID int, d *internal.Documentation, withSymbols bool) error {
+	var docID int
+	err := tx.QueryRow(ctx, `
+		INSERT INTO documentation (unit_id, goos, goarch, synopsis, source)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id`,
+		unitID, d.GOOS, d.GOARCH, database.MakeValidUnicode(d.Synopsis), d.Source).Scan(&docID)
+	if err != nil {
+		return err
+	}
+	for _, impl := range d.Implementations {
+		if _, err := tx.Exec(ctx, `
+			INSERT OR IGNORE INTO documentation_implementations (documentation_id, type, type_package_path, interface, interface_package_path)
+			VALUES (?, ?, ?, ?, ?)`,
+			docID, impl.Type, impl.TypePkgPath, impl.Interface, impl.InterfacePkgPath); err != nil {
+			return err
+		}
+	}
+	for name, passed := range d.ExampleResults {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO documentation_example_results (documentation_id, name, passed)
+			VALUES (?, ?, ?)`,
+			docID, name, passed); err != nil {
+			return err
+		}
+	}
+	if !withSymbols {
+		return nil
+	}
+	insert := func(sm *internal.SymbolMeta) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO documentation_symbols (documentation_id, name, parent_name, section, type, synopsis, deprecated, replacement)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			docID, sm.Name, sm.ParentName, string(sm.Section), string(sm.Kind), sm.Synopsis, sm.Deprecated, sm.Replacement)
+		return err
+	}
+	for _, s := range d.API {
+		if err := insert(&s.SymbolMeta); err != nil {
+			return err
+		}
+		for _, c := range s.Children {
+			if err := insert(c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// insertImportsUnique replaces the rows of the imports_unique table for the
+// module. It should only be called if the given module's version is the latest.
+func insertImportsUnique(ctx context.Context, tx *database.DB, m *internal.Module) (err error) {
+	defer derrors.WrapStack(&err, "insertImportsUnique(%q, %q)", m.ModulePath, m.Version)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM imports_unique WHERE from_module_path = ?`, m.ModulePath); err != nil {
+		return err
+	}
+	for _, u := range m.Units {
+		for _, i := range u.Imports {
+			if _, err := tx.Exec(ctx, `
+				INSERT OR IGNORE INTO imports_unique (from_path, from_module_path, to_path)
+				VALUES (?, ?, ?)`, u.Path, m.ModulePath, i); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+)
+
+// migrations holds the SQL files that define the schema. Each is named
+// NNNNNN_description.sql, where NNNNNN is the schema version it produces.
+// Migrations are numbered consecutively from 1, and a migration must never be
+// changed once it has been released: to change the schema, add a new one.
+//
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// migrate applies to db the migrations that are newer than its schema
+// version, which is stored in SQLite's user_version.
+func migrate(ctx context.Context, db *database.DB) (err error) {
+	defer derrors.Wrap(&err, "migrate")
+
+	var current int
+	if err := db.QueryRow(ctx, `PRAGMA user_version`).Scan(&current); err != nil {
+		return err
+	}
+	names, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return err
+	}
+	if current > len(names) {
+		return fmt.Errorf("database schema version %d is newer than the latest migration %d", current, len(names))
+	}
+	for i, name := range names {
+		v, err := migrationVersion(name)
+		if err != nil {
+			return err
+		}
+		if v != i+1 {
+			return fmt.Errorf("migration %s: want version %d", name, i+1)
+		}
+		if v <= current {
+			continue
+		}
+		contents, err := migrations.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		err = db.Transact(ctx, sql.LevelDefault, func(tx *database.DB) error {
+			if _, err := tx.Exec(ctx, string(contents)); err != nil {
+				return err
+			}
+			// PRAGMA arguments cannot be bound parameters.
+			_, err := tx.Exec(ctx, fmt.Sprintf(`PRAGMA user_version = %d`, v))
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		log.Infof(ctx, "sqlite: applied migration %s", name)
+	}
+	return nil
+}
+
+// migrationVersion returns the version number at the start of the base name
+// of a migration file.
+func migrationVersion(name string) (int, error) {
+	base := strings.TrimPrefix(name, "migrations/")
+	n, _, ok := strings.Cut(base, "_")
+	if !ok {
+		return 0, fmt.Errorf("migration %s: missing version prefix", name)
+	}
+	v, err := strconv.Atoi(n)
+	if err != nil {
+		return 0, fmt.Errorf("migration %s: %v", name, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,128 @@
+-- Copyright 2023 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+-- Columns holding lists of strings store them as JSON arrays, and times are
+-- stored as Unix nanoseconds.
+
+CREATE TABLE modules (
+	id INTEGER PRIMARY KEY,
+	module_path TEXT NOT NULL,
+	version TEXT NOT NULL,
+	commit_time INTEGER NOT NULL,
+	sort_version TEXT NOT NULL,
+	version_type TEXT NOT NULL,
+	series_path TEXT NOT NULL,
+	source_info TEXT,
+	redistributable BOOLEAN NOT NULL,
+	has_go_mod BOOLEAN NOT NULL,
+	incompatible BOOLEAN NOT NULL,
+	UNIQUE (module_path, version)
+);
+
+CREATE INDEX idx_modules_series_path ON modules(series_path);
+
+CREATE TABLE units (
+	id INTEGER PRIMARY KEY,
+	module_id INTEGER NOT NULL REFERENCES modules(id) ON DELETE CASCADE,
+	path TEXT NOT NULL,
+	v1path TEXT NOT NULL,
+	name TEXT NOT NULL,
+	license_types TEXT NOT NULL,
+	license_paths TEXT NOT NULL,
+	redistributable BOOLEAN NOT NULL,
+	UNIQUE (module_id, path)
+);
+
+CREATE INDEX idx_units_path ON units(path);
+
+CREATE INDEX idx_units_v1path ON units(v1path);
+
+CREATE TABLE documentation (
+	id INTEGER PRIMARY KEY,
+	unit_id INTEGER NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+	goos TEXT NOT NULL,
+	goarch TEXT NOT NULL,
+	synopsis TEXT NOT NULL,
+	source BLOB,
+	UNIQUE (unit_id, goos, goarch)
+);
+
+CREATE TABLE documentation_symbols (
+	documentation_id INTEGER NOT NULL REFERENCES documentation(id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	parent_name TEXT NOT NULL,
+	section TEXT NOT NULL,
+	type TEXT NOT NULL,
+	synopsis TEXT NOT NULL,
+	deprecated BOOLEAN NOT NULL DEFAULT 0,
+	replacement TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_documentation_symbols ON documentation_symbols(documentation_id);
+
+CREATE TABLE readmes (
+	unit_id INTEGER PRIMARY KEY REFERENCES units(id) ON DELETE CASCADE,
+	file_path TEXT NOT NULL,
+	contents TEXT NOT NULL
+);
+
+CREATE TABLE imports (
+	unit_id INTEGER NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+	to_path TEXT NOT NULL,
+	PRIMARY KEY (unit_id, to_path)
+);
+
+CREATE TABLE imports_unique (
+	from_path TEXT NOT NULL,
+	from_module_path TEXT NOT NULL,
+	to_path TEXT NOT NULL,
+	PRIMARY KEY (to_path, from_path, from_module_path)
+);
+
+CREATE TABLE licenses (
+	module_id INTEGER NOT NULL REFERENCES modules(id) ON DELETE CASCADE,
+	file_path TEXT NOT NULL,
+	contents BLOB NOT NULL,
+	types TEXT NOT NULL,
+	coverage TEXT NOT NULL,
+	PRIMARY KEY (module_id, file_path)
+);
+
+CREATE TABLE module_requirements (
+	module_id INTEGER NOT NULL REFERENCES modules(id) ON DELETE CASCADE,
+	required_path TEXT NOT NULL,
+	required_version TEXT NOT NULL,
+	indirect BOOLEAN NOT NULL,
+	PRIMARY KEY (module_id, required_path, required_version)
+);
+
+CREATE TABLE latest_module_versions (
+	module_path TEXT PRIMARY KEY,
+	series_path TEXT NOT NULL,
+	raw_version TEXT NOT NULL,
+	cooked_version TEXT NOT NULL,
+	good_version TEXT NOT NULL,
+	deprecated BOOLEAN NOT NULL,
+	raw_go_mod_bytes BLOB NOT NULL,
+	status INTEGER NOT NULL
+);
+
+CREATE TABLE version_map (
+	module_path TEXT NOT NULL,
+	requested_version TEXT NOT NULL,
+	resolved_version TEXT NOT NULL,
+	go_mod_path TEXT NOT NULL,
+	status INTEGER NOT NULL,
+	error TEXT NOT NULL,
+	sort_version TEXT NOT NULL,
+	module_id INTEGER NOT NULL,
+	updated_at INTEGER NOT NULL,
+	PRIMARY KEY (module_path, requested_version)
+);
+
+CREATE TABLE excluded_prefixes (
+	prefix TEXT PRIMARY KEY,
+	created_by TEXT NOT NULL,
+	reason TEXT NOT NULL
+);
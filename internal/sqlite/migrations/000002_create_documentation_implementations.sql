@@ -0,0 +1,12 @@
+-- Copyright 2023 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+CREATE TABLE documentation_implementations (
+	documentation_id INTEGER NOT NULL REFERENCES documentation(id) ON DELETE CASCADE,
+	type TEXT NOT NULL,
+	type_package_path TEXT NOT NULL,
+	interface TEXT NOT NULL,
+	interface_package_path TEXT NOT NULL,
+	PRIMARY KEY (documentation_id, type, type_package_path, interface, interface_package_path)
+);
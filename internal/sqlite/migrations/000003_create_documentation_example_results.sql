@@ -0,0 +1,10 @@
+-- Copyright 2023 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+CREATE TABLE documentation_example_results (
+	documentation_id INTEGER NOT NULL REFERENCES documentation(id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	passed BOOLEAN NOT NULL,
+	PRIMARY KEY (documentation_id, name)
+);
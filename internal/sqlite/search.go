@@ -0,0 +1,138 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+)
+
+// SearchSupport reports the search types supported by this datasource.
+// Only package search is supported.
+func (db *DB) SearchSupport() internal.SearchSupport {
+	return internal.BasicSearch
+}
+
+// Search searches for packages matching the given query.
+//
+// There is no full-text index: a package matches if every word of the query
+// appears in its path or synopsis. Only the latest version of each module is
+// searched. Results are ranked by where the words match and by the number of
+// importers.
+func (db *DB) Search(ctx context.Context, q string, opts internal.SearchOptions) (_ []*internal.SearchResult, err error) {
+	defer derrors.WrapStack(&err, "DB.Search(ctx, %q, %+v)", q, opts)
+
+	if opts.SearchSymbols {
+		return nil, fmt.Errorf("symbol search: %w", derrors.Unsupported)
+	}
+	words := strings.Fields(strings.ToLower(q))
+	if len(words) == 0 {
+		return nil, nil
+	}
+	query := `
+		SELECT
+			u.name,
+			u.path,
+			m.module_path,
+			m.version,
+			m.commit_time,
+			COALESCE((SELECT d.synopsis FROM documentation d WHERE d.unit_id = u.id LIMIT 1), ''),
+			u.license_types,
+			(SELECT COUNT(DISTINCT iu.from_path) FROM imports_unique iu
+				WHERE iu.to_path = u.path AND iu.from_module_path <> m.module_path)
+		FROM units u
+		INNER JOIN modules m ON m.id = u.module_id
+		WHERE u.name != ''
+		AND m.id = (
+			-- the latest version of the module, preferring the latest good version
+			SELECT m2.id
+			FROM modules m2
+			WHERE m2.module_path = m.module_path
+			ORDER BY
+				m2.version = (SELECT good_version FROM latest_module_versions l WHERE l.module_path = m2.module_path) DESC,
+				m2.version_type = 'release' DESC,
+				m2.sort_version DESC
+			LIMIT 1)`
+	var args []any
+	for _, w := range words {
+		query += `
+		AND (LOWER(u.path) LIKE ? ESCAPE '\'
+			OR u.id IN (SELECT unit_id FROM documentation WHERE LOWER(synopsis) LIKE ? ESCAPE '\'))`
+		pattern := "%" + escapeLike(w) + "%"
+		args = append(args, pattern, pattern)
+	}
+	var results []*internal.SearchResult
+	collect := func(rows *sql.Rows) error {
+		var r internal.SearchResult
+		if err := rows.Scan(&r.Name, &r.PackagePath, &r.ModulePath, &r.Version,
+			timeScanner{&r.CommitTime}, &r.Synopsis, stringsScanner{&r.Licenses},
+			&r.NumImportedBy); err != nil {
+			return err
+		}
+		r.Score = searchScore(&r, words)
+		results = append(results, &r)
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, query, collect, args...); err != nil {
+		return nil, err
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].PackagePath < results[j].PackagePath
+	})
+	for i, r := range results {
+		r.NumResults = uint64(len(results))
+		r.Offset = i
+	}
+	if opts.Offset > 0 {
+		if len(results) < opts.Offset {
+			return nil, nil
+		}
+		results = results[opts.Offset:]
+	}
+	if opts.MaxResults > 0 && len(results) > opts.MaxResults {
+		results = results[:opts.MaxResults]
+	}
+	return results, nil
+}
+
+// searchScore scores how well r matches the query words. A word is weighted
+// by where it appears: the package name, the last element of its path, the
+// rest of its path, or only the synopsis. The score is then scaled up by the
+// log of the number of importers, as popularity is in the postgres search.
+func searchScore(r *internal.SearchResult, words []string) float64 {
+	var score float64
+	name := strings.ToLower(r.Name)
+	pkgPath := strings.ToLower(r.PackagePath)
+	for _, w := range words {
+		switch {
+		case name == w:
+			score += 1
+		case strings.Contains(path.Base(pkgPath), w):
+			score += 0.8
+		case strings.Contains(pkgPath, w):
+			score += 0.4
+		default:
+			score += 0.2
+		}
+	}
+	return score * (1 + math.Log1p(float64(r.NumImportedBy)))
+}
+
+// escapeLike escapes the special characters of a LIKE pattern in s.
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s)
+}
@@ -0,0 +1,217 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sqlite provides a DataSource backed by an embedded SQLite
+// database.
+//
+// It implements internal.PostgresDB, so it can be used in place of the
+// postgres package to serve a site from a single process, without Postgres,
+// Redis or a separate worker. Modules are fetched by the frontend's fetch
+// queue and stored with InsertModule, and the data persists across restarts.
+//
+// The schema is a simplified version of the one in migrations/, and queries
+// follow the semantics of the corresponding ones in internal/postgres. It is
+// defined by the numbered migrations in this package's migrations directory,
+// which are applied when the database is opened.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+
+	_ "modernc.org/sqlite" // the "sqlite" driver
+)
+
+// DB is a DataSource backed by SQLite.
+type DB struct {
+	db                 *database.DB
+	bypassLicenseCheck bool
+}
+
+var _ internal.PostgresDB = (*DB)(nil)
+
+// Open opens the SQLite database in the file at path, creating it and its
+// tables if necessary. The special path ":memory:" opens a database that
+// lives only as long as the DB.
+func Open(ctx context.Context, path string) (_ *DB, err error) {
+	return open(ctx, path, false)
+}
+
+// OpenBypassingLicenseCheck is like Open, but the returned DB bypasses
+// license checks. That means all data will be inserted and returned for
+// non-redistributable modules, packages and directories.
+func OpenBypassingLicenseCheck(ctx context.Context, path string) (_ *DB, err error) {
+	return open(ctx, path, true)
+}
+
+func open(ctx context.Context, path string, bypass bool) (_ *DB, err error) {
+	defer derrors.Wrap(&err, "sqlite.Open(%q)", path)
+
+	sdb, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows only one writer at a time, and an in-memory database is
+	// private to its connection, so use a single connection. Queries are
+	// serialized, which is fine for the small deployments this package is
+	// meant for.
+	sdb.SetMaxOpenConns(1)
+	db := database.New(sdb, "sqlite")
+	for _, stmt := range []string{
+		`PRAGMA foreign_keys = ON`,
+		`PRAGMA journal_mode = WAL`,
+	} {
+		if _, err := db.Exec(ctx, stmt); err != nil {
+			sdb.Close()
+			return nil, err
+		}
+	}
+	if err := migrate(ctx, db); err != nil {
+		sdb.Close()
+		return nil, err
+	}
+	if bypass {
+		log.Info(ctx, "sqlite: bypassing license checks")
+	}
+	return &DB{db: db, bypassLicenseCheck: bypass}, nil
+}
+
+// Close closes the database.
+func (db *DB) Close() error {
+	return db.db.Close()
+}
+
+// Underlying returns the *database.DB inside db.
+func (db *DB) Underlying() *database.DB {
+	return db.db
+}
+
+// IsExcluded reports whether the path and version matches the excluded list.
+// A path@version is excluded if it matches a pattern in the excluded_prefixes
+// table, with the same rules as postgres.DB.IsExcluded.
+func (db *DB) IsExcluded(ctx context.Context, path, version string) bool {
+	eps, err := db.GetExcludedPatterns(ctx)
+	if err != nil {
+		log.Errorf(ctx, "getting excluded prefixes: %v", err)
+		return false
+	}
+	for _, pattern := range eps {
+		if excludes(pattern, path, version) {
+			log.Infof(ctx, "path %q and version %q matched excluded pattern %q", path, version, pattern)
+			return true
+		}
+	}
+	return false
+}
+
+func excludes(pattern, path, version string) bool {
+	// Patterns with "@" must match exactly.
+	mod, ver, found := strings.Cut(pattern, "@")
+	if found {
+		return mod == path && ver == version
+	}
+	// Patterns without "@" can match exactly or be a componentwise prefix.
+	if pattern == path {
+		return true
+	}
+	if !strings.HasSuffix(pattern, "/") {
+		pattern += "/"
+	}
+	return strings.HasPrefix(path, pattern)
+}
+
+// InsertExcludedPattern inserts pattern into the excluded_prefixes table.
+func (db *DB) InsertExcludedPattern(ctx context.Context, pattern, user, reason string) (err error) {
+	defer derrors.Wrap(&err, "DB.InsertExcludedPattern(ctx, %q, %q)", pattern, reason)
+
+	_, err = db.db.Exec(ctx, `INSERT INTO excluded_prefixes (prefix, created_by, reason) VALUES (?, ?, ?)`,
+		pattern, user, reason)
+	return err
+}
+
+// GetExcludedPatterns returns all the excluded patterns.
+func (db *DB) GetExcludedPatterns(ctx context.Context) ([]string, error) {
+	return database.Collect1[string](ctx, db.db, `SELECT prefix FROM excluded_prefixes`)
+}
+
+// jsonStrings returns the JSON encoding of ss, for storing in a column
+// holding a list of strings. A nil slice is stored as an empty list.
+func jsonStrings(ss []string) string {
+	if ss == nil {
+		ss = []string{}
+	}
+	b, err := json.Marshal(ss)
+	if err != nil {
+		// Marshaling a []string cannot fail.
+		panic(err)
+	}
+	return string(b)
+}
+
+// stringsScanner scans a JSON list of strings into a []string.
+type stringsScanner struct {
+	ptr *[]string
+}
+
+func (s stringsScanner) Scan(value any) error {
+	switch v := value.(type) {
+	case nil:
+		*s.ptr = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), s.ptr)
+	case []byte:
+		return json.Unmarshal(v, s.ptr)
+	default:
+		return fmt.Errorf("cannot scan %T as JSON", value)
+	}
+}
+
+// timeScanner scans a time stored as Unix nanoseconds.
+type timeScanner struct {
+	ptr *time.Time
+}
+
+func (s timeScanner) Scan(value any) error {
+	n, ok := value.(int64)
+	if !ok {
+		return fmt.Errorf("cannot scan %T as time", value)
+	}
+	*s.ptr = time.Unix(0, n).UTC()
+	return nil
+}
+
+// jsonScanner scans a JSON value into ptr. A NULL value leaves ptr
+// unchanged.
+type jsonScanner struct {
+	ptr any
+}
+
+func (s jsonScanner) Scan(value any) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		if v == "" || v == "null" {
+			return nil
+		}
+		return json.Unmarshal([]byte(v), s.ptr)
+	case []byte:
+		if len(v) == 0 {
+			return nil
+		}
+		return json.Unmarshal(v, s.ptr)
+	default:
+		return fmt.Errorf("cannot scan %T as JSON", value)
+	}
+}
@@ -0,0 +1,421 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/licenses"
+	"golang.org/x/pkgsite/internal/source"
+	"golang.org/x/pkgsite/internal/testing/sample"
+	"golang.org/x/pkgsite/internal/version"
+)
+
+func openTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := Open(context.Background(), ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func mustInsert(t *testing.T, db *DB, ms ...*internal.Module) {
+	t.Helper()
+	for _, m := range ms {
+		if _, err := db.InsertModule(context.Background(), m, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGetUnit(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	m := sample.DefaultModule()
+	m.Units[1].Documentation[0].API = sample.API
+	m.Units[1].Documentation[0].Implementations = []*internal.Implementation{
+		{Type: "T", TypePkgPath: sample.PackagePath, Interface: "fmt.Stringer", InterfacePkgPath: "fmt"},
+		{Type: "*sub.S", TypePkgPath: sample.PackagePath + "/sub", Interface: "I", InterfacePkgPath: sample.PackagePath},
+	}
+	m.Units[1].Documentation[0].ExampleResults = map[string]bool{"ExampleF": true, "ExampleT_M": false}
+	mustInsert(t, db, m)
+
+	um, err := db.GetUnitMeta(ctx, sample.PackagePath, internal.UnknownModulePath, version.Latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantMeta := sample.UnitMeta(sample.PackagePath, sample.ModulePath, sample.VersionString, sample.PackageName, true)
+	wantMeta.CommitTime = m.CommitTime
+	wantMeta.HasGoMod = m.HasGoMod
+	if diff := cmp.Diff(wantMeta, um, cmp.AllowUnexported(source.Info{})); diff != "" {
+		t.Errorf("GetUnitMeta mismatch (-want +got):\n%s", diff)
+	}
+
+	u, err := db.GetUnit(ctx, um, internal.AllFields, internal.BuildContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := m.Units[1]
+	if u.Path != want.Path {
+		t.Fatalf("got unit %q, want %q", u.Path, want.Path)
+	}
+	if diff := cmp.Diff(want.Documentation, u.Documentation, cmpopts.IgnoreFields(internal.Documentation{}, "API")); diff != "" {
+		t.Errorf("Documentation mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(sample.Imports(), u.Imports); diff != "" {
+		t.Errorf("Imports mismatch (-want +got):\n%s", diff)
+	}
+	// Like the postgres package, only partial license information is stored
+	// for units; it omits the Coverage field.
+	ignoreCoverage := cmpopts.IgnoreFields(licenses.Metadata{}, "Coverage")
+	if diff := cmp.Diff(sample.LicenseMetadata(), u.Licenses, ignoreCoverage); diff != "" {
+		t.Errorf("Licenses mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(sample.Licenses(), u.LicenseContents, sample.LicenseCmpOpts...); diff != "" {
+		t.Errorf("LicenseContents mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]*internal.PackageMeta{sample.PackageMeta(sample.PackagePath)}, u.Subdirectories, ignoreCoverage); diff != "" {
+		t.Errorf("Subdirectories mismatch (-want +got):\n%s", diff)
+	}
+	wantHistory := map[string]string{}
+	for _, s := range sample.API {
+		wantHistory[s.Name] = sample.VersionString
+		for _, c := range s.Children {
+			wantHistory[c.Name] = sample.VersionString
+		}
+	}
+	if diff := cmp.Diff(wantHistory, u.SymbolHistory); diff != "" {
+		t.Errorf("SymbolHistory mismatch (-want +got):\n%s", diff)
+	}
+
+	readme, err := db.GetModuleReadme(ctx, sample.ModulePath, sample.VersionString)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if readme.Contents != sample.ReadmeContents {
+		t.Errorf("got README %q, want %q", readme.Contents, sample.ReadmeContents)
+	}
+
+	if _, err := db.GetUnitMeta(ctx, "github.com/no/such/path", internal.UnknownModulePath, version.Latest); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("GetUnitMeta of missing path: got %v, want NotFound", err)
+	}
+}
+
+func TestReinsertModule(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	mustInsert(t, db, sample.DefaultModule())
+	m := sample.DefaultModule()
+	m.Units[1].Documentation[0].Synopsis = "new synopsis"
+	mustInsert(t, db, m)
+
+	um, err := db.GetUnitMeta(ctx, sample.PackagePath, sample.ModulePath, sample.VersionString)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := db.GetUnit(ctx, um, internal.WithMain, internal.BuildContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.Documentation[0].Synopsis; got != "new synopsis" {
+		t.Errorf("got synopsis %q, want %q", got, "new synopsis")
+	}
+}
+
+func TestNonRedistributable(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	m := sample.DefaultModule()
+	m.IsRedistributable = false
+	for _, u := range m.Units {
+		u.IsRedistributable = false
+	}
+	mustInsert(t, db, m)
+
+	um, err := db.GetUnitMeta(ctx, sample.PackagePath, sample.ModulePath, sample.VersionString)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := db.GetUnit(ctx, um, internal.AllFields, internal.BuildContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Documentation != nil || u.Readme != nil {
+		t.Errorf("got documentation or README for non-redistributable unit")
+	}
+}
+
+func TestVersions(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	const modulePath = "example.com/mod"
+	for _, v := range []string{"v1.0.0", "v1.1.0", "v1.2.0-pre", "v0.0.0-20190101000000-000000000000"} {
+		mustInsert(t, db, sample.Module(modulePath, v, "pkg"))
+	}
+	mustInsert(t, db, sample.Module(modulePath+"/v2", "v2.0.0", "pkg"))
+
+	um, err := db.GetUnitMeta(ctx, modulePath+"/pkg", internal.UnknownModulePath, version.Latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if um.ModulePath != modulePath || um.Version != "v1.1.0" {
+		t.Errorf("latest: got %s@%s, want %s@v1.1.0", um.ModulePath, um.Version, modulePath)
+	}
+
+	mis, err := db.GetVersionsForPath(ctx, modulePath+"/pkg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, mi := range mis {
+		got = append(got, mi.ModulePath+"@"+mi.Version)
+	}
+	want := []string{
+		modulePath + "/v2@v2.0.0",
+		modulePath + "@v1.2.0-pre",
+		modulePath + "@v1.1.0",
+		modulePath + "@v1.0.0",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetVersionsForPath mismatch (-want +got):\n%s", diff)
+	}
+
+	path, maj, err := db.GetLatestMajorPathForV1Path(ctx, modulePath+"/pkg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != modulePath+"/v2/pkg" || maj != 2 {
+		t.Errorf("GetLatestMajorPathForV1Path: got %q, %d; want %q, 2", path, maj, modulePath+"/v2/pkg")
+	}
+}
+
+func TestImportedBy(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	a := sample.Module("example.com/a", "v1.0.0", "a")
+	b := sample.Module("example.com/b", "v1.0.0", "b")
+	b.Units[1].Imports = []string{"example.com/a/a"}
+	mustInsert(t, db, a, b)
+
+	got, err := db.GetImportedBy(ctx, "example.com/a/a", "example.com/a", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"example.com/b/b"}, got); diff != "" {
+		t.Errorf("GetImportedBy mismatch (-want +got):\n%s", diff)
+	}
+	n, err := db.GetImportedByCount(ctx, "example.com/a/a", "example.com/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("GetImportedByCount: got %d, want 1", n)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	yaml := sample.Module("example.com/yaml", "v1.0.0", "yaml")
+	yaml.Units[1].Documentation[0].Synopsis = "Package yaml parses YAML documents."
+	old := sample.Module("example.com/json", "v1.0.0", "json", "legacy")
+	json := sample.Module("example.com/json", "v1.1.0", "json")
+	json.Units[1].Imports = []string{"example.com/yaml/yaml"}
+	mustInsert(t, db, yaml, old, json)
+
+	for _, test := range []struct {
+		q    string
+		want []string
+	}{
+		{"yaml", []string{"example.com/yaml/yaml"}},
+		{"parses documents", []string{"example.com/yaml/yaml"}},
+		{"json", []string{"example.com/json/json"}},
+		{"legacy", nil},
+		{"xml", nil},
+	} {
+		rs, err := db.Search(ctx, test.q, internal.SearchOptions{MaxResults: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, r := range rs {
+			got = append(got, r.PackagePath)
+		}
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("Search(%q) mismatch (-want +got):\n%s", test.q, diff)
+		}
+		if test.q == "yaml" && len(rs) > 0 && rs[0].NumImportedBy != 1 {
+			t.Errorf("Search(%q): NumImportedBy = %d, want 1", test.q, rs[0].NumImportedBy)
+		}
+	}
+}
+
+func TestVersionMap(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	mustInsert(t, db, sample.DefaultModule())
+	vm := sample.DefaultVersionMap()
+	if err := db.UpsertVersionMap(ctx, vm); err != nil {
+		t.Fatal(err)
+	}
+	vm.Status = http.StatusNotFound
+	if err := db.UpsertVersionMap(ctx, vm); err != nil {
+		t.Fatal(err)
+	}
+	got, err := db.GetVersionMap(ctx, vm.ModulePath, vm.RequestedVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(vm, got, cmpopts.IgnoreFields(internal.VersionMap{}, "UpdatedAt")); diff != "" {
+		t.Errorf("GetVersionMap mismatch (-want +got):\n%s", diff)
+	}
+	vms, err := db.GetVersionMaps(ctx, []string{vm.ModulePath, "example.com/other"}, vm.RequestedVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vms) != 1 {
+		t.Errorf("GetVersionMaps: got %d results, want 1", len(vms))
+	}
+	if _, err := db.GetVersionMap(ctx, vm.ModulePath, "v9.9.9"); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("GetVersionMap of missing version: got %v, want NotFound", err)
+	}
+}
+
+func TestExcluded(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	if err := db.InsertExcludedPattern(ctx, "example.com/bad", "user", "reason"); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		path string
+		want bool
+	}{
+		{"example.com/bad", true},
+		{"example.com/bad/pkg", true},
+		{"example.com/badger", false},
+	} {
+		if got := db.IsExcluded(ctx, test.path, "v1.0.0"); got != test.want {
+			t.Errorf("IsExcluded(%q) = %t, want %t", test.path, got, test.want)
+		}
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	ctx := context.Background()
+	file := filepath.Join(t.TempDir(), "pkgsite.db")
+	db, err := Open(ctx, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustInsert(t, db, sample.DefaultModule())
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = Open(ctx, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	names, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schemaVersion int
+	if err := db.db.QueryRow(ctx, `PRAGMA user_version`).Scan(&schemaVersion); err != nil {
+		t.Fatal(err)
+	}
+	if schemaVersion != len(names) {
+		t.Errorf("got schema version %d, want %d", schemaVersion, len(names))
+	}
+	um, err := db.GetUnitMeta(ctx, sample.PackagePath, internal.UnknownModulePath, version.Latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if um.Version != sample.VersionString {
+		t.Errorf("got version %q, want %q", um.Version, sample.VersionString)
+	}
+}
+
+func TestGetDeprecatedSymbols(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	const modulePath = "example.com/mod"
+
+	fn := func(name string, deprecated bool, replacement string) *internal.Symbol {
+		return &internal.Symbol{
+			SymbolMeta: internal.SymbolMeta{
+				Name:        name,
+				Synopsis:    "func " + name + "()",
+				Section:     internal.SymbolSectionFunctions,
+				Kind:        internal.SymbolKindFunction,
+				ParentName:  name,
+				Deprecated:  deprecated,
+				Replacement: replacement,
+			},
+		}
+	}
+	for v, api := range map[string][]*internal.Symbol{
+		"v1.0.0": {fn("F", false, ""), fn("G", true, ""), fn("H", true, "F")},
+		"v1.1.0": {fn("F", true, "H"), fn("G", false, ""), fn("H", true, "F")},
+	} {
+		m := sample.Module(modulePath, v, "pkg")
+		m.Units[1].Documentation[0].API = api
+		mustInsert(t, db, m)
+	}
+
+	got, err := db.GetDeprecatedSymbols(ctx, modulePath, "v1.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*internal.DeprecatedSymbol{
+		{SymbolMeta: fn("F", true, "H").SymbolMeta, PackagePath: modulePath + "/pkg", Since: "v1.1.0"},
+		{SymbolMeta: fn("H", true, "F").SymbolMeta, PackagePath: modulePath + "/pkg", Since: "v1.0.0"},
+	}
+	for _, w := range want {
+		w.ParentName = ""
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetDeprecatedSymbols mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGetModuleRequirements(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	m := sample.DefaultModule()
+	m.Requirements = []*internal.Requirement{
+		{ModulePath: "golang.org/x/text", Version: "v0.3.0", Indirect: true},
+		{ModulePath: "example.com/dep", Version: "v1.2.3"},
+	}
+	mustInsert(t, db, m)
+	// Reinserting the module replaces its requirements.
+	m.Requirements = m.Requirements[1:]
+	mustInsert(t, db, m)
+
+	got, err := db.GetModuleRequirements(ctx, sample.ModulePath, sample.VersionString)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*internal.Requirement{{ModulePath: "example.com/dep", Version: "v1.2.3"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := db.GetModuleRequirements(ctx, sample.ModulePath, "v9.9.9"); !errors.Is(err, derrors.NotFound) {
+		t.Errorf("got error %v for missing module, want NotFound", err)
+	}
+}
@@ -0,0 +1,728 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/licenses"
+	"golang.org/x/pkgsite/internal/stdlib"
+	"golang.org/x/pkgsite/internal/symbol"
+	"golang.org/x/pkgsite/internal/version"
+)
+
+// GetUnitMeta returns information about the "best" entity (module, path or
+// directory) with the given path. The module and version arguments provide
+// additional constraints. The rules for picking the best are those of
+// postgres.DB.GetUnitMeta.
+func (db *DB) GetUnitMeta(ctx context.Context, fullPath, requestedModulePath, requestedVersion string) (_ *internal.UnitMeta, err error) {
+	defer derrors.WrapStack(&err, "DB.GetUnitMeta(ctx, %q, %q, %q)", fullPath, requestedModulePath, requestedVersion)
+
+	modulePath := requestedModulePath
+	v := requestedVersion
+	var lmv *internal.LatestModuleVersions
+	if requestedVersion == version.Latest {
+		modulePath, v, lmv, err = db.getLatestUnitVersion(ctx, fullPath, requestedModulePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return db.getUnitMetaWithKnownVersion(ctx, fullPath, modulePath, v, lmv)
+}
+
+func (db *DB) getUnitMetaWithKnownVersion(ctx context.Context, fullPath, modulePath, v string, lmv *internal.LatestModuleVersions) (_ *internal.UnitMeta, err error) {
+	defer derrors.WrapStack(&err, "getUnitMetaWithKnownVersion")
+
+	query := `
+		SELECT ` + moduleInfoColumns + `, u.name
+		FROM modules m
+		INNER JOIN units u ON u.module_id = m.id`
+	args := []any{fullPath}
+	if internal.DefaultBranches[v] || stdlib.SupportedBranches[v] {
+		query += `
+		INNER JOIN version_map vm ON m.id = vm.module_id
+		WHERE u.path = ? AND vm.requested_version = ?`
+	} else {
+		query += `
+		WHERE u.path = ? AND m.version = ?`
+	}
+	args = append(args, v)
+	if modulePath == internal.UnknownModulePath {
+		// If we don't know the module, look for the one with the longest series path.
+		query += ` ORDER BY m.series_path DESC LIMIT 1`
+	} else {
+		query += ` AND m.module_path = ?`
+		args = append(args, modulePath)
+	}
+	um := internal.UnitMeta{Path: fullPath}
+	err = db.db.QueryRow(ctx, query, args...).Scan(
+		&um.ModulePath,
+		&um.Version,
+		timeScanner{&um.CommitTime},
+		&um.IsRedistributable,
+		&um.HasGoMod,
+		jsonScanner{&um.SourceInfo},
+		&um.Name)
+	if err == sql.ErrNoRows {
+		return nil, derrors.NotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if lmv == nil {
+		lmv, err = db.GetLatestModuleVersions(ctx, um.ModulePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if lmv != nil {
+		lmv.PopulateModuleInfo(&um.ModuleInfo)
+	}
+	return &um, nil
+}
+
+// getLatestUnitVersion gets the latest version of requestedModulePath that contains fullPath.
+// See GetUnitMeta for more details.
+func (db *DB) getLatestUnitVersion(ctx context.Context, fullPath, requestedModulePath string) (
+	modulePath, latestVersion string, lmv *internal.LatestModuleVersions, err error) {
+	defer derrors.WrapStack(&err, "getLatestUnitVersion(%q, %q)", fullPath, requestedModulePath)
+
+	modPaths := []string{requestedModulePath}
+	// If we don't know the module path, try each possible module path from longest to shortest.
+	if requestedModulePath == internal.UnknownModulePath {
+		modPaths = internal.CandidateModulePaths(fullPath)
+	}
+	lmvs, err := getMultiLatestModuleVersions(ctx, db.db, modPaths)
+	if err != nil {
+		return "", "", nil, err
+	}
+	for _, lmv = range lmvs {
+		// Collect all the versions of this module that contain fullPath.
+		allVersions, err := database.Collect1[string](ctx, db.db, `
+			SELECT m.version
+			FROM modules m
+			INNER JOIN units u ON u.module_id = m.id
+			WHERE m.module_path = ? AND u.path = ?`, lmv.ModulePath, fullPath)
+		if err != nil {
+			return "", "", nil, err
+		}
+		unretractedVersions := version.RemoveIf(allVersions, lmv.IsRetracted)
+		if len(unretractedVersions) == 0 {
+			continue
+		}
+		if !version.IsIncompatible(lmv.CookedVersion) {
+			unretractedVersions = version.RemoveIf(unretractedVersions, version.IsIncompatible)
+		}
+		// The good version should never be later than the cooked version.
+		if lmv.CookedVersion != "" {
+			unretractedVersions = version.RemoveIf(unretractedVersions, func(v string) bool {
+				return version.Later(v, lmv.CookedVersion)
+			})
+		}
+		latestVersion = version.LatestOf(unretractedVersions)
+		break
+	}
+	if latestVersion != "" {
+		return lmv.ModulePath, latestVersion, lmv, nil
+	}
+	// If we don't have latest-version info for any path (or there are no
+	// unretracted versions for paths where we do), fall back to finding the
+	// latest good version from the longest path.
+	err = db.db.QueryRow(ctx, `
+		SELECT m.module_path, m.version
+		FROM modules m
+		INNER JOIN units u ON u.module_id = m.id
+		WHERE u.path = ?
+		ORDER BY
+			m.series_path DESC,
+			m.version_type = 'release' DESC,
+			m.sort_version DESC
+		LIMIT 1`, fullPath).Scan(&modulePath, &latestVersion)
+	if err == sql.ErrNoRows {
+		return "", "", nil, derrors.NotFound
+	}
+	if err != nil {
+		return "", "", nil, err
+	}
+	return modulePath, latestVersion, nil, nil
+}
+
+// GetUnit returns a unit from the database, along with all of the data
+// associated with that unit.
+// If bc is not nil, get only the Documentation that matches it (or nil if none do).
+func (db *DB) GetUnit(ctx context.Context, um *internal.UnitMeta, fields internal.FieldSet, bc internal.BuildContext) (_ *internal.Unit, err error) {
+	defer derrors.WrapStack(&err, "GetUnit(ctx, %q, %q, %q, %v)", um.Path, um.ModulePath, um.Version, bc)
+
+	var (
+		unitID, moduleID  int
+		isRedistributable bool
+		licenseTypes      []string
+		licensePaths      []string
+	)
+	err = db.db.QueryRow(ctx, `
+		SELECT u.id, u.module_id, u.redistributable, u.license_types, u.license_paths
+		FROM units u
+		INNER JOIN modules m ON m.id = u.module_id
+		WHERE u.path = ? AND m.module_path = ? AND m.version = ?`,
+		um.Path, um.ModulePath, um.Version).Scan(
+		&unitID, &moduleID, &isRedistributable,
+		stringsScanner{&licenseTypes}, stringsScanner{&licensePaths})
+	switch err {
+	case nil:
+	case sql.ErrNoRows:
+		return nil, derrors.NotFound
+	default:
+		return nil, err
+	}
+	if db.bypassLicenseCheck {
+		isRedistributable = true
+	}
+
+	u := &internal.Unit{UnitMeta: *um}
+	if fields&internal.WithMain != 0 {
+		lics, err := zipLicenseMetadata(licenseTypes, licensePaths)
+		if err != nil {
+			return nil, err
+		}
+		u.Licenses = lics
+		if err := db.getMainFields(ctx, u, unitID, moduleID, bc); err != nil {
+			return nil, err
+		}
+	}
+	u.IsRedistributable = isRedistributable
+	if fields&internal.WithImports != 0 {
+		imports, err := database.Collect1[string](ctx, db.db,
+			`SELECT to_path FROM imports WHERE unit_id = ? ORDER BY to_path`, unitID)
+		if err != nil {
+			return nil, err
+		}
+		if len(imports) > 0 {
+			u.Imports = imports
+			u.NumImports = len(imports)
+		}
+	}
+	if fields&internal.WithLicenses != 0 {
+		lics, err := db.getLicenses(ctx, u.Path, u.ModulePath, moduleID)
+		if err != nil {
+			return nil, err
+		}
+		u.LicenseContents = lics
+	}
+	if db.bypassLicenseCheck {
+		u.IsRedistributable = true
+	} else {
+		u.RemoveNonRedistributableData()
+	}
+	return u, nil
+}
+
+// getMainFields populates the fields of u that are read for internal.WithMain.
+func (db *DB) getMainFields(ctx context.Context, u *internal.Unit, unitID, moduleID int, bc internal.BuildContext) (err error) {
+	defer derrors.WrapStack(&err, "getMainFields(ctx, %q, %q, %q)", u.Path, u.ModulePath, u.Version)
+
+	// Get build contexts.
+	err = db.db.RunQuery(ctx, `SELECT goos, goarch FROM documentation WHERE unit_id = ?`, func(rows *sql.Rows) error {
+		var c internal.BuildContext
+		if err := rows.Scan(&c.GOOS, &c.GOARCH); err != nil {
+			return err
+		}
+		u.BuildContexts = append(u.BuildContexts, c)
+		return nil
+	}, unitID)
+	if err != nil {
+		return err
+	}
+	sort.Slice(u.BuildContexts, func(i, j int) bool {
+		return internal.CompareBuildContexts(u.BuildContexts[i], u.BuildContexts[j]) < 0
+	})
+	var bcMatched internal.BuildContext
+	for _, c := range u.BuildContexts {
+		if bc.Match(c) {
+			bcMatched = c
+			break
+		}
+	}
+	if bcMatched.GOOS != "" {
+		doc := &internal.Documentation{GOOS: bcMatched.GOOS, GOARCH: bcMatched.GOARCH}
+		var docID int
+		err := db.db.QueryRow(ctx, `
+			SELECT id, synopsis, source
+			FROM documentation
+			WHERE unit_id = ? AND goos = ? AND goarch = ?`,
+			unitID, bcMatched.GOOS, bcMatched.GOARCH).Scan(&docID, &doc.Synopsis, &doc.Source)
+		if err != nil {
+			return err
+		}
+		err = db.db.RunQuery(ctx, `
+			SELECT type, type_package_path, interface, interface_package_path
+			FROM documentation_implementations
+			WHERE documentation_id = ?
+			ORDER BY interface_package_path, interface, type_package_path, type`, func(rows *sql.Rows) error {
+			var impl internal.Implementation
+			if err := rows.Scan(&impl.Type, &impl.TypePkgPath, &impl.Interface, &impl.InterfacePkgPath); err != nil {
+				return err
+			}
+			doc.Implementations = append(doc.Implementations, &impl)
+			return nil
+		}, docID)
+		if err != nil {
+			return err
+		}
+		err = db.db.RunQuery(ctx, `
+			SELECT name, passed
+			FROM documentation_example_results
+			WHERE documentation_id = ?`, func(rows *sql.Rows) error {
+			var (
+				name   string
+				passed bool
+			)
+			if err := rows.Scan(&name, &passed); err != nil {
+				return err
+			}
+			if doc.ExampleResults == nil {
+				doc.ExampleResults = map[string]bool{}
+			}
+			doc.ExampleResults[name] = passed
+			return nil
+		}, docID)
+		if err != nil {
+			return err
+		}
+		u.Documentation = []*internal.Documentation{doc}
+	}
+
+	// Get the README.
+	var r internal.Readme
+	err = db.db.QueryRow(ctx, `SELECT file_path, contents FROM readmes WHERE unit_id = ?`, unitID).Scan(&r.Filepath, &r.Contents)
+	switch err {
+	case nil:
+		if u.ModulePath != stdlib.ModulePath {
+			u.Readme = &r
+		}
+	case sql.ErrNoRows:
+	default:
+		return err
+	}
+
+	// Get import counts.
+	if err := db.db.QueryRow(ctx, `SELECT COUNT(*) FROM imports WHERE unit_id = ?`, unitID).Scan(&u.NumImports); err != nil {
+		return err
+	}
+	n, err := db.GetImportedByCount(ctx, u.Path, u.ModulePath)
+	if err != nil {
+		return err
+	}
+	u.NumImportedBy = n
+
+	u.Subdirectories, err = db.getPackagesInUnit(ctx, u.Path, moduleID)
+	if err != nil {
+		return err
+	}
+	if u.IsPackage() && !u.IsCommand() && len(u.Documentation) > 0 {
+		u.SymbolHistory, err = db.getSymbolHistoryForBuildContext(ctx, u.Path, u.ModulePath, bcMatched)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getPackagesInUnit returns all of the packages in a unit from a
+// module_id, including the package that lives at fullPath, if present.
+func (db *DB) getPackagesInUnit(ctx context.Context, fullPath string, moduleID int) (_ []*internal.PackageMeta, err error) {
+	defer derrors.WrapStack(&err, "getPackagesInUnit(ctx, %q, %d)", fullPath, moduleID)
+
+	// If a package has more than build context (GOOS/GOARCH pair), it will have
+	// more than one row in documentation. Read all the rows and pick the
+	// right one afterwards.
+	type pmbc struct {
+		pm *internal.PackageMeta
+		bc internal.BuildContext
+	}
+	packagesByPath := map[string][]pmbc{}
+	collect := func(rows *sql.Rows) error {
+		var (
+			pkg          internal.PackageMeta
+			licenseTypes []string
+			licensePaths []string
+			bc           internal.BuildContext
+		)
+		if err := rows.Scan(
+			&pkg.Path,
+			&pkg.Name,
+			&pkg.IsRedistributable,
+			database.NullIsEmpty(&pkg.Synopsis),
+			database.NullIsEmpty(&bc.GOOS),
+			database.NullIsEmpty(&bc.GOARCH),
+			stringsScanner{&licenseTypes},
+			stringsScanner{&licensePaths},
+		); err != nil {
+			return fmt.Errorf("row.Scan(): %v", err)
+		}
+		if fullPath == stdlib.ModulePath || pkg.Path == fullPath || strings.HasPrefix(pkg.Path, fullPath+"/") {
+			lics, err := zipLicenseMetadata(licenseTypes, licensePaths)
+			if err != nil {
+				return err
+			}
+			pkg.Licenses = lics
+			packagesByPath[pkg.Path] = append(packagesByPath[pkg.Path], pmbc{&pkg, bc})
+		}
+		return nil
+	}
+	err = db.db.RunQuery(ctx, `
+		SELECT u.path, u.name, u.redistributable, d.synopsis, d.goos, d.goarch, u.license_types, u.license_paths
+		FROM units u
+		LEFT JOIN documentation d ON d.unit_id = u.id
+		WHERE u.module_id = ? AND u.name != ''`, collect, moduleID)
+	if err != nil {
+		return nil, err
+	}
+	var packages []*internal.PackageMeta
+	for _, ps := range packagesByPath {
+		sort.Slice(ps, func(i, j int) bool { return internal.CompareBuildContexts(ps[i].bc, ps[j].bc) < 0 })
+		packages = append(packages, ps[0].pm)
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Path < packages[j].Path })
+	for _, p := range packages {
+		if db.bypassLicenseCheck {
+			p.IsRedistributable = true
+		} else {
+			p.RemoveNonRedistributableData()
+		}
+	}
+	return packages, nil
+}
+
+// GetModuleReadme returns the README corresponding to the modulePath and version.
+func (db *DB) GetModuleReadme(ctx context.Context, modulePath, resolvedVersion string) (_ *internal.Readme, err error) {
+	defer derrors.WrapStack(&err, "GetModuleReadme(ctx, %q, %q)", modulePath, resolvedVersion)
+
+	var readme internal.Readme
+	err = db.db.QueryRow(ctx, `
+		SELECT r.file_path, r.contents
+		FROM modules m
+		INNER JOIN units u ON u.module_id = m.id
+		INNER JOIN readmes r ON u.id = r.unit_id
+		WHERE m.module_path = ? AND m.version = ? AND u.path = m.module_path`,
+		modulePath, resolvedVersion).Scan(&readme.Filepath, &readme.Contents)
+	switch err {
+	case sql.ErrNoRows:
+		return nil, derrors.NotFound
+	case nil:
+		return &readme, nil
+	default:
+		return nil, err
+	}
+}
+
+// GetModuleRequirements returns the requirements in the go.mod file of the
+// module version, sorted by module path and version.
+func (db *DB) GetModuleRequirements(ctx context.Context, modulePath, resolvedVersion string) (_ []*internal.Requirement, err error) {
+	defer derrors.WrapStack(&err, "GetModuleRequirements(ctx, %q, %q)", modulePath, resolvedVersion)
+
+	var moduleID int
+	err = db.db.QueryRow(ctx, `
+		SELECT id FROM modules WHERE module_path = ? AND version = ?`,
+		modulePath, resolvedVersion).Scan(&moduleID)
+	if err == sql.ErrNoRows {
+		return nil, derrors.NotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var reqs []*internal.Requirement
+	err = db.db.RunQuery(ctx, `
+		SELECT required_path, required_version, indirect
+		FROM module_requirements
+		WHERE module_id = ?
+		ORDER BY required_path, required_version`, func(rows *sql.Rows) error {
+		var r internal.Requirement
+		if err := rows.Scan(&r.ModulePath, &r.Version, &r.Indirect); err != nil {
+			return err
+		}
+		reqs = append(reqs, &r)
+		return nil
+	}, moduleID)
+	if err != nil {
+		return nil, err
+	}
+	return reqs, nil
+}
+
+// getLicenses returns the licenses of the module that apply to fullPath:
+// those in the directory of fullPath or one of its parents.
+func (db *DB) getLicenses(ctx context.Context, fullPath, modulePath string, moduleID int) (_ []*licenses.License, err error) {
+	defer derrors.WrapStack(&err, "getLicenses(ctx, %q, %d)", fullPath, moduleID)
+
+	var lics []*licenses.License
+	err = db.db.RunQuery(ctx, `
+		SELECT types, file_path, contents, coverage
+		FROM licenses
+		WHERE module_id = ?`, func(rows *sql.Rows) error {
+		lic := &licenses.License{Metadata: &licenses.Metadata{}}
+		if err := rows.Scan(stringsScanner{&lic.Types}, &lic.FilePath, &lic.Contents, jsonScanner{&lic.Coverage}); err != nil {
+			return fmt.Errorf("row.Scan(): %v", err)
+		}
+		if modulePath != stdlib.ModulePath {
+			licensePath := path.Join(modulePath, path.Dir(lic.FilePath))
+			if !strings.HasPrefix(fullPath, licensePath) {
+				return nil
+			}
+		}
+		if !db.bypassLicenseCheck {
+			lic.RemoveNonRedistributableData()
+		}
+		lics = append(lics, lic)
+		return nil
+	}, moduleID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(lics, func(i, j int) bool {
+		return compareLicenses(lics[i].Metadata, lics[j].Metadata)
+	})
+	return lics, nil
+}
+
+// zipLicenseMetadata constructs licenses.Metadata from the given license types
+// and paths, by zipping and then sorting.
+func zipLicenseMetadata(licenseTypes []string, licensePaths []string) (_ []*licenses.Metadata, err error) {
+	defer derrors.Wrap(&err, "zipLicenseMetadata(%v, %v)", licenseTypes, licensePaths)
+
+	if len(licenseTypes) != len(licensePaths) {
+		return nil, fmt.Errorf("BUG: got %d license types and %d license paths", len(licenseTypes), len(licensePaths))
+	}
+	byPath := make(map[string]*licenses.Metadata)
+	var mds []*licenses.Metadata
+	for i, p := range licensePaths {
+		md, ok := byPath[p]
+		if !ok {
+			md = &licenses.Metadata{FilePath: p}
+			mds = append(mds, md)
+		}
+		// By convention, we insert a license path with empty corresponding license
+		// type if we are unable to detect *any* licenses in the file. This ensures
+		// that we mark this package as non-redistributable.
+		if licenseTypes[i] != "" {
+			md.Types = append(md.Types, licenseTypes[i])
+		}
+	}
+	sort.Slice(mds, func(i, j int) bool {
+		return compareLicenses(mds[i], mds[j])
+	})
+	return mds, nil
+}
+
+// compareLicenses reports whether i < j according to our license sorting
+// semantics.
+func compareLicenses(i, j *licenses.Metadata) bool {
+	if len(strings.Split(i.FilePath, "/")) > len(strings.Split(j.FilePath, "/")) {
+		return true
+	}
+	return i.FilePath < j.FilePath
+}
+
+// GetImportedBy fetches and returns all of the packages that import the
+// package with path, at the latest versions of the importing modules.
+//
+// Instead of supporting pagination, this query runs with a limit.
+func (db *DB) GetImportedBy(ctx context.Context, pkgPath, modulePath string, limit int) (paths []string, err error) {
+	defer derrors.WrapStack(&err, "GetImportedBy(ctx, %q, %q)", pkgPath, modulePath)
+
+	if pkgPath == "" {
+		return nil, fmt.Errorf("pkgPath cannot be empty: %w", derrors.InvalidArgument)
+	}
+	return database.Collect1[string](ctx, db.db, `
+		SELECT DISTINCT from_path
+		FROM imports_unique
+		WHERE to_path = ? AND from_module_path <> ?
+		ORDER BY from_path
+		LIMIT ?`, pkgPath, modulePath, limit)
+}
+
+// GetImportedByCount returns the number of packages that import pkgPath.
+// Unlike the postgres implementation, which reads a count that is computed
+// periodically, the count is computed on each call.
+func (db *DB) GetImportedByCount(ctx context.Context, pkgPath, modulePath string) (_ int, err error) {
+	defer derrors.WrapStack(&err, "GetImportedByCount(ctx, %q, %q)", pkgPath, modulePath)
+
+	if pkgPath == "" {
+		return 0, fmt.Errorf("pkgPath cannot be empty: %w", derrors.InvalidArgument)
+	}
+	var n int
+	err = db.db.QueryRow(ctx, `
+		SELECT COUNT(DISTINCT from_path)
+		FROM imports_unique
+		WHERE to_path = ? AND from_module_path <> ?`, pkgPath, modulePath).Scan(&n)
+	return n, err
+}
+
+// GetSymbolHistory returns a SymbolHistory, which is a representation of the
+// first version when a symbol is added to an API.
+func (db *DB) GetSymbolHistory(ctx context.Context, packagePath, modulePath string) (_ *internal.SymbolHistory, err error) {
+	defer derrors.WrapStack(&err, "GetSymbolHistory(ctx, %q, %q)", packagePath, modulePath)
+
+	sh, err := db.getPackageSymbols(ctx, packagePath, modulePath)
+	if err != nil {
+		return nil, err
+	}
+	return symbol.IntroducedHistory(sh)
+}
+
+// getSymbolHistoryForBuildContext returns a map from each symbol name of the
+// package in the build context to the first version it appeared in.
+func (db *DB) getSymbolHistoryForBuildContext(ctx context.Context, packagePath, modulePath string, bc internal.BuildContext) (_ map[string]string, err error) {
+	defer derrors.WrapStack(&err, "getSymbolHistoryForBuildContext(ctx, %q, %q, %v)", packagePath, modulePath, bc)
+
+	if bc == internal.BuildContextAll {
+		bc = internal.BuildContexts[0]
+	}
+	sh, err := db.GetSymbolHistory(ctx, packagePath, modulePath)
+	if err != nil {
+		return nil, err
+	}
+	nameToVersion := map[string]string{}
+	for _, v := range sh.Versions() {
+		for name, sus := range sh.SymbolsAtVersion(v) {
+			for _, us := range sus {
+				if _, ok := nameToVersion[name]; !ok && us.SupportsBuild(bc) {
+					nameToVersion[name] = v
+				}
+			}
+		}
+	}
+	return nameToVersion, nil
+}
+
+// getPackageSymbols returns the symbols of every release version of the
+// package, for all build contexts.
+func (db *DB) getPackageSymbols(ctx context.Context, packagePath, modulePath string) (_ *internal.SymbolHistory, err error) {
+	defer derrors.WrapStack(&err, "getPackageSymbols(ctx, %q, %q)", packagePath, modulePath)
+
+	sh := internal.NewSymbolHistory()
+	err = db.db.RunQuery(ctx, `
+		SELECT ds.name, ds.parent_name, ds.section, ds.type, ds.synopsis, m.version, d.goos, d.goarch
+		FROM modules m
+		INNER JOIN units u ON u.module_id = m.id
+		INNER JOIN documentation d ON d.unit_id = u.id
+		INNER JOIN documentation_symbols ds ON ds.documentation_id = d.id
+		WHERE u.path = ? AND m.module_path = ?
+			AND NOT m.incompatible AND m.version_type = 'release'`, func(rows *sql.Rows) error {
+		var (
+			sm    internal.SymbolMeta
+			build internal.BuildContext
+			v     string
+		)
+		if err := rows.Scan(&sm.Name, &sm.ParentName, &sm.Section, &sm.Kind, &sm.Synopsis,
+			&v, &build.GOOS, &build.GOARCH); err != nil {
+			return fmt.Errorf("row.Scan(): %v", err)
+		}
+		if build == internal.BuildContextAll {
+			for _, b := range internal.BuildContexts {
+				sh.AddSymbol(sm, v, b)
+			}
+		} else {
+			sh.AddSymbol(sm, v, build)
+		}
+		return nil
+	}, packagePath, modulePath)
+	if err != nil {
+		return nil, err
+	}
+	return sh, nil
+}
+
+// GetDeprecatedSymbols returns the deprecated symbols of the packages of the
+// module version, sorted by package path and symbol name, with the semantics
+// of postgres.DB.GetDeprecatedSymbols.
+func (db *DB) GetDeprecatedSymbols(ctx context.Context, modulePath, version string) (_ []*internal.DeprecatedSymbol, err error) {
+	defer derrors.WrapStack(&err, "GetDeprecatedSymbols(ctx, %q, %q)", modulePath, version)
+
+	var syms []*internal.DeprecatedSymbol
+	seen := map[[2]string]bool{} // package path and symbol name
+	err = db.db.RunQuery(ctx, `
+		SELECT u.path, ds.name, ds.parent_name, ds.section, ds.type, ds.synopsis, ds.replacement
+		FROM modules m
+		INNER JOIN units u ON u.module_id = m.id
+		INNER JOIN documentation d ON d.unit_id = u.id
+		INNER JOIN documentation_symbols ds ON ds.documentation_id = d.id
+		WHERE m.module_path = ? AND m.version = ? AND ds.deprecated
+		ORDER BY u.path, ds.name, d.goos, d.goarch`, func(rows *sql.Rows) error {
+		ds := &internal.DeprecatedSymbol{}
+		if err := rows.Scan(&ds.PackagePath, &ds.Name, &ds.ParentName,
+			&ds.Section, &ds.Kind, &ds.Synopsis, &ds.Replacement); err != nil {
+			return err
+		}
+		key := [2]string{ds.PackagePath, ds.Name}
+		if seen[key] {
+			return nil
+		}
+		seen[key] = true
+		if ds.ParentName == ds.Name {
+			ds.ParentName = ""
+		}
+		ds.Deprecated = true
+		syms = append(syms, ds)
+		return nil
+	}, modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	pkgToSince := map[string]map[string]string{}
+	for _, ds := range syms {
+		if _, ok := pkgToSince[ds.PackagePath]; ok {
+			continue
+		}
+		sh, err := db.getDeprecationHistory(ctx, ds.PackagePath, modulePath)
+		if err != nil {
+			return nil, err
+		}
+		pkgToSince[ds.PackagePath] = symbol.DeprecatedSince(sh)
+	}
+	for _, ds := range syms {
+		ds.Since = pkgToSince[ds.PackagePath][ds.Name]
+	}
+	return syms, nil
+}
+
+// getDeprecationHistory returns the symbol history of the package, with only
+// the names and deprecation of the symbols filled in.
+func (db *DB) getDeprecationHistory(ctx context.Context, packagePath, modulePath string) (_ *internal.SymbolHistory, err error) {
+	defer derrors.WrapStack(&err, "getDeprecationHistory(ctx, %q, %q)", packagePath, modulePath)
+
+	sh := internal.NewSymbolHistory()
+	err = db.db.RunQuery(ctx, `
+		SELECT ds.name, ds.deprecated, m.version, d.goos, d.goarch
+		FROM modules m
+		INNER JOIN units u ON u.module_id = m.id
+		INNER JOIN documentation d ON d.unit_id = u.id
+		INNER JOIN documentation_symbols ds ON ds.documentation_id = d.id
+		WHERE u.path = ? AND m.module_path = ?
+			AND NOT m.incompatible AND m.version_type = 'release'`, func(rows *sql.Rows) error {
+		var (
+			sm    internal.SymbolMeta
+			build internal.BuildContext
+			v     string
+		)
+		if err := rows.Scan(&sm.Name, &sm.Deprecated, &v, &build.GOOS, &build.GOARCH); err != nil {
+			return fmt.Errorf("row.Scan(): %v", err)
+		}
+		sh.AddSymbol(sm, v, build)
+		return nil
+	}, packagePath, modulePath)
+	if err != nil {
+		return nil, err
+	}
+	return sh, nil
+}
@@ -0,0 +1,633 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/log"
+	"golang.org/x/pkgsite/internal/stdlib"
+	"golang.org/x/pkgsite/internal/version"
+)
+
+// moduleInfoColumns are the columns of the modules table read by
+// scanModuleInfo.
+const moduleInfoColumns = `m.module_path, m.version, m.commit_time, m.redistributable, m.has_go_mod, m.source_info`
+
+// scanModuleInfo constructs an *internal.ModuleInfo from the given scanner.
+func scanModuleInfo(scan func(dest ...any) error) (*internal.ModuleInfo, error) {
+	var mi internal.ModuleInfo
+	if err := scan(&mi.ModulePath, &mi.Version, timeScanner{&mi.CommitTime},
+		&mi.IsRedistributable, &mi.HasGoMod, jsonScanner{&mi.SourceInfo}); err != nil {
+		return nil, err
+	}
+	return &mi, nil
+}
+
+// GetVersionsForPath returns a list of tagged versions sorted in
+// descending semver order if any exist. If none, it returns the 10 most
+// recent from a list of pseudo-versions sorted in descending semver order.
+func (db *DB) GetVersionsForPath(ctx context.Context, path string) (_ []*internal.ModuleInfo, err error) {
+	defer derrors.WrapStack(&err, "GetVersionsForPath(ctx, %q)", path)
+
+	versions, err := db.getPathVersions(ctx, path, version.TypeRelease, version.TypePrerelease)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) != 0 {
+		return versions, nil
+	}
+	return db.getPathVersions(ctx, path, version.TypePseudo)
+}
+
+// getPathVersions returns a list of versions sorted in descending semver
+// order. The version types included in the list are specified by a list of
+// VersionTypes.
+func (db *DB) getPathVersions(ctx context.Context, path string, versionTypes ...version.Type) (_ []*internal.ModuleInfo, err error) {
+	defer derrors.WrapStack(&err, "getPathVersions(ctx, %q, %v)", path, versionTypes)
+
+	var vts []string
+	for _, vt := range versionTypes {
+		vts = append(vts, fmt.Sprintf("'%s'", vt))
+	}
+	limit := 800 // as in the postgres package, to avoid overly long pages
+	if len(versionTypes) == 1 && versionTypes[0] == version.TypePseudo {
+		limit = 10
+	}
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM modules m
+		INNER JOIN units u ON u.module_id = m.id
+		WHERE
+			u.v1path = (SELECT v1path FROM units WHERE path = ? LIMIT 1)
+			AND m.version_type IN (%s)
+		ORDER BY
+			m.incompatible,
+			m.module_path DESC,
+			m.sort_version DESC
+		LIMIT %d`, moduleInfoColumns, strings.Join(vts, ", "), limit)
+	var versions []*internal.ModuleInfo
+	collect := func(rows *sql.Rows) error {
+		mi, err := scanModuleInfo(rows.Scan)
+		if err != nil {
+			return fmt.Errorf("row.Scan(): %v", err)
+		}
+		versions = append(versions, mi)
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, query, collect, path); err != nil {
+		return nil, err
+	}
+	if err := db.populateLatestInfos(ctx, versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// GetNestedModules returns the latest major version of all nested modules
+// given a modulePath path prefix with or without major version.
+func (db *DB) GetNestedModules(ctx context.Context, modulePath string) (_ []*internal.ModuleInfo, err error) {
+	defer derrors.WrapStack(&err, "GetNestedModules(ctx, %v)", modulePath)
+
+	// SQLite has no DISTINCT ON, so read all the versions in order and keep
+	// the first for each series path.
+	query := `
+		SELECT m.series_path, ` + moduleInfoColumns + `
+		FROM modules m
+		WHERE m.module_path LIKE ? || '/%'
+		ORDER BY
+			m.series_path,
+			m.incompatible,
+			m.version_type = 'release' DESC,
+			m.sort_version DESC`
+	var (
+		modules []*internal.ModuleInfo
+		prev    string
+	)
+	collect := func(rows *sql.Rows) error {
+		var seriesPath string
+		mi, err := scanModuleInfo(func(dest ...any) error {
+			return rows.Scan(append([]any{&seriesPath}, dest...)...)
+		})
+		if err != nil {
+			return fmt.Errorf("rows.Scan(): %v", err)
+		}
+		if seriesPath == prev {
+			return nil
+		}
+		prev = seriesPath
+		if !db.IsExcluded(ctx, mi.ModulePath, mi.Version) {
+			modules = append(modules, mi)
+		}
+		return nil
+	}
+	seriesPath := internal.SeriesPathForModule(modulePath)
+	if err := db.db.RunQuery(ctx, query, collect, seriesPath); err != nil {
+		return nil, err
+	}
+	if err := db.populateLatestInfos(ctx, modules); err != nil {
+		return nil, err
+	}
+	return modules, nil
+}
+
+func (db *DB) populateLatestInfos(ctx context.Context, mis []*internal.ModuleInfo) (err error) {
+	defer derrors.WrapStack(&err, "populateLatestInfos(%d ModuleInfos)", len(mis))
+
+	lmvs := map[string]*internal.LatestModuleVersions{}
+	for _, mi := range mis {
+		lmv, ok := lmvs[mi.ModulePath]
+		if !ok {
+			lmv, err = db.GetLatestModuleVersions(ctx, mi.ModulePath)
+			if err != nil {
+				return err
+			}
+			lmvs[mi.ModulePath] = lmv
+		}
+		if lmv != nil {
+			lmv.PopulateModuleInfo(mi)
+		}
+	}
+	return nil
+}
+
+// GetLatestInfo returns the latest information about the unit in the module.
+// See internal.LatestInfo for documentation about the returned values.
+// If latestUnitMeta is non-nil, it is the result of GetUnitMeta(unitPath, internal.UnknownModulePath, internal.LatestVersion).
+func (db *DB) GetLatestInfo(ctx context.Context, unitPath, modulePath string, latestUnitMeta *internal.UnitMeta) (latest internal.LatestInfo, err error) {
+	defer derrors.WrapStack(&err, "DB.GetLatestInfo(ctx, %q, %q)", unitPath, modulePath)
+
+	if latestUnitMeta == nil {
+		latestUnitMeta, err = db.GetUnitMeta(ctx, unitPath, internal.UnknownModulePath, version.Latest)
+		if err != nil {
+			return internal.LatestInfo{}, err
+		}
+	}
+	latest.MinorVersion = latestUnitMeta.Version
+	latest.MinorModulePath = latestUnitMeta.ModulePath
+	latest.MajorModulePath, latest.MajorUnitPath, err = db.getLatestMajorVersion(ctx, unitPath, modulePath)
+	if err != nil {
+		return internal.LatestInfo{}, err
+	}
+	latest.UnitExistsAtMinor, err = db.unitExistsAtLatest(ctx, unitPath, modulePath)
+	if err != nil {
+		return internal.LatestInfo{}, err
+	}
+	return latest, nil
+}
+
+// getLatestMajorVersion returns the latest module path and the full package
+// path of the latest version found, given the fullPath and the modulePath.
+// See the method of the same name in internal/postgres.
+func (db *DB) getLatestMajorVersion(ctx context.Context, fullPath, modulePath string) (modPath, pkgPath string, err error) {
+	defer derrors.WrapStack(&err, "DB.getLatestMajorVersion(%q)", modulePath)
+
+	// Collect all the non-deprecated module paths for the series that have at
+	// least one good version, along with that good version.
+	type pathver struct {
+		path, version string
+	}
+	var pathvers []pathver
+	err = db.db.RunQuery(ctx, `
+		SELECT module_path, good_version
+		FROM latest_module_versions
+		WHERE series_path = ? AND NOT deprecated AND good_version != ''`,
+		func(rows *sql.Rows) error {
+			var pv pathver
+			if err := rows.Scan(&pv.path, &pv.version); err != nil {
+				return err
+			}
+			pathvers = append(pathvers, pv)
+			return nil
+		}, internal.SeriesPathForModule(modulePath))
+	if err != nil {
+		return "", "", err
+	}
+
+	// Find the highest tagged version from among the (module path, good
+	// version) pairs.
+	var max pathver
+	for _, pv := range pathvers {
+		if version.IsPseudo(pv.version) {
+			continue
+		}
+		if max.path == "" || semver.Compare(pv.version, max.version) > 0 {
+			max = pv
+		}
+	}
+	if max.path == "" {
+		return "", "", nil
+	}
+
+	// Find the unit path at the max-version module path.
+	v1Path := internal.V1Path(fullPath, modulePath)
+	var path string
+	err = db.db.QueryRow(ctx, `
+		SELECT u.path
+		FROM units u
+		INNER JOIN modules m ON m.id = u.module_id
+		WHERE u.v1path = ? AND m.module_path = ? AND m.version = ?`,
+		v1Path, max.path, max.version).Scan(&path)
+	switch err {
+	case nil:
+		return max.path, path, nil
+	case sql.ErrNoRows:
+		return max.path, max.path, nil
+	default:
+		return "", "", err
+	}
+}
+
+// unitExistsAtLatest reports whether unitPath exists at the latest version of modulePath.
+func (db *DB) unitExistsAtLatest(ctx context.Context, unitPath, modulePath string) (unitExists bool, err error) {
+	defer derrors.WrapStack(&err, "DB.unitExistsAtLatest(ctx, %q, %q)", unitPath, modulePath)
+
+	var latestGoodVersion string
+	lmv, err := db.GetLatestModuleVersions(ctx, modulePath)
+	if err != nil {
+		return false, err
+	}
+	if lmv != nil && lmv.GoodVersion != "" {
+		latestGoodVersion = lmv.GoodVersion
+	} else {
+		// Otherwise, query the modules table, ignoring all adjustments for
+		// incompatible and retracted versions.
+		err := db.db.QueryRow(ctx, `
+			SELECT version
+			FROM modules
+			WHERE module_path = ?
+			ORDER BY
+				version_type = 'release' DESC,
+				sort_version DESC
+			LIMIT 1`, modulePath).Scan(&latestGoodVersion)
+		if err != nil {
+			return false, err
+		}
+	}
+	var x int
+	err = db.db.QueryRow(ctx, `
+		SELECT 1
+		FROM units u
+		INNER JOIN modules m ON m.id = u.module_id
+		WHERE u.path = ? AND m.module_path = ? AND m.version = ?`,
+		unitPath, modulePath, latestGoodVersion).Scan(&x)
+	switch err {
+	case nil:
+		return true, nil
+	case sql.ErrNoRows:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// GetLatestMajorPathForV1Path reports the latest unit path in the series for
+// the given v1path. It also returns the major version for that path.
+func (db *DB) GetLatestMajorPathForV1Path(ctx context.Context, v1path string) (_ string, _ int, err error) {
+	defer derrors.WrapStack(&err, "DB.GetLatestPathForV1Path(ctx, %q)", v1path)
+
+	paths := map[string]string{} // from unit path to series path
+	err = db.db.RunQuery(ctx, `
+		SELECT u.path, m.series_path
+		FROM units u
+		INNER JOIN modules m ON u.module_id = m.id
+		WHERE u.v1path = ?`, func(rows *sql.Rows) error {
+		var p, sp string
+		if err := rows.Scan(&p, &sp); err != nil {
+			return err
+		}
+		paths[p] = sp
+		return nil
+	}, v1path)
+	if err != nil {
+		return "", 0, err
+	}
+	var (
+		maj     int
+		majPath string
+	)
+	for p, sp := range paths {
+		// Trim the series path and suffix from the unit path.
+		// Keep only the N following vN.
+		suffix := internal.Suffix(v1path, sp)
+		modPath := strings.TrimSuffix(p, "/"+suffix)
+		_, i := internal.SeriesPathAndMajorVersion(modPath)
+		if i == 0 {
+			return "", 0, fmt.Errorf("bad module path %q", modPath)
+		}
+		if maj <= i {
+			maj = i
+			majPath = p
+		}
+	}
+	if maj == 0 {
+		// Return 1 as the major version for all v0 or v1 majPaths.
+		maj = 1
+	}
+	return majPath, maj, nil
+}
+
+// GetStdlibPathsWithSuffix returns information about all paths in the latest
+// version of the standard library whose last component is suffix. A path that
+// exactly match suffix is not included; the path must end with "/" + suffix.
+func (db *DB) GetStdlibPathsWithSuffix(ctx context.Context, suffix string) (paths []string, err error) {
+	defer derrors.WrapStack(&err, "DB.GetStdlibPaths(ctx, %q)", suffix)
+
+	return database.Collect1[string](ctx, db.db, `
+		SELECT u.path
+		FROM units u
+		WHERE module_id = (
+			-- latest release version of stdlib
+			SELECT id
+			FROM modules
+			WHERE module_path = ?
+			ORDER BY
+				version_type = 'release' DESC,
+				sort_version DESC
+			LIMIT 1)
+			AND u.name != ''
+			AND u.path NOT LIKE 'cmd/%'
+			AND u.path LIKE '%/' || ?
+		ORDER BY u.path`, stdlib.ModulePath, suffix)
+}
+
+// GetLatestModuleVersions returns the row of the latest_module_versions table for modulePath.
+// If the module path is not found, it returns nil, nil.
+func (db *DB) GetLatestModuleVersions(ctx context.Context, modulePath string) (_ *internal.LatestModuleVersions, err error) {
+	return getLatestModuleVersions(ctx, db.db, modulePath)
+}
+
+func getLatestModuleVersions(ctx context.Context, db *database.DB, modulePath string) (_ *internal.LatestModuleVersions, err error) {
+	defer derrors.WrapStack(&err, "getLatestModuleVersions(%q)", modulePath)
+
+	lmvs, err := getMultiLatestModuleVersions(ctx, db, []string{modulePath})
+	if err != nil || len(lmvs) == 0 {
+		return nil, err
+	}
+	return lmvs[0], nil
+}
+
+// getMultiLatestModuleVersions returns the latest-version information for
+// the given module paths that have it, ordered by decreasing module path.
+func getMultiLatestModuleVersions(ctx context.Context, db *database.DB, modulePaths []string) (lmvs []*internal.LatestModuleVersions, err error) {
+	defer derrors.WrapStack(&err, "getMultiLatestModuleVersions(%v)", modulePaths)
+
+	if len(modulePaths) == 0 {
+		return nil, nil
+	}
+	args := make([]any, len(modulePaths))
+	for i, p := range modulePaths {
+		args[i] = p
+	}
+	query := `
+		SELECT module_path, raw_version, cooked_version, good_version, raw_go_mod_bytes
+		FROM latest_module_versions
+		WHERE module_path IN (?` + strings.Repeat(", ?", len(modulePaths)-1) + `)
+		AND status = 200
+		ORDER BY module_path DESC`
+	err = db.RunQuery(ctx, query, func(rows *sql.Rows) error {
+		var (
+			modulePath, raw, cooked, good string
+			goModBytes                    []byte
+		)
+		if err := rows.Scan(&modulePath, &raw, &cooked, &good, &goModBytes); err != nil {
+			return err
+		}
+		lmv, err := internal.NewLatestModuleVersions(modulePath, raw, cooked, good, goModBytes)
+		if err != nil {
+			return err
+		}
+		lmvs = append(lmvs, lmv)
+		return nil
+	}, args...)
+	if err != nil {
+		return nil, err
+	}
+	return lmvs, nil
+}
+
+// UpdateLatestModuleVersions upserts its argument into the latest_module_versions table
+// if the row doesn't exist, or the new version is later.
+// It returns the version that is in the DB when it completes.
+func (db *DB) UpdateLatestModuleVersions(ctx context.Context, vNew *internal.LatestModuleVersions) (_ *internal.LatestModuleVersions, err error) {
+	defer derrors.WrapStack(&err, "UpdateLatestModuleVersions(%q)", vNew.ModulePath)
+
+	var vResult *internal.LatestModuleVersions
+	err = db.db.Transact(ctx, sql.LevelDefault, func(tx *database.DB) error {
+		vCur, err := getLatestModuleVersions(ctx, tx, vNew.ModulePath)
+		if err != nil {
+			return err
+		}
+		// The raw latest version can go backwards if it was an incompatible
+		// version, but then a compatible version with a go.mod file is
+		// published.
+		rawIsMoreRecent := func(v1, v2 string) bool {
+			return version.Later(v1, v2) || (version.IsIncompatible(v2) && !version.IsIncompatible(v1))
+		}
+		update := vCur == nil || rawIsMoreRecent(vNew.RawVersion, vCur.RawVersion) ||
+			// If new versions are added, cooked can change even if raw is the same.
+			(vNew.RawVersion == vCur.RawVersion && vNew.CookedVersion != vCur.CookedVersion)
+		if !update {
+			log.Debugf(ctx, "%s: not updating latest module versions", vNew.ModulePath)
+			vResult = vCur
+			return nil
+		}
+		if vCur != nil {
+			// If the latest good version is now retracted, recompute it.
+			if vCur.GoodVersion != "" && vNew.IsRetracted(vCur.GoodVersion) {
+				good, err := getLatestGoodVersion(ctx, tx, vNew.ModulePath, vNew)
+				if err != nil {
+					return err
+				}
+				vNew.GoodVersion = good
+			} else {
+				vNew.GoodVersion = vCur.GoodVersion
+			}
+		}
+		vResult = vNew
+		goModBytes, err := vNew.GoModFile.Format()
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO latest_module_versions (
+				module_path,
+				series_path,
+				raw_version,
+				cooked_version,
+				good_version,
+				deprecated,
+				raw_go_mod_bytes,
+				status
+			) VALUES (?, ?, ?, ?, ?, ?, ?, 200)
+			ON CONFLICT (module_path)
+			DO UPDATE SET
+				series_path=excluded.series_path,
+				raw_version=excluded.raw_version,
+				cooked_version=excluded.cooked_version,
+				good_version=excluded.good_version,
+				deprecated=excluded.deprecated,
+				raw_go_mod_bytes=excluded.raw_go_mod_bytes,
+				status=excluded.status`,
+			vNew.ModulePath, internal.SeriesPathForModule(vNew.ModulePath),
+			vNew.RawVersion, vNew.CookedVersion, vNew.GoodVersion, vNew.Deprecated, goModBytes)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vResult, nil
+}
+
+// getLatestGoodVersion returns the latest version of a module in the modules
+// table, respecting the retractions and other information in the given
+// LatestModuleVersions. If lmv is nil, it finds the latest version, favoring
+// release over pre-release, including incompatible versions, and ignoring
+// retractions.
+func getLatestGoodVersion(ctx context.Context, tx *database.DB, modulePath string, lmv *internal.LatestModuleVersions) (_ string, err error) {
+	defer derrors.WrapStack(&err, "getLatestGoodVersion(%q)", modulePath)
+
+	query := `SELECT version FROM modules WHERE module_path = ?`
+	if !internal.GoodVersionsIncludeIncompatible(lmv) {
+		query += ` AND NOT incompatible`
+	}
+	vs, err := database.Collect1[string](ctx, tx, query, modulePath)
+	if err != nil {
+		return "", err
+	}
+	return internal.LatestGoodVersion(vs, lmv), nil
+}
+
+// updateLatestGoodVersion updates latest_module_versions.good_version for modulePath to version.
+func updateLatestGoodVersion(ctx context.Context, tx *database.DB, modulePath, version string) (err error) {
+	defer derrors.WrapStack(&err, "updateLatestGoodVersion(%q, %q)", modulePath, version)
+
+	_, err = tx.Exec(ctx, `UPDATE latest_module_versions SET good_version = ? WHERE module_path = ?`,
+		version, modulePath)
+	return err
+}
+
+// UpsertVersionMap inserts a version_map entry into the database.
+func (db *DB) UpsertVersionMap(ctx context.Context, vm *internal.VersionMap) (err error) {
+	defer derrors.WrapStack(&err, "DB.UpsertVersionMap(ctx, tx, %q, %q, %q)",
+		vm.ModulePath, vm.RequestedVersion, vm.ResolvedVersion)
+
+	var moduleID int
+	if vm.ResolvedVersion != "" {
+		if err := db.db.QueryRow(ctx, `SELECT id FROM modules WHERE module_path = ? AND version = ?`,
+			vm.ModulePath, vm.ResolvedVersion).Scan(&moduleID); err != nil && err != sql.ErrNoRows {
+			return err
+		}
+	}
+	var sortVersion string
+	if vm.ResolvedVersion != "" {
+		sortVersion = version.ForSorting(vm.ResolvedVersion)
+	}
+	_, err = db.db.Exec(ctx, `
+		INSERT INTO version_map(
+			module_path,
+			requested_version,
+			resolved_version,
+			go_mod_path,
+			status,
+			error,
+			sort_version,
+			module_id,
+			updated_at)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (module_path, requested_version)
+		DO UPDATE SET
+			go_mod_path=excluded.go_mod_path,
+			resolved_version=excluded.resolved_version,
+			status=excluded.status,
+			error=excluded.error,
+			sort_version=excluded.sort_version,
+			module_id=excluded.module_id,
+			updated_at=excluded.updated_at`,
+		vm.ModulePath, vm.RequestedVersion, vm.ResolvedVersion, vm.GoModPath,
+		vm.Status, vm.Error, sortVersion, moduleID, time.Now().UnixNano())
+	return err
+}
+
+const versionMapColumns = `module_path, requested_version, resolved_version, go_mod_path, status, error, updated_at`
+
+func scanVersionMap(scan func(dest ...any) error) (*internal.VersionMap, error) {
+	var vm internal.VersionMap
+	if err := scan(&vm.ModulePath, &vm.RequestedVersion, &vm.ResolvedVersion, &vm.GoModPath,
+		&vm.Status, &vm.Error, timeScanner{&vm.UpdatedAt}); err != nil {
+		return nil, err
+	}
+	return &vm, nil
+}
+
+// GetVersionMap fetches a version_map entry corresponding to the given
+// modulePath and requestedVersion.
+func (db *DB) GetVersionMap(ctx context.Context, modulePath, requestedVersion string) (_ *internal.VersionMap, err error) {
+	defer derrors.WrapStack(&err, "DB.GetVersionMap(ctx, tx, %q, %q)", modulePath, requestedVersion)
+	if modulePath == internal.UnknownModulePath {
+		return nil, fmt.Errorf("modulePath must be specified: %w", derrors.InvalidArgument)
+	}
+
+	row := db.db.QueryRow(ctx, `
+		SELECT `+versionMapColumns+`
+		FROM version_map
+		WHERE module_path = ? AND requested_version = ?`,
+		modulePath, requestedVersion)
+	vm, err := scanVersionMap(row.Scan)
+	switch err {
+	case nil:
+		return vm, nil
+	case sql.ErrNoRows:
+		return nil, derrors.NotFound
+	default:
+		return nil, err
+	}
+}
+
+// GetVersionMaps returns all of the version maps for the provided
+// path and requested version if they are present.
+func (db *DB) GetVersionMaps(ctx context.Context, paths []string, requestedVersion string) (_ []*internal.VersionMap, err error) {
+	defer derrors.WrapStack(&err, "DB.GetVersionMaps(ctx, %v, %q)", paths, requestedVersion)
+
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	var args []any
+	for _, p := range paths {
+		args = append(args, p)
+	}
+	args = append(args, requestedVersion, requestedVersion)
+	query := `
+		SELECT ` + versionMapColumns + `
+		FROM version_map
+		WHERE module_path IN (?` + strings.Repeat(", ?", len(paths)-1) + `)
+		AND (requested_version = ? OR resolved_version = ?)
+		ORDER BY module_path DESC`
+	var result []*internal.VersionMap
+	seen := map[string]bool{}
+	collect := func(rows *sql.Rows) error {
+		vm, err := scanVersionMap(rows.Scan)
+		if err != nil {
+			return err
+		}
+		if !seen[vm.ModulePath] {
+			seen[vm.ModulePath] = true
+			result = append(result, vm)
+		}
+		return nil
+	}
+	if err := db.db.RunQuery(ctx, query, collect, args...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
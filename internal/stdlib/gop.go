@@ -0,0 +1,91 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stdlib
+
+import (
+	"strings"
+
+	"golang.org/x/mod/semver"
+	"golang.org/x/pkgsite/internal/version"
+)
+
+// The Go+ repository holds the Go+ toolchain and the packages that Go+
+// programs depend on at run time. Pkgsite treats it as a second standard
+// library: its packages are served at short paths under GopShortPrefix, like
+// "gop/builtin" for "github.com/goplus/gop/builtin", to which their import
+// paths redirect, and "/gop" is its landing page. Unlike the Go standard
+// library, it is an ordinary module, so it is fetched like any other, and its
+// tags are its versions.
+const (
+	GopModulePath  = "github.com/goplus/gop"
+	GopRepoURL     = "https://github.com/goplus/gop"
+	GopShortPrefix = "gop"
+)
+
+// GopSupportedBranches are the branches of the Go+ repo that can be
+// requested in place of a version.
+var GopSupportedBranches = map[string]bool{
+	"main": true,
+}
+
+// GopContains reports whether the import path is in the Go+ module.
+func GopContains(path string) bool {
+	return path == GopModulePath || strings.HasPrefix(path, GopModulePath+"/")
+}
+
+// IsGopShortPath reports whether path is a short path for a Go+ package, like
+// "gop/builtin", or is "gop" itself.
+func IsGopShortPath(path string) bool {
+	return path == GopShortPrefix || strings.HasPrefix(path, GopShortPrefix+"/")
+}
+
+// GopPathForShortPath returns the import path for the Go+ short path, or ""
+// if it is not one.
+// Examples:
+//
+//	"gop" => "github.com/goplus/gop"
+//	"gop/builtin" => "github.com/goplus/gop/builtin"
+func GopPathForShortPath(short string) string {
+	if !IsGopShortPath(short) {
+		return ""
+	}
+	return GopModulePath + strings.TrimPrefix(short, GopShortPrefix)
+}
+
+// GopShortPath returns the short path for the import path of a package in the
+// Go+ module, or "" if it is not in the module. It is the inverse of
+// GopPathForShortPath.
+func GopShortPath(path string) string {
+	if !GopContains(path) {
+		return ""
+	}
+	return GopShortPrefix + strings.TrimPrefix(path, GopModulePath)
+}
+
+// GopVersionForTag returns the semantic version for a tag of the Go+ repo, or
+// "" if tag doesn't correspond to a version. Go+ tags are semantic versions,
+// but in URLs the leading "v" may be omitted. As for VersionForTag, "latest"
+// and supported branches are returned as is.
+// Examples:
+//
+//	"v1.2.6" => "v1.2.6"
+//	"1.2.6" => "v1.2.6"
+//	"v1.1.0-beta3" => "v1.1.0-beta3"
+//	"v1.2" => ""
+//	"main" => "main"
+func GopVersionForTag(tag string) string {
+	if tag == version.Latest || GopSupportedBranches[tag] {
+		return tag
+	}
+	if !strings.HasPrefix(tag, "v") {
+		tag = "v" + tag
+	}
+	// Require a complete version: the tag is the version, so "v1.2" cannot
+	// stand for "v1.2.0".
+	if semver.Canonical(tag) != tag {
+		return ""
+	}
+	return tag
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stdlib
+
+import "testing"
+
+func TestGopVersionForTag(t *testing.T) {
+	for _, test := range []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"v1.2.6", "v1.2.6"},
+		{"1.2.6", "v1.2.6"},
+		{"v1.1.0-beta3", "v1.1.0-beta3"},
+		{"v1.2.0-pre.1", "v1.2.0-pre.1"},
+		{"v1.2", ""},
+		{"go1.21", ""},
+		{"latest", "latest"},
+		{"main", "main"},
+	} {
+		got := GopVersionForTag(test.in)
+		if got != test.want {
+			t.Errorf("GopVersionForTag(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestGopShortPath(t *testing.T) {
+	for _, test := range []struct {
+		path, short string
+	}{
+		{"github.com/goplus/gop", "gop"},
+		{"github.com/goplus/gop/builtin", "gop/builtin"},
+		{"github.com/goplus/gop/builtin/ng", "gop/builtin/ng"},
+	} {
+		if got := GopShortPath(test.path); got != test.short {
+			t.Errorf("GopShortPath(%q) = %q, want %q", test.path, got, test.short)
+		}
+		if got := GopPathForShortPath(test.short); got != test.path {
+			t.Errorf("GopPathForShortPath(%q) = %q, want %q", test.short, got, test.path)
+		}
+	}
+	for _, path := range []string{"github.com/goplus/gopher", "fmt", "github.com/goplus/igop"} {
+		if got := GopShortPath(path); got != "" {
+			t.Errorf("GopShortPath(%q) = %q, want empty", path, got)
+		}
+	}
+	for _, short := range []string{"gopher", "fmt", "std"} {
+		if got := GopPathForShortPath(short); got != "" {
+			t.Errorf("GopPathForShortPath(%q) = %q, want empty", short, got)
+		}
+	}
+}
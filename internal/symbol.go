@@ -74,6 +74,28 @@ type SymbolMeta struct {
 	// the empty string. For example, the parent type for
 	// net/http.FileServer is Handler.
 	ParentName string
+
+	// Deprecated reports whether the symbol's doc comment has a paragraph
+	// that starts with "Deprecated:".
+	Deprecated bool
+
+	// Replacement is the name of the symbol that the deprecation paragraph
+	// suggests using instead, like "NewReader" or "io.ReadAll", or the empty
+	// string if there is none or it could not be determined.
+	Replacement string
+}
+
+// DeprecatedSymbol is a deprecated symbol of a package.
+type DeprecatedSymbol struct {
+	SymbolMeta
+
+	// PackagePath is the import path of the package the symbol belongs to.
+	PackagePath string
+
+	// Since is the first release version of the module from which the symbol
+	// has been deprecated in every version that has it, according to the
+	// symbol history of the package. It is empty if that is not known.
+	Since string
 }
 
 // SymbolHistory represents the history for when a symbol name was first added
@@ -172,7 +194,10 @@ func (us *SymbolBuildContexts) BuildContexts() []BuildContext {
 		builds = append(builds, b)
 	}
 	sort.Slice(builds, func(i, j int) bool {
-		return builds[i].GOOS < builds[j].GOOS
+		if builds[i].GOOS != builds[j].GOOS {
+			return builds[i].GOOS < builds[j].GOOS
+		}
+		return builds[i].GOARCH < builds[j].GOARCH
 	})
 	return builds
 }
@@ -203,7 +228,12 @@ func (us *SymbolBuildContexts) SupportsBuild(build BuildContext) bool {
 
 // InAll reports whether the unit symbol supports all build contexts.
 func (us *SymbolBuildContexts) InAll() bool {
-	return len(us.builds) == len(BuildContexts)
+	for _, b := range BuildContexts {
+		if !us.builds[b] {
+			return false
+		}
+	}
+	return true
 }
 
 // RemoveBuildContexts removes all of the build contexts associated with this
@@ -0,0 +1,37 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package symbol
+
+import "golang.org/x/pkgsite/internal"
+
+// DeprecatedSince returns a map from the name of each symbol that is
+// deprecated in the last version of sh that has it, to the first version from
+// which it has been deprecated in every version that has it. A symbol counts
+// as deprecated at a version if it is deprecated in all of the build contexts
+// in which it appears there.
+//
+// The input sh holds the symbols of every version, such as the result of
+// reading package symbols from the database, not the output of
+// IntroducedHistory.
+func DeprecatedSince(sh *internal.SymbolHistory) map[string]string {
+	since := map[string]string{}
+	for _, v := range sh.Versions() {
+		for name, sms := range sh.SymbolsAtVersion(v) {
+			deprecated := true
+			for sm := range sms {
+				if !sm.Deprecated {
+					deprecated = false
+					break
+				}
+			}
+			if !deprecated {
+				delete(since, name)
+			} else if _, ok := since[name]; !ok {
+				since[name] = v
+			}
+		}
+	}
+	return since
+}
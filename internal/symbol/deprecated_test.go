@@ -0,0 +1,56 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package symbol
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal"
+)
+
+func TestDeprecatedSince(t *testing.T) {
+	linux := internal.BuildContext{GOOS: "linux", GOARCH: "amd64"}
+	windows := internal.BuildContext{GOOS: "windows", GOARCH: "amd64"}
+	sh := internal.NewSymbolHistory()
+	for _, s := range []struct {
+		name, version string
+		deprecated    bool
+		build         internal.BuildContext
+	}{
+		// Deprecated from v1.1.0 on.
+		{"A", "v1.0.0", false, internal.BuildContextAll},
+		{"A", "v1.1.0", true, internal.BuildContextAll},
+		{"A", "v1.2.0", true, internal.BuildContextAll},
+		// Deprecated, undeprecated, and deprecated again.
+		{"B", "v1.0.0", true, internal.BuildContextAll},
+		{"B", "v1.1.0", false, internal.BuildContextAll},
+		{"B", "v1.2.0", true, internal.BuildContextAll},
+		// Deprecated from the start, and removed in v1.2.0.
+		{"C", "v1.0.0", true, internal.BuildContextAll},
+		{"C", "v1.1.0", true, internal.BuildContextAll},
+		// No longer deprecated.
+		{"D", "v1.1.0", true, internal.BuildContextAll},
+		{"D", "v1.2.0", false, internal.BuildContextAll},
+		// Deprecated on only one build context at v1.1.0.
+		{"E", "v1.1.0", true, linux},
+		{"E", "v1.1.0", false, windows},
+		{"E", "v1.2.0", true, linux},
+		{"E", "v1.2.0", true, windows},
+	} {
+		sm := internal.SymbolMeta{Name: s.name, Deprecated: s.deprecated, Synopsis: s.build.GOOS}
+		sh.AddSymbol(sm, s.version, s.build)
+	}
+	got := DeprecatedSince(sh)
+	want := map[string]string{
+		"A": "v1.1.0",
+		"B": "v1.2.0",
+		"C": "v1.0.0",
+		"E": "v1.2.0",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
@@ -239,6 +239,23 @@ func (ds *FakeDataSource) GetModuleReadme(ctx context.Context, modulePath, resol
 	return nil, nil
 }
 
+// GetModuleRequirements returns the requirements of the module version,
+// sorted by module path and version.
+func (ds *FakeDataSource) GetModuleRequirements(ctx context.Context, modulePath, resolvedVersion string) ([]*internal.Requirement, error) {
+	m := ds.getModule(modulePath, resolvedVersion)
+	if m == nil {
+		return nil, derrors.NotFound
+	}
+	reqs := append([]*internal.Requirement(nil), m.Requirements...)
+	sort.Slice(reqs, func(i, j int) bool {
+		if reqs[i].ModulePath != reqs[j].ModulePath {
+			return reqs[i].ModulePath < reqs[j].ModulePath
+		}
+		return reqs[i].Version < reqs[j].Version
+	})
+	return reqs, nil
+}
+
 // GetLatestInfo gets information about the latest versions of a unit and module.
 // See LatestInfo for documentation.
 func (ds *FakeDataSource) GetLatestInfo(ctx context.Context, unitPath, modulePath string, latestUnitMeta *internal.UnitMeta) (latest internal.LatestInfo, err error) {
@@ -363,6 +380,46 @@ func (ds *FakeDataSource) GetStdlibPathsWithSuffix(ctx context.Context, suffix s
 	return nil, errNotImplemented
 }
 
+// GetDeprecatedSymbols returns the deprecated symbols in the documentation of
+// the module version. Since is never set, because the FakeDataSource does not
+// keep symbol history.
+func (ds *FakeDataSource) GetDeprecatedSymbols(ctx context.Context, modulePath, version string) ([]*internal.DeprecatedSymbol, error) {
+	m := ds.getModule(modulePath, version)
+	if m == nil {
+		return nil, derrors.NotFound
+	}
+	var syms []*internal.DeprecatedSymbol
+	for _, u := range m.Units {
+		seen := map[string]bool{}
+		add := func(sm *internal.SymbolMeta) {
+			if !sm.Deprecated || seen[sm.Name] {
+				return
+			}
+			seen[sm.Name] = true
+			d := &internal.DeprecatedSymbol{SymbolMeta: *sm, PackagePath: u.Path}
+			if d.ParentName == d.Name {
+				d.ParentName = ""
+			}
+			syms = append(syms, d)
+		}
+		for _, doc := range u.Documentation {
+			for _, s := range doc.API {
+				add(&s.SymbolMeta)
+				for _, c := range s.Children {
+					add(c)
+				}
+			}
+		}
+	}
+	sort.Slice(syms, func(i, j int) bool {
+		if syms[i].PackagePath != syms[j].PackagePath {
+			return syms[i].PackagePath < syms[j].PackagePath
+		}
+		return syms[i].Name < syms[j].Name
+	})
+	return syms, nil
+}
+
 func (ds *FakeDataSource) GetSymbolHistory(ctx context.Context, packagePath, modulePath string) (*internal.SymbolHistory, error) {
 	return &internal.SymbolHistory{}, nil
 }
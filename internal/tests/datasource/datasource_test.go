@@ -0,0 +1,125 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package datasource checks that the Postgres and SQLite data sources store
+// and return modules alike.
+package datasource
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/pkgsite/internal"
+	"golang.org/x/pkgsite/internal/database"
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/licenses"
+	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/sqlite"
+	"golang.org/x/pkgsite/internal/testing/sample"
+)
+
+// pgDB is the Postgres test database, or nil if there is none.
+var pgDB *postgres.DB
+
+func TestMain(m *testing.M) {
+	database.QueryLoggingDisabled = true
+	db, err := postgres.SetupTestDB("discovery_datasource_test")
+	if err != nil {
+		if !errors.Is(err, derrors.NotFound) || os.Getenv("GO_DISCOVERY_TESTDB") == "true" {
+			log.Fatal(err)
+		}
+		// Run the tests against SQLite only.
+		log.Printf("SKIPPING Postgres: could not connect to DB (see doc/postgres.md to set up): %v", err)
+	} else {
+		pgDB = db
+	}
+	code := m.Run()
+	if pgDB != nil {
+		if err := pgDB.Close(); err != nil {
+			log.Fatal(err)
+		}
+	}
+	os.Exit(code)
+}
+
+// A store is a data source that modules can be inserted into.
+type store interface {
+	InsertModule(ctx context.Context, m *internal.Module, lmv *internal.LatestModuleVersions) (bool, error)
+	GetUnitMeta(ctx context.Context, path, requestedModulePath, requestedVersion string) (*internal.UnitMeta, error)
+	GetUnit(ctx context.Context, pathInfo *internal.UnitMeta, fields internal.FieldSet, bc internal.BuildContext) (*internal.Unit, error)
+}
+
+// forEachStore runs f as a subtest on an empty store of each kind.
+func forEachStore(t *testing.T, f func(t *testing.T, s store)) {
+	t.Run("postgres", func(t *testing.T) {
+		if pgDB == nil {
+			t.Skip("no Postgres database")
+		}
+		defer postgres.ResetTestDB(pgDB, t)
+		f(t, pgDB)
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		db, err := sqlite.Open(context.Background(), ":memory:")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		f(t, db)
+	})
+}
+
+func TestInsertModuleGetUnit(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store) {
+		ctx := context.Background()
+		m := sample.DefaultModule()
+		// Null runes can't be stored in Postgres, so they are removed.
+		m.Units[1].Readme = &internal.Readme{Filepath: "README.md", Contents: "readme\x00 contents"}
+		if _, err := s.InsertModule(ctx, m, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		um, err := s.GetUnitMeta(ctx, sample.PackagePath, sample.ModulePath, sample.VersionString)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := sample.UnitMeta(sample.PackagePath, sample.ModulePath, sample.VersionString, sample.PackageName, true)
+		if diff := cmp.Diff(want, um, cmpopts.IgnoreFields(internal.UnitMeta{}, "CommitTime", "HasGoMod", "SourceInfo")); diff != "" {
+			t.Errorf("GetUnitMeta mismatch (-want +got):\n%s", diff)
+		}
+
+		u, err := s.GetUnit(ctx, um, internal.AllFields, internal.BuildContext{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantUnit := m.Units[1]
+		if diff := cmp.Diff(wantUnit.Documentation, u.Documentation, cmpopts.IgnoreFields(internal.Documentation{}, "API")); diff != "" {
+			t.Errorf("Documentation mismatch (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff(sample.Imports(), u.Imports); diff != "" {
+			t.Errorf("Imports mismatch (-want +got):\n%s", diff)
+		}
+		ignoreCoverage := cmpopts.IgnoreFields(licenses.Metadata{}, "Coverage")
+		if diff := cmp.Diff(sample.LicenseMetadata(), u.Licenses, ignoreCoverage); diff != "" {
+			t.Errorf("Licenses mismatch (-want +got):\n%s", diff)
+		}
+		if u.Readme == nil || u.Readme.Contents != "readme contents" {
+			t.Errorf("got README %+v, want contents %q", u.Readme, "readme contents")
+		}
+	})
+}
+
+func TestInsertInvalidModule(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store) {
+		m := sample.DefaultModule()
+		m.Version = "not-a-version"
+		if _, err := s.InsertModule(context.Background(), m, nil); !errors.Is(err, derrors.DBModuleInsertInvalid) {
+			t.Errorf("got %v, want DBModuleInsertInvalid", err)
+		}
+	})
+}
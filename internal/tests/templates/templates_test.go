@@ -37,12 +37,17 @@ func TestCheckFrontendTemplates(t *testing.T) {
 		{"license-policy", nil, frontend.LicensePolicyPage{}},
 		{"search", nil, frontend.SearchPage{}},
 		{"search-help", nil, page.BasePage{}},
+		{"source", nil, frontend.SourcePage{}},
 		{"unit/main", nil, frontend.UnitPage{}},
 		{
 			"unit/main",
 			[]string{"unit-outline", "unit-readme", "unit-doc", "unit-files", "unit-directories"},
 			frontend.MainDetails{},
 		},
+		{"unit/deprecated", nil, frontend.UnitPage{}},
+		{"unit/deprecated", []string{"deprecated"}, frontend.DeprecatedDetails{}},
+		{"unit/licensereport", nil, frontend.UnitPage{}},
+		{"unit/licensereport", []string{"licensereport"}, frontend.LicenseReportDetails{}},
 		{"unit/importedby", nil, frontend.UnitPage{}},
 		{"unit/importedby", []string{"importedby"}, frontend.ImportedByDetails{}},
 		{"unit/imports", nil, frontend.UnitPage{}},
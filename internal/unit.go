@@ -69,6 +69,28 @@ type Documentation struct {
 	Synopsis string
 	Source   []byte // encoded ast.Files; see godoc.Package.Encode
 	API      []*Symbol
+	// Implementations optionally lists the interfaces that the package's
+	// types implement and the types of the module that implement the
+	// package's interfaces, as determined by type-checking.
+	Implementations []*Implementation
+	// ExampleResults optionally maps the names of the package's example
+	// functions, like "ExampleT_M", to whether their output matched their
+	// output comments when they were run. Examples that were not run are
+	// omitted.
+	ExampleResults map[string]bool
+}
+
+// An Implementation records that a type implements an interface, where at
+// least one of them is declared in the package being documented.
+//
+// Names are qualified by package name unless they are declared in the package
+// being documented. If only the pointer type implements the interface, Type
+// starts with "*".
+type Implementation struct {
+	Type             string // e.g. "*File"
+	TypePkgPath      string // e.g. "os"
+	Interface        string // e.g. "io.Reader"
+	InterfacePkgPath string // e.g. "io"
 }
 
 // Readme is a README at the specified filepath.
@@ -0,0 +1,101 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vuln
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+
+	"golang.org/x/pkgsite/internal/derrors"
+	"golang.org/x/pkgsite/internal/osv"
+)
+
+// NewLocalClient returns a client that reads the vulnerability database at
+// filename on the local filesystem, which is either a directory or a zip file.
+//
+// The database is either laid out as the Go vulnerability database is served,
+// with an index directory (as in https://vuln.go.dev/vulndb.zip), or is a
+// collection of OSV entries, one per .json file (as in the export of the Go
+// ecosystem by osv.dev). In the latter case, all entries are read into memory.
+func NewLocalClient(filename string) (_ *Client, err error) {
+	defer derrors.Wrap(&err, "NewLocalClient(%q)", filename)
+
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+	var fsys fs.FS
+	if fi.IsDir() {
+		fsys = os.DirFS(filename)
+	} else {
+		// The zip file stays open for the lifetime of the client.
+		zr, err := zip.OpenReader(filename)
+		if err != nil {
+			return nil, err
+		}
+		fsys = zr
+	}
+	if _, err := fs.Stat(fsys, dbEndpoint+".json"); err == nil {
+		return &Client{src: &fsSource{fsys: fsys}}, nil
+	}
+	entries, err := readEntries(fsys)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no %s or OSV entries found", dbEndpoint+".json")
+	}
+	src, err := newInMemorySource(entries)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{src: src}, nil
+}
+
+// readEntries reads the OSV entries in all the .json files of fsys.
+func readEntries(fsys fs.FS) ([]*osv.Entry, error) {
+	var entries []*osv.Entry
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(p) != ".json" {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		var e osv.Entry
+		if err := json.Unmarshal(data, &e); err != nil {
+			return fmt.Errorf("%s: %v", p, err)
+		}
+		entries = append(entries, &e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// fsSource reads databases from an fs.FS, such as a zip file.
+type fsSource struct {
+	fsys fs.FS
+}
+
+func (s *fsSource) get(ctx context.Context, endpoint string) ([]byte, error) {
+	data, err := fs.ReadFile(s.fsys, endpoint+".json")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no data found at endpoint %q", endpoint)
+	}
+	return data, err
+}
@@ -0,0 +1,117 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vuln
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/pkgsite/internal/osv"
+	"golang.org/x/tools/txtar"
+)
+
+func TestNewLocalClient(t *testing.T) {
+	// files of a database in the layout of vuln.go.dev.
+	indexed := map[string][]byte{}
+	ar, err := txtar.ParseFile(dbTxtar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range ar.Files {
+		indexed[f.Name+".json"] = f.Data
+	}
+	// files of a database of OSV entries only.
+	entries := map[string][]byte{}
+	for _, e := range []*osv.Entry{&testOSV1, &testOSV2, &testOSV3} {
+		data, err := json.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[e.ID+".json"] = data
+	}
+
+	for _, test := range []struct {
+		name  string
+		files map[string][]byte
+		zip   bool
+	}{
+		{"indexed dir", indexed, false},
+		{"indexed zip", indexed, true},
+		{"entries dir", entries, false},
+		{"entries zip", entries, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			filename := t.TempDir()
+			if test.zip {
+				filename = filepath.Join(filename, "db.zip")
+				writeZip(t, filename, test.files)
+			} else {
+				for name, data := range test.files {
+					writeFile(t, filepath.Join(filename, name), data)
+				}
+			}
+			c, err := NewLocalClient(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := context.Background()
+			got, err := c.ByID(ctx, testOSV1.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(&testOSV1, got); diff != "" {
+				t.Errorf("ByID(%q) mismatch (-want +got):\n%s", testOSV1.ID, diff)
+			}
+			id, err := c.ByAlias(ctx, testOSV2.Aliases[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id != testOSV2.ID {
+				t.Errorf("ByAlias(%q) = %q, want %q", testOSV2.Aliases[0], id, testOSV2.ID)
+			}
+		})
+	}
+
+	if _, err := NewLocalClient(t.TempDir()); err == nil {
+		t.Error("empty directory: got nil error, want error")
+	}
+}
+
+func writeFile(t *testing.T, filename string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeZip(t *testing.T, filename string, files map[string][]byte) {
+	t.Helper()
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
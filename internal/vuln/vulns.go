@@ -9,8 +9,10 @@ import (
 	"context"
 	"fmt"
 	"go/token"
+	"sort"
 	"strings"
 
+	"golang.org/x/pkgsite/internal/derrors"
 	"golang.org/x/pkgsite/internal/osv"
 	"golang.org/x/pkgsite/internal/stdlib"
 	vers "golang.org/x/pkgsite/internal/version"
@@ -32,21 +34,9 @@ func VulnsForPackage(ctx context.Context, modulePath, version, packagePath strin
 		return nil
 	}
 
-	// Handle special module paths.
-	if modulePath == stdlib.ModulePath {
-		// Stdlib pages requested at master will map to a pseudo version
-		// that puts all vulns in range.
-		// We can't really tell you're at master so version.IsPseudo
-		// is the best we can do. The result is vulns won't be reported for a
-		// pseudoversion that refers to a commit that is in a vulnerable range.
-		switch {
-		case vers.IsPseudo(version):
-			return nil
-		case strings.HasPrefix(packagePath, "cmd/"):
-			modulePath = osv.GoCmdModulePath
-		default:
-			modulePath = osv.GoStdModulePath
-		}
+	modulePath, ok := vulnModulePath(modulePath, version, packagePath)
+	if !ok {
+		return nil
 	}
 
 	// Get all the vulns for this package/version.
@@ -58,6 +48,97 @@ func VulnsForPackage(ctx context.Context, modulePath, version, packagePath strin
 	return toVulns(entries)
 }
 
+// vulnModulePath returns the module path under which the vulnerability
+// database records vulnerabilities of the given module, which differs for
+// the standard library. It reports false if vulnerabilities can't be
+// determined for the version.
+func vulnModulePath(modulePath, version, packagePath string) (string, bool) {
+	if modulePath != stdlib.ModulePath {
+		return modulePath, true
+	}
+	// Stdlib pages requested at master will map to a pseudo version
+	// that puts all vulns in range.
+	// We can't really tell you're at master so version.IsPseudo
+	// is the best we can do. The result is vulns won't be reported for a
+	// pseudoversion that refers to a commit that is in a vulnerable range.
+	switch {
+	case vers.IsPseudo(version):
+		return "", false
+	case strings.HasPrefix(packagePath, "cmd/"):
+		return osv.GoCmdModulePath, true
+	default:
+		return osv.GoStdModulePath, true
+	}
+}
+
+// A SymbolVuln is a vulnerability in a package, along with the symbols of
+// the package that it affects.
+type SymbolVuln struct {
+	Vuln
+	// Symbols are the affected functions and methods of the package, like
+	// "F" or "T.M", sorted. If empty, the whole package is affected.
+	Symbols []string
+}
+
+// SymbolVulnsForPackage returns the vulnerabilities that affect the package
+// at the given version, with the symbols each of them affects. Unlike
+// VulnsForPackage, it reports an error in obtaining them as an error.
+func SymbolVulnsForPackage(ctx context.Context, modulePath, version, packagePath string, vc *Client) (_ []*SymbolVuln, err error) {
+	defer derrors.Wrap(&err, "SymbolVulnsForPackage(%q, %q, %q)", modulePath, version, packagePath)
+
+	if vc == nil {
+		return nil, nil
+	}
+	modulePath, ok := vulnModulePath(modulePath, version, packagePath)
+	if !ok {
+		return nil, nil
+	}
+	entries, err := vc.ByPackage(ctx, &PackageRequest{Module: modulePath, Package: packagePath, Version: version})
+	if err != nil {
+		return nil, err
+	}
+	var svs []*SymbolVuln
+	for _, e := range entries {
+		svs = append(svs, &SymbolVuln{
+			Vuln:    Vuln{ID: e.ID, Details: e.Summary},
+			Symbols: affectedSymbols(e, modulePath, version, packagePath),
+		})
+	}
+	return svs, nil
+}
+
+// affectedSymbols returns the symbols of the package that the entry says are
+// affected at the given version of the module, sorted. It returns nil if the
+// entry doesn't list them, which means all of the package is affected.
+func affectedSymbols(e *osv.Entry, modulePath, version, packagePath string) []string {
+	seen := map[string]bool{}
+	for _, a := range e.Affected {
+		if a.Module.Path != modulePath || !osv.AffectsSemver(a.Ranges, version) {
+			continue
+		}
+		if len(a.EcosystemSpecific.Packages) == 0 {
+			return nil
+		}
+		for _, p := range a.EcosystemSpecific.Packages {
+			if p.Path != packagePath {
+				continue
+			}
+			if len(p.Symbols) == 0 {
+				return nil
+			}
+			for _, s := range p.Symbols {
+				seen[s] = true
+			}
+		}
+	}
+	var symbols []string
+	for s := range seen {
+		symbols = append(symbols, s)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
 func toVulns(entries []*osv.Entry) []Vuln {
 	if len(entries) == 0 {
 		return nil
@@ -161,6 +161,114 @@ func TestVulnsForPackage(t *testing.T) {
 	}
 }
 
+func TestSymbolVulnsForPackage(t *testing.T) {
+	withSymbols := &osv.Entry{
+		ID:      "GO-1999-0001",
+		Summary: "bad symbols",
+		Affected: []osv.Affected{{
+			Module: osv.Module{Path: "bad.com"},
+			Ranges: []osv.Range{{
+				Type:   osv.RangeTypeSemver,
+				Events: []osv.RangeEvent{{Introduced: "0"}, {Fixed: "1.2.3"}},
+			}},
+			EcosystemSpecific: osv.EcosystemSpecific{
+				Packages: []osv.Package{{
+					Path:    "bad.com/pkg",
+					Symbols: []string{"T.M", "F"},
+				}, {
+					Path:    "bad.com/pkg",
+					GOOS:    []string{"windows"},
+					Symbols: []string{"G", "F"},
+				}, {
+					Path:    "bad.com/other",
+					Symbols: []string{"H"},
+				}},
+			},
+		}},
+	}
+	wholePackage := &osv.Entry{
+		ID:      "GO-1999-0002",
+		Summary: "bad package",
+		Affected: []osv.Affected{{
+			Module: osv.Module{Path: "bad.com"},
+			EcosystemSpecific: osv.EcosystemSpecific{
+				Packages: []osv.Package{{Path: "bad.com/pkg"}},
+			},
+		}},
+	}
+	stdlib := &osv.Entry{
+		ID: "GO-2000-0003",
+		Affected: []osv.Affected{{
+			Module: osv.Module{Path: osv.GoStdModulePath},
+			Ranges: []osv.Range{{
+				Type:   osv.RangeTypeSemver,
+				Events: []osv.RangeEvent{{Introduced: "0"}, {Fixed: "1.19.4"}},
+			}},
+			EcosystemSpecific: osv.EcosystemSpecific{
+				Packages: []osv.Package{{
+					Path:    "net/http",
+					Symbols: []string{"Serve"},
+				}},
+			},
+		}},
+	}
+	client, err := NewInMemoryClient([]*osv.Entry{withSymbols, wholePackage, stdlib})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		name              string
+		mod, pkg, version string
+		want              []*SymbolVuln
+	}{
+		{
+			name: "symbols and whole package",
+			mod:  "bad.com", pkg: "bad.com/pkg", version: "v1.0.0",
+			want: []*SymbolVuln{
+				{Vuln: Vuln{ID: "GO-1999-0001", Details: "bad symbols"}, Symbols: []string{"F", "G", "T.M"}},
+				{Vuln: Vuln{ID: "GO-1999-0002", Details: "bad package"}},
+			},
+		},
+		{
+			name: "fixed",
+			mod:  "bad.com", pkg: "bad.com/pkg", version: "v1.2.3",
+			want: []*SymbolVuln{
+				{Vuln: Vuln{ID: "GO-1999-0002", Details: "bad package"}},
+			},
+		},
+		{
+			name: "other package",
+			mod:  "bad.com", pkg: "bad.com/other", version: "v1.0.0",
+			want: []*SymbolVuln{
+				{Vuln: Vuln{ID: "GO-1999-0001", Details: "bad symbols"}, Symbols: []string{"H"}},
+			},
+		},
+		{
+			name: "stdlib",
+			mod:  "std", pkg: "net/http", version: "go1.19.3",
+			want: []*SymbolVuln{
+				{Vuln: Vuln{ID: "GO-2000-0003"}, Symbols: []string{"Serve"}},
+			},
+		},
+		{
+			name: "stdlib pseudoversion",
+			mod:  "std", pkg: "net/http", version: "v0.0.0-20230104211531-bae7d772e800",
+			want: nil,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := SymbolVulnsForPackage(context.Background(), test.mod, test.version, test.pkg, client)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestCollectRangePairs(t *testing.T) {
 	in := osv.Affected{
 		Module: osv.Module{Path: "github.com/a/b"},
@@ -325,7 +325,14 @@ func (f *Fetcher) fetchAndInsertModule(ctx context.Context, modulePath, requeste
 	go func() {
 		defer wg.Done()
 		start := time.Now()
-		fr := fetch.FetchModule(ctx, modulePath, requestedVersion, moduleGetter)
+		lm := fetch.FetchLazyModule(ctx, modulePath, requestedVersion, moduleGetter)
+		if experiment.IsActive(ctx, internal.ExperimentTypeCheck) {
+			lm.EnableTypeChecking([]fetch.ModuleGetter{moduleGetter})
+		}
+		if experiment.IsActive(ctx, internal.ExperimentVerifyExamples) {
+			lm.EnableExampleVerification()
+		}
+		fr := lm.FetchResult(ctx)
 		if fr == nil {
 			panic("fetch.FetchModule should never return a nil FetchResult")
 		}
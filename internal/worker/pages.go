@@ -8,11 +8,13 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -24,6 +26,7 @@ import (
 	"golang.org/x/pkgsite/internal/memory"
 	"golang.org/x/pkgsite/internal/middleware"
 	"golang.org/x/pkgsite/internal/postgres"
+	"golang.org/x/pkgsite/internal/queue/pgqueue"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -188,6 +191,61 @@ func (s *Server) doVersionsPage(w http.ResponseWriter, r *http.Request) (err err
 	}
 	return renderPage(ctx, w, page, s.templates[versionsTemplate])
 }
+
+// doStatesPage writes a page listing the module version states that match
+// the filter in the request's query params, with actions to retry, delete or
+// exclude them.
+func (s *Server) doStatesPage(w http.ResponseWriter, r *http.Request) (err error) {
+	defer derrors.Wrap(&err, "doStatesPage")
+	filter, err := parseStatesFilter(r)
+	if err != nil {
+		return &serverError{http.StatusBadRequest, err}
+	}
+	limit := parseIntParam(r, "limit", defaultStatesLimit)
+	states, err := s.db.GetModuleVersionStates(r.Context(), filter, limit)
+	if err != nil {
+		return err
+	}
+	page := struct {
+		Env                   string
+		Status, Error, Prefix string
+		Limit                 int
+		Done                  string
+		States                []*internal.ModuleVersionState
+	}{
+		Env:    env(s.cfg),
+		Status: r.FormValue("status"),
+		Error:  filter.ErrorContains,
+		Prefix: filter.ModulePrefix,
+		Limit:  limit,
+		Done:   r.FormValue("done"),
+		States: states,
+	}
+	return renderPage(r.Context(), w, page, s.templates[statesTemplate])
+}
+
+// defaultStatesLimit is the default number of module version states on the
+// states page, and retried by its bulk retry action.
+const defaultStatesLimit = 100
+
+// parseStatesFilter parses the filter of the states page from the "status",
+// "error" and "prefix" params of r. The "status" param is a comma-separated
+// list of status codes.
+func parseStatesFilter(r *http.Request) (postgres.ModuleVersionStateFilter, error) {
+	filter := postgres.ModuleVersionStateFilter{
+		ErrorContains: r.FormValue("error"),
+		ModulePrefix:  strings.TrimSpace(r.FormValue("prefix")),
+	}
+	for _, f := range strings.FieldsFunc(r.FormValue("status"), func(r rune) bool { return r == ',' || r == ' ' }) {
+		code, err := strconv.Atoi(f)
+		if err != nil {
+			return filter, fmt.Errorf("status is invalid: %q", f)
+		}
+		filter.Statuses = append(filter.Statuses, code)
+	}
+	return filter, nil
+}
+
 func (s *Server) doExcludedPage(w http.ResponseWriter, r *http.Request) (err error) {
 	excluded, err := s.db.GetExcludedPatterns(r.Context())
 	if err != nil {
@@ -203,6 +261,50 @@ func (s *Server) doExcludedPage(w http.ResponseWriter, r *http.Request) (err err
 	return renderPage(r.Context(), w, page, s.templates[excludedTemplate])
 }
 
+func (s *Server) doTasksPage(w http.ResponseWriter, r *http.Request) (err error) {
+	defer derrors.Wrap(&err, "doTasksPage")
+	const pageSize = 50
+	page := struct {
+		Env                      string
+		Supported                bool
+		Running, Pending, Failed []*pgqueue.Task
+	}{
+		Env: env(s.cfg),
+	}
+	q, ok := s.queue.(*pgqueue.Queue)
+	if !ok {
+		return renderPage(r.Context(), w, page, s.templates[tasksTemplate])
+	}
+	page.Supported = true
+	g, ctx := errgroup.WithContext(r.Context())
+	for _, x := range []struct {
+		status pgqueue.Status
+		tasks  *[]*pgqueue.Task
+	}{
+		{pgqueue.StatusRunning, &page.Running},
+		{pgqueue.StatusPending, &page.Pending},
+		{pgqueue.StatusFailed, &page.Failed},
+	} {
+		x := x
+		g.Go(func() error {
+			tasks, err := q.Tasks(ctx, x.status, pageSize)
+			if err != nil {
+				return annotation{err, "error fetching " + string(x.status) + " tasks"}
+			}
+			*x.tasks = tasks
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		var e annotation
+		if errors.As(err, &e) {
+			log.Errorf(ctx, e.msg, err)
+		}
+		return err
+	}
+	return renderPage(ctx, w, page, s.templates[tasksTemplate])
+}
+
 func env(cfg *config.Config) string {
 	e := cfg.DeploymentEnvironment()
 	return strings.ToUpper(e[:1]) + e[1:]
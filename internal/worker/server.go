@@ -47,7 +47,7 @@ import (
 // Server can be installed to serve the go discovery worker.
 type Server struct {
 	cfg            *config.Config
-	indexClient    *index.Client
+	indexClient    index.Source
 	proxyClient    *proxy.Client
 	sourceClient   *source.Client
 	cache          *cache.Cache
@@ -65,7 +65,7 @@ type Server struct {
 // ServerConfig contains everything needed by a Server.
 type ServerConfig struct {
 	DB                   *postgres.DB
-	IndexClient          *index.Client
+	IndexClient          index.Source
 	ProxyClient          *proxy.Client
 	SourceClient         *source.Client
 	RedisCacheClient     *redis.Client
@@ -80,13 +80,15 @@ const (
 	indexTemplate    = "index.tmpl"
 	versionsTemplate = "versions.tmpl"
 	excludedTemplate = "excluded.tmpl"
+	tasksTemplate    = "tasks.tmpl"
+	statesTemplate   = "states.tmpl"
 )
 
 // NewServer creates a new Server with the given dependencies.
 func NewServer(cfg *config.Config, scfg ServerConfig) (_ *Server, err error) {
 	defer derrors.Wrap(&err, "NewServer(db, %+v)", scfg)
 	templates := map[string]*template.Template{}
-	for _, templateName := range []string{indexTemplate, versionsTemplate, excludedTemplate} {
+	for _, templateName := range []string{indexTemplate, versionsTemplate, excludedTemplate, tasksTemplate, statesTemplate} {
 		t, err := parseTemplate(cfg, scfg.StaticPath, templateName)
 		if err != nil {
 			return nil, err
@@ -275,6 +277,14 @@ func (s *Server) DebugHandler() (http.Handler, error) {
 	// Serve a list of excluded prefixes and module versions.
 	mux.Handle("/excluded", http.HandlerFunc(s.handleHTMLPage(s.doExcludedPage)))
 
+	// Serve an HTML page listing the pending and failed tasks of the queue,
+	// if it is backed by Postgres.
+	mux.Handle("/tasks", http.HandlerFunc(s.handleHTMLPage(s.doTasksPage)))
+
+	// Serve an HTML page listing module versions by status and error, with
+	// actions to requeue, delete or exclude them, and to retry them in bulk.
+	mux.Handle("/states", s.errorHandler(s.handleStates))
+
 	return mux, nil
 }
 
@@ -509,18 +519,23 @@ func (s *Server) handleEnqueue(w http.ResponseWriter, r *http.Request) (err erro
 	span.Annotate([]trace.Attribute{trace.Int64Attribute("modules to fetch", int64(len(modules)))}, "processed limit")
 	w.Header().Set("Content-Type", "text/plain")
 	log.Infof(ctx, "Scheduling modules to be fetched: queuing %d modules", len(modules))
+	nEnqueued, nErrors := s.enqueueModules(ctx, modules, suffixParam)
+	log.Infof(ctx, "Successfully scheduled modules to be fetched: %d modules enqueued, %d errors", nEnqueued, nErrors)
+	return nil
+}
 
+// enqueueModules enqueues the module versions for processing, appending
+// suffix to the task names to avoid deduplication. It returns the number of
+// module versions that were enqueued, and the number that couldn't be.
+func (s *Server) enqueueModules(ctx context.Context, modules []*internal.ModuleVersionState, suffix string) (nEnqueued, nErrors int) {
 	// Enqueue concurrently, because sequentially takes a while.
 	const concurrentEnqueues = 10
-	var (
-		mu                 sync.Mutex
-		nEnqueued, nErrors int
-	)
+	var mu sync.Mutex
 	sem := make(chan struct{}, concurrentEnqueues)
 	for _, m := range modules {
 		m := m
 		opts := queue.Options{
-			Suffix:            suffixParam,
+			Suffix:            suffix,
 			DisableProxyFetch: shouldDisableProxyFetch(m),
 			Source:            queue.SourceWorkerValue,
 		}
@@ -534,7 +549,7 @@ func (s *Server) handleEnqueue(w http.ResponseWriter, r *http.Request) (err erro
 				nErrors++
 			} else if enqueued {
 				nEnqueued++
-				recordEnqueue(r.Context(), m.Status)
+				recordEnqueue(ctx, m.Status)
 			}
 			mu.Unlock()
 		}()
@@ -543,8 +558,7 @@ func (s *Server) handleEnqueue(w http.ResponseWriter, r *http.Request) (err erro
 	for i := 0; i < concurrentEnqueues; i++ {
 		sem <- struct{}{}
 	}
-	log.Infof(ctx, "Successfully scheduled modules to be fetched: %d modules enqueued, %d errors", nEnqueued, nErrors)
-	return nil
+	return nEnqueued, nErrors
 }
 
 func shouldDisableProxyFetch(m *internal.ModuleVersionState) bool {
@@ -744,6 +758,116 @@ func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
+// handleStates serves the states page for GET requests, and performs the
+// actions on the page for POST requests.
+func (s *Server) handleStates(w http.ResponseWriter, r *http.Request) error {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		return s.doStatesPage(w, r)
+	case http.MethodPost:
+		if err := checkSameOrigin(r); err != nil {
+			return &serverError{http.StatusForbidden, err}
+		}
+		return s.doStatesAction(w, r)
+	default:
+		return &serverError{http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method)}
+	}
+}
+
+// checkSameOrigin returns an error if r was sent by a browser from a page of
+// another site, so that a page elsewhere can't make an administrator's
+// browser post to the states page. Requests from other clients, which send
+// neither Sec-Fetch-Site nor Origin, are allowed.
+func checkSameOrigin(r *http.Request) error {
+	if site := r.Header.Get("Sec-Fetch-Site"); site != "" {
+		if site != "same-origin" && site != "none" {
+			return fmt.Errorf("cross-site request (Sec-Fetch-Site: %s)", site)
+		}
+		return nil
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		if err != nil || u.Host != r.Host {
+			return fmt.Errorf("cross-origin request (Origin: %s)", origin)
+		}
+	}
+	return nil
+}
+
+// doStatesAction performs the action in the "action" param on the module
+// version in the "module" and "version" params, or, for "retry", on the
+// module versions matching the filter of the states page. It then redirects
+// back to the page.
+func (s *Server) doStatesAction(w http.ResponseWriter, r *http.Request) (err error) {
+	action := r.FormValue("action")
+	modulePath, version := r.FormValue("module"), r.FormValue("version")
+	defer derrors.Wrap(&err, "doStatesAction(%q, %q, %q)", action, modulePath, version)
+	ctx := r.Context()
+
+	var done string
+	if action == "retry" {
+		filter, err := parseStatesFilter(r)
+		if err != nil {
+			return &serverError{http.StatusBadRequest, err}
+		}
+		states, err := s.db.GetModuleVersionStates(ctx, filter, parseIntParam(r, "limit", defaultStatesLimit))
+		if err != nil {
+			return err
+		}
+		nEnqueued, nErrors := s.enqueueModules(ctx, states, r.FormValue("suffix"))
+		done = fmt.Sprintf("Retried %d module versions: %d enqueued, %d errors.", len(states), nEnqueued, nErrors)
+	} else {
+		if modulePath == "" || version == "" {
+			return &serverError{http.StatusBadRequest, errors.New("module and version must be specified")}
+		}
+		switch action {
+		case "requeue":
+			mvs, err := s.db.GetModuleVersionState(ctx, modulePath, version)
+			if errors.Is(err, derrors.NotFound) {
+				return &serverError{http.StatusNotFound, err}
+			}
+			if err != nil {
+				return err
+			}
+			if _, nErrors := s.enqueueModules(ctx, []*internal.ModuleVersionState{mvs}, r.FormValue("suffix")); nErrors > 0 {
+				return fmt.Errorf("could not enqueue %s@%s", modulePath, version)
+			}
+			done = fmt.Sprintf("Requeued %s@%s.", modulePath, version)
+		case "delete":
+			// Delete the module's data, if it was fetched, and then its state.
+			// This doesn't stop the version from being processed again if it
+			// is enqueued again, for instance by the poller; "exclude" does.
+			if err := s.db.DeleteModule(ctx, modulePath, version); err != nil {
+				return err
+			}
+			if err := s.db.DeleteModuleVersionState(ctx, modulePath, version); err != nil {
+				return err
+			}
+			done = fmt.Sprintf("Deleted %s@%s.", modulePath, version)
+		case "exclude":
+			reason := r.FormValue("reason")
+			if reason == "" {
+				return &serverError{http.StatusBadRequest, errors.New("a reason for the exclusion must be specified")}
+			}
+			pattern := modulePath + "@" + version
+			if err := s.db.InsertExcludedPattern(ctx, pattern, "worker", reason); err != nil {
+				return err
+			}
+			done = fmt.Sprintf("Excluded %s.", pattern)
+		default:
+			return &serverError{http.StatusBadRequest, fmt.Errorf("unknown action %q", action)}
+		}
+	}
+	log.Infof(ctx, "states page: %s", done)
+
+	// The forms on the page post to its URL, so its query holds the filter.
+	q := r.URL.Query()
+	q.Set("done", done)
+	w.Header().Set("Location", "?"+q.Encode())
+	w.WriteHeader(http.StatusSeeOther)
+	return nil
+}
+
 // Consider a module version for cleaning only if it is older than this.
 const cleanDays = 7
 
@@ -263,6 +263,56 @@ func TestParseIntParam(t *testing.T) {
 	}
 }
 
+func TestParseStatesFilter(t *testing.T) {
+	for _, test := range []struct {
+		query   string
+		want    postgres.ModuleVersionStateFilter
+		wantErr bool
+	}{
+		{"", postgres.ModuleVersionStateFilter{}, false},
+		{"status=500", postgres.ModuleVersionStateFilter{Statuses: []int{500}}, false},
+		{"status=500,+520", postgres.ModuleVersionStateFilter{Statuses: []int{500, 520}}, false},
+		{"error=timed+out&prefix=+golang.org/x/+", postgres.ModuleVersionStateFilter{ErrorContains: "timed out", ModulePrefix: "golang.org/x/"}, false},
+		{"status=5xx", postgres.ModuleVersionStateFilter{}, true},
+	} {
+		got, err := parseStatesFilter(httptest.NewRequest("GET", "/states?"+test.query, nil))
+		if (err != nil) != test.wantErr {
+			t.Errorf("%q: got error %v, want error %t", test.query, err, test.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("%q: mismatch (-want, +got):\n%s", test.query, diff)
+		}
+	}
+}
+
+func TestCheckSameOrigin(t *testing.T) {
+	for _, test := range []struct {
+		header, value string
+		wantErr       bool
+	}{
+		{"", "", false},
+		{"Sec-Fetch-Site", "same-origin", false},
+		{"Sec-Fetch-Site", "none", false},
+		{"Sec-Fetch-Site", "same-site", true},
+		{"Sec-Fetch-Site", "cross-site", true},
+		{"Origin", "http://example.com", false},
+		{"Origin", "http://evil.com", true},
+		{"Origin", "null", true},
+	} {
+		r := httptest.NewRequest("POST", "http://example.com/states", nil)
+		if test.header != "" {
+			r.Header.Set(test.header, test.value)
+		}
+		if err := checkSameOrigin(r); (err != nil) != test.wantErr {
+			t.Errorf("%s: %q: got error %v, want error %t", test.header, test.value, err, test.wantErr)
+		}
+	}
+}
+
 func TestParseModulePathAndVersion(t *testing.T) {
 	testCases := []struct {
 		path      string
@@ -0,0 +1,10 @@
+-- Copyright 2023 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+ALTER TABLE documentation_symbols DROP COLUMN deprecated;
+ALTER TABLE documentation_symbols DROP COLUMN replacement;
+
+END;
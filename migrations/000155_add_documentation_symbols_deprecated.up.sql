@@ -0,0 +1,13 @@
+-- Copyright 2023 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+-- deprecated reports whether the doc comment of the symbol in the
+-- documentation has a "Deprecated:" paragraph, and replacement is the symbol
+-- that paragraph suggests using instead, if any.
+ALTER TABLE documentation_symbols ADD COLUMN deprecated BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE documentation_symbols ADD COLUMN replacement TEXT NOT NULL DEFAULT '';
+
+END;
@@ -0,0 +1,30 @@
+-- Copyright 2023 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE queue_tasks (
+    task_id TEXT NOT NULL PRIMARY KEY,
+    module_path TEXT NOT NULL,
+    version TEXT NOT NULL,
+    disable_proxy_fetch BOOLEAN NOT NULL DEFAULT FALSE,
+    source TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'running', 'done', 'failed')),
+    num_attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_status INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+COMMENT ON TABLE queue_tasks IS
+'TABLE queue_tasks holds the fetch tasks of the Postgres-backed worker queue (internal/queue/pgqueue).
+A task is pending until a worker claims it, running while it is processed, and then done, pending again
+for a retry after next_attempt_at, or failed once it has run out of attempts.
+Rows for done and failed tasks are kept for a while so that tasks with the same ID are de-duplicated.';
+
+CREATE INDEX idx_queue_tasks_status_next_attempt_at ON queue_tasks (status, next_attempt_at);
+
+END;
@@ -0,0 +1,11 @@
+-- Copyright 2023 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+ALTER TABLE modules DROP COLUMN requirements_recorded;
+
+DROP TABLE module_requirements;
+
+END;
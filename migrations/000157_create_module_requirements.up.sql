@@ -0,0 +1,23 @@
+-- Copyright 2023 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE module_requirements (
+    module_id INTEGER NOT NULL REFERENCES modules(id) ON DELETE CASCADE,
+    required_path TEXT NOT NULL,
+    required_version TEXT NOT NULL,
+    indirect BOOLEAN NOT NULL,
+    PRIMARY KEY (module_id, required_path, required_version)
+);
+
+COMMENT ON TABLE module_requirements IS
+'TABLE module_requirements contains the requirements in the go.mod file of each module version.';
+
+ALTER TABLE modules ADD COLUMN requirements_recorded BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN modules.requirements_recorded IS
+'COLUMN requirements_recorded reports whether the requirements of the module version are in the module_requirements table. It is false for modules processed before that table was created.';
+
+END;
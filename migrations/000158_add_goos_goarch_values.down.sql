@@ -0,0 +1,6 @@
+-- Copyright 2023 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+-- Values cannot be removed from an enum type, and documentation for the
+-- added values may already be stored, so they are kept.
@@ -0,0 +1,19 @@
+-- Copyright 2023 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE documentation_implementations (
+    documentation_id bigint NOT NULL REFERENCES documentation(id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    type_package_path TEXT NOT NULL,
+    interface TEXT NOT NULL,
+    interface_package_path TEXT NOT NULL,
+    PRIMARY KEY (documentation_id, type, type_package_path, interface, interface_package_path)
+);
+
+COMMENT ON TABLE documentation_implementations IS
+'TABLE documentation_implementations contains, for a given row in the documentation table, the interfaces that the types of the package implement and the types of the module that implement the interfaces of the package, as determined by type-checking.';
+
+END;
@@ -0,0 +1,17 @@
+-- Copyright 2023 The Go Authors. All rights reserved.
+-- Use of this source code is governed by a BSD-style
+-- license that can be found in the LICENSE file.
+
+BEGIN;
+
+CREATE TABLE documentation_example_results (
+    documentation_id bigint NOT NULL REFERENCES documentation(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    passed BOOLEAN NOT NULL,
+    PRIMARY KEY (documentation_id, name)
+);
+
+COMMENT ON TABLE documentation_example_results IS
+'TABLE documentation_example_results contains, for a given row in the documentation table, whether the output of each example function of the package that was run matched its output comment.';
+
+END;
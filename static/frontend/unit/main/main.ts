@@ -139,6 +139,7 @@ window.addEventListener('hashchange', () => openDeprecatedSymbol());
  */
 document.querySelectorAll('.js-buildContextSelect').forEach(el => {
   el.addEventListener('change', e => {
-    window.location.search = `?GOOS=${(e.target as HTMLSelectElement).value}`;
+    const [goos, goarch] = (e.target as HTMLSelectElement).value.split('/');
+    window.location.search = `?GOOS=${goos}&GOARCH=${goarch}`;
   });
 });